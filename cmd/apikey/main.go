@@ -0,0 +1,72 @@
+// Command apikey creates an API key and prints it with its secret as JSON, eg to bootstrap the first admin key
+// before keys can be managed through the admin API. It exits with status 1 when the key cannot be created.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	"github.com/jennwah/crypto-assignment/internal/pkg/postgresql"
+	walletrepo "github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	walletsrv "github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run creates the key and returns the exit status, so deferred cleanups run before exiting.
+func run() int {
+	name := flag.String("name", "", "name of the service the key is for")
+	scopes := flag.String("scopes", "", "comma separated scopes, eg wallet:deposit,wallet:withdraw or admin")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("failed loading application config", slog.Any("error", err))
+		return 1
+	}
+
+	db, err := postgresql.New(cfg.Postgres)
+	if err != nil {
+		logger.Error("failed initializing connection with database", slog.Any("error", err))
+		return 1
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// API keys are stored in postgres only, they do not need the idempotency cache
+	walletService := walletsrv.New(walletrepo.New(db.DB, nil, logger))
+
+	key, secret, err := walletService.CreateAPIKey(ctx, cfg.APIKeySecret, *name, strings.Split(*scopes, ","))
+	if err != nil {
+		logger.Error("create api key err", slog.Any("error", err))
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(struct {
+		ID     string   `json:"id"`
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+		Secret string   `json:"secret"`
+	}{ID: key.ID, Name: key.Name, Scopes: key.Scopes, Secret: secret})
+	if err != nil {
+		logger.Error("create api key output err", slog.Any("error", err))
+		return 1
+	}
+
+	return 0
+}
@@ -12,11 +12,27 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jennwah/crypto-assignment/internal/config"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jennwah/crypto-assignment/internal/handler"
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
 	"github.com/jennwah/crypto-assignment/internal/pkg/postgresql"
+	"github.com/jennwah/crypto-assignment/internal/pkg/publisher"
 	"github.com/jennwah/crypto-assignment/internal/pkg/redis"
+	"github.com/jennwah/crypto-assignment/internal/pkg/webhook"
+	walletrepo "github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	walletsrv "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/jennwah/crypto-assignment/internal/worker"
 )
 
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 JWT of the user as "Bearer {token}", its sub claim is the user id
+
+// @securityDefinitions.apikey  APIKeyAuth
+// @in                          header
+// @name                        X-API-KEY-ID
+// @description                 API key id of an internal service, with the X-API-TIMESTAMP and X-API-SIGNATURE headers signing the request. Wallet requests act on behalf of the X-USER-ID user
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
@@ -37,14 +53,57 @@ func main() {
 
 	router := gin.Default()
 	router.Use(gin.Recovery())
+	// client IPs, which requests are rate limited by, are only read from X-Forwarded-For when sent by trusted proxies
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(fmt.Errorf("invalid trusted proxies: %w", err))
+	}
+
+	// a nil verifier trusts the X-USER-ID header
+	var verifier *auth.Verifier
+	if cfg.TrustUserIDHeader {
+		logger.Warn("X-USER-ID header trusted and admin routes open, never enable this outside local development")
+	} else {
+		verifier, err = auth.NewVerifier(context.Background(), cfg.Auth)
+		if err != nil {
+			panic(fmt.Errorf("failed initializing jwt verifier: %w", err))
+		}
+	}
+
+	switch {
+	case cfg.APIKeySecret == "":
+		logger.Warn("api keys are disabled without X_API_KEY_SECRET")
+	case len(cfg.APIKeySecret) < domainwallet.MinAPIKeyServerSecretLength:
+		panic(fmt.Errorf("X_API_KEY_SECRET shorter than %d characters", domainwallet.MinAPIKeyServerSecretLength))
+	}
 
-	handler.SetupHandlers(router, logger, db.DB, cache)
+	if err := handler.SetupHandlers(router, logger, db.DB, cache, cfg, verifier); err != nil {
+		panic(fmt.Errorf("failed setting up handlers: %w", err))
+	}
 
 	srv := &http.Server{
 		Addr:    ":8080",
 		Handler: router.Handler(),
 	}
 
+	// background workers run until shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	walletService := walletsrv.New(walletrepo.New(db.DB, cache, logger))
+	go worker.NewHoldSweeper(logger, walletService, cfg.HoldSweepInterval).Run(workerCtx)
+	go worker.NewBalanceSnapshotter(logger, walletService, cfg.BalanceSnapshotInterval).Run(workerCtx)
+	go worker.NewReconciler(logger, walletService, cfg.ReconcileInterval, cfg.ReconcileFreeze).Run(workerCtx)
+
+	// events go to the stream for downstream services, and to pub/sub for the instances streaming wallets live
+	eventPublisher := publisher.NewFanoutPublisher(
+		publisher.NewRedisStreamPublisher(cache, cfg.EventStream, cfg.EventStreamMaxLen),
+		publisher.NewRedisPubSubPublisher(cache),
+	)
+	go worker.NewOutboxRelay(logger, walletService, eventPublisher, cfg.OutboxRelayInterval).Run(workerCtx)
+
+	webhookSender := webhook.NewHTTPSender(cfg.WebhookTimeout)
+	go worker.NewWebhookDispatcher(logger, walletService, webhookSender, cfg.WebhookDispatchInterval).Run(workerCtx)
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			panic(fmt.Errorf("listen: %w", err))
@@ -60,6 +119,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutdown Server ...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
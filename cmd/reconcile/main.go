@@ -0,0 +1,68 @@
+// Command reconcile recomputes every wallet's balances from its transactions and reports the balances that do not match
+// the stored ones as JSON. It exits with status 1 when any balance is mismatched, and 2 when the reconciliation fails.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	"github.com/jennwah/crypto-assignment/internal/pkg/postgresql"
+	walletrepo "github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	walletsrv "github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run reconciles and returns the exit status, so deferred cleanups run before exiting.
+func run() int {
+	freeze := flag.Bool("freeze", false, "freeze the wallets failing reconciliation")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("failed loading application config", slog.Any("error", err))
+		return 2
+	}
+
+	db, err := postgresql.New(cfg.Postgres)
+	if err != nil {
+		logger.Error("failed initializing connection with database", slog.Any("error", err))
+		return 2
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Reconciliation reads and freezes wallets through postgres only, it does not need the idempotency cache
+	walletService := walletsrv.New(walletrepo.New(db.DB, nil, logger))
+
+	reconciliation, err := walletService.Reconcile(ctx, *freeze)
+	if err != nil {
+		logger.Error("reconcile err", slog.Any("error", err))
+		return 2
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(reconciliation); err != nil {
+		logger.Error("reconcile output err", slog.Any("error", err))
+		return 2
+	}
+
+	if reconciliation.MismatchCount > 0 {
+		return 1
+	}
+
+	return 0
+}
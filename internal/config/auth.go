@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type Auth struct {
+	// JWKSURL or JWKSFile is the JSON Web Key Set verifying the JWTs of wallet requests, the URL takes precedence
+	JWKSURL             string        `envconfig:"X_JWT_JWKS_URL"`
+	JWKSFile            string        `envconfig:"X_JWT_JWKS_FILE"`
+	JWKSRefreshInterval time.Duration `envconfig:"X_JWT_JWKS_REFRESH_INTERVAL" default:"5m"`
+	JWTIssuer           string        `envconfig:"X_JWT_ISSUER"`
+	JWTAudience         string        `envconfig:"X_JWT_AUDIENCE"`
+	// JWTLeeway tolerates clock skew with the issuer when checking expiry
+	JWTLeeway time.Duration `envconfig:"X_JWT_LEEWAY" default:"30s"`
+	// APIKeySecret derives the secrets of API keys, at least 32 characters, API keys are disabled without it
+	APIKeySecret string `envconfig:"X_API_KEY_SECRET"`
+	// APISignatureWindow is how far from the server's clock the timestamp of API key signed requests may be
+	APISignatureWindow time.Duration `envconfig:"X_API_SIGNATURE_WINDOW" default:"5m"`
+	// TrustUserIDHeader authenticates wallet requests by the X-USER-ID header alone and admin requests without API keys,
+	// for local development only
+	TrustUserIDHeader bool `envconfig:"X_AUTH_TRUST_USER_ID_HEADER" default:"false"`
+}
@@ -13,6 +13,11 @@ import (
 type Config struct {
 	Postgres
 	Redis
+	Worker
+	Events
+	Auth
+	RateLimit
+	Fraud
 }
 
 func LoadConfig() (Config, error) {
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type Events struct {
+	EventStream       string `envconfig:"X_EVENT_STREAM"         default:"wallet-events"`
+	EventStreamMaxLen int64  `envconfig:"X_EVENT_STREAM_MAX_LEN" default:"1000000"`
+	// WebhookTimeout bounds each webhook delivery attempt, including reading the response
+	WebhookTimeout time.Duration `envconfig:"X_WEBHOOK_TIMEOUT" default:"10s"`
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// Fraud configures the rules screening withdrawals and transfers, each deciding "allow", "review" or "block".
+// A rule with an empty or zero threshold is off.
+type Fraud struct {
+	// FraudNewRecipientAmounts flags first transfers to a recipient above an amount, "," separated "{asset}:{minor units}"
+	FraudNewRecipientAmounts  map[string]uint64 `envconfig:"X_FRAUD_NEW_RECIPIENT_AMOUNTS"  default:"USD:100000"`
+	FraudNewRecipientDecision string            `envconfig:"X_FRAUD_NEW_RECIPIENT_DECISION" default:"review"`
+	// FraudMaxHourlyWithdrawals flags withdrawals beyond this many within an hour
+	FraudMaxHourlyWithdrawals       int    `envconfig:"X_FRAUD_MAX_HOURLY_WITHDRAWALS"       default:"10"`
+	FraudWithdrawalVelocityDecision string `envconfig:"X_FRAUD_WITHDRAWAL_VELOCITY_DECISION" default:"block"`
+	// FraudNewWalletAge flags withdrawals of everything available from wallets younger than this
+	FraudNewWalletAge           time.Duration `envconfig:"X_FRAUD_NEW_WALLET_AGE"            default:"24h"`
+	FraudNewWalletDrainDecision string        `envconfig:"X_FRAUD_NEW_WALLET_DRAIN_DECISION" default:"review"`
+}
@@ -0,0 +1,14 @@
+package config
+
+// RateLimit limits are "{rate}/{period}", eg "100/1m", an empty limit allows every request.
+type RateLimit struct {
+	RateLimitIP     string `envconfig:"X_RATE_LIMIT_IP"      default:"600/1m"`
+	RateLimitUser   string `envconfig:"X_RATE_LIMIT_USER"    default:"300/1m"`
+	RateLimitAPIKey string `envconfig:"X_RATE_LIMIT_API_KEY" default:"3000/1m"`
+	// RateLimitRoutes limits every user and API key per route, ";" separated "{method} {gin route path}={rate}/{period}"
+	RateLimitRoutes string `envconfig:"X_RATE_LIMIT_ROUTES" default:"POST /api/v1/wallet/transfer=30/1m;POST /api/v1/wallet/withdraw=30/1m"`
+	// TrustedProxies are the proxies X-Forwarded-For is read from for client IPs, IPs or CIDRs, none by default
+	TrustedProxies []string `envconfig:"X_TRUSTED_PROXIES"`
+	// RateLimitFailOpen allows requests when Redis is unavailable, instead of rejecting them with 503
+	RateLimitFailOpen bool `envconfig:"X_RATE_LIMIT_FAIL_OPEN" default:"true"`
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type Worker struct {
+	HoldSweepInterval       time.Duration `envconfig:"X_HOLD_SWEEP_INTERVAL"       default:"1m"`
+	BalanceSnapshotInterval time.Duration `envconfig:"X_BALANCE_SNAPSHOT_INTERVAL" default:"24h"`
+	ReconcileInterval       time.Duration `envconfig:"X_RECONCILE_INTERVAL"        default:"24h"`
+	OutboxRelayInterval     time.Duration `envconfig:"X_OUTBOX_RELAY_INTERVAL"     default:"1s"`
+	WebhookDispatchInterval time.Duration `envconfig:"X_WEBHOOK_DISPATCH_INTERVAL" default:"1s"`
+	// ReconcileFreeze freezes the wallets failing a scheduled reconciliation
+	ReconcileFreeze bool `envconfig:"X_RECONCILE_FREEZE" default:"false"`
+}
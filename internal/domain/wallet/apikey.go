@@ -0,0 +1,166 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAPIKey              = errors.New("invalid api key")
+	ErrAPIKeyNotFound             = errors.New("api key not found")
+	ErrAPIKeyRevoked              = errors.New("api key revoked")
+	ErrAPIKeysDisabled            = errors.New("api keys disabled")
+	ErrInvalidAPIRequestSignature = errors.New("invalid api request signature")
+	ErrStaleAPIRequest            = errors.New("api request timestamp outside of the replay window")
+)
+
+// Scopes of API keys, each allowing the routes of a group.
+const (
+	ScopeWalletRead     = "wallet:read"
+	ScopeWalletDeposit  = "wallet:deposit"
+	ScopeWalletWithdraw = "wallet:withdraw"
+	ScopeWalletTransfer = "wallet:transfer"
+	ScopeAdmin          = "admin"
+)
+
+const (
+	// MinAPIKeyServerSecretLength is the shortest server secret API key secrets are derived from.
+	MinAPIKeyServerSecretLength = 32
+	maxAPIKeyNameLength         = 100
+)
+
+// APIKeyScopes are the scopes API keys can be granted.
+var APIKeyScopes = []string{ScopeWalletRead, ScopeWalletDeposit, ScopeWalletWithdraw, ScopeWalletTransfer, ScopeAdmin}
+
+// UserScopes are the scopes of users authenticated as themselves, every wallet scope of their own wallet.
+var UserScopes = []string{ScopeWalletRead, ScopeWalletDeposit, ScopeWalletWithdraw, ScopeWalletTransfer}
+
+// APIKey authenticates an internal service, which signs its requests with the key's secret.
+// Secrets are derived from the key id with the server secret and never stored, only their hash is,
+// so the database alone does not allow signing requests.
+type APIKey struct {
+	ID         string     `db:"id"`
+	Name       string     `db:"name"`
+	SecretHash string     `db:"secret_hash"`
+	Scopes     []string   `db:"-"`
+	CreatedAt  time.Time  `db:"created_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+// NewAPIKey validates a new API key, a name and at least one known scope, duplicates removed,
+// and returns it with its secret, which cannot be retrieved again.
+func NewAPIKey(id, name string, scopes []string, serverSecret string) (APIKey, string, error) {
+	if len(serverSecret) < MinAPIKeyServerSecretLength {
+		return APIKey{}, "", ErrAPIKeysDisabled
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return APIKey{}, "", fmt.Errorf("name of 1 to %d characters required: %w", maxAPIKeyNameLength, ErrInvalidAPIKey)
+	}
+
+	if len(scopes) == 0 {
+		return APIKey{}, "", fmt.Errorf("no scopes: %w", ErrInvalidAPIKey)
+	}
+
+	keyScopes := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return APIKey{}, "", fmt.Errorf("scope %q: %w", scope, ErrInvalidAPIKey)
+		}
+
+		if !slices.Contains(keyScopes, scope) {
+			keyScopes = append(keyScopes, scope)
+		}
+	}
+
+	secret := DeriveAPIKeySecret(serverSecret, id)
+	return APIKey{ID: id, Name: name, SecretHash: HashAPIKeySecret(secret), Scopes: keyScopes}, secret, nil
+}
+
+// DeriveAPIKeySecret returns the secret of the key id, the hex HMAC-SHA256 of the id keyed with the server secret.
+func DeriveAPIKeySecret(serverSecret, keyID string) string {
+	mac := hmac.New(sha256.New, []byte(serverSecret))
+	mac.Write([]byte("api-key:" + keyID))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashAPIKeySecret returns the hex SHA-256 of the secret, as stored.
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Secret returns the key's secret derived with the server secret, which fails when the key was issued with another one.
+func (k APIKey) Secret(serverSecret string) (string, error) {
+	if len(serverSecret) < MinAPIKeyServerSecretLength {
+		return "", ErrAPIKeysDisabled
+	}
+
+	if k.RevokedAt != nil {
+		return "", fmt.Errorf("api key %s: %w", k.ID, ErrAPIKeyRevoked)
+	}
+
+	secret := DeriveAPIKeySecret(serverSecret, k.ID)
+	if !hmac.Equal([]byte(HashAPIKeySecret(secret)), []byte(k.SecretHash)) {
+		return "", fmt.Errorf("api key %s issued with another server secret: %w", k.ID, ErrAPIKeyNotFound)
+	}
+
+	return secret, nil
+}
+
+// HasScope reports whether the key was granted the scope.
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// SignedAPIRequest is a request signed with an API key's secret. Timestamp is in unix seconds, and Signature is
+// "sha256=" followed by the hex HMAC-SHA256 of "{method}\n{path}\n{timestamp}\n{hex sha256 of body}", path including its query.
+type SignedAPIRequest struct {
+	KeyID     string
+	Method    string
+	Path      string
+	Timestamp string
+	Signature string
+	Body      []byte
+}
+
+// SignAPIRequest returns the signature of the request sent at timestamp with the key's secret.
+func SignAPIRequest(secret, method, path string, timestamp time.Time, body []byte) string {
+	bodySum := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + strconv.FormatInt(timestamp.Unix(), 10) + "\n"))
+	mac.Write([]byte(hex.EncodeToString(bodySum[:])))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the request was signed with secret less than window away from now, either way, so captured requests
+// cannot be replayed once the window passed.
+func (r SignedAPIRequest) Verify(secret string, now time.Time, window time.Duration) error {
+	unix, err := strconv.ParseInt(r.Timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp %q: %w", r.Timestamp, ErrInvalidAPIRequestSignature)
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if timestamp.Before(now.Add(-window)) || timestamp.After(now.Add(window)) {
+		return fmt.Errorf("timestamp %s: %w", timestamp.UTC().Format(time.RFC3339), ErrStaleAPIRequest)
+	}
+
+	expected := SignAPIRequest(secret, r.Method, r.Path, timestamp, r.Body)
+	if !hmac.Equal([]byte(expected), []byte(r.Signature)) {
+		return ErrInvalidAPIRequestSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+const (
+	apiKeyServerSecret = "server-secret-0123456789abcdefghij"
+	apiKeyID           = "5c8a3c35-0e5e-4d6b-9a51-3f0f7c1b9d2e"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		keyName        string
+		scopes         []string
+		serverSecret   string
+		expectedScopes []string
+		expectedErr    error
+	}{
+		{
+			name:           "Valid key with duplicate scopes",
+			keyName:        " payment-gateway ",
+			scopes:         []string{wallet.ScopeWalletDeposit, wallet.ScopeWalletWithdraw, wallet.ScopeWalletDeposit},
+			serverSecret:   apiKeyServerSecret,
+			expectedScopes: []string{wallet.ScopeWalletDeposit, wallet.ScopeWalletWithdraw},
+		},
+		{
+			name:         "No name",
+			keyName:      " ",
+			scopes:       []string{wallet.ScopeAdmin},
+			serverSecret: apiKeyServerSecret,
+			expectedErr:  wallet.ErrInvalidAPIKey,
+		},
+		{
+			name:         "No scopes",
+			keyName:      "back-office",
+			serverSecret: apiKeyServerSecret,
+			expectedErr:  wallet.ErrInvalidAPIKey,
+		},
+		{
+			name:         "Unknown scope",
+			keyName:      "back-office",
+			scopes:       []string{"wallet:write"},
+			serverSecret: apiKeyServerSecret,
+			expectedErr:  wallet.ErrInvalidAPIKey,
+		},
+		{
+			name:        "No server secret",
+			keyName:     "back-office",
+			scopes:      []string{wallet.ScopeAdmin},
+			expectedErr: wallet.ErrAPIKeysDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, secret, err := wallet.NewAPIKey(apiKeyID, tt.keyName, tt.scopes, tt.serverSecret)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, apiKeyID, key.ID)
+			assert.Equal(t, "payment-gateway", key.Name)
+			assert.Equal(t, tt.expectedScopes, key.Scopes)
+			assert.Equal(t, wallet.HashAPIKeySecret(secret), key.SecretHash)
+			assert.NotContains(t, key.SecretHash, secret)
+		})
+	}
+}
+
+func TestAPIKeySecret(t *testing.T) {
+	key, secret, err := wallet.NewAPIKey(apiKeyID, "back-office", []string{wallet.ScopeAdmin}, apiKeyServerSecret)
+	require.NoError(t, err)
+
+	revokedAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	revoked := key
+	revoked.RevokedAt = &revokedAt
+
+	tests := []struct {
+		name         string
+		key          wallet.APIKey
+		serverSecret string
+		expectedErr  error
+	}{
+		{
+			name:         "Secret derived again",
+			key:          key,
+			serverSecret: apiKeyServerSecret,
+		},
+		{
+			name:         "Revoked key",
+			key:          revoked,
+			serverSecret: apiKeyServerSecret,
+			expectedErr:  wallet.ErrAPIKeyRevoked,
+		},
+		{
+			name:         "Issued with another server secret",
+			key:          key,
+			serverSecret: "another-server-secret-0123456789ab",
+			expectedErr:  wallet.ErrAPIKeyNotFound,
+		},
+		{
+			name:        "No server secret",
+			key:         key,
+			expectedErr: wallet.ErrAPIKeysDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.key.Secret(tt.serverSecret)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, secret, got)
+		})
+	}
+}
+
+func TestSignedAPIRequestVerify(t *testing.T) {
+	secret := wallet.DeriveAPIKeySecret(apiKeyServerSecret, apiKeyID)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"amount":100}`)
+	signed := func(method, path string, at time.Time, body []byte) wallet.SignedAPIRequest {
+		return wallet.SignedAPIRequest{
+			KeyID:     apiKeyID,
+			Method:    method,
+			Path:      path,
+			Timestamp: "1792238400",
+			Signature: wallet.SignAPIRequest(secret, method, path, at, body),
+			Body:      body,
+		}
+	}
+	signedAt := time.Unix(1792238400, 0)
+
+	tests := []struct {
+		name        string
+		req         wallet.SignedAPIRequest
+		now         time.Time
+		expectedErr error
+	}{
+		{
+			name: "Valid signature",
+			req:  signed("POST", "/api/v1/wallet/deposit", signedAt, body),
+			now:  signedAt.Add(time.Minute),
+		},
+		{
+			name: "Body changed",
+			req: func() wallet.SignedAPIRequest {
+				req := signed("POST", "/api/v1/wallet/deposit", signedAt, body)
+				req.Body = []byte(`{"amount":1000}`)
+				return req
+			}(),
+			now:         signedAt,
+			expectedErr: wallet.ErrInvalidAPIRequestSignature,
+		},
+		{
+			name: "Path changed",
+			req: func() wallet.SignedAPIRequest {
+				req := signed("POST", "/api/v1/wallet/deposit", signedAt, body)
+				req.Path = "/api/v1/wallet/withdraw"
+				return req
+			}(),
+			now:         signedAt,
+			expectedErr: wallet.ErrInvalidAPIRequestSignature,
+		},
+		{
+			name:        "Replayed after the window",
+			req:         signed("POST", "/api/v1/wallet/deposit", signedAt, body),
+			now:         signedAt.Add(6 * time.Minute),
+			expectedErr: wallet.ErrStaleAPIRequest,
+		},
+		{
+			name: "Invalid timestamp",
+			req: func() wallet.SignedAPIRequest {
+				req := signed("GET", "/api/v1/wallet", signedAt, nil)
+				req.Timestamp = now.Format(time.RFC3339)
+				return req
+			}(),
+			now:         signedAt,
+			expectedErr: wallet.ErrInvalidAPIRequestSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Verify(secret, tt.now, 5*time.Minute)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrUnsupportedAsset = errors.New("unsupported asset")
+
+// DefaultAsset is assumed when a caller does not specify an asset, which keeps
+// clients written against the original USD-cents wallet working.
+const DefaultAsset = "USD"
+
+// Asset describes a currency a wallet can hold. Amounts are always stored and
+// moved as uint64 in the asset's minor unit (satoshi for BTC, cents for USD),
+// Decimals is the number of minor-unit digits after the decimal point.
+type Asset struct {
+	Code     string
+	Decimals int32
+}
+
+// assets is the registry of supported assets keyed by asset code.
+//
+// ETH is not supported: its minor unit, wei, has 18 decimals and amounts are stored as BIGINT,
+// which bounds a single ETH balance to ~9.22 ETH. Supporting it needs NUMERIC(78,0) amount columns.
+var assets = map[string]Asset{
+	"BTC":  {Code: "BTC", Decimals: 8},
+	"USDT": {Code: "USDT", Decimals: 6},
+	"USD":  {Code: "USD", Decimals: 2},
+}
+
+// LookupAsset returns the registered asset for code.
+func LookupAsset(code string) (Asset, error) {
+	asset, ok := assets[code]
+	if !ok {
+		return Asset{}, fmt.Errorf("asset %q: %w", code, ErrUnsupportedAsset)
+	}
+
+	return asset, nil
+}
+
+// FormatAmount used for displaying a minor-unit amount in string with the asset's precision,
+// eg: 150000000 BTC satoshis is displayed as "1.50000000".
+func (a Asset) FormatAmount(amount uint64) string {
+	return decimal.NewFromUint64(amount).Shift(-a.Decimals).StringFixed(a.Decimals)
+}
+
+// FormatSignedAmount displays a signed minor-unit amount, eg a balance change or a ledger balance,
+// with the asset's precision, eg: -150 USD cents is displayed as "-1.50".
+func (a Asset) FormatSignedAmount(amount int64) string {
+	return decimal.NewFromInt(amount).Shift(-a.Decimals).StringFixed(a.Decimals)
+}
+
+// FormatAmount looks up the asset by code and formats the minor-unit amount with its precision.
+func FormatAmount(code string, amount uint64) (string, error) {
+	asset, err := LookupAsset(code)
+	if err != nil {
+		return "", err
+	}
+
+	return asset.FormatAmount(amount), nil
+}
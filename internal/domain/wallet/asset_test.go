@@ -0,0 +1,150 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestLookupAsset(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		expected    wallet.Asset
+		expectedErr error
+	}{
+		{
+			name:     "BTC",
+			code:     "BTC",
+			expected: wallet.Asset{Code: "BTC", Decimals: 8},
+		},
+		{
+			name:     "USDT",
+			code:     "USDT",
+			expected: wallet.Asset{Code: "USDT", Decimals: 6},
+		},
+		{
+			name:     "USD",
+			code:     "USD",
+			expected: wallet.Asset{Code: "USD", Decimals: 2},
+		},
+		{
+			name:        "Unknown asset",
+			code:        "DOGE",
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+		{
+			name:        "ETH is not supported",
+			code:        "ETH",
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+		{
+			name:        "Lowercase code",
+			code:        "btc",
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := wallet.LookupAsset(tt.code)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		input       uint64
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "Zero BTC",
+			code:     "BTC",
+			input:    0,
+			expected: "0.00000000",
+		},
+		{
+			name:     "One satoshi",
+			code:     "BTC",
+			input:    1,
+			expected: "0.00000001",
+		},
+		{
+			name:     "One and a half BTC",
+			code:     "BTC",
+			input:    150000000,
+			expected: "1.50000000",
+		},
+		{
+			name:     "Max uint64 satoshis",
+			code:     "BTC",
+			input:    ^uint64(0), // 18446744073709551615
+			expected: "184467440737.09551615",
+		},
+		{
+			name:     "Ten USDT",
+			code:     "USDT",
+			input:    10000000,
+			expected: "10.000000",
+		},
+		{
+			name:     "One dollar and ninety-nine cents",
+			code:     "USD",
+			input:    199,
+			expected: "1.99",
+		},
+		{
+			name:        "Unknown asset",
+			code:        "DOGE",
+			input:       100,
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := wallet.FormatAmount(tt.code, tt.input)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestAssetFormatSignedAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		input    int64
+		expected string
+	}{
+		{name: "Zero USD", code: "USD", input: 0, expected: "0.00"},
+		{name: "Credit of one dollar and a half", code: "USD", input: 150, expected: "1.50"},
+		{name: "Debit of one dollar and a half", code: "USD", input: -150, expected: "-1.50"},
+		{name: "Debit of one satoshi", code: "BTC", input: -1, expected: "-0.00000001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := wallet.LookupAsset(tt.code)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, asset.FormatSignedAmount(tt.input))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// TransactionCreatedEvent is published for every transaction recorded, once per wallet it involves.
+	TransactionCreatedEvent = "wallet.transaction.created"
+	// TransactionReviewedEvent is published for every transaction approved or rejected in review, with the
+	// TransactionCreated payload of its final status, once per wallet it involves.
+	TransactionReviewedEvent = "wallet.transaction.reviewed"
+)
+
+// OutboxEvent is a wallet change recorded in the same db transaction as the change, to be relayed to downstream services.
+// IDs increase in commit order for the events of a wallet, so consumers can process them in order and skip duplicates.
+type OutboxEvent struct {
+	ID        int64     `db:"id"`
+	Type      string    `db:"event_type"`
+	WalletID  string    `db:"wallet_id"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WalletUpdatesChannel is the Redis pub/sub channel the events of a wallet are fanned out on, to every instance
+// streaming them to the wallet's user.
+func WalletUpdatesChannel(walletID string) string {
+	return "wallet-updates:" + walletID
+}
+
+// EventEnvelope is an event as sent outside the service, Data is the event's payload.
+type EventEnvelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Envelope returns the JSON envelope of the event.
+func (e OutboxEvent) Envelope() ([]byte, error) {
+	envelope, err := json.Marshal(EventEnvelope{
+		ID:        e.ID,
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt.UTC(),
+		Data:      e.Payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+
+	return envelope, nil
+}
+
+// TransactionCreated is the payload of TransactionCreatedEvent, seen from one of the wallets of the transaction.
+// Failed transactions and transactions pending review moved no money, so they are only published to their initiator.
+type TransactionCreated struct {
+	WalletID    string           `json:"wallet_id"`
+	UserID      string           `json:"user_id"`
+	Transaction EventTransaction `json:"transaction"`
+	// Balance is the wallet's balance of the transaction's asset right after the transaction
+	Balance EventBalance `json:"balance"`
+}
+
+// EventTransaction is a transaction as published in events, amounts are in the asset's minor unit.
+type EventTransaction struct {
+	ID                  string            `json:"id"`
+	Type                TransactionType   `json:"type"`
+	Status              TransactionStatus `json:"status"`
+	FailureReason       *FailureReason    `json:"failure_reason,omitempty"`
+	Asset               string            `json:"asset"`
+	Amount              uint64            `json:"amount"`
+	InitiatorUserID     string            `json:"initiator_user_id"`
+	RecipientUserID     *string           `json:"recipient_user_id,omitempty"`
+	ParentTransactionID *string           `json:"parent_transaction_id,omitempty"`
+	CreatedAt           string            `json:"created_at"`
+}
+
+type EventBalance struct {
+	Asset     string `json:"asset"`
+	Balance   uint64 `json:"balance"`
+	Held      uint64 `json:"held"`
+	Available uint64 `json:"available"`
+}
+
+// NewTransactionCreated returns the TransactionCreatedEvent payload of the transaction for one of its wallets.
+func NewTransactionCreated(walletID, userID string, txn Transaction, balance Balance) TransactionCreated {
+	return TransactionCreated{
+		WalletID: walletID,
+		UserID:   userID,
+		Transaction: EventTransaction{
+			ID:                  txn.ID,
+			Type:                txn.Type,
+			Status:              txn.Status,
+			FailureReason:       txn.FailureReason,
+			Asset:               txn.Asset,
+			Amount:              txn.Amount,
+			InitiatorUserID:     txn.InitiatorWalletUserId,
+			RecipientUserID:     txn.RecipientWalletUserId,
+			ParentTransactionID: txn.ParentTransactionID,
+			CreatedAt:           txn.CreatedAt,
+		},
+		Balance: EventBalance{
+			Asset:     balance.Asset,
+			Balance:   balance.Balance,
+			Held:      balance.Held,
+			Available: balance.Available(),
+		},
+	}
+}
+
+// Event returns the TransactionCreatedEvent outbox event carrying the payload.
+func (e TransactionCreated) Event() (OutboxEvent, error) {
+	return e.EventOf(TransactionCreatedEvent)
+}
+
+// EventOf returns the outbox event of eventType carrying the payload.
+func (e TransactionCreated) EventOf(eventType string) (OutboxEvent, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return OutboxEvent{}, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	return OutboxEvent{Type: eventType, WalletID: e.WalletID, Payload: payload}, nil
+}
@@ -0,0 +1,85 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestTransactionCreatedEvent(t *testing.T) {
+	recipient := "user-2"
+	reason := wallet.FailureInsufficientBalance
+
+	tests := []struct {
+		name            string
+		txn             wallet.Transaction
+		balance         wallet.Balance
+		expectedPayload string
+	}{
+		{
+			name: "Transfer seen from the recipient",
+			txn: wallet.Transaction{
+				ID:                    "tx-1",
+				InitiatorWalletUserId: "user-1",
+				RecipientWalletUserId: &recipient,
+				Type:                  wallet.Transfer,
+				Status:                wallet.Success,
+				Asset:                 "USD",
+				Amount:                250,
+				CreatedAt:             "2026-10-17T00:00:00Z",
+			},
+			balance: wallet.Balance{Asset: "USD", Balance: 1000, Held: 100},
+			expectedPayload: `{"wallet_id":"wallet-2","user_id":"user-2","transaction":{"id":"tx-1","type":"transfer","status":"success",` +
+				`"asset":"USD","amount":250,"initiator_user_id":"user-1","recipient_user_id":"user-2","created_at":"2026-10-17T00:00:00Z"},` +
+				`"balance":{"asset":"USD","balance":1000,"held":100,"available":900}}`,
+		},
+		{
+			name: "Failed withdrawal",
+			txn: wallet.Transaction{
+				ID:                    "tx-2",
+				InitiatorWalletUserId: "user-2",
+				Type:                  wallet.Withdraw,
+				Status:                wallet.Failed,
+				FailureReason:         &reason,
+				Asset:                 "USD",
+				Amount:                5000,
+				CreatedAt:             "2026-10-17T00:00:01Z",
+			},
+			balance: wallet.Balance{Asset: "USD", Balance: 1000},
+			expectedPayload: `{"wallet_id":"wallet-2","user_id":"user-2","transaction":{"id":"tx-2","type":"withdraw","status":"failed",` +
+				`"failure_reason":"insufficient_balance","asset":"USD","amount":5000,"initiator_user_id":"user-2","created_at":"2026-10-17T00:00:01Z"},` +
+				`"balance":{"asset":"USD","balance":1000,"held":0,"available":1000}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := wallet.NewTransactionCreated("wallet-2", "user-2", tt.txn, tt.balance).Event()
+			require.NoError(t, err)
+
+			assert.Equal(t, wallet.TransactionCreatedEvent, event.Type)
+			assert.Equal(t, "wallet-2", event.WalletID)
+			assert.JSONEq(t, tt.expectedPayload, string(event.Payload))
+		})
+	}
+}
+
+func TestOutboxEventEnvelope(t *testing.T) {
+	event := wallet.OutboxEvent{
+		ID:        42,
+		Type:      wallet.TransactionCreatedEvent,
+		WalletID:  "wallet-1",
+		Payload:   []byte(`{"wallet_id":"wallet-1"}`),
+		CreatedAt: time.Date(2026, 10, 17, 8, 0, 0, 0, time.FixedZone("", 8*60*60)),
+	}
+
+	envelope, err := event.Envelope()
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":42,"type":"wallet.transaction.created","created_at":"2026-10-17T00:00:00Z","data":{"wallet_id":"wallet-1"}}`, string(envelope))
+	assert.Equal(t, "wallet-updates:wallet-1", wallet.WalletUpdatesChannel(event.WalletID))
+}
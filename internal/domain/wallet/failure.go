@@ -0,0 +1,65 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTransactionLimitExceeded = errors.New("transaction limit exceeded")
+
+// FailureReason is the reason code recorded on a failed transaction.
+type FailureReason string
+
+const (
+	FailureInsufficientBalance FailureReason = "insufficient_balance"
+	FailureWalletFrozen        FailureReason = "wallet_frozen"
+	FailureWalletClosed        FailureReason = "wallet_closed"
+	FailureLimitExceeded       FailureReason = "limit_exceeded"
+	FailureFraudBlocked        FailureReason = "fraud_blocked"
+	FailureReviewRejected      FailureReason = "review_rejected"
+)
+
+var failureReasonErrors = map[FailureReason]error{
+	FailureInsufficientBalance: ErrWalletInsufficientBalance,
+	FailureWalletFrozen:        ErrWalletFrozen,
+	FailureWalletClosed:        ErrWalletClosed,
+	FailureLimitExceeded:       ErrTransactionLimitExceeded,
+	FailureFraudBlocked:        ErrTransactionBlocked,
+	FailureReviewRejected:      ErrTransactionRejected,
+}
+
+// FailureReasonOf returns the reason code of a business rule violation that is recorded
+// as a failed transaction, false for any other error.
+func FailureReasonOf(err error) (FailureReason, bool) {
+	for reason, reasonErr := range failureReasonErrors {
+		if errors.Is(err, reasonErr) {
+			return reason, true
+		}
+	}
+
+	return "", false
+}
+
+// Err returns the domain error the reason code was recorded for.
+func (r FailureReason) Err() error {
+	if err, ok := failureReasonErrors[r]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unknown failure reason %q", string(r))
+}
+
+// FailedTransactionError is returned when a transaction is rejected by a business rule
+// and recorded with status failed. It unwraps to the domain error of its reason code.
+type FailedTransactionError struct {
+	TransactionID string
+	Reason        FailureReason
+}
+
+func (e *FailedTransactionError) Error() string {
+	return fmt.Sprintf("transaction %s failed: %s", e.TransactionID, e.Reason.Err())
+}
+
+func (e *FailedTransactionError) Unwrap() error {
+	return e.Reason.Err()
+}
@@ -0,0 +1,80 @@
+package wallet_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestFailureReasonOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected wallet.FailureReason
+		ok       bool
+	}{
+		{
+			name:     "insufficient balance",
+			err:      fmt.Errorf("insufficient balance to deduct: %w", wallet.ErrWalletInsufficientBalance),
+			expected: wallet.FailureInsufficientBalance,
+			ok:       true,
+		},
+		{
+			name:     "frozen wallet",
+			err:      fmt.Errorf("wallet cannot be debited: %w", wallet.ErrWalletFrozen),
+			expected: wallet.FailureWalletFrozen,
+			ok:       true,
+		},
+		{
+			name:     "closed wallet",
+			err:      wallet.ErrWalletClosed,
+			expected: wallet.FailureWalletClosed,
+			ok:       true,
+		},
+		{
+			name:     "limit exceeded",
+			err:      wallet.ErrTransactionLimitExceeded,
+			expected: wallet.FailureLimitExceeded,
+			ok:       true,
+		},
+		{
+			name: "wallet not found is not recorded",
+			err:  wallet.ErrWalletNotFound,
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := wallet.FailureReasonOf(tt.err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, reason)
+		})
+	}
+}
+
+func TestFailedTransactionError(t *testing.T) {
+	err := fmt.Errorf("withdraw wallet repo err: %w", &wallet.FailedTransactionError{
+		TransactionID: "tx123",
+		Reason:        wallet.FailureInsufficientBalance,
+	})
+
+	assert.ErrorIs(t, err, wallet.ErrWalletInsufficientBalance)
+	assert.Equal(t, "withdraw wallet repo err: transaction tx123 failed: wallet insufficient balance", err.Error())
+
+	var failedErr *wallet.FailedTransactionError
+	assert.ErrorAs(t, err, &failedErr)
+	assert.Equal(t, "tx123", failedErr.TransactionID)
+}
+
+func TestFailureReasonErr(t *testing.T) {
+	assert.ErrorIs(t, wallet.FailureWalletFrozen.Err(), wallet.ErrWalletFrozen)
+	assert.EqualError(t, wallet.FailureReason("unknown").Err(), `unknown failure reason "unknown"`)
+}
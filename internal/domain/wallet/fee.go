@@ -0,0 +1,158 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+var (
+	ErrInvalidFeeSchedule  = errors.New("invalid fee schedule")
+	ErrFeeScheduleNotFound = errors.New("fee schedule not found")
+)
+
+// FeeTransactionTypes are the transaction types fees are charged on.
+var FeeTransactionTypes = []TransactionType{Withdraw, Transfer}
+
+// MaxBasisPoints is 100%, percentage fees are set in basis points, hundredths of a percent.
+const MaxBasisPoints = 10000
+
+// FeeTier is a tier of the fee schedule of a transaction type and asset, it prices amounts from MinAmount up to the
+// next tier's MinAmount, in the asset's minor unit. The fee is FlatFee plus BasisPoints of the amount, rounded up,
+// then raised to MinFee and lowered to MaxFee when set.
+type FeeTier struct {
+	Type        TransactionType `db:"type"`
+	Asset       string          `db:"asset"`
+	MinAmount   uint64          `db:"min_amount"`
+	FlatFee     uint64          `db:"flat_fee"`
+	BasisPoints uint64          `db:"basis_points"`
+	MinFee      *uint64         `db:"min_fee"`
+	MaxFee      *uint64         `db:"max_fee"`
+}
+
+// FeeSchedule is the tiers of a transaction type and asset, ordered by MinAmount.
+// Amounts below the first tier's MinAmount, or of a type and asset without a schedule, are free.
+type FeeSchedule []FeeTier
+
+// FeeBreakdown is what a transaction of Amount costs. The initiator is debited Total, Amount reaches the recipient
+// or leaves the wallet system and Fee is credited to the house fee account.
+type FeeBreakdown struct {
+	Asset         string
+	Amount        uint64
+	FlatFee       uint64
+	PercentageFee uint64
+	Fee           uint64
+	Total         uint64
+}
+
+// Validate checks the schedule has tiers of a single fee transaction type and supported asset, with distinct
+// minimum amounts, percentages of at most 100% and minimum fees not over maximum fees.
+func (s FeeSchedule) Validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("%w: no tiers", ErrInvalidFeeSchedule)
+	}
+
+	txnType, asset := s[0].Type, s[0].Asset
+	if !slices.Contains(FeeTransactionTypes, txnType) {
+		return fmt.Errorf("%w: type must be one of %v", ErrInvalidFeeSchedule, FeeTransactionTypes)
+	}
+
+	if _, err := LookupAsset(asset); err != nil {
+		return err
+	}
+
+	minAmounts := make(map[uint64]bool, len(s))
+	for _, t := range s {
+		if t.Type != txnType || t.Asset != asset {
+			return fmt.Errorf("%w: tiers of %s %s and %s %s", ErrInvalidFeeSchedule, txnType, asset, t.Type, t.Asset)
+		}
+
+		if minAmounts[t.MinAmount] {
+			return fmt.Errorf("%w: more than one tier from %d", ErrInvalidFeeSchedule, t.MinAmount)
+		}
+		minAmounts[t.MinAmount] = true
+
+		if t.BasisPoints > MaxBasisPoints {
+			return fmt.Errorf("%w: basis points over %d", ErrInvalidFeeSchedule, MaxBasisPoints)
+		}
+
+		if t.MinFee != nil && t.MaxFee != nil && *t.MinFee > *t.MaxFee {
+			return fmt.Errorf("%w: min fee over max fee", ErrInvalidFeeSchedule)
+		}
+	}
+
+	return nil
+}
+
+// Quote prices a transaction of amount with the tier of the highest MinAmount not over it.
+// It returns ErrAmountTooLarge when amount and fee do not fit in a ledger entry.
+func (s FeeSchedule) Quote(asset string, amount uint64) (FeeBreakdown, error) {
+	quote := FeeBreakdown{Asset: asset, Amount: amount}
+
+	var tier *FeeTier
+	for i := range s {
+		if s[i].MinAmount <= amount && (tier == nil || s[i].MinAmount > tier.MinAmount) {
+			tier = &s[i]
+		}
+	}
+
+	if tier != nil {
+		quote.FlatFee = tier.FlatFee
+		quote.PercentageFee = percentageOf(amount, tier.BasisPoints)
+
+		var carry uint64
+		quote.Fee, carry = bits.Add64(quote.FlatFee, quote.PercentageFee, 0)
+		if carry != 0 {
+			return FeeBreakdown{}, fmt.Errorf("fee of %d: %w", amount, ErrAmountTooLarge)
+		}
+
+		if tier.MinFee != nil && quote.Fee < *tier.MinFee {
+			quote.Fee = *tier.MinFee
+		}
+
+		if tier.MaxFee != nil && quote.Fee > *tier.MaxFee {
+			quote.Fee = *tier.MaxFee
+		}
+	}
+
+	total, carry := bits.Add64(amount, quote.Fee, 0)
+	if carry != 0 || total > math.MaxInt64 {
+		return FeeBreakdown{}, fmt.Errorf("amount %d with fee %d: %w", amount, quote.Fee, ErrAmountTooLarge)
+	}
+	quote.Total = total
+
+	return quote, nil
+}
+
+// Journal builds the balanced journal of the transaction, moving Amount from one account to another and the fee from
+// the same account to the house fee account.
+func (f FeeBreakdown) Journal(from, to LedgerAccount) (Journal, error) {
+	journal, err := NewJournal(f.Asset, f.Amount, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Fee == 0 {
+		return journal, nil
+	}
+
+	fee, err := NewJournal(f.Asset, f.Fee, from, SystemLedgerAccount(Fees))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(journal, fee...), nil
+}
+
+// percentageOf returns basisPoints of amount rounded up to the minor unit, without overflowing the product.
+func percentageOf(amount, basisPoints uint64) uint64 {
+	hi, lo := bits.Mul64(amount, basisPoints)
+	quo, rem := bits.Div64(hi, lo, MaxBasisPoints)
+	if rem > 0 {
+		quo++
+	}
+
+	return quo
+}
@@ -0,0 +1,167 @@
+package wallet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func feeOf(v uint64) *uint64 {
+	return &v
+}
+
+func TestFeeScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule wallet.FeeSchedule
+		err      error
+	}{
+		{
+			name: "tiered withdraw schedule",
+			schedule: wallet.FeeSchedule{
+				{Type: wallet.Withdraw, Asset: "USD", FlatFee: 100},
+				{Type: wallet.Withdraw, Asset: "USD", MinAmount: 100000, BasisPoints: 50, MinFee: feeOf(100), MaxFee: feeOf(2500)},
+			},
+		},
+		{
+			name: "no tiers",
+			err:  wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name:     "deposits are free",
+			schedule: wallet.FeeSchedule{{Type: wallet.Deposit, Asset: "USD", FlatFee: 100}},
+			err:      wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name:     "unsupported asset",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "DOGE"}},
+			err:      wallet.ErrUnsupportedAsset,
+		},
+		{
+			name: "tiers of another asset",
+			schedule: wallet.FeeSchedule{
+				{Type: wallet.Transfer, Asset: "USD"},
+				{Type: wallet.Transfer, Asset: "BTC", MinAmount: 100},
+			},
+			err: wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name: "tiers from the same amount",
+			schedule: wallet.FeeSchedule{
+				{Type: wallet.Transfer, Asset: "USD", FlatFee: 1},
+				{Type: wallet.Transfer, Asset: "USD", FlatFee: 2},
+			},
+			err: wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name:     "over 100%",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", BasisPoints: 10001}},
+			err:      wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name:     "min fee over max fee",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", MinFee: feeOf(10), MaxFee: feeOf(5)}},
+			err:      wallet.ErrInvalidFeeSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.schedule.Validate(), tt.err)
+		})
+	}
+}
+
+func TestFeeScheduleQuote(t *testing.T) {
+	schedule := wallet.FeeSchedule{
+		{Type: wallet.Withdraw, Asset: "USD", MinAmount: 1000, FlatFee: 25, BasisPoints: 150, MinFee: feeOf(50)},
+		{Type: wallet.Withdraw, Asset: "USD", MinAmount: 100000, BasisPoints: 100, MaxFee: feeOf(2500)},
+	}
+
+	tests := []struct {
+		name     string
+		schedule wallet.FeeSchedule
+		amount   uint64
+		expected wallet.FeeBreakdown
+		err      error
+	}{
+		{
+			name:     "below the first tier",
+			schedule: schedule,
+			amount:   999,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 999, Total: 999},
+		},
+		{
+			name:     "raised to the min fee",
+			schedule: schedule,
+			amount:   1000,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 1000, FlatFee: 25, PercentageFee: 15, Fee: 50, Total: 1050},
+		},
+		{
+			name:     "flat and percentage rounded up",
+			schedule: schedule,
+			amount:   5001,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 5001, FlatFee: 25, PercentageFee: 76, Fee: 101, Total: 5102},
+		},
+		{
+			name:     "higher tier",
+			schedule: schedule,
+			amount:   100000,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 100000, PercentageFee: 1000, Fee: 1000, Total: 101000},
+		},
+		{
+			name:     "lowered to the max fee",
+			schedule: schedule,
+			amount:   1000000,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 1000000, PercentageFee: 10000, Fee: 2500, Total: 1002500},
+		},
+		{
+			name:     "no schedule",
+			amount:   5000,
+			expected: wallet.FeeBreakdown{Asset: "USD", Amount: 5000, Total: 5000},
+		},
+		{
+			name:     "total overflows int64",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", FlatFee: 1}},
+			amount:   math.MaxInt64,
+			err:      wallet.ErrAmountTooLarge,
+		},
+		{
+			name:     "percentage of the largest amount",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", BasisPoints: 10000}},
+			amount:   math.MaxUint64,
+			err:      wallet.ErrAmountTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := tt.schedule.Quote("USD", tt.amount)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, quote)
+		})
+	}
+}
+
+func TestFeeBreakdownJournal(t *testing.T) {
+	from, to := wallet.WalletAccount("wallet-1"), wallet.SystemLedgerAccount(wallet.ExternalCashOut)
+
+	journal, err := wallet.FeeBreakdown{Asset: "USD", Amount: 1000, Fee: 50, Total: 1050}.Journal(from, to)
+	require.NoError(t, err)
+	assert.NoError(t, journal.Validate())
+	assert.Equal(t, wallet.Journal{
+		{LedgerAccount: from, Asset: "USD", Amount: -1000},
+		{LedgerAccount: to, Asset: "USD", Amount: 1000},
+		{LedgerAccount: from, Asset: "USD", Amount: -50},
+		{LedgerAccount: wallet.SystemLedgerAccount(wallet.Fees), Asset: "USD", Amount: 50},
+	}, journal)
+
+	journal, err = wallet.FeeBreakdown{Asset: "USD", Amount: 1000, Total: 1000}.Journal(from, to)
+	require.NoError(t, err)
+	assert.Len(t, journal, 2)
+}
@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrTransactionBlocked       = errors.New("transaction blocked")
+	ErrTransactionPendingReview = errors.New("transaction pending review")
+	ErrInvalidFraudDecision     = errors.New("invalid fraud decision")
+)
+
+// FraudDecision is what a fraud rule decides about a withdrawal or transfer, from the least to the most strict:
+// execute it, hold it for review by ops, or reject it.
+type FraudDecision string
+
+const (
+	FraudAllow  FraudDecision = "allow"
+	FraudReview FraudDecision = "review"
+	FraudBlock  FraudDecision = "block"
+)
+
+// FraudDecisions are the decisions from the least to the most strict.
+var FraudDecisions = []FraudDecision{FraudAllow, FraudReview, FraudBlock}
+
+// WithdrawalVelocityWindow is how far back withdrawals are counted in FraudSignals.RecentWithdrawals.
+const WithdrawalVelocityWindow = time.Hour
+
+// ParseFraudDecision returns the decision named s.
+func ParseFraudDecision(s string) (FraudDecision, error) {
+	decision := FraudDecision(s)
+	if !slices.Contains(FraudDecisions, decision) {
+		return "", fmt.Errorf("%w: %q, must be one of %v", ErrInvalidFraudDecision, s, FraudDecisions)
+	}
+
+	return decision, nil
+}
+
+// stricterThan reports whether d is stricter than other, unknown decisions allow.
+func (d FraudDecision) stricterThan(other FraudDecision) bool {
+	return slices.Index(FraudDecisions, d) > slices.Index(FraudDecisions, other)
+}
+
+// FraudCheck is a withdrawal or transfer to screen before it moves money, RecipientUserID is only set for transfers.
+// HoldID is set for the capture of a hold into a withdrawal or transfer, the asset is then the hold's.
+type FraudCheck struct {
+	Type            TransactionType
+	UserID          string
+	RecipientUserID string
+	HoldID          string
+	Asset           string
+	Amount          uint64
+}
+
+// FraudSignals is what the fraud rules know of a withdrawal or transfer and of its initiator wallet.
+type FraudSignals struct {
+	FraudCheck
+	// Total is what the transaction would debit, its amount and fee
+	Total uint64
+	// Available is the initiator wallet's balance of the asset not reserved by holds or pending reviews,
+	// and the held amount of the hold a capture releases
+	Available uint64
+	// WalletAge is how long ago the initiator wallet was opened
+	WalletAge time.Duration
+	// RecentWithdrawals counts the withdrawals the wallet initiated within WithdrawalVelocityWindow,
+	// successful or pending review
+	RecentWithdrawals int
+	// NewRecipient is set for transfers to a recipient the initiator never transferred to successfully
+	NewRecipient bool
+}
+
+// FraudRule decides on a withdrawal or transfer from its signals. Any type implementing it can be plugged into the
+// rules screening transactions, Name is recorded on the transactions it holds for review.
+type FraudRule interface {
+	Name() string
+	Evaluate(signals FraudSignals) FraudDecision
+}
+
+// FraudVerdict is the decision of the fraud rules on a transaction and the rule that made it, empty when allowed.
+type FraudVerdict struct {
+	Decision FraudDecision
+	Rule     string
+}
+
+// FraudRules are the rules every withdrawal and transfer is screened with.
+type FraudRules []FraudRule
+
+// Screen evaluates every rule on the signals, the strictest decision wins and ties go to the first rule making it.
+// Transactions no rule flags are allowed.
+func (r FraudRules) Screen(signals FraudSignals) FraudVerdict {
+	verdict := FraudVerdict{Decision: FraudAllow}
+	for _, rule := range r {
+		if decision := rule.Evaluate(signals); decision.stricterThan(verdict.Decision) {
+			verdict = FraudVerdict{Decision: decision, Rule: rule.Name()}
+		}
+	}
+
+	return verdict
+}
+
+// NewRecipientRule flags the first transfer to a recipient above the threshold of its asset, in the asset's minor unit.
+// Transfers of assets without a threshold are not flagged.
+type NewRecipientRule struct {
+	Thresholds map[string]uint64
+	Decision   FraudDecision
+}
+
+func (NewRecipientRule) Name() string { return "new_recipient" }
+
+func (r NewRecipientRule) Evaluate(s FraudSignals) FraudDecision {
+	threshold, ok := r.Thresholds[s.Asset]
+	if s.Type != Transfer || !s.NewRecipient || !ok || s.Amount <= threshold {
+		return FraudAllow
+	}
+
+	return r.Decision
+}
+
+// WithdrawalVelocityRule flags a withdrawal beyond Max withdrawals within WithdrawalVelocityWindow.
+type WithdrawalVelocityRule struct {
+	Max      int
+	Decision FraudDecision
+}
+
+func (WithdrawalVelocityRule) Name() string { return "withdrawal_velocity" }
+
+func (r WithdrawalVelocityRule) Evaluate(s FraudSignals) FraudDecision {
+	if s.Type != Withdraw || s.RecentWithdrawals < r.Max {
+		return FraudAllow
+	}
+
+	return r.Decision
+}
+
+// NewWalletDrainRule flags a withdrawal of everything available, fee included, from a wallet opened less than MinAge ago.
+type NewWalletDrainRule struct {
+	MinAge   time.Duration
+	Decision FraudDecision
+}
+
+func (NewWalletDrainRule) Name() string { return "new_wallet_drain" }
+
+func (r NewWalletDrainRule) Evaluate(s FraudSignals) FraudDecision {
+	if s.Type != Withdraw || s.WalletAge >= r.MinAge || s.Total < s.Available {
+		return FraudAllow
+	}
+
+	return r.Decision
+}
@@ -0,0 +1,194 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestParseFraudDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision string
+		expected wallet.FraudDecision
+		err      error
+	}{
+		{name: "allow", decision: "allow", expected: wallet.FraudAllow},
+		{name: "review", decision: "review", expected: wallet.FraudReview},
+		{name: "block", decision: "block", expected: wallet.FraudBlock},
+		{name: "unknown", decision: "flag", err: wallet.ErrInvalidFraudDecision},
+		{name: "empty", decision: "", err: wallet.ErrInvalidFraudDecision},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := wallet.ParseFraudDecision(tt.decision)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, decision)
+		})
+	}
+}
+
+func TestFraudRulesScreen(t *testing.T) {
+	rules := wallet.FraudRules{
+		wallet.NewRecipientRule{Thresholds: map[string]uint64{"USD": 100000}, Decision: wallet.FraudReview},
+		wallet.WithdrawalVelocityRule{Max: 5, Decision: wallet.FraudBlock},
+		wallet.NewWalletDrainRule{MinAge: 24 * time.Hour, Decision: wallet.FraudReview},
+	}
+
+	tests := []struct {
+		name     string
+		rules    wallet.FraudRules
+		signals  wallet.FraudSignals
+		expected wallet.FraudVerdict
+	}{
+		{
+			name:     "no rules",
+			signals:  wallet.FraudSignals{FraudCheck: wallet.FraudCheck{Type: wallet.Withdraw}, RecentWithdrawals: 100},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "first transfer to a new recipient above the threshold",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:   wallet.FraudCheck{Type: wallet.Transfer, Asset: "USD", Amount: 100001},
+				Available:    500000,
+				WalletAge:    48 * time.Hour,
+				NewRecipient: true,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudReview, Rule: "new_recipient"},
+		},
+		{
+			name:  "first transfer to a new recipient at the threshold",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:   wallet.FraudCheck{Type: wallet.Transfer, Asset: "USD", Amount: 100000},
+				Available:    500000,
+				WalletAge:    48 * time.Hour,
+				NewRecipient: true,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "transfer to a known recipient",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck: wallet.FraudCheck{Type: wallet.Transfer, Asset: "USD", Amount: 200000},
+				Available:  500000,
+				WalletAge:  48 * time.Hour,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "new recipient of an asset without a threshold",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:   wallet.FraudCheck{Type: wallet.Transfer, Asset: "BTC", Amount: 200000},
+				Available:    500000,
+				WalletAge:    48 * time.Hour,
+				NewRecipient: true,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "withdrawal beyond the hourly maximum",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:        wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 100},
+				Total:             100,
+				Available:         500000,
+				WalletAge:         48 * time.Hour,
+				RecentWithdrawals: 5,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudBlock, Rule: "withdrawal_velocity"},
+		},
+		{
+			name:  "transfers are not counted as withdrawals",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:        wallet.FraudCheck{Type: wallet.Transfer, Asset: "USD", Amount: 100},
+				Available:         500000,
+				WalletAge:         48 * time.Hour,
+				RecentWithdrawals: 5,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "new wallet withdrawing everything with the fee",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck: wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 9975},
+				Total:      10000,
+				Available:  10000,
+				WalletAge:  time.Hour,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudReview, Rule: "new_wallet_drain"},
+		},
+		{
+			name:  "new wallet withdrawing part of its balance",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck: wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 5000},
+				Total:      5000,
+				Available:  10000,
+				WalletAge:  time.Hour,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "old wallet withdrawing everything",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck: wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 10000},
+				Total:      10000,
+				Available:  10000,
+				WalletAge:  25 * time.Hour,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudAllow},
+		},
+		{
+			name:  "strictest decision wins",
+			rules: rules,
+			signals: wallet.FraudSignals{
+				FraudCheck:        wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 10000},
+				Total:             10000,
+				Available:         10000,
+				WalletAge:         time.Hour,
+				RecentWithdrawals: 7,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudBlock, Rule: "withdrawal_velocity"},
+		},
+		{
+			name: "first rule wins ties",
+			rules: wallet.FraudRules{
+				wallet.NewWalletDrainRule{MinAge: 24 * time.Hour, Decision: wallet.FraudReview},
+				wallet.WithdrawalVelocityRule{Max: 5, Decision: wallet.FraudReview},
+			},
+			signals: wallet.FraudSignals{
+				FraudCheck:        wallet.FraudCheck{Type: wallet.Withdraw, Asset: "USD", Amount: 10000},
+				Total:             10000,
+				Available:         10000,
+				WalletAge:         time.Hour,
+				RecentWithdrawals: 7,
+			},
+			expected: wallet.FraudVerdict{Decision: wallet.FraudReview, Rule: "new_wallet_drain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rules.Screen(tt.signals))
+		})
+	}
+}
+
+func TestTransactionReviewCanDecide(t *testing.T) {
+	approved := wallet.ReviewApproved
+
+	assert.NoError(t, wallet.TransactionReview{}.CanDecide())
+	assert.ErrorIs(t, wallet.TransactionReview{Decision: &approved}.CanDecide(), wallet.ErrReviewDecided)
+}
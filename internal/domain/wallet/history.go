@@ -0,0 +1,99 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTransactionFilter = errors.New("invalid transaction filter")
+	ErrInvalidCursor            = errors.New("invalid cursor")
+)
+
+// TransactionFilter narrows down a wallet's transactions history, zero fields do not filter.
+type TransactionFilter struct {
+	Asset  string
+	Type   TransactionType
+	Status TransactionStatus
+	// From is inclusive and To is exclusive
+	From, To time.Time
+	// MinAmount and MaxAmount are inclusive, in the asset's minor unit
+	MinAmount, MaxAmount uint64
+	// CounterpartyUserID only keeps transactions with this user on the other side
+	CounterpartyUserID string
+}
+
+// Validate rejects unknown assets, types and statuses, and empty date or amount ranges.
+func (f TransactionFilter) Validate() error {
+	if f.Asset != "" {
+		if _, err := LookupAsset(f.Asset); err != nil {
+			return err
+		}
+	}
+
+	switch f.Type {
+	case "", Deposit, Withdraw, Transfer, Reversal:
+	default:
+		return fmt.Errorf("type %q: %w", f.Type, ErrInvalidTransactionFilter)
+	}
+
+	switch f.Status {
+	case "", Success, Failed, PendingReview:
+	default:
+		return fmt.Errorf("status %q: %w", f.Status, ErrInvalidTransactionFilter)
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("date range: %w", ErrInvalidTransactionFilter)
+	}
+
+	if f.MaxAmount > 0 && f.MinAmount > f.MaxAmount {
+		return fmt.Errorf("amount range: %w", ErrInvalidTransactionFilter)
+	}
+
+	return nil
+}
+
+// TransactionCursor is the keyset position of a transaction in the history, which is ordered by
+// (created_at, id) descending. A page after the cursor starts right after that transaction,
+// so transactions landing meanwhile neither shift nor duplicate the following pages.
+type TransactionCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+}
+
+// CursorOf returns the cursor positioned at the transaction.
+func CursorOf(txn Transaction) (TransactionCursor, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, txn.CreatedAt)
+	if err != nil {
+		return TransactionCursor{}, fmt.Errorf("transaction %s created at: %w", txn.ID, err)
+	}
+
+	return TransactionCursor{CreatedAt: createdAt.UTC(), ID: txn.ID}, nil
+}
+
+// Encode returns the opaque cursor handed out to clients.
+func (c TransactionCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeTransactionCursor parses a cursor returned by Encode.
+func DecodeTransactionCursor(s string) (TransactionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	var c TransactionCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	c.CreatedAt = c.CreatedAt.UTC()
+
+	return c, nil
+}
@@ -0,0 +1,112 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestTransactionFilterValidate(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   wallet.TransactionFilter
+		expected error
+	}{
+		{
+			name:     "No filter",
+			filter:   wallet.TransactionFilter{},
+			expected: nil,
+		},
+		{
+			name: "Every filter",
+			filter: wallet.TransactionFilter{
+				Asset:              "BTC",
+				Type:               wallet.Reversal,
+				Status:             wallet.Success,
+				From:               from,
+				To:                 to,
+				MinAmount:          100,
+				MaxAmount:          100,
+				CounterpartyUserID: "user456",
+			},
+			expected: nil,
+		},
+		{
+			name:     "Unsupported asset",
+			filter:   wallet.TransactionFilter{Asset: "DOGE"},
+			expected: wallet.ErrUnsupportedAsset,
+		},
+		{
+			name:     "Unknown type",
+			filter:   wallet.TransactionFilter{Type: "refund"},
+			expected: wallet.ErrInvalidTransactionFilter,
+		},
+		{
+			name:     "Unknown status",
+			filter:   wallet.TransactionFilter{Status: "pending"},
+			expected: wallet.ErrInvalidTransactionFilter,
+		},
+		{
+			name:     "Empty date range",
+			filter:   wallet.TransactionFilter{From: to, To: to},
+			expected: wallet.ErrInvalidTransactionFilter,
+		},
+		{
+			name:     "Only minimum amount",
+			filter:   wallet.TransactionFilter{MinAmount: 500},
+			expected: nil,
+		},
+		{
+			name:     "Empty amount range",
+			filter:   wallet.TransactionFilter{MinAmount: 500, MaxAmount: 100},
+			expected: wallet.ErrInvalidTransactionFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.filter.Validate(), tt.expected)
+		})
+	}
+}
+
+func TestTransactionCursor(t *testing.T) {
+	txn := wallet.Transaction{ID: "tx1", CreatedAt: "2025-05-13T12:50:39.101388Z"}
+
+	cursor, err := wallet.CursorOf(txn)
+	require.NoError(t, err)
+	assert.Equal(t, wallet.TransactionCursor{
+		CreatedAt: time.Date(2025, 5, 13, 12, 50, 39, 101388000, time.UTC),
+		ID:        "tx1",
+	}, cursor)
+
+	decoded, err := wallet.DecodeTransactionCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestDecodeTransactionCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "Not base64", cursor: "not a cursor!"},
+		{name: "Not JSON", cursor: "bm90IGpzb24"},
+		{name: "Missing ID", cursor: wallet.TransactionCursor{CreatedAt: time.Now()}.Encode()},
+		{name: "Missing created at", cursor: wallet.TransactionCursor{ID: "tx1"}.Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := wallet.DecodeTransactionCursor(tt.cursor)
+			assert.ErrorIs(t, err, wallet.ErrInvalidCursor)
+		})
+	}
+}
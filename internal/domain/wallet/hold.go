@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is no longer active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds held amount")
+	ErrInvalidHoldExpiry  = errors.New("invalid hold expiry")
+)
+
+// MaxHoldDuration is the longest a hold may reserve funds before it expires.
+const MaxHoldDuration = 7 * 24 * time.Hour
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldReleased HoldStatus = "released"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold reserves an amount of a wallet's asset balance without moving it, until it is captured
+// into a withdrawal or transfer, released, or expires.
+type Hold struct {
+	ID             string     `db:"id"`
+	WalletID       string     `db:"wallet_id"`
+	Asset          string     `db:"asset"`
+	Amount         uint64     `db:"amount"`
+	CapturedAmount uint64     `db:"captured_amount"`
+	Status         HoldStatus `db:"status"`
+	TransactionID  *string    `db:"transaction_id"`
+	ExpiresAt      time.Time  `db:"expires_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// ValidateHoldExpiry checks a hold created at the given time expires after it, within MaxHoldDuration.
+func ValidateHoldExpiry(expiresAt, now time.Time) error {
+	if !expiresAt.After(now) || expiresAt.Sub(now) > MaxHoldDuration {
+		return ErrInvalidHoldExpiry
+	}
+
+	return nil
+}
+
+// CanCapture checks amount can be captured from the hold at the given time. A hold is captured once,
+// a partial capture releases the remainder.
+func (h Hold) CanCapture(amount uint64, now time.Time) error {
+	if h.Status != HoldActive {
+		return ErrHoldNotActive
+	}
+
+	if !now.Before(h.ExpiresAt) {
+		return ErrHoldExpired
+	}
+
+	if amount > h.Amount {
+		return ErrCaptureExceedsHold
+	}
+
+	return nil
+}
+
+// CanRelease checks the hold can be released. Releasing an already released hold is a no-op.
+func (h Hold) CanRelease() error {
+	switch h.Status {
+	case HoldActive, HoldReleased:
+		return nil
+	default:
+		return ErrHoldNotActive
+	}
+}
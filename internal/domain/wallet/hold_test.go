@@ -0,0 +1,130 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestValidateHoldExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected error
+	}{
+		{
+			name:     "Within max duration",
+			duration: time.Hour,
+			expected: nil,
+		},
+		{
+			name:     "Max duration",
+			duration: wallet.MaxHoldDuration,
+			expected: nil,
+		},
+		{
+			name:     "Expires immediately",
+			duration: 0,
+			expected: wallet.ErrInvalidHoldExpiry,
+		},
+		{
+			name:     "Longer than max duration",
+			duration: wallet.MaxHoldDuration + time.Second,
+			expected: wallet.ErrInvalidHoldExpiry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, wallet.ValidateHoldExpiry(now.Add(tt.duration), now), tt.expected)
+		})
+	}
+}
+
+func TestHoldCanCapture(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		hold     wallet.Hold
+		amount   uint64
+		expected error
+	}{
+		{
+			name:     "Full capture",
+			hold:     wallet.Hold{Amount: 100, Status: wallet.HoldActive, ExpiresAt: now.Add(time.Minute)},
+			amount:   100,
+			expected: nil,
+		},
+		{
+			name:     "Partial capture",
+			hold:     wallet.Hold{Amount: 100, Status: wallet.HoldActive, ExpiresAt: now.Add(time.Minute)},
+			amount:   40,
+			expected: nil,
+		},
+		{
+			name:     "Capture exceeds held amount",
+			hold:     wallet.Hold{Amount: 100, Status: wallet.HoldActive, ExpiresAt: now.Add(time.Minute)},
+			amount:   101,
+			expected: wallet.ErrCaptureExceedsHold,
+		},
+		{
+			name:     "Expired hold",
+			hold:     wallet.Hold{Amount: 100, Status: wallet.HoldActive, ExpiresAt: now},
+			amount:   100,
+			expected: wallet.ErrHoldExpired,
+		},
+		{
+			name:     "Released hold",
+			hold:     wallet.Hold{Amount: 100, Status: wallet.HoldReleased, ExpiresAt: now.Add(time.Minute)},
+			amount:   100,
+			expected: wallet.ErrHoldNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.hold.CanCapture(tt.amount, now), tt.expected)
+		})
+	}
+}
+
+func TestHoldCanRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   wallet.HoldStatus
+		expected error
+	}{
+		{
+			name:     "Active hold",
+			status:   wallet.HoldActive,
+			expected: nil,
+		},
+		{
+			name:     "Already released hold",
+			status:   wallet.HoldReleased,
+			expected: nil,
+		},
+		{
+			name:     "Captured hold",
+			status:   wallet.HoldCaptured,
+			expected: wallet.ErrHoldNotActive,
+		},
+		{
+			name:     "Expired hold",
+			status:   wallet.HoldExpired,
+			expected: wallet.ErrHoldNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, wallet.Hold{Status: tt.status}.CanRelease(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package wallet
+
+import "errors"
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request in progress")
+)
+
+// IdempotentOperation is the operation an idempotency key was used for.
+type IdempotentOperation string
+
+const (
+	HoldOperation    IdempotentOperation = "hold"
+	CaptureOperation IdempotentOperation = "capture"
+	ReverseOperation IdempotentOperation = "reverse"
+)
+
+// Operation returns the idempotent operation of a money movement of this type.
+func (t TransactionType) Operation() IdempotentOperation {
+	return IdempotentOperation(t)
+}
+
+// IdempotentResponse is the response recorded for an idempotency key, replayed verbatim on retries.
+type IdempotentResponse struct {
+	StatusCode int    `db:"response_status" json:"status_code"`
+	Body       []byte `db:"response_body" json:"body"`
+}
+
+// IdempotencyKey is the client supplied key deduplicating retries of a money movement or hold.
+type IdempotencyKey struct {
+	Key string
+	// RequestHash fingerprints the request, reusing the key for a request with a different hash is rejected.
+	RequestHash string
+	// Render builds the response for the outcome of the money movement, outcome is nil on success,
+	// ErrTransactionPendingReview when a fraud rule held the transaction for review, or a FailedTransactionError. It is recorded with the key in the same db transaction as the money movement.
+	// id is the ID of the transaction, or of the hold for HoldOperation, and fee what the transaction was charged,
+	// zero for transactions free of fees.
+	Render func(id string, fee FeeBreakdown, outcome error) (IdempotentResponse, error)
+}
@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrInvalidKYCTier    = errors.New("invalid kyc tier")
+	ErrKYCTierNotAllowed = errors.New("kyc tier does not allow this operation")
+)
+
+// KYCTier is how far the wallet's user is verified, it decides the transactions the wallet may initiate
+// and, through the tier's default limits, at what amounts.
+type KYCTier string
+
+const (
+	Unverified KYCTier = "unverified"
+	BasicKYC   KYCTier = "basic"
+	FullKYC    KYCTier = "full"
+)
+
+// KYCTiers are the tiers from least to most verified.
+var KYCTiers = []KYCTier{Unverified, BasicKYC, FullKYC}
+
+// kycTierTransactionTypes are the transaction types a wallet of each tier may initiate. Every wallet may receive funds,
+// so unverified wallets can be deposited into and transferred to but cannot send funds out.
+var kycTierTransactionTypes = map[KYCTier][]TransactionType{
+	Unverified: {Deposit},
+	BasicKYC:   {Deposit, Withdraw, Transfer},
+	FullKYC:    {Deposit, Withdraw, Transfer},
+}
+
+// ParseKYCTier returns the tier named s.
+func ParseKYCTier(s string) (KYCTier, error) {
+	tier := KYCTier(s)
+	if !slices.Contains(KYCTiers, tier) {
+		return "", fmt.Errorf("%w: %q, must be one of %v", ErrInvalidKYCTier, s, KYCTiers)
+	}
+
+	return tier, nil
+}
+
+// Allows reports whether a wallet of the tier may initiate transactions of txnType.
+// Holds reserve funds to be withdrawn or transferred, so they are allowed as withdrawals.
+func (t KYCTier) Allows(txnType TransactionType) error {
+	if !slices.Contains(kycTierTransactionTypes[t], txnType) {
+		return fmt.Errorf("%w: %s wallets cannot %s", ErrKYCTierNotAllowed, t, txnType)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestParseKYCTier(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     string
+		expected wallet.KYCTier
+		err      error
+	}{
+		{name: "unverified", tier: "unverified", expected: wallet.Unverified},
+		{name: "basic", tier: "basic", expected: wallet.BasicKYC},
+		{name: "full", tier: "full", expected: wallet.FullKYC},
+		{name: "unknown", tier: "gold", err: wallet.ErrInvalidKYCTier},
+		{name: "empty", tier: "", err: wallet.ErrInvalidKYCTier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier, err := wallet.ParseKYCTier(tt.tier)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, tier)
+		})
+	}
+}
+
+func TestKYCTierAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		tier    wallet.KYCTier
+		txnType wallet.TransactionType
+		err     error
+	}{
+		{name: "unverified deposit", tier: wallet.Unverified, txnType: wallet.Deposit},
+		{name: "unverified withdraw", tier: wallet.Unverified, txnType: wallet.Withdraw, err: wallet.ErrKYCTierNotAllowed},
+		{name: "unverified transfer", tier: wallet.Unverified, txnType: wallet.Transfer, err: wallet.ErrKYCTierNotAllowed},
+		{name: "basic withdraw", tier: wallet.BasicKYC, txnType: wallet.Withdraw},
+		{name: "basic transfer", tier: wallet.BasicKYC, txnType: wallet.Transfer},
+		{name: "full transfer", tier: wallet.FullKYC, txnType: wallet.Transfer},
+		{name: "unknown tier", tier: wallet.KYCTier("gold"), txnType: wallet.Deposit, err: wallet.ErrKYCTierNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.tier.Allows(tt.txnType), tt.err)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrUnbalancedJournal = errors.New("ledger journal is not balanced")
+	ErrAmountTooLarge    = errors.New("amount too large")
+)
+
+// SystemAccount is a ledger account owned by the service rather than by a wallet,
+// it is the counterparty of money entering or leaving the wallet system.
+type SystemAccount string
+
+const (
+	ExternalCashIn  SystemAccount = "external_cash_in"
+	ExternalCashOut SystemAccount = "external_cash_out"
+	Fees            SystemAccount = "fees"
+	OpeningBalance  SystemAccount = "opening_balance"
+)
+
+// LedgerAccount identifies the account a ledger entry is posted to,
+// exactly one of WalletID or SystemAccount is set.
+type LedgerAccount struct {
+	WalletID      *string        `db:"wallet_id"`
+	SystemAccount *SystemAccount `db:"system_account"`
+}
+
+func WalletAccount(walletID string) LedgerAccount {
+	return LedgerAccount{WalletID: &walletID}
+}
+
+func SystemLedgerAccount(account SystemAccount) LedgerAccount {
+	return LedgerAccount{SystemAccount: &account}
+}
+
+// LedgerEntry posts a signed amount of an asset to a single account.
+// Positive amounts credit (increase) the account and negative amounts debit (decrease) it,
+// so a wallet account's balance is the sum of its entries.
+type LedgerEntry struct {
+	LedgerAccount
+	Asset  string `db:"asset"`
+	Amount int64  `db:"amount"`
+}
+
+// Journal is the set of ledger entries posted for a single transaction.
+type Journal []LedgerEntry
+
+// NewJournal builds a balanced journal moving amount of asset from one account to another.
+// Journals for operations touching more than two accounts are built by appending journals.
+func NewJournal(asset string, amount uint64, from, to LedgerAccount) (Journal, error) {
+	if amount > math.MaxInt64 {
+		return nil, fmt.Errorf("journal amount %d: %w", amount, ErrAmountTooLarge)
+	}
+
+	return Journal{
+		{LedgerAccount: from, Asset: asset, Amount: -int64(amount)},
+		{LedgerAccount: to, Asset: asset, Amount: int64(amount)},
+	}, nil
+}
+
+// Validate enforces the double-entry invariant: entries of every asset sum to zero.
+// Entries must post a non-zero amount to exactly one account.
+func (j Journal) Validate() error {
+	if len(j) < 2 {
+		return fmt.Errorf("journal has %d entries: %w", len(j), ErrUnbalancedJournal)
+	}
+
+	sums := make(map[string]int64, 1)
+	for _, e := range j {
+		if e.Amount == 0 || (e.WalletID == nil) == (e.SystemAccount == nil) {
+			return fmt.Errorf("invalid journal entry: %w", ErrUnbalancedJournal)
+		}
+
+		sums[e.Asset] += e.Amount
+	}
+
+	for asset, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%s entries sum to %d: %w", asset, sum, ErrUnbalancedJournal)
+		}
+	}
+
+	return nil
+}
+
+// LedgerBalance compares a wallet's stored balance of an asset with the balance derived from its ledger entries.
+type LedgerBalance struct {
+	Asset         string `db:"asset"`
+	Balance       uint64 `db:"balance"`
+	LedgerBalance int64  `db:"ledger_balance"`
+}
+
+// Matches reports whether the stored balance equals the sum of the ledger entries.
+func (b LedgerBalance) Matches() bool {
+	return b.LedgerBalance >= 0 && uint64(b.LedgerBalance) == b.Balance
+}
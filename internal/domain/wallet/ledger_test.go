@@ -0,0 +1,170 @@
+package wallet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestNewJournal(t *testing.T) {
+	tests := []struct {
+		name        string
+		asset       string
+		amount      uint64
+		from        wallet.LedgerAccount
+		to          wallet.LedgerAccount
+		expected    wallet.Journal
+		expectedErr error
+	}{
+		{
+			name:   "Deposit",
+			asset:  "USD",
+			amount: 100,
+			from:   wallet.SystemLedgerAccount(wallet.ExternalCashIn),
+			to:     wallet.WalletAccount("wallet-1"),
+			expected: wallet.Journal{
+				{LedgerAccount: wallet.SystemLedgerAccount(wallet.ExternalCashIn), Asset: "USD", Amount: -100},
+				{LedgerAccount: wallet.WalletAccount("wallet-1"), Asset: "USD", Amount: 100},
+			},
+		},
+		{
+			name:   "Transfer",
+			asset:  "BTC",
+			amount: 5000,
+			from:   wallet.WalletAccount("wallet-1"),
+			to:     wallet.WalletAccount("wallet-2"),
+			expected: wallet.Journal{
+				{LedgerAccount: wallet.WalletAccount("wallet-1"), Asset: "BTC", Amount: -5000},
+				{LedgerAccount: wallet.WalletAccount("wallet-2"), Asset: "BTC", Amount: 5000},
+			},
+		},
+		{
+			name:        "Amount overflows int64",
+			asset:       "USD",
+			amount:      math.MaxInt64 + 1,
+			from:        wallet.WalletAccount("wallet-1"),
+			to:          wallet.SystemLedgerAccount(wallet.ExternalCashOut),
+			expectedErr: wallet.ErrAmountTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := wallet.NewJournal(tt.asset, tt.amount, tt.from, tt.to)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, result.Validate())
+		})
+	}
+}
+
+func TestJournalValidate(t *testing.T) {
+	walletAccount := wallet.WalletAccount("wallet-1")
+	feesAccount := wallet.SystemLedgerAccount(wallet.Fees)
+	cashOutAccount := wallet.SystemLedgerAccount(wallet.ExternalCashOut)
+
+	tests := []struct {
+		name        string
+		journal     wallet.Journal
+		expectedErr error
+	}{
+		{
+			name: "Balanced journal with three entries",
+			journal: wallet.Journal{
+				{LedgerAccount: walletAccount, Asset: "USD", Amount: -110},
+				{LedgerAccount: cashOutAccount, Asset: "USD", Amount: 100},
+				{LedgerAccount: feesAccount, Asset: "USD", Amount: 10},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Unbalanced journal",
+			journal: wallet.Journal{
+				{LedgerAccount: walletAccount, Asset: "USD", Amount: -100},
+				{LedgerAccount: cashOutAccount, Asset: "USD", Amount: 90},
+			},
+			expectedErr: wallet.ErrUnbalancedJournal,
+		},
+		{
+			name: "Balanced total but mixed assets",
+			journal: wallet.Journal{
+				{LedgerAccount: walletAccount, Asset: "USD", Amount: -100},
+				{LedgerAccount: cashOutAccount, Asset: "BTC", Amount: 100},
+			},
+			expectedErr: wallet.ErrUnbalancedJournal,
+		},
+		{
+			name: "Single entry",
+			journal: wallet.Journal{
+				{LedgerAccount: walletAccount, Asset: "USD", Amount: 100},
+			},
+			expectedErr: wallet.ErrUnbalancedJournal,
+		},
+		{
+			name: "Zero amount entry",
+			journal: wallet.Journal{
+				{LedgerAccount: walletAccount, Asset: "USD", Amount: 0},
+				{LedgerAccount: cashOutAccount, Asset: "USD", Amount: 0},
+			},
+			expectedErr: wallet.ErrUnbalancedJournal,
+		},
+		{
+			name: "Entry without account",
+			journal: wallet.Journal{
+				{Asset: "USD", Amount: -100},
+				{LedgerAccount: cashOutAccount, Asset: "USD", Amount: 100},
+			},
+			expectedErr: wallet.ErrUnbalancedJournal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.journal.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLedgerBalanceMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  wallet.LedgerBalance
+		expected bool
+	}{
+		{
+			name:     "Matching balance",
+			balance:  wallet.LedgerBalance{Asset: "USD", Balance: 100, LedgerBalance: 100},
+			expected: true,
+		},
+		{
+			name:     "Stored balance drifted",
+			balance:  wallet.LedgerBalance{Asset: "USD", Balance: 150, LedgerBalance: 100},
+			expected: false,
+		},
+		{
+			name:     "Negative ledger balance",
+			balance:  wallet.LedgerBalance{Asset: "USD", Balance: 0, LedgerBalance: -100},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.balance.Matches())
+		})
+	}
+}
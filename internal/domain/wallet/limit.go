@@ -0,0 +1,158 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidTransactionLimit  = errors.New("invalid transaction limit")
+	ErrTransactionLimitNotFound = errors.New("transaction limit not found")
+)
+
+// LimitedTransactionTypes are the transaction types debiting a wallet, the only ones limits apply to.
+var LimitedTransactionTypes = []TransactionType{Withdraw, Transfer}
+
+// LimitWindow is a rolling window transactions are totalled and counted over, ending now.
+type LimitWindow string
+
+const (
+	DailyWindow   LimitWindow = "daily"
+	WeeklyWindow  LimitWindow = "weekly"
+	MonthlyWindow LimitWindow = "monthly"
+)
+
+// Duration returns how far back the window reaches.
+func (w LimitWindow) Duration() time.Duration {
+	switch w {
+	case DailyWindow:
+		return 24 * time.Hour
+	case WeeklyWindow:
+		return 7 * 24 * time.Hour
+	default:
+		return 30 * 24 * time.Hour
+	}
+}
+
+// TransactionLimit caps the transactions of a type and asset a wallet initiates, amounts in the asset's minor unit.
+// Nil caps allow any amount or count. Limits with a nil WalletID are defaults, of the wallets of KYCTier or of every
+// wallet when nil. A wallet's own limit of a type and asset overrides its tier's default, which overrides the default
+// of every wallet, each as a whole.
+type TransactionLimit struct {
+	WalletID       *string         `db:"wallet_id"`
+	KYCTier        *KYCTier        `db:"kyc_tier"`
+	Type           TransactionType `db:"type"`
+	Asset          string          `db:"asset"`
+	PerTransaction *uint64         `db:"per_transaction_amount"`
+	DailyAmount    *uint64         `db:"daily_amount"`
+	DailyCount     *uint64         `db:"daily_count"`
+	WeeklyAmount   *uint64         `db:"weekly_amount"`
+	WeeklyCount    *uint64         `db:"weekly_count"`
+	MonthlyAmount  *uint64         `db:"monthly_amount"`
+	MonthlyCount   *uint64         `db:"monthly_count"`
+}
+
+// LimitUsage is the total amount and count of successful transactions of a type and asset a wallet initiated
+// in each rolling window.
+type LimitUsage struct {
+	DailyAmount   uint64 `db:"daily_amount"`
+	DailyCount    uint64 `db:"daily_count"`
+	WeeklyAmount  uint64 `db:"weekly_amount"`
+	WeeklyCount   uint64 `db:"weekly_count"`
+	MonthlyAmount uint64 `db:"monthly_amount"`
+	MonthlyCount  uint64 `db:"monthly_count"`
+}
+
+// LimitStatus is a limit in effect for a wallet and the wallet's usage of it.
+type LimitStatus struct {
+	Limit TransactionLimit
+	Usage LimitUsage
+}
+
+// WindowHeadroom is what a wallet has used of a limit window and what remains before it is exceeded.
+// Max and remaining values are nil when uncapped.
+type WindowHeadroom struct {
+	Window          LimitWindow
+	MaxAmount       *uint64
+	UsedAmount      uint64
+	RemainingAmount *uint64
+	MaxCount        *uint64
+	UsedCount       uint64
+	RemainingCount  *uint64
+}
+
+// Validate checks the limit is of a limited transaction type and a supported asset.
+func (l TransactionLimit) Validate() error {
+	if !slices.Contains(LimitedTransactionTypes, l.Type) {
+		return fmt.Errorf("%w: type must be one of %v", ErrInvalidTransactionLimit, LimitedTransactionTypes)
+	}
+
+	if _, err := LookupAsset(l.Asset); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Headroom returns the wallet's headroom in every window given its usage.
+func (l TransactionLimit) Headroom(usage LimitUsage) []WindowHeadroom {
+	return []WindowHeadroom{
+		newWindowHeadroom(DailyWindow, l.DailyAmount, usage.DailyAmount, l.DailyCount, usage.DailyCount),
+		newWindowHeadroom(WeeklyWindow, l.WeeklyAmount, usage.WeeklyAmount, l.WeeklyCount, usage.WeeklyCount),
+		newWindowHeadroom(MonthlyWindow, l.MonthlyAmount, usage.MonthlyAmount, l.MonthlyCount, usage.MonthlyCount),
+	}
+}
+
+// Check returns ErrTransactionLimitExceeded, naming the limit, when a transaction of amount would exceed
+// the per transaction cap or any window cap given the wallet's usage.
+func (l TransactionLimit) Check(usage LimitUsage, amount uint64) error {
+	if l.PerTransaction != nil && amount > *l.PerTransaction {
+		return fmt.Errorf("%w: %s per transaction amount", ErrTransactionLimitExceeded, l.Type)
+	}
+
+	for _, h := range l.Headroom(usage) {
+		if h.RemainingAmount != nil && amount > *h.RemainingAmount {
+			return fmt.Errorf("%w: %s %s amount", ErrTransactionLimitExceeded, h.Window, l.Type)
+		}
+
+		if h.RemainingCount != nil && *h.RemainingCount == 0 {
+			return fmt.Errorf("%w: %s %s count", ErrTransactionLimitExceeded, h.Window, l.Type)
+		}
+	}
+
+	return nil
+}
+
+func newWindowHeadroom(
+	window LimitWindow,
+	maxAmount *uint64,
+	usedAmount uint64,
+	maxCount *uint64,
+	usedCount uint64,
+) WindowHeadroom {
+	return WindowHeadroom{
+		Window:          window,
+		MaxAmount:       maxAmount,
+		UsedAmount:      usedAmount,
+		RemainingAmount: remaining(maxAmount, usedAmount),
+		MaxCount:        maxCount,
+		UsedCount:       usedCount,
+		RemainingCount:  remaining(maxCount, usedCount),
+	}
+}
+
+// remaining returns what is left of capped after used, never below zero, nil when uncapped.
+func remaining(capped *uint64, used uint64) *uint64 {
+	if capped == nil {
+		return nil
+	}
+
+	left := uint64(0)
+	if used < *capped {
+		left = *capped - used
+	}
+
+	return &left
+}
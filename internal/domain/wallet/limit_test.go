@@ -0,0 +1,168 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func capOf(v uint64) *uint64 {
+	return &v
+}
+
+func TestTransactionLimitValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit wallet.TransactionLimit
+		err   error
+	}{
+		{
+			name:  "withdraw",
+			limit: wallet.TransactionLimit{Type: wallet.Withdraw, Asset: "USD"},
+		},
+		{
+			name:  "transfer",
+			limit: wallet.TransactionLimit{Type: wallet.Transfer, Asset: "BTC", DailyCount: capOf(0)},
+		},
+		{
+			name:  "deposits are not limited",
+			limit: wallet.TransactionLimit{Type: wallet.Deposit, Asset: "USD"},
+			err:   wallet.ErrInvalidTransactionLimit,
+		},
+		{
+			name:  "unsupported asset",
+			limit: wallet.TransactionLimit{Type: wallet.Withdraw, Asset: "DOGE"},
+			err:   wallet.ErrUnsupportedAsset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.limit.Validate(), tt.err)
+		})
+	}
+}
+
+func TestTransactionLimitCheck(t *testing.T) {
+	limit := wallet.TransactionLimit{
+		Type:           wallet.Withdraw,
+		Asset:          "USD",
+		PerTransaction: capOf(1000),
+		DailyAmount:    capOf(2000),
+		DailyCount:     capOf(3),
+		MonthlyAmount:  capOf(5000),
+	}
+
+	tests := []struct {
+		name    string
+		limit   wallet.TransactionLimit
+		usage   wallet.LimitUsage
+		amount  uint64
+		wantErr string
+	}{
+		{
+			name:   "within every cap",
+			limit:  limit,
+			usage:  wallet.LimitUsage{DailyAmount: 1000, DailyCount: 2, MonthlyAmount: 4000},
+			amount: 1000,
+		},
+		{
+			name:    "over per transaction cap",
+			limit:   limit,
+			amount:  1001,
+			wantErr: "transaction limit exceeded: withdraw per transaction amount",
+		},
+		{
+			name:    "over daily amount",
+			limit:   limit,
+			usage:   wallet.LimitUsage{DailyAmount: 1500, DailyCount: 1, MonthlyAmount: 1500},
+			amount:  501,
+			wantErr: "transaction limit exceeded: daily withdraw amount",
+		},
+		{
+			name:    "daily count used up",
+			limit:   limit,
+			usage:   wallet.LimitUsage{DailyAmount: 30, DailyCount: 3, MonthlyAmount: 30},
+			amount:  1,
+			wantErr: "transaction limit exceeded: daily withdraw count",
+		},
+		{
+			name:    "over monthly amount",
+			limit:   limit,
+			usage:   wallet.LimitUsage{MonthlyAmount: 4500},
+			amount:  600,
+			wantErr: "transaction limit exceeded: monthly withdraw amount",
+		},
+		{
+			name:    "usage over a lowered cap",
+			limit:   limit,
+			usage:   wallet.LimitUsage{DailyAmount: 2500, MonthlyAmount: 2500},
+			amount:  1,
+			wantErr: "transaction limit exceeded: daily withdraw amount",
+		},
+		{
+			name:   "uncapped",
+			limit:  wallet.TransactionLimit{Type: wallet.Transfer, Asset: "USD"},
+			usage:  wallet.LimitUsage{DailyAmount: 1 << 62, DailyCount: 1 << 62},
+			amount: 1 << 62,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limit.Check(tt.usage, tt.amount)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, wallet.ErrTransactionLimitExceeded)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTransactionLimitHeadroom(t *testing.T) {
+	limit := wallet.TransactionLimit{
+		Type:         wallet.Transfer,
+		Asset:        "USD",
+		DailyAmount:  capOf(2000),
+		WeeklyCount:  capOf(10),
+		MonthlyCount: capOf(5),
+	}
+
+	headroom := limit.Headroom(wallet.LimitUsage{
+		DailyAmount:   500,
+		DailyCount:    1,
+		WeeklyAmount:  700,
+		WeeklyCount:   2,
+		MonthlyAmount: 900,
+		MonthlyCount:  7,
+	})
+
+	assert.Equal(t, []wallet.WindowHeadroom{
+		{
+			Window:          wallet.DailyWindow,
+			MaxAmount:       capOf(2000),
+			UsedAmount:      500,
+			RemainingAmount: capOf(1500),
+			UsedCount:       1,
+		},
+		{
+			Window:         wallet.WeeklyWindow,
+			UsedAmount:     700,
+			MaxCount:       capOf(10),
+			UsedCount:      2,
+			RemainingCount: capOf(8),
+		},
+		{
+			Window:         wallet.MonthlyWindow,
+			UsedAmount:     900,
+			MaxCount:       capOf(5),
+			UsedCount:      7,
+			RemainingCount: capOf(0),
+		},
+	}, headroom)
+}
@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReconciliationInProgress = errors.New("reconciliation already in progress")
+	ErrReconciliationNotFound   = errors.New("reconciliation not found")
+)
+
+type ReconciliationStatus string
+
+const (
+	ReconciliationRunning   ReconciliationStatus = "running"
+	ReconciliationCompleted ReconciliationStatus = "completed"
+	ReconciliationFailed    ReconciliationStatus = "failed"
+)
+
+// Reconciliation is a run verifying every wallet's stored balances against the balances recomputed from its transactions.
+// When Freeze is set, wallets failing reconciliation are frozen.
+type Reconciliation struct {
+	ID             string               `db:"id"              json:"id"`
+	Status         ReconciliationStatus `db:"status"          json:"status"`
+	Freeze         bool                 `db:"freeze"          json:"freeze"`
+	WalletsChecked int                  `db:"wallets_checked" json:"wallets_checked"`
+	MismatchCount  int                  `db:"mismatches"      json:"mismatch_count"`
+	StartedAt      time.Time            `db:"started_at"      json:"started_at"`
+	FinishedAt     *time.Time           `db:"finished_at"     json:"finished_at,omitempty"`
+	Mismatches     []BalanceMismatch    `db:"-"               json:"mismatches,omitempty"`
+}
+
+// BalanceMismatch is a wallet's asset whose stored balance differs from the balance recomputed from its successful
+// transactions: deposits and incoming transfers minus withdrawals and outgoing transfers, reversals moving funds back.
+type BalanceMismatch struct {
+	WalletID        string       `db:"wallet_id"        json:"wallet_id"`
+	UserID          string       `db:"user_id"          json:"user_id"`
+	WalletStatus    WalletStatus `db:"wallet_status"    json:"wallet_status"`
+	Asset           string       `db:"asset"            json:"asset"`
+	Balance         int64        `db:"balance"          json:"balance"`
+	ComputedBalance int64        `db:"computed_balance" json:"computed_balance"`
+	// Frozen reports whether the reconciliation froze the wallet
+	Frozen bool `db:"frozen" json:"frozen"`
+}
+
+// Difference returns how much the stored balance exceeds the recomputed balance, negative when it falls short.
+func (m BalanceMismatch) Difference() int64 {
+	return m.Balance - m.ComputedBalance
+}
+
+// ReconciliationPage is the outcome of reconciling a page of wallets in wallet id order.
+type ReconciliationPage struct {
+	WalletsChecked int
+	LastWalletID   string
+	Mismatches     []BalanceMismatch
+}
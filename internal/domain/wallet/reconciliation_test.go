@@ -0,0 +1,27 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestBalanceMismatchDifference(t *testing.T) {
+	tests := []struct {
+		name     string
+		mismatch wallet.BalanceMismatch
+		expected int64
+	}{
+		{name: "Stored balance exceeds history", mismatch: wallet.BalanceMismatch{Balance: 1500, ComputedBalance: 1000}, expected: 500},
+		{name: "Stored balance falls short of history", mismatch: wallet.BalanceMismatch{Balance: 1000, ComputedBalance: 1200}, expected: -200},
+		{name: "Balance without any transaction", mismatch: wallet.BalanceMismatch{Balance: 100}, expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mismatch.Difference())
+		})
+	}
+}
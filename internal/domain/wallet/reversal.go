@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+var (
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrTransactionNotReversible = errors.New("transaction cannot be reversed")
+	ErrReversalExceedsAmount    = errors.New("reversal exceeds the transaction's remaining amount")
+	ErrReversalFundsSpent       = errors.New("insufficient balance to reverse, funds already spent")
+)
+
+// CanReverse reports whether a transaction of this type and status may be reversed.
+// Only successful money movements can be reversed, reversals themselves cannot.
+func (t TransactionType) CanReverse(status TransactionStatus) error {
+	if status != Success {
+		return ErrTransactionNotReversible
+	}
+
+	switch t {
+	case Deposit, Withdraw, Transfer:
+		return nil
+	default:
+		return ErrTransactionNotReversible
+	}
+}
+
+// ReversalAmount returns the amount reversed out of a transaction of amount, of which reversed was already reversed.
+// A requested amount of zero reverses the whole remainder, the total reversed never exceeds the transaction amount.
+func ReversalAmount(amount, reversed, requested uint64) (uint64, error) {
+	if reversed >= amount {
+		return 0, ErrReversalExceedsAmount
+	}
+
+	remaining := amount - reversed
+	if requested == 0 {
+		return remaining, nil
+	}
+
+	if requested > remaining {
+		return 0, ErrReversalExceedsAmount
+	}
+
+	return requested, nil
+}
+
+// ReversalFee returns the part of the fee of a transaction of amount refunded when reversing reversing more of it, of
+// which reversed was already reversed with refunded of the fee. The fee is refunded in proportion to the amount reversed
+// so far, rounded down, so partial refunds never refund more than their share and reversing the remainder refunds the
+// remainder of the fee.
+func ReversalFee(amount, fee, reversed, refunded, reversing uint64) uint64 {
+	if amount == 0 || reversed+reversing > amount {
+		return 0
+	}
+
+	// reversed+reversing is at most amount, so the quotient is at most fee and fits
+	hi, lo := bits.Mul64(fee, reversed+reversing)
+	due, _ := bits.Div64(hi, lo, amount)
+	if due <= refunded {
+		return 0
+	}
+
+	return due - refunded
+}
+
+// TransactionAccounts returns the ledger accounts a transaction of this type moved funds from and to,
+// a reversal moves funds back from the to account into the from account.
+func TransactionAccounts(t TransactionType, initiatorWalletID string, recipientWalletID *string) (from, to LedgerAccount, err error) {
+	switch t {
+	case Deposit:
+		return SystemLedgerAccount(ExternalCashIn), WalletAccount(initiatorWalletID), nil
+	case Withdraw:
+		return WalletAccount(initiatorWalletID), SystemLedgerAccount(ExternalCashOut), nil
+	case Transfer:
+		if recipientWalletID == nil {
+			return LedgerAccount{}, LedgerAccount{}, fmt.Errorf("transfer without recipient: %w", ErrTransactionNotReversible)
+		}
+
+		return WalletAccount(initiatorWalletID), WalletAccount(*recipientWalletID), nil
+	default:
+		return LedgerAccount{}, LedgerAccount{}, fmt.Errorf("transaction type %q: %w", t, ErrTransactionNotReversible)
+	}
+}
@@ -0,0 +1,137 @@
+package wallet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestTransactionTypeCanReverse(t *testing.T) {
+	tests := []struct {
+		name     string
+		txType   wallet.TransactionType
+		status   wallet.TransactionStatus
+		expected error
+	}{
+		{name: "Successful deposit", txType: wallet.Deposit, status: wallet.Success, expected: nil},
+		{name: "Successful withdraw", txType: wallet.Withdraw, status: wallet.Success, expected: nil},
+		{name: "Successful transfer", txType: wallet.Transfer, status: wallet.Success, expected: nil},
+		{name: "Failed transfer", txType: wallet.Transfer, status: wallet.Failed, expected: wallet.ErrTransactionNotReversible},
+		{name: "Reversal", txType: wallet.Reversal, status: wallet.Success, expected: wallet.ErrTransactionNotReversible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.txType.CanReverse(tt.status), tt.expected)
+		})
+	}
+}
+
+func TestReversalAmount(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      uint64
+		reversed    uint64
+		requested   uint64
+		expected    uint64
+		expectedErr error
+	}{
+		{name: "Full reversal", amount: 100, requested: 0, expected: 100},
+		{name: "Partial refund", amount: 100, requested: 40, expected: 40},
+		{name: "Remainder after partial refund", amount: 100, reversed: 40, requested: 0, expected: 60},
+		{name: "Refund up to the remainder", amount: 100, reversed: 40, requested: 60, expected: 60},
+		{name: "Refund exceeds the remainder", amount: 100, reversed: 40, requested: 61, expectedErr: wallet.ErrReversalExceedsAmount},
+		{name: "Already fully reversed", amount: 100, reversed: 100, requested: 0, expectedErr: wallet.ErrReversalExceedsAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := wallet.ReversalAmount(tt.amount, tt.reversed, tt.requested)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestReversalFee(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    uint64
+		fee       uint64
+		reversed  uint64
+		refunded  uint64
+		reversing uint64
+		expected  uint64
+	}{
+		{name: "Full reversal", amount: 1000, fee: 25, reversing: 1000, expected: 25},
+		{name: "No fee", amount: 1000, reversing: 1000, expected: 0},
+		{name: "Partial refund rounds down", amount: 1000, fee: 25, reversing: 300, expected: 7},
+		{name: "Second partial refund", amount: 1000, fee: 25, reversed: 300, refunded: 7, reversing: 300, expected: 8},
+		{name: "Remainder refunds the rest of the fee", amount: 1000, fee: 25, reversed: 600, refunded: 15, reversing: 400, expected: 10},
+		{name: "Partial refund too small for a minor unit", amount: 1000, fee: 25, reversing: 39, expected: 0},
+		{name: "Reversing over the amount", amount: 1000, fee: 25, reversed: 600, reversing: 401, expected: 0},
+		{name: "Large amount and fee", amount: math.MaxInt64, fee: math.MaxInt64, reversing: math.MaxInt64 / 2, expected: math.MaxInt64 / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, wallet.ReversalFee(tt.amount, tt.fee, tt.reversed, tt.refunded, tt.reversing))
+		})
+	}
+}
+
+func TestTransactionAccounts(t *testing.T) {
+	recipientWalletID := "wallet-2"
+
+	tests := []struct {
+		name              string
+		txType            wallet.TransactionType
+		recipientWalletID *string
+		expectedFrom      wallet.LedgerAccount
+		expectedTo        wallet.LedgerAccount
+		expectedErr       error
+	}{
+		{
+			name:         "Deposit",
+			txType:       wallet.Deposit,
+			expectedFrom: wallet.SystemLedgerAccount(wallet.ExternalCashIn),
+			expectedTo:   wallet.WalletAccount("wallet-1"),
+		},
+		{
+			name:         "Withdraw",
+			txType:       wallet.Withdraw,
+			expectedFrom: wallet.WalletAccount("wallet-1"),
+			expectedTo:   wallet.SystemLedgerAccount(wallet.ExternalCashOut),
+		},
+		{
+			name:              "Transfer",
+			txType:            wallet.Transfer,
+			recipientWalletID: &recipientWalletID,
+			expectedFrom:      wallet.WalletAccount("wallet-1"),
+			expectedTo:        wallet.WalletAccount("wallet-2"),
+		},
+		{
+			name:        "Transfer without recipient",
+			txType:      wallet.Transfer,
+			expectedErr: wallet.ErrTransactionNotReversible,
+		},
+		{
+			name:              "Reversal",
+			txType:            wallet.Reversal,
+			recipientWalletID: &recipientWalletID,
+			expectedErr:       wallet.ErrTransactionNotReversible,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := wallet.TransactionAccounts(tt.txType, "wallet-1", tt.recipientWalletID)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedFrom, from)
+			assert.Equal(t, tt.expectedTo, to)
+		})
+	}
+}
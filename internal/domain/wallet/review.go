@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTransactionRejected = errors.New("transaction rejected in review")
+	ErrReviewNotFound      = errors.New("transaction review not found")
+	ErrReviewDecided       = errors.New("transaction review already decided")
+)
+
+// ReviewDecision is how ops decided on a transaction held for review.
+type ReviewDecision string
+
+const (
+	ReviewApproved ReviewDecision = "approved"
+	ReviewRejected ReviewDecision = "rejected"
+)
+
+// TransactionReview is a withdrawal or transfer a fraud rule held for review instead of executing it. Its amount and fee
+// stay reserved on the initiator wallet until ops approve it, executing it, or reject it.
+type TransactionReview struct {
+	Transaction
+	Fee       uint64          `db:"fee"`
+	Rule      string          `db:"rule"`
+	Decision  *ReviewDecision `db:"decision"`
+	DecidedAt *time.Time      `db:"decided_at"`
+}
+
+// CanDecide returns ErrReviewDecided once the review was approved or rejected.
+func (r TransactionReview) CanDecide() error {
+	if r.Decision != nil {
+		return fmt.Errorf("transaction %s %s: %w", r.ID, *r.Decision, ErrReviewDecided)
+	}
+
+	return nil
+}
+
+// FeeBreakdown returns what the transaction is charged on approval, the fee quoted when it was held for review.
+func (r TransactionReview) FeeBreakdown() FeeBreakdown {
+	return FeeBreakdown{Asset: r.Asset, Amount: r.Amount, Fee: r.Fee, Total: r.Amount + r.Fee}
+}
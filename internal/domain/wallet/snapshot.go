@@ -0,0 +1,48 @@
+package wallet
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidAsOf = errors.New("as of must not be in the future")
+	// ErrTransactionsNotVisible is returned when the database role cannot see the transactions of other roles in
+	// pg_stat_activity, so a balance snapshot cannot tell which of them are in flight. Grant it pg_read_all_stats.
+	ErrTransactionsNotVisible = errors.New("transactions of other database roles not visible")
+)
+
+// AssetBalance is a wallet's balance of a single asset derived from the ledger, in the asset's minor unit.
+type AssetBalance struct {
+	Asset   string `db:"asset"`
+	Balance int64  `db:"balance"`
+}
+
+// WalletBalancesAt is a wallet's non-zero balances at a past instant. Holds are not tracked over time,
+// so only the total balance of each asset is known, not the part that was held.
+type WalletBalancesAt struct {
+	WalletID string
+	UserID   string
+	AsOf     time.Time
+	Balances []AssetBalance
+}
+
+// BalanceOf returns the balance of the given asset, zero if the wallet did not hold it.
+func (w WalletBalancesAt) BalanceOf(asset string) AssetBalance {
+	for _, b := range w.Balances {
+		if b.Asset == asset {
+			return b
+		}
+	}
+
+	return AssetBalance{Asset: asset}
+}
+
+// ValidateAsOf checks balances are asked at an instant that is not in the future, as they are not known yet.
+func ValidateAsOf(asOf, now time.Time) error {
+	if asOf.After(now) {
+		return ErrInvalidAsOf
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestValidateAsOf(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		asOf     time.Time
+		expected error
+	}{
+		{name: "Past instant", asOf: time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC), expected: nil},
+		{name: "Now", asOf: now, expected: nil},
+		{name: "Future instant", asOf: now.Add(time.Second), expected: wallet.ErrInvalidAsOf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, wallet.ValidateAsOf(tt.asOf, now), tt.expected)
+		})
+	}
+}
+
+func TestWalletBalancesAtBalanceOf(t *testing.T) {
+	balances := wallet.WalletBalancesAt{
+		WalletID: "wallet-1",
+		Balances: []wallet.AssetBalance{{Asset: "BTC", Balance: 5}, {Asset: "USD", Balance: 1000}},
+	}
+
+	assert.Equal(t, wallet.AssetBalance{Asset: "USD", Balance: 1000}, balances.BalanceOf("USD"))
+	assert.Equal(t, wallet.AssetBalance{Asset: "USDT"}, balances.BalanceOf("USDT"))
+}
@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrInvalidStatementPeriod = errors.New("invalid statement period")
+
+// statementPeriodLayout is the yyyy-mm layout of a monthly statement period.
+const statementPeriodLayout = "2006-01"
+
+// StatementLine is a transaction of a wallet's history along with its effect on that wallet.
+type StatementLine struct {
+	Transaction
+	// Change is the signed amount the transaction credited (positive) or debited (negative) the wallet, zero when it failed
+	Change int64 `db:"change"`
+	// Balance is the wallet's balance of the transaction's asset right after the transaction
+	Balance int64 `db:"balance"`
+}
+
+// CounterpartyUserID returns the user on the other side of the transaction from userID,
+// empty for deposits and withdrawals which have no counterparty.
+func (t Transaction) CounterpartyUserID(userID string) string {
+	if t.RecipientWalletUserId == nil {
+		return ""
+	}
+
+	if t.InitiatorWalletUserId == userID {
+		return *t.RecipientWalletUserId
+	}
+
+	return t.InitiatorWalletUserId
+}
+
+// StatementPeriod is the calendar month, in UTC, a statement covers. Start is inclusive and End is exclusive.
+type StatementPeriod struct {
+	Start, End time.Time
+}
+
+// ParseStatementPeriod parses a yyyy-mm month, rejecting months that have not started by now.
+// The current month can be requested, its statement covers the transactions so far.
+func ParseStatementPeriod(s string, now time.Time) (StatementPeriod, error) {
+	start, err := time.Parse(statementPeriodLayout, s)
+	if err != nil {
+		return StatementPeriod{}, fmt.Errorf("period %q: %w", s, ErrInvalidStatementPeriod)
+	}
+
+	if start.After(now) {
+		return StatementPeriod{}, fmt.Errorf("period %q is in the future: %w", s, ErrInvalidStatementPeriod)
+	}
+
+	return StatementPeriod{Start: start, End: start.AddDate(0, 1, 0)}, nil
+}
+
+// String returns the period as yyyy-mm.
+func (p StatementPeriod) String() string {
+	return p.Start.Format(statementPeriodLayout)
+}
+
+// StatementTotal sums the transactions of a single type over a statement's period.
+type StatementTotal struct {
+	Type TransactionType
+	// Count includes failed transactions, which credit and debit nothing
+	Count    int
+	Credited uint64
+	Debited  uint64
+}
+
+// AssetStatement is the statement of a single asset of the wallet.
+// ClosingBalance is OpeningBalance plus the change of every line, the balance of the last line when there is one.
+type AssetStatement struct {
+	Asset          string
+	OpeningBalance int64
+	ClosingBalance int64
+	Totals         []StatementTotal
+	Lines          []StatementLine
+}
+
+// Statement is a wallet's statement over a period, with one AssetStatement per asset it held or moved.
+// Past statements are reproducible, as transactions and ledger entries are never updated nor backdated.
+type Statement struct {
+	UserID string
+	Period StatementPeriod
+	Assets []AssetStatement
+}
+
+// NewStatement starts a statement from the wallet's balances at the start of the period, keyed by asset.
+// Lines of the period are then added oldest first with Add.
+func NewStatement(userID string, period StatementPeriod, openingBalances map[string]int64) *Statement {
+	s := &Statement{UserID: userID, Period: period}
+	for asset, balance := range openingBalances {
+		if balance != 0 {
+			s.asset(asset).OpeningBalance = balance
+		}
+	}
+
+	for i := range s.Assets {
+		s.Assets[i].ClosingBalance = s.Assets[i].OpeningBalance
+	}
+
+	return s
+}
+
+// Add appends a line of the period to the statement of its asset, and adds it to the totals of its type.
+func (s *Statement) Add(line StatementLine) {
+	a := s.asset(line.Asset)
+	a.Lines = append(a.Lines, line)
+	a.ClosingBalance += line.Change
+
+	total := a.total(line.Type)
+	total.Count++
+	if line.Change > 0 {
+		total.Credited += uint64(line.Change)
+	} else {
+		total.Debited += uint64(-line.Change)
+	}
+}
+
+// asset returns the statement of the asset, adding it in asset code order when missing.
+func (s *Statement) asset(code string) *AssetStatement {
+	i := sort.Search(len(s.Assets), func(i int) bool { return s.Assets[i].Asset >= code })
+	if i == len(s.Assets) || s.Assets[i].Asset != code {
+		s.Assets = append(s.Assets, AssetStatement{})
+		copy(s.Assets[i+1:], s.Assets[i:])
+		s.Assets[i] = AssetStatement{Asset: code}
+	}
+
+	return &s.Assets[i]
+}
+
+// statementTypes is the order of the totals of a statement.
+var statementTypes = []TransactionType{Deposit, Withdraw, Transfer, Reversal}
+
+// total returns the total of the type, adding it in statementTypes order when missing.
+func (a *AssetStatement) total(txType TransactionType) *StatementTotal {
+	rank := func(t TransactionType) int {
+		for i, st := range statementTypes {
+			if st == t {
+				return i
+			}
+		}
+		return len(statementTypes)
+	}
+
+	i := sort.Search(len(a.Totals), func(i int) bool { return rank(a.Totals[i].Type) >= rank(txType) })
+	if i == len(a.Totals) || a.Totals[i].Type != txType {
+		a.Totals = append(a.Totals, StatementTotal{})
+		copy(a.Totals[i+1:], a.Totals[i:])
+		a.Totals[i] = StatementTotal{Type: txType}
+	}
+
+	return &a.Totals[i]
+}
@@ -0,0 +1,142 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func TestTransactionCounterpartyUserID(t *testing.T) {
+	recipient := "user456"
+
+	tests := []struct {
+		name     string
+		txn      wallet.Transaction
+		userID   string
+		expected string
+	}{
+		{
+			name:     "Deposit",
+			txn:      wallet.Transaction{Type: wallet.Deposit, InitiatorWalletUserId: "user123"},
+			userID:   "user123",
+			expected: "",
+		},
+		{
+			name:     "Outgoing transfer",
+			txn:      wallet.Transaction{Type: wallet.Transfer, InitiatorWalletUserId: "user123", RecipientWalletUserId: &recipient},
+			userID:   "user123",
+			expected: "user456",
+		},
+		{
+			name:     "Incoming transfer",
+			txn:      wallet.Transaction{Type: wallet.Transfer, InitiatorWalletUserId: "user123", RecipientWalletUserId: &recipient},
+			userID:   "user456",
+			expected: "user123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.txn.CounterpartyUserID(tt.userID))
+		})
+	}
+}
+
+func TestParseStatementPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		period      string
+		expected    wallet.StatementPeriod
+		expectedErr error
+	}{
+		{
+			name:   "Past month",
+			period: "2025-05",
+			expected: wallet.StatementPeriod{
+				Start: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "December ends in the next year",
+			period: "2025-12",
+			expected: wallet.StatementPeriod{
+				Start: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "Current month",
+			period: "2026-10",
+			expected: wallet.StatementPeriod{
+				Start: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "Future month", period: "2026-11", expectedErr: wallet.ErrInvalidStatementPeriod},
+		{name: "Not a month", period: "2025-13", expectedErr: wallet.ErrInvalidStatementPeriod},
+		{name: "Date", period: "2025-05-01", expectedErr: wallet.ErrInvalidStatementPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, err := wallet.ParseStatementPeriod(tt.period, now)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, period)
+		})
+	}
+}
+
+func TestStatement(t *testing.T) {
+	period := wallet.StatementPeriod{
+		Start: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	failed := wallet.FailureInsufficientBalance
+	lines := []wallet.StatementLine{
+		{Transaction: wallet.Transaction{ID: "tx1", Type: wallet.Transfer, Status: wallet.Success, Asset: "USD", Amount: 300}, Change: -300, Balance: 700},
+		{Transaction: wallet.Transaction{ID: "tx2", Type: wallet.Deposit, Status: wallet.Success, Asset: "BTC", Amount: 5}, Change: 5, Balance: 5},
+		{Transaction: wallet.Transaction{ID: "tx3", Type: wallet.Transfer, Status: wallet.Failed, Asset: "USD", Amount: 5000, FailureReason: &failed}, Change: 0, Balance: 700},
+		{Transaction: wallet.Transaction{ID: "tx4", Type: wallet.Deposit, Status: wallet.Success, Asset: "USD", Amount: 200}, Change: 200, Balance: 900},
+		{Transaction: wallet.Transaction{ID: "tx5", Type: wallet.Transfer, Status: wallet.Success, Asset: "USD", Amount: 100}, Change: 100, Balance: 1000},
+	}
+
+	statement := wallet.NewStatement("user123", period, map[string]int64{"USD": 1000, "ETH": 0, "USDT": 42})
+	for _, line := range lines {
+		statement.Add(line)
+	}
+
+	assert.Equal(t, &wallet.Statement{
+		UserID: "user123",
+		Period: period,
+		Assets: []wallet.AssetStatement{
+			{
+				Asset:          "BTC",
+				OpeningBalance: 0,
+				ClosingBalance: 5,
+				Totals:         []wallet.StatementTotal{{Type: wallet.Deposit, Count: 1, Credited: 5}},
+				Lines:          lines[1:2],
+			},
+			{
+				Asset:          "USD",
+				OpeningBalance: 1000,
+				ClosingBalance: 1000,
+				Totals: []wallet.StatementTotal{
+					{Type: wallet.Deposit, Count: 1, Credited: 200},
+					{Type: wallet.Transfer, Count: 3, Credited: 100, Debited: 300},
+				},
+				Lines: []wallet.StatementLine{lines[0], lines[2], lines[3], lines[4]},
+			},
+			{
+				Asset:          "USDT",
+				OpeningBalance: 42,
+				ClosingBalance: 42,
+			},
+		},
+	}, statement)
+}
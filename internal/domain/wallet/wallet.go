@@ -1,35 +1,64 @@
 package wallet
 
-import (
-	"errors"
-
-	"github.com/shopspring/decimal"
-)
+import "errors"
 
 var (
-	ErrWalletNotFound            = errors.New("wallet not found")
-	ErrWalletInsufficientBalance = errors.New("wallet insufficient balance")
+	ErrWalletNotFound                = errors.New("wallet not found")
+	ErrWalletInsufficientBalance     = errors.New("wallet insufficient balance")
+	ErrWalletFrozen                  = errors.New("wallet is frozen")
+	ErrWalletClosed                  = errors.New("wallet is closed")
+	ErrWalletHasBalance              = errors.New("wallet still has balance")
+	ErrInvalidWalletStatusTransition = errors.New("invalid wallet status transition")
+	ErrSelfTransfer                  = errors.New("cannot transfer to own wallet")
 )
 
 type (
+	WalletStatus      string
 	TransactionType   string
 	TransactionStatus string
 )
 
+const (
+	Active WalletStatus = "active"
+	Frozen WalletStatus = "frozen"
+	Closed WalletStatus = "closed"
+)
+
 const (
 	Deposit  TransactionType = "deposit"
 	Withdraw TransactionType = "withdraw"
 	Transfer TransactionType = "transfer"
+	Reversal TransactionType = "reversal"
 
-	Success TransactionStatus = "success"
-	Failed  TransactionStatus = "failed"
+	Success       TransactionStatus = "success"
+	Failed        TransactionStatus = "failed"
+	PendingReview TransactionStatus = "pending_review"
 )
 
 type Wallet struct {
-	ID        string `db:"id"`
-	UserID    string `db:"user_id"`
-	Balance   uint64 `db:"balance"`
-	CreatedAt string `db:"created_at"`
+	ID        string       `db:"id"`
+	UserID    string       `db:"user_id"`
+	Status    WalletStatus `db:"status"`
+	KYCTier   KYCTier      `db:"kyc_tier"`
+	CreatedAt string       `db:"created_at"`
+	Balances  []Balance    `db:"-"`
+}
+
+// Balance is the amount of a single asset held by a wallet, in the asset's minor unit.
+// Held is the part of the balance reserved by active holds, it cannot be withdrawn or transferred.
+type Balance struct {
+	Asset   string `db:"asset"`
+	Balance uint64 `db:"balance"`
+	Held    uint64 `db:"held"`
+}
+
+// Available returns the part of the balance not reserved by holds.
+func (b Balance) Available() uint64 {
+	if b.Held > b.Balance {
+		return 0
+	}
+
+	return b.Balance - b.Held
 }
 
 type Transaction struct {
@@ -37,13 +66,77 @@ type Transaction struct {
 	InitiatorWalletUserId string            `db:"initiator_wallet_user_id"`
 	Type                  TransactionType   `db:"type"`
 	Status                TransactionStatus `db:"status"`
+	Asset                 string            `db:"asset"`
 	Amount                uint64            `db:"amount"`
 	RecipientWalletUserId *string           `db:"recipient_wallet_user_id"`
+	FailureReason         *FailureReason    `db:"failure_reason"`
+	ParentTransactionID   *string           `db:"parent_transaction_id"`
+	ReversedAmount        uint64            `db:"reversed_amount"`
+	IdempotencyKey        *string           `db:"idempotency_key"`
+	HoldID                *string           `db:"hold_id"`
 	CreatedAt             string            `db:"created_at"`
 }
 
+// BalanceOf returns the wallet's balance of the given asset, zero if the wallet never held it.
+func (w Wallet) BalanceOf(asset string) Balance {
+	for _, b := range w.Balances {
+		if b.Asset == asset {
+			return b
+		}
+	}
+
+	return Balance{Asset: asset}
+}
+
+// IsEmpty reports whether the wallet holds no funds in any asset.
+func (w Wallet) IsEmpty() bool {
+	for _, b := range w.Balances {
+		if b.Balance > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CanDebit reports whether funds may leave a wallet in this status.
+// Frozen wallets can still receive funds but cannot send them out.
+func (s WalletStatus) CanDebit() error {
+	switch s {
+	case Frozen:
+		return ErrWalletFrozen
+	case Closed:
+		return ErrWalletClosed
+	default:
+		return nil
+	}
+}
+
+// CanCredit reports whether funds may enter a wallet in this status.
+func (s WalletStatus) CanCredit() error {
+	if s == Closed {
+		return ErrWalletClosed
+	}
+
+	return nil
+}
+
+// CanTransitionTo reports whether a wallet may move from its current status to next.
+// Closed is terminal, and a wallet cannot transition to the status it is already in.
+func (s WalletStatus) CanTransitionTo(next WalletStatus) bool {
+	switch s {
+	case Active:
+		return next == Frozen || next == Closed
+	case Frozen:
+		return next == Active || next == Closed
+	default:
+		return false
+	}
+}
+
 // ConvertFromCentsToDollarsString used for displaying dollars amount in string
+//
+// Deprecated: amounts are no longer USD-only, use Asset.FormatAmount or FormatAmount instead.
 func ConvertFromCentsToDollarsString(cents uint64) string {
-	amount := decimal.NewFromUint64(cents).Div(decimal.NewFromInt(100))
-	return amount.StringFixed(2)
+	return assets[DefaultAsset].FormatAmount(cents)
 }
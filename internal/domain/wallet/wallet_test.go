@@ -59,3 +59,219 @@ func TestConvertFromCentsToDollarsString(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletStatusCanDebit(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   wallet.WalletStatus
+		expected error
+	}{
+		{
+			name:     "Active wallet",
+			status:   wallet.Active,
+			expected: nil,
+		},
+		{
+			name:     "Frozen wallet",
+			status:   wallet.Frozen,
+			expected: wallet.ErrWalletFrozen,
+		},
+		{
+			name:     "Closed wallet",
+			status:   wallet.Closed,
+			expected: wallet.ErrWalletClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.CanDebit())
+		})
+	}
+}
+
+func TestWalletStatusCanCredit(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   wallet.WalletStatus
+		expected error
+	}{
+		{
+			name:     "Active wallet",
+			status:   wallet.Active,
+			expected: nil,
+		},
+		{
+			name:     "Frozen wallet",
+			status:   wallet.Frozen,
+			expected: nil,
+		},
+		{
+			name:     "Closed wallet",
+			status:   wallet.Closed,
+			expected: wallet.ErrWalletClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.CanCredit())
+		})
+	}
+}
+
+func TestWalletStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     wallet.WalletStatus
+		to       wallet.WalletStatus
+		expected bool
+	}{
+		{
+			name:     "Freeze active wallet",
+			from:     wallet.Active,
+			to:       wallet.Frozen,
+			expected: true,
+		},
+		{
+			name:     "Close active wallet",
+			from:     wallet.Active,
+			to:       wallet.Closed,
+			expected: true,
+		},
+		{
+			name:     "Unfreeze frozen wallet",
+			from:     wallet.Frozen,
+			to:       wallet.Active,
+			expected: true,
+		},
+		{
+			name:     "Close frozen wallet",
+			from:     wallet.Frozen,
+			to:       wallet.Closed,
+			expected: true,
+		},
+		{
+			name:     "Unfreeze active wallet",
+			from:     wallet.Active,
+			to:       wallet.Active,
+			expected: false,
+		},
+		{
+			name:     "Freeze frozen wallet",
+			from:     wallet.Frozen,
+			to:       wallet.Frozen,
+			expected: false,
+		},
+		{
+			name:     "Reopen closed wallet",
+			from:     wallet.Closed,
+			to:       wallet.Active,
+			expected: false,
+		},
+		{
+			name:     "Freeze closed wallet",
+			from:     wallet.Closed,
+			to:       wallet.Frozen,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestWalletBalanceOf(t *testing.T) {
+	w := wallet.Wallet{
+		Balances: []wallet.Balance{
+			{Asset: "USD", Balance: 100},
+			{Asset: "BTC", Balance: 5000, Held: 1000},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		asset    string
+		expected wallet.Balance
+	}{
+		{
+			name:     "Held asset",
+			asset:    "BTC",
+			expected: wallet.Balance{Asset: "BTC", Balance: 5000, Held: 1000},
+		},
+		{
+			name:     "Asset never held",
+			asset:    "USDT",
+			expected: wallet.Balance{Asset: "USDT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, w.BalanceOf(tt.asset))
+		})
+	}
+}
+
+func TestBalanceAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  wallet.Balance
+		expected uint64
+	}{
+		{
+			name:     "Nothing held",
+			balance:  wallet.Balance{Asset: "USD", Balance: 100},
+			expected: 100,
+		},
+		{
+			name:     "Partially held",
+			balance:  wallet.Balance{Asset: "USD", Balance: 100, Held: 30},
+			expected: 70,
+		},
+		{
+			name:     "Fully held",
+			balance:  wallet.Balance{Asset: "USD", Balance: 100, Held: 100},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.balance.Available())
+		})
+	}
+}
+
+func TestWalletIsEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []wallet.Balance
+		expected bool
+	}{
+		{
+			name:     "No balances",
+			balances: nil,
+			expected: true,
+		},
+		{
+			name:     "Only zero balances",
+			balances: []wallet.Balance{{Asset: "USD", Balance: 0}, {Asset: "BTC", Balance: 0}},
+			expected: true,
+		},
+		{
+			name:     "Non-zero balance",
+			balances: []wallet.Balance{{Asset: "USD", Balance: 0}, {Asset: "BTC", Balance: 1}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, wallet.Wallet{Balances: tt.balances}.IsEmpty())
+		})
+	}
+}
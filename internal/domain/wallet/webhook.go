@@ -0,0 +1,185 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidWebhookSubscription = errors.New("invalid webhook subscription")
+	ErrWebhookNotFound            = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound    = errors.New("webhook delivery not found")
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of "{timestamp}.{body}" keyed with the subscription's secret,
+// receivers recompute it and reject timestamps too far from their clock to prevent replays.
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// MinWebhookSecretLength is the shortest secret accepted to sign webhooks.
+	MinWebhookSecretLength = 16
+	// MaxWebhookAttempts is the number of attempts after which a delivery is dead-lettered.
+	MaxWebhookAttempts = 8
+
+	webhookBaseRetryDelay = 30 * time.Second
+	webhookMaxRetryDelay  = 6 * time.Hour
+)
+
+// webhookEventTypes are the event types webhooks can subscribe to.
+var webhookEventTypes = []string{TransactionCreatedEvent, TransactionReviewedEvent}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDead      WebhookDeliveryStatus = "dead"
+)
+
+// WebhookSubscription pushes the events of its types to URL. Without UserID it receives the events of every wallet.
+type WebhookSubscription struct {
+	ID         string    `db:"id"`
+	URL        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes []string  `db:"-"`
+	UserID     *string   `db:"user_id"`
+	Active     bool      `db:"active"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// NewWebhookSubscription validates a new subscription: an absolute http(s) URL, a secret of at least
+// MinWebhookSecretLength characters and at least one known event type, duplicates removed.
+func NewWebhookSubscription(rawURL, secret string, eventTypes []string, userID *string) (WebhookSubscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return WebhookSubscription{}, fmt.Errorf("url %q: %w", rawURL, ErrInvalidWebhookSubscription)
+	}
+
+	if len(secret) < MinWebhookSecretLength {
+		return WebhookSubscription{}, fmt.Errorf(
+			"secret shorter than %d characters: %w",
+			MinWebhookSecretLength,
+			ErrInvalidWebhookSubscription,
+		)
+	}
+
+	if len(eventTypes) == 0 {
+		return WebhookSubscription{}, fmt.Errorf("no event types: %w", ErrInvalidWebhookSubscription)
+	}
+
+	types := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !slices.Contains(webhookEventTypes, t) {
+			return WebhookSubscription{}, fmt.Errorf("event type %q: %w", t, ErrInvalidWebhookSubscription)
+		}
+
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+
+	return WebhookSubscription{URL: rawURL, Secret: secret, EventTypes: types, UserID: userID, Active: true}, nil
+}
+
+// WebhookDelivery is the delivery of an outbox event to a subscription, retried with exponential backoff
+// until it succeeds or MaxWebhookAttempts attempts failed.
+type WebhookDelivery struct {
+	ID             string                `db:"id"`
+	SubscriptionID string                `db:"subscription_id"`
+	EventID        int64                 `db:"event_id"`
+	Status         WebhookDeliveryStatus `db:"status"`
+	Attempts       int                   `db:"attempts"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at"`
+	LastStatusCode *int                  `db:"last_status_code"`
+	LastError      *string               `db:"last_error"`
+	CreatedAt      time.Time             `db:"created_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at"`
+}
+
+// WebhookDispatch is a delivery claimed for an attempt, with what is needed to send it.
+type WebhookDispatch struct {
+	WebhookDelivery
+	URL    string      `db:"url"`
+	Secret string      `db:"secret"`
+	Event  OutboxEvent `db:"event"`
+}
+
+// Body returns the JSON body of the webhook request, the event's envelope. Every attempt of a delivery sends the same body,
+// receivers skip event ids they already processed.
+func (d WebhookDispatch) Body() ([]byte, error) {
+	return d.Event.Envelope()
+}
+
+// WebhookAttempt is the outcome of sending a delivery, StatusCode is zero when no response was received.
+type WebhookAttempt struct {
+	StatusCode int
+	Err        error
+}
+
+// Succeeded reports whether the receiver acknowledged the delivery with a 2xx response.
+func (a WebhookAttempt) Succeeded() bool {
+	return a.Err == nil && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Attempted returns the delivery after the attempt made at now: succeeded, retried after WebhookRetryDelay,
+// or dead once MaxWebhookAttempts attempts failed.
+func (d WebhookDelivery) Attempted(attempt WebhookAttempt, now time.Time) WebhookDelivery {
+	d.Attempts++
+	d.LastStatusCode = nil
+	if attempt.StatusCode != 0 {
+		statusCode := attempt.StatusCode
+		d.LastStatusCode = &statusCode
+	}
+
+	d.LastError = nil
+	if attempt.Err != nil {
+		lastError := attempt.Err.Error()
+		d.LastError = &lastError
+	}
+
+	switch {
+	case attempt.Succeeded():
+		d.Status = WebhookSucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= MaxWebhookAttempts:
+		d.Status = WebhookDead
+	default:
+		d.Status = WebhookPending
+		d.NextAttemptAt = now.Add(WebhookRetryDelay(d.Attempts))
+	}
+
+	return d
+}
+
+// WebhookRetryDelay returns how long to wait before the next attempt after the given number of failed attempts,
+// doubling from 30 seconds up to 6 hours.
+func WebhookRetryDelay(attempts int) time.Duration {
+	delay := webhookBaseRetryDelay
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, webhookMaxRetryDelay)
+}
+
+// SignWebhook returns the signature of a webhook body sent at timestamp with the subscription's secret.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
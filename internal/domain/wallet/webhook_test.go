@@ -0,0 +1,178 @@
+package wallet_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+const webhookSecret = "whsec_0123456789abcdef"
+
+func TestNewWebhookSubscription(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		secret             string
+		eventTypes         []string
+		expectedEventTypes []string
+		expectedErr        error
+	}{
+		{
+			name:               "Valid subscription with duplicate event types",
+			url:                "https://example.com/hooks",
+			secret:             webhookSecret,
+			eventTypes:         []string{wallet.TransactionCreatedEvent, wallet.TransactionCreatedEvent},
+			expectedEventTypes: []string{wallet.TransactionCreatedEvent},
+		},
+		{
+			name:        "Relative URL",
+			url:         "/hooks",
+			secret:      webhookSecret,
+			eventTypes:  []string{wallet.TransactionCreatedEvent},
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+		{
+			name:        "Unsupported scheme",
+			url:         "ftp://example.com/hooks",
+			secret:      webhookSecret,
+			eventTypes:  []string{wallet.TransactionCreatedEvent},
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+		{
+			name:        "Short secret",
+			url:         "https://example.com/hooks",
+			secret:      "secret",
+			eventTypes:  []string{wallet.TransactionCreatedEvent},
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+		{
+			name:        "No event types",
+			url:         "https://example.com/hooks",
+			secret:      webhookSecret,
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+		{
+			name:        "Unknown event type",
+			url:         "https://example.com/hooks",
+			secret:      webhookSecret,
+			eventTypes:  []string{"wallet.closed"},
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := wallet.NewWebhookSubscription(tt.url, tt.secret, tt.eventTypes, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, sub.URL)
+			assert.Equal(t, tt.expectedEventTypes, sub.EventTypes)
+			assert.True(t, sub.Active)
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	timestamp := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	signature := wallet.SignWebhook(webhookSecret, timestamp, []byte(`{"id":1}`))
+
+	assert.Equal(t, "sha256=6af9c73038d76cc8f666237b4ae17e7c783e90e4ea1455877a198cf79e4efb0b", signature)
+	assert.NotEqual(t, signature, wallet.SignWebhook(webhookSecret, timestamp.Add(time.Second), []byte(`{"id":1}`)))
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 5, expected: 8 * time.Minute},
+		{attempts: 20, expected: 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, wallet.WebhookRetryDelay(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
+
+func TestWebhookDeliveryAttempted(t *testing.T) {
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	statusCode := func(code int) *int { return &code }
+	message := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		delivery wallet.WebhookDelivery
+		attempt  wallet.WebhookAttempt
+		expected wallet.WebhookDelivery
+	}{
+		{
+			name:     "Acknowledged",
+			delivery: wallet.WebhookDelivery{ID: "d-1", Status: wallet.WebhookPending, Attempts: 1, LastStatusCode: statusCode(500)},
+			attempt:  wallet.WebhookAttempt{StatusCode: 204},
+			expected: wallet.WebhookDelivery{
+				ID:             "d-1",
+				Status:         wallet.WebhookSucceeded,
+				Attempts:       2,
+				LastStatusCode: statusCode(204),
+				DeliveredAt:    &now,
+			},
+		},
+		{
+			name:     "Rejected and retried",
+			delivery: wallet.WebhookDelivery{ID: "d-1", Status: wallet.WebhookPending, Attempts: 2},
+			attempt:  wallet.WebhookAttempt{StatusCode: 503},
+			expected: wallet.WebhookDelivery{
+				ID:             "d-1",
+				Status:         wallet.WebhookPending,
+				Attempts:       3,
+				NextAttemptAt:  now.Add(2 * time.Minute),
+				LastStatusCode: statusCode(503),
+			},
+		},
+		{
+			name:     "Unreachable on the last attempt",
+			delivery: wallet.WebhookDelivery{ID: "d-1", Status: wallet.WebhookPending, Attempts: wallet.MaxWebhookAttempts - 1},
+			attempt:  wallet.WebhookAttempt{Err: errors.New("connection refused")},
+			expected: wallet.WebhookDelivery{
+				ID:        "d-1",
+				Status:    wallet.WebhookDead,
+				Attempts:  wallet.MaxWebhookAttempts,
+				LastError: message("connection refused"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.delivery.Attempted(tt.attempt, now))
+		})
+	}
+}
+
+func TestWebhookDispatchBody(t *testing.T) {
+	dispatch := wallet.WebhookDispatch{
+		Event: wallet.OutboxEvent{
+			ID:        42,
+			Type:      wallet.TransactionCreatedEvent,
+			Payload:   []byte(`{"wallet_id":"wallet-1"}`),
+			CreatedAt: time.Date(2026, 10, 17, 8, 0, 0, 0, time.FixedZone("", 8*60*60)),
+		},
+	}
+
+	body, err := dispatch.Body()
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":42,"type":"wallet.transaction.created","created_at":"2026-10-17T00:00:00Z","data":{"wallet_id":"wallet-1"}}`, string(body))
+}
@@ -1,11 +1,19 @@
 package handler
 
 import (
+	"expvar"
+	"fmt"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/jennwah/crypto-assignment/docs"
+	"github.com/jennwah/crypto-assignment/internal/config"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/middleware"
 	"github.com/jennwah/crypto-assignment/internal/handler/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
+	"github.com/jennwah/crypto-assignment/internal/pkg/fraud"
+	"github.com/jennwah/crypto-assignment/internal/pkg/ratelimit"
 	walletrepo "github.com/jennwah/crypto-assignment/internal/repository/wallet"
 	walletsrv "github.com/jennwah/crypto-assignment/internal/service/wallet"
 	"github.com/jmoiron/sqlx"
@@ -14,24 +22,156 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupHandlers(router *gin.Engine, logger *slog.Logger, db *sqlx.DB, cache *redis.Client) {
+// SetupHandlers registers the routes. Users are authenticated with JWTs checked by verifier, or by their X-USER-ID header
+// when nil, for local development only, and internal services with API keys. Every route group requires its scope,
+// and requests are rate limited per IP, then per user or API key once authenticated.
+func SetupHandlers(
+	router *gin.Engine,
+	logger *slog.Logger,
+	db *sqlx.DB,
+	cache *redis.Client,
+	cfg config.Config,
+	verifier *auth.Verifier,
+) error {
+	rateLimitPolicy, err := ratelimit.NewPolicy(cfg.RateLimit)
+	if err != nil {
+		return fmt.Errorf("invalid rate limits: %w", err)
+	}
+
+	fraudRules, err := fraud.NewRules(cfg.Fraud)
+	if err != nil {
+		return fmt.Errorf("invalid fraud rules: %w", err)
+	}
+
 	walletRepo := walletrepo.New(db, cache, logger)
-	walletService := walletsrv.New(walletRepo)
-	walletHandler := wallet.New(logger, walletService)
+	walletService := walletsrv.New(walletRepo, fraudRules...)
+	walletHandler := wallet.New(logger, walletService, cfg.APIKeySecret)
+	authenticator := middleware.NewAuthenticator(logger, verifier, walletService, cfg.APIKeySecret, cfg.APISignatureWindow)
+	rateLimiter := middleware.NewRateLimiter(logger, ratelimit.NewLimiter(cache), rateLimitPolicy, cfg.RateLimitFailOpen)
 
 	// v1
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", rateLimiter.ByIP())
 	{
-		v1Wallet := v1.Group("/wallet")
+		v1Wallet := v1.Group("/wallet", authenticator.Wallet(), rateLimiter.ByPrincipal())
+		{
+			v1WalletRead := v1Wallet.Group("", middleware.RequireScope(domainwallet.ScopeWalletRead))
+			{
+				v1WalletRead.GET("/", walletHandler.GetWallet)
+				v1WalletRead.GET("/stream", walletHandler.StreamWallet)
+				v1WalletRead.GET("/stream/ws", walletHandler.StreamWalletWebSocket)
+				v1WalletRead.GET("/transactions", walletHandler.GetTransactions)
+				v1WalletRead.GET("/transactions/export", walletHandler.ExportTransactions)
+				v1WalletRead.GET("/transactions/:transactionID", walletHandler.GetTransaction)
+				v1WalletRead.GET("/statements/:period", walletHandler.GetStatement)
+				v1WalletRead.GET("/limits", walletHandler.GetTransactionLimits)
+				v1WalletRead.GET("/fees/quote", walletHandler.QuoteFee)
+			}
+
+			// wallets are provisioned to be credited
+			v1WalletDeposit := v1Wallet.Group("", middleware.RequireScope(domainwallet.ScopeWalletDeposit))
+			{
+				v1WalletDeposit.POST("/", walletHandler.CreateWallet)
+				v1WalletDeposit.POST("/deposit", walletHandler.DepositWallet)
+			}
+
+			// holds reserve funds to be paid out
+			v1WalletWithdraw := v1Wallet.Group("", middleware.RequireScope(domainwallet.ScopeWalletWithdraw))
+			{
+				v1WalletWithdraw.POST("/withdraw", walletHandler.WithdrawWallet)
+				v1WalletWithdraw.POST("/holds", walletHandler.CreateHold)
+				v1WalletWithdraw.POST("/holds/:holdID/capture", walletHandler.CaptureHold)
+				v1WalletWithdraw.POST("/holds/:holdID/release", walletHandler.ReleaseHold)
+			}
+
+			v1WalletTransfer := v1Wallet.Group("", middleware.RequireScope(domainwallet.ScopeWalletTransfer))
+			{
+				v1WalletTransfer.POST("/transfer", walletHandler.Transfer)
+			}
+		}
+
+		v1Admin := v1.Group(
+			"/admin",
+			authenticator.Admin(),
+			rateLimiter.ByPrincipal(),
+			middleware.RequireScope(domainwallet.ScopeAdmin),
+		)
+		{
+			v1AdminWallets := v1Admin.Group("/wallets")
+			{
+				v1AdminWallets.GET("/balances", walletHandler.ListWalletBalancesAsOf)
+				v1AdminWallets.POST("/:userID/freeze", walletHandler.FreezeWallet)
+				v1AdminWallets.POST("/:userID/unfreeze", walletHandler.UnfreezeWallet)
+				v1AdminWallets.POST("/:userID/close", walletHandler.CloseWallet)
+				v1AdminWallets.PUT("/:userID/kyc-tier", walletHandler.SetWalletKYCTier)
+				v1AdminWallets.GET("/:userID/ledger", walletHandler.GetWalletLedger)
+				v1AdminWallets.GET("/:userID/limits", walletHandler.GetWalletTransactionLimits)
+				v1AdminWallets.PUT("/:userID/limits", walletHandler.SetWalletTransactionLimit)
+				v1AdminWallets.DELETE("/:userID/limits/:type/:asset", walletHandler.DeleteWalletTransactionLimit)
+			}
+
+			v1AdminFees := v1Admin.Group("/fees")
+			{
+				v1AdminFees.GET("/", walletHandler.ListFeeSchedules)
+				v1AdminFees.PUT("/:type/:asset", walletHandler.SetFeeSchedule)
+				v1AdminFees.DELETE("/:type/:asset", walletHandler.DeleteFeeSchedule)
+			}
+
+			v1AdminReviews := v1Admin.Group("/reviews")
+			{
+				v1AdminReviews.GET("/", walletHandler.ListTransactionReviews)
+				v1AdminReviews.POST("/:transactionID/approve", walletHandler.ApproveTransactionReview)
+				v1AdminReviews.POST("/:transactionID/reject", walletHandler.RejectTransactionReview)
+			}
+
+			v1AdminReconciliations := v1Admin.Group("/reconciliations")
+			{
+				v1AdminReconciliations.GET("/", walletHandler.ListReconciliations)
+				v1AdminReconciliations.GET("/:reconciliationID", walletHandler.GetReconciliation)
+			}
+
+			v1AdminWebhooks := v1Admin.Group("/webhooks")
+			{
+				v1AdminWebhooks.POST("/", walletHandler.CreateWebhook)
+				v1AdminWebhooks.GET("/", walletHandler.ListWebhooks)
+				v1AdminWebhooks.DELETE("/:webhookID", walletHandler.DeleteWebhook)
+				v1AdminWebhooks.GET("/:webhookID/deliveries", walletHandler.ListWebhookDeliveries)
+				v1AdminWebhooks.POST("/:webhookID/deliveries/:deliveryID/replay", walletHandler.ReplayWebhookDelivery)
+			}
+
+			v1AdminAPIKeys := v1Admin.Group("/api-keys")
+			{
+				v1AdminAPIKeys.POST("/", walletHandler.CreateAPIKey)
+				v1AdminAPIKeys.GET("/", walletHandler.ListAPIKeys)
+				v1AdminAPIKeys.DELETE("/:apiKeyID", walletHandler.RevokeAPIKey)
+			}
+		}
+
+		// admin, reversals are not scoped to a user wallet
+		v1Transactions := v1.Group(
+			"/transactions",
+			authenticator.Admin(),
+			rateLimiter.ByPrincipal(),
+			middleware.RequireScope(domainwallet.ScopeAdmin),
+		)
 		{
-			v1Wallet.GET("/", walletHandler.GetWallet)
-			v1Wallet.GET("/transactions", walletHandler.GetTransactions)
-			v1Wallet.POST("/deposit", walletHandler.DepositWallet)
-			v1Wallet.POST("/withdraw", walletHandler.WithdrawWallet)
-			v1Wallet.POST("/transfer", walletHandler.Transfer)
+			v1Transactions.POST("/:transactionID/reverse", walletHandler.ReverseTransaction)
 		}
 	}
 
+	// expose expvar metrics, eg db transaction retries, to admins only as they include process memstats and cmdline
+	debug := router.Group(
+		"/debug",
+		rateLimiter.ByIP(),
+		authenticator.Admin(),
+		rateLimiter.ByPrincipal(),
+		middleware.RequireScope(domainwallet.ScopeAdmin),
+	)
+	{
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
+	}
+
 	// setup Swagger docs
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+
+	return nil
 }
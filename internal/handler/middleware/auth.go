@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	// maxSignedBodyBytes bounds the body read to verify the signature of API key requests.
+	maxSignedBodyBytes = 1 << 20
+)
+
+// apiKeyAuthErrors are the errors of requests not authenticated by their API key.
+var apiKeyAuthErrors = []error{
+	domainwallet.ErrAPIKeyNotFound,
+	domainwallet.ErrAPIKeyRevoked,
+	domainwallet.ErrAPIKeysDisabled,
+	domainwallet.ErrInvalidAPIRequestSignature,
+	domainwallet.ErrStaleAPIRequest,
+}
+
+// Authenticator authenticates users with JWTs, or with their X-USER-ID header alone without a verifier,
+// and internal services with API keys signing their requests. It sets the scopes granted to the caller, checked by RequireScope.
+type Authenticator struct {
+	logger          *slog.Logger
+	verifier        *auth.Verifier
+	walletService   wallet.IWalletService
+	apiKeySecret    string
+	signatureWindow time.Duration
+}
+
+// NewAuthenticator returns an Authenticator verifying user JWTs with verifier, or trusting the X-USER-ID header when nil,
+// for local development only.
+func NewAuthenticator(
+	logger *slog.Logger,
+	verifier *auth.Verifier,
+	walletService wallet.IWalletService,
+	apiKeySecret string,
+	signatureWindow time.Duration,
+) *Authenticator {
+	return &Authenticator{
+		logger:          logger,
+		verifier:        verifier,
+		walletService:   walletService,
+		apiKeySecret:    apiKeySecret,
+		signatureWindow: signatureWindow,
+	}
+}
+
+// Wallet authenticates requests scoped to a user wallet. Users are granted every wallet scope of their own wallet,
+// API keys act on behalf of the user of the X-USER-ID header with their scopes.
+func (a *Authenticator) Wallet() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(models.APIKeyIDHeader) != "" {
+			if !a.authenticateAPIKey(c) {
+				return
+			}
+
+			userID := c.GetHeader(models.UserIDHeader)
+			if err := uuid.Validate(userID); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+					Message: "invalid user id",
+				})
+				return
+			}
+
+			c.Set(models.UserIDContextKey, userID)
+			c.Next()
+			return
+		}
+
+		if !a.authenticateUser(c) {
+			return
+		}
+
+		c.Set(models.ScopesContextKey, domainwallet.UserScopes)
+		c.Next()
+	}
+}
+
+// Admin authenticates requests not scoped to a user wallet, with API keys only. Without a verifier,
+// requests without an API key are granted every scope, for local development only.
+func (a *Authenticator) Admin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(models.APIKeyIDHeader) != "" {
+			if !a.authenticateAPIKey(c) {
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if a.verifier == nil {
+			c.Set(models.ScopesContextKey, domainwallet.APIKeyScopes)
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Message: "missing api key",
+		})
+	}
+}
+
+// RequireScope rejects requests of callers not granted the scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(c.GetStringSlice(models.ScopesContextKey), scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Message: "missing scope " + scope,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateUser sets the user id from the bearer token's sub claim, which must be a UUID and match the X-USER-ID
+// header when sent, or from the header alone without a verifier. It aborts the request and returns false on failure.
+func (a *Authenticator) authenticateUser(c *gin.Context) bool {
+	headerUserID := c.GetHeader(models.UserIDHeader)
+	if a.verifier == nil {
+		if err := uuid.Validate(headerUserID); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Message: "invalid user id",
+			})
+			return false
+		}
+
+		c.Set(models.UserIDContextKey, headerUserID)
+		return true
+	}
+
+	header := c.GetHeader("Authorization")
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		c.Header("WWW-Authenticate", `Bearer`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Message: "missing bearer token",
+		})
+		return false
+	}
+
+	userID, err := a.verifier.Verify(c.Request.Context(), header[len(bearerPrefix):])
+	if err == nil && uuid.Validate(userID) != nil {
+		err = auth.ErrInvalidToken
+	}
+	if err != nil {
+		a.logger.Debug("authenticate user middleware err", slog.Any("error", err))
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Message: "invalid token",
+		})
+		return false
+	}
+
+	if headerUserID != "" && !strings.EqualFold(headerUserID, userID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+			Message: "user id does not match token",
+		})
+		return false
+	}
+
+	c.Set(models.UserIDContextKey, userID)
+	return true
+}
+
+// authenticateAPIKey verifies the request's signature with its API key, and sets the key's scopes.
+// It aborts the request and returns false on failure.
+func (a *Authenticator) authenticateAPIKey(c *gin.Context) bool {
+	keyID := c.GetHeader(models.APIKeyIDHeader)
+	if err := uuid.Validate(keyID); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Message: "invalid api key",
+		})
+		return false
+	}
+
+	// The body is read to be signed, then restored for the handler
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodyBytes))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Message: "request body too large",
+		})
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	key, err := a.walletService.AuthenticateAPIRequest(c, a.apiKeySecret, domainwallet.SignedAPIRequest{
+		KeyID:     keyID,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Timestamp: c.GetHeader(models.APITimestampHeader),
+		Signature: c.GetHeader(models.APISignatureHeader),
+		Body:      body,
+	}, a.signatureWindow)
+	if err != nil {
+		for _, authErr := range apiKeyAuthErrors {
+			if errors.Is(err, authErr) {
+				a.logger.Debug("authenticate api key middleware err", slog.Any("error", err))
+				c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+					Message: "invalid api key",
+				})
+				return false
+			}
+		}
+
+		a.logger.Error("authenticate api key middleware err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return false
+	}
+
+	c.Set(models.APIKeyIDContextKey, key.ID)
+	c.Set(models.ScopesContextKey, key.Scopes)
+	return true
+}
@@ -0,0 +1,598 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/middleware"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "crypto-wallet"
+	testUserID   = "8e9f5a4e-2a43-4a59-9d5b-4a4f1f0b7c11"
+
+	apiKeyServerSecret = "server-secret-0123456789abcdefghij"
+	apiKeyID           = "5d0c6f1e-9b7a-4c3d-8e2f-1a2b3c4d5e6f"
+	signatureWindow    = 5 * time.Minute
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestVerifier returns a verifier of tokens signed with the returned key, served as the only key of a JWKS.
+func newTestVerifier(t *testing.T) (*auth.Verifier, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string][]map[string]string{"keys": {{
+		"kty": "RSA",
+		"kid": "rsa-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := auth.NewVerifier(context.Background(), config.Auth{
+		JWKSURL:             server.URL,
+		JWKSRefreshInterval: time.Minute,
+		JWTIssuer:           testIssuer,
+		JWTAudience:         testAudience,
+	})
+	require.NoError(t, err)
+
+	return verifier, key
+}
+
+func signUserToken(t *testing.T, key *rsa.PrivateKey, subject string, expiresAt time.Time) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	token.Header["kid"] = "rsa-1"
+
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+// newAuthRouter serves GET /wallet behind the authenticator's Wallet middleware and GET /admin behind its Admin
+// middleware, both responding with the authenticated user id and scopes, and POST /admin echoing the request body.
+func newAuthRouter(authenticator *middleware.Authenticator, scope string) *gin.Engine {
+	respond := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id": c.GetString(models.UserIDContextKey),
+			"scopes":  c.GetStringSlice(models.ScopesContextKey),
+		})
+	}
+
+	router := gin.New()
+	router.GET("/wallet", authenticator.Wallet(), middleware.RequireScope(scope), respond)
+	router.GET("/admin", authenticator.Admin(), middleware.RequireScope(scope), respond)
+	router.POST("/admin", authenticator.Admin(), middleware.RequireScope(scope), func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"body": string(body)})
+	})
+
+	return router
+}
+
+func TestAuthenticatorWalletJWT(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	router := newAuthRouter(middleware.NewAuthenticator(slog.Default(), verifier, nil, "", time.Minute), "wallet:read")
+	validToken := signUserToken(t, key, testUserID, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name                    string
+		authorization           string
+		userIDHeader            string
+		expectedStatus          int
+		expectedWWWAuthenticate string
+		expectedUserID          string
+	}{
+		{
+			name:           "valid token",
+			authorization:  "Bearer " + validToken,
+			expectedStatus: http.StatusOK,
+			expectedUserID: testUserID,
+		},
+		{
+			name:           "valid token with matching user id header and lowercase scheme",
+			authorization:  "bearer " + validToken,
+			userIDHeader:   "8E9F5A4E-2A43-4A59-9D5B-4A4F1F0B7C11",
+			expectedStatus: http.StatusOK,
+			expectedUserID: testUserID,
+		},
+		{
+			name:           "user id header of another user",
+			authorization:  "Bearer " + validToken,
+			userIDHeader:   "0b1c2d3e-4f50-4617-8293-a4b5c6d7e8f9",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:                    "no bearer token",
+			userIDHeader:            testUserID,
+			expectedStatus:          http.StatusUnauthorized,
+			expectedWWWAuthenticate: `Bearer`,
+		},
+		{
+			name:                    "basic credentials",
+			authorization:           "Basic dXNlcjpwYXNz",
+			expectedStatus:          http.StatusUnauthorized,
+			expectedWWWAuthenticate: `Bearer`,
+		},
+		{
+			name:                    "expired token",
+			authorization:           "Bearer " + signUserToken(t, key, testUserID, time.Now().Add(-time.Hour)),
+			expectedStatus:          http.StatusUnauthorized,
+			expectedWWWAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:                    "token signed with another key",
+			authorization:           "Bearer " + signUserToken(t, otherKey, testUserID, time.Now().Add(time.Hour)),
+			expectedStatus:          http.StatusUnauthorized,
+			expectedWWWAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:                    "sub claim not a uuid",
+			authorization:           "Bearer " + signUserToken(t, key, "user123", time.Now().Add(time.Hour)),
+			expectedStatus:          http.StatusUnauthorized,
+			expectedWWWAuthenticate: `Bearer error="invalid_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/wallet", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.userIDHeader != "" {
+				req.Header.Set(models.UserIDHeader, tt.userIDHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedWWWAuthenticate, rec.Header().Get("WWW-Authenticate"))
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				UserID string   `json:"user_id"`
+				Scopes []string `json:"scopes"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedUserID, resp.UserID)
+			assert.Contains(t, resp.Scopes, "wallet:read")
+		})
+	}
+}
+
+func TestAuthenticatorWithoutVerifier(t *testing.T) {
+	router := newAuthRouter(middleware.NewAuthenticator(slog.Default(), nil, nil, "", time.Minute), "admin")
+
+	tests := []struct {
+		name           string
+		path           string
+		userIDHeader   string
+		expectedStatus int
+	}{
+		{
+			name:           "wallet request trusts the user id header, without the admin scope",
+			path:           "/wallet",
+			userIDHeader:   testUserID,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "wallet request with an invalid user id header",
+			path:           "/wallet",
+			userIDHeader:   "user123",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "admin request without api key is granted every scope",
+			path:           "/admin",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.userIDHeader != "" {
+				req.Header.Set(models.UserIDHeader, tt.userIDHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestAuthenticatorAdminRequiresAPIKey(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+	router := newAuthRouter(middleware.NewAuthenticator(slog.Default(), verifier, nil, "", time.Minute), "admin")
+
+	// User tokens do not authenticate admin requests
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+signUserToken(t, key, testUserID, time.Now().Add(time.Hour)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// signedRequest is an API key request, signed over "{method}\n{path}\n{timestamp}\n{hex sha256 of body}" with the signed
+// method, path, timestamp and body, which default to the ones sent.
+type signedRequest struct {
+	method, path string
+	body         string
+	timestamp    time.Time
+
+	signedMethod, signedPath, signedBody string
+	signedTimestamp                      *time.Time
+}
+
+func (r signedRequest) build(secret string) *http.Request {
+	signedMethod, signedPath, signedBody, signedTimestamp := r.method, r.path, r.body, r.timestamp
+	if r.signedMethod != "" {
+		signedMethod = r.signedMethod
+	}
+	if r.signedPath != "" {
+		signedPath = r.signedPath
+	}
+	if r.signedBody != "" {
+		signedBody = r.signedBody
+	}
+	if r.signedTimestamp != nil {
+		signedTimestamp = *r.signedTimestamp
+	}
+
+	req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+	req.Header.Set(models.APIKeyIDHeader, apiKeyID)
+	req.Header.Set(models.APITimestampHeader, strconv.FormatInt(r.timestamp.Unix(), 10))
+	req.Header.Set(
+		models.APISignatureHeader,
+		domainwallet.SignAPIRequest(secret, signedMethod, signedPath, signedTimestamp, []byte(signedBody)),
+	)
+
+	return req
+}
+
+func TestAuthenticatorAPIKey(t *testing.T) {
+	verifier, _ := newTestVerifier(t)
+
+	adminKey, secret, err := domainwallet.NewAPIKey(apiKeyID, "back-office", []string{domainwallet.ScopeAdmin}, apiKeyServerSecret)
+	require.NoError(t, err)
+
+	readKey := adminKey
+	readKey.Scopes = []string{domainwallet.ScopeWalletRead}
+
+	revokedKey := adminKey
+	revokedAt := time.Now().Add(-time.Hour)
+	revokedKey.RevokedAt = &revokedAt
+
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	errDB := errors.New("db down")
+
+	tests := []struct {
+		name           string
+		request        signedRequest
+		prepareMock    func(*mocks.MockIWalletRepository)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "signed request, body restored for the handler",
+			request: signedRequest{method: http.MethodPost, path: "/admin", body: `{"amount":100}`, timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"body":"{\"amount\":100}"}`,
+		},
+		{
+			name:    "signed request with query and no body, within the replay window",
+			request: signedRequest{method: http.MethodGet, path: "/admin?limit=5", timestamp: now.Add(-4 * time.Minute)},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "query left out of the signature",
+			request: signedRequest{method: http.MethodGet, path: "/admin?limit=5", signedPath: "/admin", timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "body tampered after signing",
+			request: signedRequest{
+				method:     http.MethodPost,
+				path:       "/admin",
+				body:       `{"amount":100000}`,
+				signedBody: `{"amount":100}`,
+				timestamp:  now,
+			},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "method other than the signed one",
+			request: signedRequest{method: http.MethodPost, path: "/admin", signedMethod: http.MethodGet, timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "timestamp other than the signed one",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now, signedTimestamp: &earlier},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "replayed outside the window",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now.Add(-signatureWindow - time.Minute)},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "timestamp too far in the future",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now.Add(signatureWindow + time.Minute)},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(adminKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "revoked key",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(revokedKey, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "unknown key",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(domainwallet.APIKey{}, domainwallet.ErrAPIKeyNotFound)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:    "key without the scope",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(readKey, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "repository error",
+			request: signedRequest{method: http.MethodGet, path: "/admin", timestamp: now},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(domainwallet.APIKey{}, errDB)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.prepareMock(mockRepo)
+
+			authenticator := middleware.NewAuthenticator(
+				slog.Default(),
+				verifier,
+				servicewallet.New(mockRepo),
+				apiKeyServerSecret,
+				signatureWindow,
+			)
+			router := newAuthRouter(authenticator, domainwallet.ScopeAdmin)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, tt.request.build(secret))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticatorAPIKeyCanonicalString(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, secret, err := domainwallet.NewAPIKey(apiKeyID, "back-office", []string{domainwallet.ScopeAdmin}, apiKeyServerSecret)
+	require.NoError(t, err)
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	mockRepo.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(key, nil)
+
+	verifier, _ := newTestVerifier(t)
+	authenticator := middleware.NewAuthenticator(slog.Default(), verifier, servicewallet.New(mockRepo), apiKeyServerSecret, signatureWindow)
+	router := newAuthRouter(authenticator, domainwallet.ScopeAdmin)
+
+	// Signed the way clients are documented to: method, path with query, unix timestamp and hex sha256 of the body
+	body := `{"amount":100}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodySum := sha256.Sum256([]byte(body))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("POST\n/admin?dry_run=true\n" + timestamp + "\n" + hex.EncodeToString(bodySum[:])))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin?dry_run=true", strings.NewReader(body))
+	req.Header.Set(models.APIKeyIDHeader, apiKeyID)
+	req.Header.Set(models.APITimestampHeader, timestamp)
+	req.Header.Set(models.APISignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"body":"{\"amount\":100}"}`, rec.Body.String())
+}
+
+func TestAuthenticatorAPIKeyRequest(t *testing.T) {
+	verifier, _ := newTestVerifier(t)
+
+	walletKey, secret, err := domainwallet.NewAPIKey(apiKeyID, "payment-gateway", []string{domainwallet.ScopeWalletRead}, apiKeyServerSecret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		keyID          string
+		userIDHeader   string
+		body           string
+		prepareMock    func(*mocks.MockIWalletRepository)
+		expectedStatus int
+		expectedUserID string
+	}{
+		{
+			name:         "acts on behalf of the user id header",
+			keyID:        apiKeyID,
+			userIDHeader: testUserID,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(walletKey, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedUserID: testUserID,
+		},
+		{
+			name:  "no user id header",
+			keyID: apiKeyID,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), apiKeyID).Return(walletKey, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "key id not a uuid",
+			keyID:          "key-1",
+			userIDHeader:   testUserID,
+			prepareMock:    func(m *mocks.MockIWalletRepository) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "body too large to be signed",
+			keyID:          apiKeyID,
+			userIDHeader:   testUserID,
+			body:           strings.Repeat("a", 1<<20+1),
+			prepareMock:    func(m *mocks.MockIWalletRepository) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.prepareMock(mockRepo)
+
+			authenticator := middleware.NewAuthenticator(
+				slog.Default(),
+				verifier,
+				servicewallet.New(mockRepo),
+				apiKeyServerSecret,
+				signatureWindow,
+			)
+			router := newAuthRouter(authenticator, domainwallet.ScopeWalletRead)
+
+			req := signedRequest{method: http.MethodGet, path: "/wallet", body: tt.body, timestamp: time.Now()}.build(secret)
+			req.Header.Set(models.APIKeyIDHeader, tt.keyID)
+			if tt.userIDHeader != "" {
+				req.Header.Set(models.UserIDHeader, tt.userIDHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				UserID string   `json:"user_id"`
+				Scopes []string `json:"scopes"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedUserID, resp.UserID)
+			assert.Equal(t, []string{domainwallet.ScopeWalletRead}, resp.Scopes)
+		})
+	}
+}
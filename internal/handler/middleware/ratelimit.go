@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+	"github.com/jennwah/crypto-assignment/internal/pkg/ratelimit"
+)
+
+// rateLimitContextKey holds the result of the most restrictive limit counted for the request, the one its headers report.
+const rateLimitContextKey = "rateLimit"
+
+// RateLimiter rejects requests over the limits of their policy with 429 Too Many Requests. Every response reports
+// its most restrictive limit in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset (seconds) headers,
+// and 429 responses how many seconds to wait in Retry-After.
+type RateLimiter struct {
+	logger   *slog.Logger
+	limiter  *ratelimit.Limiter
+	policy   ratelimit.Policy
+	failOpen bool
+}
+
+// NewRateLimiter returns a RateLimiter allowing requests when Redis is unavailable if failOpen,
+// or rejecting them with 503 Service Unavailable otherwise.
+func NewRateLimiter(
+	logger *slog.Logger,
+	limiter *ratelimit.Limiter,
+	policy ratelimit.Policy,
+	failOpen bool,
+) *RateLimiter {
+	return &RateLimiter{
+		logger:   logger,
+		limiter:  limiter,
+		policy:   policy,
+		failOpen: failOpen,
+	}
+}
+
+// ByIP limits requests per client IP, before they are authenticated.
+func (rl *RateLimiter) ByIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl.allow(c, "ip:"+c.ClientIP(), rl.policy.IP) {
+			c.Next()
+		}
+	}
+}
+
+// ByPrincipal limits authenticated requests per API key, or per user otherwise, and per API key or user on routes
+// with a limit of their own.
+func (rl *RateLimiter) ByPrincipal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, limit := "user:"+c.GetString(models.UserIDContextKey), rl.policy.User
+		if apiKeyID := c.GetString(models.APIKeyIDContextKey); apiKeyID != "" {
+			principal, limit = "apikey:"+apiKeyID, rl.policy.APIKey
+		}
+
+		if !rl.allow(c, principal, limit) {
+			return
+		}
+
+		route := ratelimit.RouteKey(c.Request.Method, c.FullPath())
+		if routeLimit, ok := rl.policy.Routes[route]; ok && !rl.allow(c, principal+":"+route, routeLimit) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// allow counts the request against the limit of key, setting the rate limit headers when it is the most restrictive
+// limit so far. It aborts the request and returns false when the limit is exceeded, or Redis is unavailable and
+// the limiter fails closed.
+func (rl *RateLimiter) allow(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	if limit.Unlimited() {
+		return true
+	}
+
+	result, err := rl.limiter.Allow(c, key, limit)
+	if err != nil {
+		rl.logger.Error("rate limit middleware err", slog.String("key", key), slog.Any("error", err))
+		if rl.failOpen {
+			return true
+		}
+
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Message: "rate limiter unavailable",
+		})
+		return false
+	}
+
+	previous, counted := c.Get(rateLimitContextKey)
+	if previousResult, _ := previous.(ratelimit.Result); !counted || result.Remaining < previousResult.Remaining {
+		c.Set(rateLimitContextKey, result)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit.Rate))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	}
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Message: "too many requests",
+		})
+		return false
+	}
+
+	return true
+}
+
+// ceilSeconds rounds d up to whole seconds, so clients waiting that long are not rejected again.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,264 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/handler/middleware"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+	"github.com/jennwah/crypto-assignment/internal/pkg/ratelimit"
+)
+
+const testClientIP = "192.0.2.10"
+
+var testPolicy = ratelimit.Policy{
+	IP:     ratelimit.Limit{Rate: 10, Period: time.Second},
+	User:   ratelimit.Limit{Rate: 300, Period: time.Minute},
+	APIKey: ratelimit.Limit{Rate: 3000, Period: time.Minute},
+	Routes: map[string]ratelimit.Limit{
+		"POST /transfer": {Rate: 5, Period: time.Minute},
+	},
+}
+
+// expectGCRA expects the rate limit script to run on the bucket of key, answering allowed, remaining,
+// retry after and reset after in microseconds.
+func expectGCRA(redisMock redismock.ClientMock, key string, limit ratelimit.Limit, result ...int64) *redismock.ExpectedCmd {
+	interval := max(limit.Period.Microseconds()/int64(limit.Rate), 1)
+	cmd := redisMock.Regexp().ExpectEvalSha(`^[0-9a-f]{40}$`, []string{regexp.QuoteMeta("ratelimit:" + key)}, limit.Rate, interval)
+	if len(result) > 0 {
+		values := make([]interface{}, len(result))
+		for i, value := range result {
+			values[i] = value
+		}
+		cmd.SetVal(values)
+	}
+
+	return cmd
+}
+
+// newRateLimitRouter limits GET /ping by client IP, and GET /wallet and POST /transfer by the user, or the API key
+// when apiKeyID is not empty.
+func newRateLimitRouter(rateLimiter *middleware.RateLimiter, apiKeyID string) *gin.Engine {
+	router := gin.New()
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+
+	router.GET("/ping", rateLimiter.ByIP(), ok)
+
+	authenticated := router.Group("/", func(c *gin.Context) {
+		c.Set(models.UserIDContextKey, testUserID)
+		if apiKeyID != "" {
+			c.Set(models.APIKeyIDContextKey, apiKeyID)
+		}
+		c.Next()
+	}, rateLimiter.ByPrincipal())
+	authenticated.GET("/wallet", ok)
+	authenticated.POST("/transfer", ok)
+
+	return router
+}
+
+func TestRateLimiter(t *testing.T) {
+	errRedis := errors.New("connection refused")
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		apiKeyID        string
+		policy          ratelimit.Policy
+		failOpen        bool
+		prepareRedis    func(redisMock redismock.ClientMock)
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			name:   "allowed by ip",
+			method: http.MethodGet,
+			path:   "/ping",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "ip:"+testClientIP, testPolicy.IP, 1, 9, 0, 100000)
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "9",
+				"RateLimit-Reset":     "1",
+				"Retry-After":         "",
+			},
+		},
+		{
+			name:   "denied by ip",
+			method: http.MethodGet,
+			path:   "/ping",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "ip:"+testClientIP, testPolicy.IP, 0, 0, 1500000, 2000000)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "2",
+				"Retry-After":         "2",
+			},
+		},
+		{
+			name:           "no limit",
+			method:         http.MethodGet,
+			path:           "/ping",
+			prepareRedis:   func(redisMock redismock.ClientMock) {},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit": "",
+				"Retry-After":     "",
+			},
+		},
+		{
+			name:     "redis unavailable fails open",
+			method:   http.MethodGet,
+			path:     "/ping",
+			policy:   testPolicy,
+			failOpen: true,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "ip:"+testClientIP, testPolicy.IP).SetErr(errRedis)
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit": "",
+				"Retry-After":     "",
+			},
+		},
+		{
+			name:   "redis unavailable fails closed",
+			method: http.MethodGet,
+			path:   "/ping",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "ip:"+testClientIP, testPolicy.IP).SetErr(errRedis)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit": "",
+				"Retry-After":     "",
+			},
+		},
+		{
+			name:   "allowed by user",
+			method: http.MethodGet,
+			path:   "/wallet",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "user:"+testUserID, testPolicy.User, 1, 299, 0, 200000)
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "300",
+				"RateLimit-Remaining": "299",
+				"RateLimit-Reset":     "1",
+			},
+		},
+		{
+			name:     "allowed by api key",
+			method:   http.MethodGet,
+			path:     "/wallet",
+			apiKeyID: apiKeyID,
+			policy:   testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "apikey:"+apiKeyID, testPolicy.APIKey, 1, 2999, 0, 20000)
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "3000",
+				"RateLimit-Remaining": "2999",
+				"RateLimit-Reset":     "1",
+			},
+		},
+		{
+			name:   "route limit is the most restrictive",
+			method: http.MethodPost,
+			path:   "/transfer",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "user:"+testUserID, testPolicy.User, 1, 299, 0, 200000)
+				expectGCRA(redisMock, "user:"+testUserID+":POST /transfer", testPolicy.Routes["POST /transfer"], 1, 4, 0, 12000000)
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "4",
+				"RateLimit-Reset":     "12",
+			},
+		},
+		{
+			name:   "denied by route",
+			method: http.MethodPost,
+			path:   "/transfer",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "user:"+testUserID, testPolicy.User, 1, 299, 0, 200000)
+				expectGCRA(redisMock, "user:"+testUserID+":POST /transfer", testPolicy.Routes["POST /transfer"], 0, 0, 11500000, 60000000)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "5",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "12",
+			},
+		},
+		{
+			name:   "denied by user before the route is counted",
+			method: http.MethodPost,
+			path:   "/transfer",
+			policy: testPolicy,
+			prepareRedis: func(redisMock redismock.ClientMock) {
+				expectGCRA(redisMock, "user:"+testUserID, testPolicy.User, 0, 0, 200000, 60000000)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit":     "300",
+				"RateLimit-Remaining": "0",
+				"Retry-After":         "1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisClient, redisMock := redismock.NewClientMock()
+			tt.prepareRedis(redisMock)
+
+			rateLimiter := middleware.NewRateLimiter(
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				ratelimit.NewLimiter(redisClient),
+				tt.policy,
+				tt.failOpen,
+			)
+			router := newRateLimitRouter(rateLimiter, tt.apiKeyID)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.RemoteAddr = testClientIP + ":54321"
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			for header, value := range tt.expectedHeaders {
+				assert.Equal(t, value, rec.Header().Get(header), header)
+			}
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
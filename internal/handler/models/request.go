@@ -1,9 +1,44 @@
 package models
 
 const (
+	// UserIDContextKey holds the id of the authenticated user in the gin context
+	UserIDContextKey = "userID"
+	// ScopesContextKey holds the scopes granted to the authenticated caller
+	ScopesContextKey = "scopes"
+	// APIKeyIDContextKey holds the id of the API key of requests authenticated by one
+	APIKeyIDContextKey = "apiKeyID"
+
 	UserIDHeader         = "X-USER-ID"
 	IdempotencyKeyHeader = "X-IDEMPOTENCY-KEY"
+	APIKeyIDHeader       = "X-API-KEY-ID"
+	APITimestampHeader   = "X-API-TIMESTAMP"
+	APISignatureHeader   = "X-API-SIGNATURE"
+
+	UserIDPathParams           = "userID"
+	HoldIDPathParams           = "holdID"
+	TransactionIDPathParams    = "transactionID"
+	PeriodPathParams           = "period"
+	ReconciliationIDPathParams = "reconciliationID"
+	WebhookIDPathParams        = "webhookID"
+	DeliveryIDPathParams       = "deliveryID"
+	APIKeyIDPathParams         = "apiKeyID"
+	TransactionTypePathParams  = "type"
+	AssetPathParams            = "asset"
 
 	PageQueryParams     = "page"
 	PageSizeQueryParams = "pageSize"
+	AssetQueryParams    = "asset"
+	LimitQueryParams    = "limit"
+
+	CursorQueryParams       = "cursor"
+	TypeQueryParams         = "type"
+	StatusQueryParams       = "status"
+	FromQueryParams         = "from"
+	ToQueryParams           = "to"
+	MinAmountQueryParams    = "minAmount"
+	MaxAmountQueryParams    = "maxAmount"
+	CounterpartyQueryParams = "counterparty"
+	FormatQueryParams       = "format"
+	AsOfQueryParams         = "as_of"
+	AmountQueryParams       = "amount"
 )
@@ -2,4 +2,6 @@ package models
 
 type ErrorResponse struct {
 	Message string `json:"message"`
+	// TransactionID is set when the request was recorded as a failed transaction
+	TransactionID string `json:"transaction_id,omitempty"`
 }
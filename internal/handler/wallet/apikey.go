@@ -0,0 +1,171 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"   binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+type APIKeyResponse struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"created_at"`
+	RevokedAt *string  `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	// Secret signs the requests of the key, it is only returned once
+	Secret string `json:"secret"`
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+func newAPIKeyResponse(key domainwallet.APIKey) APIKeyResponse {
+	resp := APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+
+	if key.RevokedAt != nil {
+		revokedAt := key.RevokedAt.Format(time.RFC3339)
+		resp.RevokedAt = &revokedAt
+	}
+
+	return resp
+}
+
+// CreateAPIKey godoc
+// @Summary      Create API key
+// @Description  Creates an API key for an internal service, granted scopes among wallet:read, wallet:deposit, wallet:withdraw, wallet:transfer and admin.
+// @Description  Requests are signed with the returned secret, which cannot be retrieved again: X-API-KEY-ID is the key id, X-API-TIMESTAMP the unix time in seconds
+// @Description  and X-API-SIGNATURE "sha256=" followed by the hex HMAC-SHA256 of "{method}\n{path with query}\n{timestamp}\n{hex SHA-256 of body}" keyed with the secret.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        request body CreateAPIKeyRequest true "API key name and scopes"
+// @Success      201 {object} CreateAPIKeyResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Failure      503 {object} models.ErrorResponse
+// @Router       /api/v1/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var reqBody CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	key, secret, err := h.walletService.CreateAPIKey(c, h.apiKeySecret, reqBody.Name, reqBody.Scopes)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("create api key handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(key),
+		Secret:         secret,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  Returns every API key, revoked ones included, most recent first, without their secrets.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Success      200 {object} ListAPIKeysResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/api-keys [get]
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.walletService.ListAPIKeys(c)
+	if err != nil {
+		h.logger.Error("list api keys handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListAPIKeysResponse{
+		APIKeys: make([]APIKeyResponse, 0, len(keys)),
+	}
+	for _, key := range keys {
+		resp.APIKeys = append(resp.APIKeys, newAPIKeyResponse(key))
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  Revokes the API key for good, its requests are rejected from then on.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        apiKeyID path string true "API key ID (UUID)"
+// @Success      200 {object} APIKeyResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/api-keys/{apiKeyID} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param(models.APIKeyIDPathParams)
+	if err := uuid.Validate(keyID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid api key id",
+		})
+		return
+	}
+
+	key, err := h.walletService.RevokeAPIKey(c, keyID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("revoke api key handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, newAPIKeyResponse(key))
+}
@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// GetWalletBalancesAsOfResponse is the wallet's balances at a past instant. Holds are not tracked over time,
+// so there is no available nor held part.
+type GetWalletBalancesAsOfResponse struct {
+	WalletID string                `json:"wallet_id"`
+	UserID   string                `json:"user_id"`
+	AsOf     string                `json:"as_of"`
+	Balances []BalanceAsOfResponse `json:"balances"`
+}
+
+type BalanceAsOfResponse struct {
+	Asset   string `json:"asset"`
+	Balance string `json:"balance"`
+}
+
+type ListWalletBalancesAsOfResponse struct {
+	AsOf     string                          `json:"as_of"`
+	Wallets  []GetWalletBalancesAsOfResponse `json:"wallets"`
+	PageSize int                             `json:"page_size"`
+	// NextCursor fetches the page after this one, it is omitted on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func newWalletBalancesAsOfResponse(w domainwallet.WalletBalancesAt) (GetWalletBalancesAsOfResponse, error) {
+	resp := GetWalletBalancesAsOfResponse{
+		WalletID: w.WalletID,
+		UserID:   w.UserID,
+		AsOf:     w.AsOf.Format(time.RFC3339Nano),
+		Balances: make([]BalanceAsOfResponse, 0, len(w.Balances)),
+	}
+
+	for _, b := range w.Balances {
+		asset, err := domainwallet.LookupAsset(b.Asset)
+		if err != nil {
+			return GetWalletBalancesAsOfResponse{}, err
+		}
+
+		resp.Balances = append(resp.Balances, BalanceAsOfResponse{
+			Asset:   b.Asset,
+			Balance: asset.FormatSignedAmount(b.Balance),
+		})
+	}
+
+	return resp, nil
+}
+
+// getWalletAsOf responds to GetWallet with the user wallet's balances at the as_of instant, of a single asset when not empty.
+func (h *Handler) getWalletAsOf(c *gin.Context, userID, asOfStr, asset string) {
+	asOf, err := time.Parse(time.RFC3339, asOfStr)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid as_of parameter",
+		})
+		return
+	}
+
+	balances, err := h.walletService.GetWalletBalancesAsOf(c, userID, asOf)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get wallet balances as of handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	if asset != "" {
+		balances.Balances = []domainwallet.AssetBalance{balances.BalanceOf(asset)}
+	}
+
+	resp, err := newWalletBalancesAsOfResponse(balances)
+	if err != nil {
+		h.logger.Error("get wallet balances as of handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// ListWalletBalancesAsOf godoc
+// @Summary      List wallet balances at an instant
+// @Description  Returns the balances of every wallet at a past instant, in wallet id order, paged with the next_cursor of the previous page.
+// @Description  Balances are derived from the latest daily balance snapshot before the instant and the ledger entries posted since.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        as_of query string true "Instant, RFC3339, eg 2026-03-31T23:59:59Z"
+// @Param        cursor query string false "Opaque next_cursor of the previous page"
+// @Param        pageSize query int false "Number of wallets per page (default is 100, max 1000)"
+// @Success      200 {object} ListWalletBalancesAsOfResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/balances [get]
+func (h *Handler) ListWalletBalancesAsOf(c *gin.Context) {
+	asOf, err := time.Parse(time.RFC3339, c.Query(models.AsOfQueryParams))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid as_of parameter",
+		})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery(models.PageSizeQueryParams, "100"))
+	if err != nil || pageSize < 1 || pageSize > 1000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid pageSize parameter",
+		})
+		return
+	}
+
+	// The cursor is the id of the last wallet of the previous page
+	cursor := c.Query(models.CursorQueryParams)
+	if cursor != "" {
+		if err := uuid.Validate(cursor); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Message: "invalid cursor parameter",
+			})
+			return
+		}
+	}
+
+	wallets, next, err := h.walletService.ListWalletBalancesAsOf(c, asOf, cursor, pageSize)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("list wallet balances as of handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListWalletBalancesAsOfResponse{
+		AsOf:       asOf.UTC().Format(time.RFC3339Nano),
+		Wallets:    make([]GetWalletBalancesAsOfResponse, 0, len(wallets)),
+		PageSize:   pageSize,
+		NextCursor: next,
+	}
+	for _, w := range wallets {
+		walletResp, err := newWalletBalancesAsOfResponse(w)
+		if err != nil {
+			h.logger.Error("list wallet balances as of handler err", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
+		resp.Wallets = append(resp.Wallets, walletResp)
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
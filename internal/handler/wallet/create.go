@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// CreateWallet godoc
+// @Summary      Create wallet
+// @Description  Provisions an empty wallet for the current user. Idempotent on the user, an existing wallet is returned with 200
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Success      200 {object} GetWalletResponse
+// @Success      201 {object} GetWalletResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet [post]
+func (h *Handler) CreateWallet(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	userWallet, created, err := h.walletService.CreateWallet(c, userID)
+	if err != nil {
+		h.logger.Error("create wallet handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	h.respondWallet(c, status, userWallet)
+}
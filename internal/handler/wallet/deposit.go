@@ -1,7 +1,6 @@
 package wallet
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 
@@ -12,35 +11,38 @@ import (
 )
 
 type DepositWalletRequest struct {
+	Asset  string `json:"asset"`
 	Amount uint64 `json:"amount" binding:"required"`
 }
 
 type DepositWalletResponse struct {
 	TransactionID string `json:"transaction_id"`
+	Asset         string `json:"asset"`
 }
 
 // DepositWallet godoc
 // @Summary      Deposit to wallet
-// @Description  Deposit a specific amount (in cents) to the user's wallet
+// @Description  Deposit a specific amount (in the asset's minor unit) to the user's wallet. Asset defaults to USD
 // @Tags         Wallet
 // @Accept       json
 // @Produce      json
-// @Param        X-USER-ID header string true "User ID (UUID)"
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
 // @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
-// @Param        request body DepositWalletRequest true "Deposit amount in cents"
+// @Param        request body DepositWalletRequest true "Deposit asset and amount in minor unit"
 // @Success      200 {object} DepositWalletResponse
 // @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
 // @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
 // @Failure      500 {object} models.ErrorResponse
 // @Router       /api/v1/wallet/deposit [post]
 func (h *Handler) DepositWallet(c *gin.Context) {
-	userID := c.GetHeader(models.UserIDHeader)
-	if err := uuid.Validate(userID); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-			Message: "invalid user id",
-		})
-		return
-	}
+	userID := c.GetString(models.UserIDContextKey)
 
 	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
 	if err := uuid.Validate(idempotencyKey); err != nil {
@@ -58,12 +60,33 @@ func (h *Handler) DepositWallet(c *gin.Context) {
 		return
 	}
 
-	transactionID, err := h.walletService.DepositWallet(c, userID, idempotencyKey, reqBody.Amount)
+	if reqBody.Asset == "" {
+		reqBody.Asset = domainwallet.DefaultAsset
+	}
+
+	key, err := newIdempotencyKey(idempotencyKey, string(domainwallet.Deposit), reqBody, func(transactionID string) any {
+		return DepositWalletResponse{
+			TransactionID: transactionID,
+			Asset:         reqBody.Asset,
+		}
+	})
 	if err != nil {
-		if errors.Is(err, domainwallet.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Message: domainwallet.ErrWalletNotFound.Error(),
-			})
+		h.logger.Error("deposit wallet handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := h.walletService.DepositWallet(
+		c,
+		userID,
+		key,
+		reqBody.Asset,
+		reqBody.Amount,
+	)
+	if err != nil {
+		if abortWithDomainError(c, err) {
 			return
 		}
 
@@ -74,7 +97,5 @@ func (h *Handler) DepositWallet(c *gin.Context) {
 		return
 	}
 
-	c.AbortWithStatusJSON(http.StatusOK, DepositWalletResponse{
-		TransactionID: transactionID,
-	})
+	respondIdempotent(c, resp)
 }
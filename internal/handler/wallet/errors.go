@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// domainErrorStatuses maps domain errors to the HTTP status code returned to callers.
+var domainErrorStatuses = []struct {
+	err    error
+	status int
+}{
+	{err: domainwallet.ErrWalletNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrWalletInsufficientBalance, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrWalletFrozen, status: http.StatusConflict},
+	{err: domainwallet.ErrWalletClosed, status: http.StatusConflict},
+	{err: domainwallet.ErrWalletHasBalance, status: http.StatusConflict},
+	{err: domainwallet.ErrInvalidWalletStatusTransition, status: http.StatusConflict},
+	{err: domainwallet.ErrUnsupportedAsset, status: http.StatusBadRequest},
+	{err: domainwallet.ErrSelfTransfer, status: http.StatusBadRequest},
+	{err: domainwallet.ErrKYCTierNotAllowed, status: http.StatusForbidden},
+	{err: domainwallet.ErrInvalidKYCTier, status: http.StatusBadRequest},
+	{err: domainwallet.ErrTransactionLimitExceeded, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrInvalidTransactionLimit, status: http.StatusBadRequest},
+	{err: domainwallet.ErrTransactionLimitNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrInvalidFeeSchedule, status: http.StatusBadRequest},
+	{err: domainwallet.ErrFeeScheduleNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrAmountTooLarge, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrTransactionBlocked, status: http.StatusForbidden},
+	{err: domainwallet.ErrTransactionRejected, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrReviewNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrReviewDecided, status: http.StatusConflict},
+	{err: domainwallet.ErrIdempotencyKeyReused, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrIdempotencyKeyInProgress, status: http.StatusConflict},
+	{err: domainwallet.ErrHoldNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrHoldNotActive, status: http.StatusConflict},
+	{err: domainwallet.ErrHoldExpired, status: http.StatusConflict},
+	{err: domainwallet.ErrCaptureExceedsHold, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrInvalidHoldExpiry, status: http.StatusBadRequest},
+	{err: domainwallet.ErrTransactionNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrTransactionNotReversible, status: http.StatusConflict},
+	{err: domainwallet.ErrReversalExceedsAmount, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrReversalFundsSpent, status: http.StatusUnprocessableEntity},
+	{err: domainwallet.ErrInvalidTransactionFilter, status: http.StatusBadRequest},
+	{err: domainwallet.ErrInvalidCursor, status: http.StatusBadRequest},
+	{err: domainwallet.ErrInvalidStatementPeriod, status: http.StatusBadRequest},
+	{err: domainwallet.ErrInvalidAsOf, status: http.StatusBadRequest},
+	{err: domainwallet.ErrReconciliationNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrReconciliationInProgress, status: http.StatusConflict},
+	{err: domainwallet.ErrInvalidWebhookSubscription, status: http.StatusBadRequest},
+	{err: domainwallet.ErrWebhookNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrWebhookDeliveryNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrInvalidAPIKey, status: http.StatusBadRequest},
+	{err: domainwallet.ErrAPIKeyNotFound, status: http.StatusNotFound},
+	{err: domainwallet.ErrAPIKeysDisabled, status: http.StatusServiceUnavailable},
+}
+
+// abortWithDomainError aborts the request with the status mapped to a known domain error
+// and reports whether it did so. Unknown errors are left for the caller to handle.
+func abortWithDomainError(c *gin.Context, err error) bool {
+	status, resp, ok := domainErrorResponse(err)
+	if !ok {
+		return false
+	}
+
+	c.AbortWithStatusJSON(status, resp)
+	return true
+}
+
+// domainErrorResponse returns the status and error response mapped to a known domain error.
+// Failed transactions also return the ID they were recorded under.
+func domainErrorResponse(err error) (int, models.ErrorResponse, bool) {
+	for _, e := range domainErrorStatuses {
+		if errors.Is(err, e.err) {
+			resp := models.ErrorResponse{
+				Message: e.err.Error(),
+			}
+
+			var failedErr *domainwallet.FailedTransactionError
+			if errors.As(err, &failedErr) {
+				resp.TransactionID = failedErr.TransactionID
+			}
+
+			return e.status, resp, true
+		}
+	}
+
+	return 0, models.ErrorResponse{}, false
+}
@@ -0,0 +1,334 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+const (
+	csvExportFormat   = "csv"
+	jsonlExportFormat = "jsonl"
+	ofxExportFormat   = "ofx"
+)
+
+// transactionExporter writes a statement of transactions in a single file format, one line at a time.
+type transactionExporter interface {
+	contentType() string
+	begin() error
+	write(line domainwallet.StatementLine) error
+	end() error
+}
+
+// ExportTransactionResponse is a single JSON Lines line of an export.
+type ExportTransactionResponse struct {
+	ID                  string  `json:"id"`
+	CreatedAt           string  `json:"created_at"`
+	Type                string  `json:"type"`
+	Status              string  `json:"status"`
+	Asset               string  `json:"asset"`
+	Amount              string  `json:"amount"`
+	Change              string  `json:"change"`
+	Balance             string  `json:"balance"`
+	CounterpartyUserID  *string `json:"counterparty_user_id,omitempty"`
+	FailureReason       *string `json:"failure_reason,omitempty"`
+	ParentTransactionID *string `json:"parent_transaction_id,omitempty"`
+}
+
+func newExportTransactionResponse(userID string, line domainwallet.StatementLine) (ExportTransactionResponse, error) {
+	asset, err := domainwallet.LookupAsset(line.Asset)
+	if err != nil {
+		return ExportTransactionResponse{}, err
+	}
+
+	resp := ExportTransactionResponse{
+		ID:                  line.ID,
+		CreatedAt:           line.CreatedAt,
+		Type:                string(line.Type),
+		Status:              string(line.Status),
+		Asset:               line.Asset,
+		Amount:              asset.FormatAmount(line.Amount),
+		Change:              asset.FormatSignedAmount(line.Change),
+		Balance:             asset.FormatSignedAmount(line.Balance),
+		ParentTransactionID: line.ParentTransactionID,
+	}
+
+	if counterparty := line.CounterpartyUserID(userID); counterparty != "" {
+		resp.CounterpartyUserID = &counterparty
+	}
+
+	if line.FailureReason != nil {
+		reason := string(*line.FailureReason)
+		resp.FailureReason = &reason
+	}
+
+	return resp, nil
+}
+
+type csvExporter struct {
+	userID string
+	w      *csv.Writer
+}
+
+func (e *csvExporter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvExporter) begin() error {
+	return e.w.Write([]string{
+		"id", "created_at", "type", "status", "asset", "amount", "change", "balance",
+		"counterparty_user_id", "failure_reason", "parent_transaction_id",
+	})
+}
+
+func (e *csvExporter) write(line domainwallet.StatementLine) error {
+	resp, err := newExportTransactionResponse(e.userID, line)
+	if err != nil {
+		return err
+	}
+
+	return e.w.Write([]string{
+		resp.ID, resp.CreatedAt, resp.Type, resp.Status, resp.Asset, resp.Amount, resp.Change, resp.Balance,
+		stringOrEmpty(resp.CounterpartyUserID), stringOrEmpty(resp.FailureReason), stringOrEmpty(resp.ParentTransactionID),
+	})
+}
+
+func (e *csvExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlExporter struct {
+	userID string
+	enc    *json.Encoder
+}
+
+func (e *jsonlExporter) contentType() string { return "application/x-ndjson" }
+
+func (e *jsonlExporter) begin() error { return nil }
+
+func (e *jsonlExporter) write(line domainwallet.StatementLine) error {
+	resp, err := newExportTransactionResponse(e.userID, line)
+	if err != nil {
+		return err
+	}
+
+	// Encode terminates every value with a newline
+	return e.enc.Encode(resp)
+}
+
+func (e *jsonlExporter) end() error { return nil }
+
+// ofxExporter writes an OFX 2.2 bank statement of a single asset. Failed transactions moved no money, so they are left out.
+type ofxExporter struct {
+	userID   string
+	asset    domainwallet.Asset
+	from, to time.Time
+	w        io.Writer
+	balance  int64
+}
+
+const ofxTimeLayout = "20060102150405"
+
+func (e *ofxExporter) contentType() string { return "application/x-ofx" }
+
+func (e *ofxExporter) begin() error {
+	_, err := fmt.Fprintf(e.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF><BANKACCTFROM><BANKID>WALLET</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`,
+		time.Now().UTC().Format(ofxTimeLayout),
+		e.asset.Code,
+		e.userID,
+		e.from.UTC().Format(ofxTimeLayout),
+		e.to.UTC().Format(ofxTimeLayout),
+	)
+	return err
+}
+
+func (e *ofxExporter) write(line domainwallet.StatementLine) error {
+	e.balance = line.Balance
+	if line.Change == 0 {
+		return nil
+	}
+
+	trnType := "CREDIT"
+	if line.Change < 0 {
+		trnType = "DEBIT"
+	}
+
+	postedAt, err := time.Parse(time.RFC3339Nano, line.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("transaction %s created at: %w", line.ID, err)
+	}
+
+	memo := string(line.Type)
+	if counterparty := line.CounterpartyUserID(e.userID); counterparty != "" {
+		memo += " " + counterparty
+	}
+
+	var escapedMemo strings.Builder
+	if err := xml.EscapeText(&escapedMemo, []byte(memo)); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(
+		e.w,
+		"<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><MEMO>%s</MEMO></STMTTRN>\n",
+		trnType,
+		postedAt.UTC().Format(ofxTimeLayout),
+		e.asset.FormatSignedAmount(line.Change),
+		line.ID,
+		escapedMemo.String(),
+	)
+	return err
+}
+
+// end closes the statement with the balance after the last transaction exported, zero when there was none.
+func (e *ofxExporter) end() error {
+	_, err := fmt.Fprintf(e.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`,
+		e.asset.FormatSignedAmount(e.balance),
+		e.to.UTC().Format(ofxTimeLayout),
+	)
+	return err
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// ExportTransactions godoc
+// @Summary      Export wallet transactions history
+// @Description  Streams the full wallet transactions history matching the filters, oldest first, with the balance change of every transaction and the running balance of its asset right after it.
+// @Description  csv and jsonl include failed transactions, ofx is a bank statement of a single asset which leaves them out.
+// @Tags         Wallet
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/x-ofx
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        format query string false "Export format (default is csv)" Enums(csv, jsonl, ofx)
+// @Param        asset query string false "Only export transactions of this asset, required for ofx"
+// @Param        type query string false "Only export transactions of this type" Enums(deposit, withdraw, transfer, reversal)
+// @Param        status query string false "Only export transactions of this status" Enums(success, failed, pending_review)
+// @Param        from query string false "Only export transactions created at or after, RFC3339 or YYYY-MM-DD"
+// @Param        to query string false "Only export transactions created before, RFC3339 or YYYY-MM-DD"
+// @Param        minAmount query int false "Only export transactions of at least this amount in minor unit"
+// @Param        maxAmount query int false "Only export transactions of at most this amount in minor unit"
+// @Param        counterparty query string false "Only export transactions with this user (UUID) on the other side"
+// @Success      200 {file} file
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/transactions/export [get]
+func (h *Handler) ExportTransactions(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	filter, msg, ok := parseTransactionFilter(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: msg,
+		})
+		return
+	}
+
+	format := c.DefaultQuery(models.FormatQueryParams, csvExportFormat)
+
+	var exporter transactionExporter
+	switch format {
+	case csvExportFormat:
+		exporter = &csvExporter{userID: userID, w: csv.NewWriter(c.Writer)}
+	case jsonlExportFormat:
+		exporter = &jsonlExporter{userID: userID, enc: json.NewEncoder(c.Writer)}
+	case ofxExportFormat:
+		asset, err := domainwallet.LookupAsset(filter.Asset)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Message: "asset is required for ofx format",
+			})
+			return
+		}
+
+		// OFX statements require a date range, open ends default to the epoch and now
+		from, to := filter.From, filter.To
+		if from.IsZero() {
+			from = time.Unix(0, 0)
+		}
+		if to.IsZero() {
+			to = time.Now()
+		}
+		exporter = &ofxExporter{userID: userID, asset: asset, from: from, to: to, w: c.Writer}
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid format parameter",
+		})
+		return
+	}
+
+	// The response starts on the first line, so errors before it, eg wallet not found, are still returned as JSON
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", exporter.contentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transactions.%s"`, format))
+		c.Status(http.StatusOK)
+		return exporter.begin()
+	}
+
+	err := h.walletService.StreamWalletTransactions(c, userID, filter, func(line domainwallet.StatementLine) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		return exporter.write(line)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err != nil {
+		if !started && abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("export wallet transactions handler err", slog.Any("error", err))
+		if started {
+			// The status is already sent, the truncated body is all the client gets
+			c.Abort()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+}
@@ -0,0 +1,302 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// FeeResponse is the fee breakdown of a transaction: the initiator is debited total, amount reaches the recipient or
+// leaves the wallet system and fee, flat_fee plus percentage_fee within the tier's min and max fee, is kept by the house.
+type FeeResponse struct {
+	Asset         string `json:"asset"`
+	Amount        string `json:"amount"`
+	FlatFee       string `json:"flat_fee"`
+	PercentageFee string `json:"percentage_fee"`
+	Fee           string `json:"fee"`
+	Total         string `json:"total"`
+}
+
+// SetFeeScheduleRequest is the tiers of a fee schedule, amounts in the asset's minor unit.
+type SetFeeScheduleRequest struct {
+	Tiers []FeeTierRequest `json:"tiers" binding:"required,min=1,dive"`
+}
+
+// FeeTierRequest prices amounts from min_amount up to the next tier's. Omitted min and max fees do not bound the fee.
+type FeeTierRequest struct {
+	MinAmount   uint64  `json:"min_amount"`
+	FlatFee     uint64  `json:"flat_fee"`
+	BasisPoints uint64  `json:"basis_points"`
+	MinFee      *uint64 `json:"min_fee"`
+	MaxFee      *uint64 `json:"max_fee"`
+}
+
+type FeeSchedulesResponse struct {
+	Schedules []FeeScheduleResponse `json:"schedules"`
+}
+
+type FeeScheduleResponse struct {
+	Type  string            `json:"type"`
+	Asset string            `json:"asset"`
+	Tiers []FeeTierResponse `json:"tiers"`
+}
+
+// FeeTierResponse is a tier of a fee schedule, unbounded min and max fees are null.
+type FeeTierResponse struct {
+	MinAmount   string  `json:"min_amount"`
+	FlatFee     string  `json:"flat_fee"`
+	BasisPoints uint64  `json:"basis_points"`
+	MinFee      *string `json:"min_fee"`
+	MaxFee      *string `json:"max_fee"`
+}
+
+func newFeeResponse(fee domainwallet.FeeBreakdown) (FeeResponse, error) {
+	asset, err := domainwallet.LookupAsset(fee.Asset)
+	if err != nil {
+		return FeeResponse{}, err
+	}
+
+	return FeeResponse{
+		Asset:         asset.Code,
+		Amount:        asset.FormatAmount(fee.Amount),
+		FlatFee:       asset.FormatAmount(fee.FlatFee),
+		PercentageFee: asset.FormatAmount(fee.PercentageFee),
+		Fee:           asset.FormatAmount(fee.Fee),
+		Total:         asset.FormatAmount(fee.Total),
+	}, nil
+}
+
+func newFeeScheduleResponse(schedule domainwallet.FeeSchedule) (FeeScheduleResponse, error) {
+	resp := FeeScheduleResponse{
+		Tiers: make([]FeeTierResponse, 0, len(schedule)),
+	}
+
+	for _, tier := range schedule {
+		asset, err := domainwallet.LookupAsset(tier.Asset)
+		if err != nil {
+			return FeeScheduleResponse{}, err
+		}
+
+		resp.Type, resp.Asset = string(tier.Type), asset.Code
+		resp.Tiers = append(resp.Tiers, FeeTierResponse{
+			MinAmount:   asset.FormatAmount(tier.MinAmount),
+			FlatFee:     asset.FormatAmount(tier.FlatFee),
+			BasisPoints: tier.BasisPoints,
+			MinFee:      formatLimitAmount(asset, tier.MinFee),
+			MaxFee:      formatLimitAmount(asset, tier.MaxFee),
+		})
+	}
+
+	return resp, nil
+}
+
+// QuoteFee godoc
+// @Summary      Quote transaction fee
+// @Description  Prices a withdrawal or transfer of the amount (in the asset's minor unit) with the fee schedule in effect, without moving funds. Asset defaults to USD
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        type query string true "Transaction type" Enums(withdraw, transfer)
+// @Param        asset query string false "Asset code, eg BTC"
+// @Param        amount query int true "Amount in the asset's minor unit"
+// @Success      200 {object} FeeResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/fees/quote [get]
+func (h *Handler) QuoteFee(c *gin.Context) {
+	amount, err := strconv.ParseUint(c.Query(models.AmountQueryParams), 10, 64)
+	if err != nil || amount == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid amount",
+		})
+		return
+	}
+
+	fee, err := h.walletService.QuoteFee(
+		c,
+		domainwallet.TransactionType(c.Query(models.TypeQueryParams)),
+		c.DefaultQuery(models.AssetQueryParams, domainwallet.DefaultAsset),
+		amount,
+	)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("quote fee handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newFeeResponse(fee)
+	if err != nil {
+		h.logger.Error("fee response err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// ListFeeSchedules godoc
+// @Summary      List fee schedules
+// @Description  Lists the fee schedule of every transaction type and asset charged a fee. Transactions of types and assets not listed are free
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Success      200 {object} FeeSchedulesResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/fees [get]
+func (h *Handler) ListFeeSchedules(c *gin.Context) {
+	h.respondFeeSchedules(c)
+}
+
+// SetFeeSchedule godoc
+// @Summary      Set fee schedule
+// @Description  Sets the fee schedule of a transaction type (withdraw or transfer) and asset, replacing the one in effect as a whole. Each tier prices amounts from its min_amount up to the next tier's as flat_fee plus basis_points (hundredths of a percent) of the amount rounded up, raised to min_fee and lowered to max_fee when set. Amounts below the first tier are free. Amounts are in the asset's minor unit
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        type path string true "Transaction type, withdraw or transfer"
+// @Param        asset path string true "Asset code"
+// @Param        request body SetFeeScheduleRequest true "Fee schedule tiers"
+// @Success      200 {object} FeeScheduleResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/fees/{type}/{asset} [put]
+func (h *Handler) SetFeeSchedule(c *gin.Context) {
+	var reqBody SetFeeScheduleRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	schedule := make(domainwallet.FeeSchedule, 0, len(reqBody.Tiers))
+	for _, tier := range reqBody.Tiers {
+		schedule = append(schedule, domainwallet.FeeTier{
+			Type:        domainwallet.TransactionType(c.Param(models.TransactionTypePathParams)),
+			Asset:       c.Param(models.AssetPathParams),
+			MinAmount:   tier.MinAmount,
+			FlatFee:     tier.FlatFee,
+			BasisPoints: tier.BasisPoints,
+			MinFee:      tier.MinFee,
+			MaxFee:      tier.MaxFee,
+		})
+	}
+
+	schedule, err := h.walletService.SetFeeSchedule(c, schedule)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("set fee schedule handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newFeeScheduleResponse(schedule)
+	if err != nil {
+		h.logger.Error("fee schedule response err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// DeleteFeeSchedule godoc
+// @Summary      Delete fee schedule
+// @Description  Deletes the fee schedule of a transaction type and asset, its transactions are free again
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        type path string true "Transaction type, withdraw or transfer"
+// @Param        asset path string true "Asset code"
+// @Success      200 {object} FeeSchedulesResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/fees/{type}/{asset} [delete]
+func (h *Handler) DeleteFeeSchedule(c *gin.Context) {
+	err := h.walletService.DeleteFeeSchedule(
+		c,
+		domainwallet.TransactionType(c.Param(models.TransactionTypePathParams)),
+		c.Param(models.AssetPathParams),
+	)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("delete fee schedule handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	h.respondFeeSchedules(c)
+}
+
+func (h *Handler) respondFeeSchedules(c *gin.Context) {
+	schedules, err := h.walletService.GetFeeSchedules(c)
+	if err != nil {
+		h.logger.Error("get fee schedules handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := FeeSchedulesResponse{
+		Schedules: make([]FeeScheduleResponse, 0, len(schedules)),
+	}
+	for _, schedule := range schedules {
+		s, err := newFeeScheduleResponse(schedule)
+		if err != nil {
+			h.logger.Error("fee schedule response err", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
+		resp.Schedules = append(resp.Schedules, s)
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
@@ -1,50 +1,118 @@
 package wallet
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jennwah/crypto-assignment/internal/handler/models"
 )
 
 type GetWalletResponse struct {
-	ID        string `json:"id"`
-	UserID    string `json:"user_id"`
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Balances  []BalanceResponse `json:"balances"`
+	Status    string            `json:"status"`
+	KYCTier   string            `json:"kyc_tier"`
+	CreatedAt string            `json:"created_at"`
+}
+
+// BalanceResponse splits the balance into the available part and the part held by active holds.
+type BalanceResponse struct {
+	Asset     string `json:"asset"`
 	Balance   string `json:"balance"`
-	CreatedAt string `json:"created_at"`
+	Available string `json:"available"`
+	Held      string `json:"held"`
+}
+
+func newGetWalletResponse(w domainwallet.Wallet) (GetWalletResponse, error) {
+	resp := GetWalletResponse{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		Balances:  make([]BalanceResponse, 0, len(w.Balances)),
+		Status:    string(w.Status),
+		KYCTier:   string(w.KYCTier),
+		CreatedAt: w.CreatedAt,
+	}
+
+	for _, b := range w.Balances {
+		balance, err := newBalanceResponse(b)
+		if err != nil {
+			return GetWalletResponse{}, err
+		}
+
+		resp.Balances = append(resp.Balances, balance)
+	}
+
+	return resp, nil
+}
+
+func newBalanceResponse(b domainwallet.Balance) (BalanceResponse, error) {
+	balance, err := domainwallet.FormatAmount(b.Asset, b.Balance)
+	if err != nil {
+		return BalanceResponse{}, err
+	}
+
+	available, err := domainwallet.FormatAmount(b.Asset, b.Available())
+	if err != nil {
+		return BalanceResponse{}, err
+	}
+
+	held, err := domainwallet.FormatAmount(b.Asset, b.Held)
+	if err != nil {
+		return BalanceResponse{}, err
+	}
+
+	return BalanceResponse{
+		Asset:     b.Asset,
+		Balance:   balance,
+		Available: available,
+		Held:      held,
+	}, nil
 }
 
 // GetWallet godoc
 // @Summary      Get wallet
-// @Description  Retrieves the wallet details of the current user
+// @Description  Retrieves the wallet details of the current user with its balance of every asset held, or of a single asset
+// @Description  With as_of, returns a GetWalletBalancesAsOfResponse of the balances at that past instant instead, derived from the ledger
 // @Tags         Wallet
 // @Accept       json
 // @Produce      json
-// @Param        X-USER-ID header string true "User ID (UUID)"
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        asset query string false "Only return the balance of this asset, eg BTC"
+// @Param        as_of query string false "Return the balances at this past instant, RFC3339, eg 2026-03-31T23:59:59Z"
 // @Success      200 {object} GetWalletResponse
 // @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
 // @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
 // @Failure      500 {object} models.ErrorResponse
 // @Router       /api/v1/wallet [get]
 func (h *Handler) GetWallet(c *gin.Context) {
-	userID := c.GetHeader(models.UserIDHeader)
-	if err := uuid.Validate(userID); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-			Message: "invalid user id",
-		})
+	userID := c.GetString(models.UserIDContextKey)
+
+	asset := c.Query(models.AssetQueryParams)
+	if asset != "" {
+		if _, err := domainwallet.LookupAsset(asset); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Message: "invalid asset parameter",
+			})
+			return
+		}
+	}
+
+	if asOf := c.Query(models.AsOfQueryParams); asOf != "" {
+		h.getWalletAsOf(c, userID, asOf, asset)
 		return
 	}
 
 	userWallet, err := h.walletService.GetWallet(c, userID)
 	if err != nil {
-		if errors.Is(err, domainwallet.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Message: domainwallet.ErrWalletNotFound.Error(),
-			})
+		if abortWithDomainError(c, err) {
 			return
 		}
 
@@ -55,10 +123,23 @@ func (h *Handler) GetWallet(c *gin.Context) {
 		return
 	}
 
-	c.AbortWithStatusJSON(http.StatusOK, GetWalletResponse{
-		ID:        userWallet.ID,
-		UserID:    userWallet.UserID,
-		Balance:   domainwallet.ConvertFromCentsToDollarsString(userWallet.Balance),
-		CreatedAt: userWallet.CreatedAt,
-	})
+	if asset != "" {
+		userWallet.Balances = []domainwallet.Balance{userWallet.BalanceOf(asset)}
+	}
+
+	h.respondWallet(c, http.StatusOK, userWallet)
+}
+
+// respondWallet writes the wallet as a GetWalletResponse with the given status code.
+func (h *Handler) respondWallet(c *gin.Context, status int, w domainwallet.Wallet) {
+	resp, err := newGetWalletResponse(w)
+	if err != nil {
+		h.logger.Error("wallet response err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(status, resp)
 }
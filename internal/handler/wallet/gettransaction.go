@@ -1,10 +1,10 @@
 package wallet
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,44 +14,165 @@ import (
 
 type GetWalletTransactionsHistoryResponse struct {
 	Transactions []GetWalletTransactionResponse `json:"transactions"`
-	Page         int                            `json:"page"`
-	PageSize     int                            `json:"page_size"`
-	Total        int                            `json:"total"`
-	TotalPages   int                            `json:"total_pages"`
+	// Page, Total and TotalPages are only returned in page mode, cursor mode does not count transactions
+	Page       *int `json:"page,omitempty"`
+	PageSize   int  `json:"page_size"`
+	Total      *int `json:"total,omitempty"`
+	TotalPages *int `json:"total_pages,omitempty"`
+	// NextCursor fetches the page after this one in cursor mode, it is omitted on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type GetWalletTransactionResponse struct {
 	ID                    string  `json:"id"`
 	InitiatorWalletUserID string  `json:"initiator_wallet_user_id"`
+	Asset                 string  `json:"asset"`
 	Amount                string  `json:"amount"`
 	Type                  string  `json:"type"`
 	Status                string  `json:"status"`
 	RecipientWalletUserID *string `json:"recipient_wallet_user_id,omitempty"`
+	FailureReason         *string `json:"failure_reason,omitempty"`
+	ParentTransactionID   *string `json:"parent_transaction_id,omitempty"`
+	ReversedAmount        *string `json:"reversed_amount,omitempty"`
 	CreatedAt             string  `json:"created_at"`
 }
 
+type GetWalletTransactionDetailResponse struct {
+	GetWalletTransactionResponse
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+	HoldID         *string `json:"hold_id,omitempty"`
+}
+
+func newTransactionResponse(txn domainwallet.Transaction) (GetWalletTransactionResponse, error) {
+	amount, err := domainwallet.FormatAmount(txn.Asset, txn.Amount)
+	if err != nil {
+		return GetWalletTransactionResponse{}, err
+	}
+
+	var failureReason *string
+	if txn.FailureReason != nil {
+		reason := string(*txn.FailureReason)
+		failureReason = &reason
+	}
+
+	// Only transactions reversed at least partially show the reversed amount
+	var reversedAmount *string
+	if txn.ReversedAmount > 0 {
+		reversed, err := domainwallet.FormatAmount(txn.Asset, txn.ReversedAmount)
+		if err != nil {
+			return GetWalletTransactionResponse{}, err
+		}
+		reversedAmount = &reversed
+	}
+
+	return GetWalletTransactionResponse{
+		ID:                    txn.ID,
+		InitiatorWalletUserID: txn.InitiatorWalletUserId,
+		Asset:                 txn.Asset,
+		Amount:                amount,
+		Type:                  string(txn.Type),
+		Status:                string(txn.Status),
+		RecipientWalletUserID: txn.RecipientWalletUserId,
+		FailureReason:         failureReason,
+		ParentTransactionID:   txn.ParentTransactionID,
+		ReversedAmount:        reversedAmount,
+		CreatedAt:             txn.CreatedAt,
+	}, nil
+}
+
+// parseTransactionFilter parses the transactions history filter query params,
+// returning the message of the bad request on invalid params.
+func parseTransactionFilter(c *gin.Context) (domainwallet.TransactionFilter, string, bool) {
+	filter := domainwallet.TransactionFilter{
+		Asset:              c.Query(models.AssetQueryParams),
+		Type:               domainwallet.TransactionType(c.Query(models.TypeQueryParams)),
+		Status:             domainwallet.TransactionStatus(c.Query(models.StatusQueryParams)),
+		CounterpartyUserID: c.Query(models.CounterpartyQueryParams),
+	}
+
+	if filter.CounterpartyUserID != "" {
+		if err := uuid.Validate(filter.CounterpartyUserID); err != nil {
+			return domainwallet.TransactionFilter{}, "invalid counterparty parameter", false
+		}
+	}
+
+	var ok bool
+	if filter.From, ok = parseTimeQuery(c, models.FromQueryParams); !ok {
+		return domainwallet.TransactionFilter{}, "invalid from parameter", false
+	}
+
+	if filter.To, ok = parseTimeQuery(c, models.ToQueryParams); !ok {
+		return domainwallet.TransactionFilter{}, "invalid to parameter", false
+	}
+
+	if filter.MinAmount, ok = parseAmountQuery(c, models.MinAmountQueryParams); !ok {
+		return domainwallet.TransactionFilter{}, "invalid minAmount parameter", false
+	}
+
+	if filter.MaxAmount, ok = parseAmountQuery(c, models.MaxAmountQueryParams); !ok {
+		return domainwallet.TransactionFilter{}, "invalid maxAmount parameter", false
+	}
+
+	return filter, "", true
+}
+
+// parseTimeQuery parses an RFC3339 timestamp or a YYYY-MM-DD date (midnight UTC), the zero time when absent.
+func parseTimeQuery(c *gin.Context, key string) (time.Time, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), true
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	return t, err == nil
+}
+
+// parseAmountQuery parses an amount in the asset's minor unit, zero when absent.
+func parseAmountQuery(c *gin.Context, key string) (uint64, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, true
+	}
+
+	amount, err := strconv.ParseUint(value, 10, 64)
+	return amount, err == nil && amount > 0
+}
+
 // GetTransactions godoc
 // @Summary      Get wallet transactions history
-// @Description  Retrieves the wallet transactions history of the user
+// @Description  Retrieves the wallet transactions history of the user, latest first, including failed transactions with their failure reason, and reversals linked to the transaction they reverse.
+// @Description  Passing cursor (empty for the first page) pages with the next_cursor of the previous page instead of page numbers, which neither skips nor repeats transactions landing between pages and does not count the total
 // @Tags         Wallet
 // @Accept       json
 // @Produce      json
-// @Param        X-USER-ID header string true "User ID (UUID)"
-// @Param        page query int false "Page number (default is 1)"
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        page query int false "Page number (default is 1), ignored in cursor mode"
 // @Param        pageSize query int false "Number of items per page (default is 10)"
+// @Param        cursor query string false "Opaque next_cursor of the previous page, empty for the first page in cursor mode"
+// @Param        asset query string false "Only return transactions of this asset, eg BTC"
+// @Param        type query string false "Only return transactions of this type" Enums(deposit, withdraw, transfer, reversal)
+// @Param        status query string false "Only return transactions of this status" Enums(success, failed, pending_review)
+// @Param        from query string false "Only return transactions created at or after, RFC3339 or YYYY-MM-DD"
+// @Param        to query string false "Only return transactions created before, RFC3339 or YYYY-MM-DD"
+// @Param        minAmount query int false "Only return transactions of at least this amount in minor unit"
+// @Param        maxAmount query int false "Only return transactions of at most this amount in minor unit"
+// @Param        counterparty query string false "Only return transactions with this user (UUID) on the other side"
 // @Success      200 {object} GetWalletTransactionsHistoryResponse
 // @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
 // @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
 // @Failure      500 {object} models.ErrorResponse
 // @Router       /api/v1/wallet/transactions [get]
 func (h *Handler) GetTransactions(c *gin.Context) {
-	userID := c.GetHeader(models.UserIDHeader)
-	if err := uuid.Validate(userID); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-			Message: "invalid user id",
-		})
-		return
-	}
+	userID := c.GetString(models.UserIDContextKey)
 
 	pageStr := c.DefaultQuery(models.PageQueryParams, "1")
 	pageSizeStr := c.DefaultQuery(models.PageSizeQueryParams, "10")
@@ -72,18 +193,55 @@ func (h *Handler) GetTransactions(c *gin.Context) {
 		return
 	}
 
-	offset := (page - 1) * pageSize
-	transactions, total, err := h.walletService.GetWalletTransactionsHistory(
-		c,
-		userID,
-		offset,
-		pageSize,
-	)
+	filter, msg, ok := parseTransactionFilter(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: msg,
+		})
+		return
+	}
+
+	resp := GetWalletTransactionsHistoryResponse{
+		PageSize: pageSize,
+	}
+
+	var transactions []domainwallet.Transaction
+	if cursor, cursorMode := c.GetQuery(models.CursorQueryParams); cursorMode {
+		var after *domainwallet.TransactionCursor
+		if cursor != "" {
+			decoded, err := domainwallet.DecodeTransactionCursor(cursor)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+					Message: "invalid cursor parameter",
+				})
+				return
+			}
+			after = &decoded
+		}
+
+		var next *domainwallet.TransactionCursor
+		transactions, next, err = h.walletService.GetWalletTransactionsPage(c, userID, filter, after, pageSize)
+		if next != nil {
+			resp.NextCursor = next.Encode()
+		}
+	} else {
+		var total int
+		offset := (page - 1) * pageSize
+		transactions, total, err = h.walletService.GetWalletTransactionsHistory(c, userID, filter, offset, pageSize)
+
+		totalPages := (total + pageSize - 1) / pageSize
+		resp.Page, resp.Total, resp.TotalPages = &page, &total, &totalPages
+
+		// Hand out the cursor of the next page too, so clients can move over to cursor mode
+		if err == nil && page < totalPages && len(transactions) > 0 {
+			next, cursorErr := domainwallet.CursorOf(transactions[len(transactions)-1])
+			if cursorErr == nil {
+				resp.NextCursor = next.Encode()
+			}
+		}
+	}
 	if err != nil {
-		if errors.Is(err, domainwallet.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Message: domainwallet.ErrWalletNotFound.Error(),
-			})
+		if abortWithDomainError(c, err) {
 			return
 		}
 
@@ -94,25 +252,77 @@ func (h *Handler) GetTransactions(c *gin.Context) {
 		return
 	}
 
-	resp := GetWalletTransactionsHistoryResponse{
-		Transactions: make([]GetWalletTransactionResponse, 0, len(transactions)),
-		Page:         page,
-		PageSize:     pageSize,
-		Total:        total,
-		TotalPages:   (total + pageSize - 1) / pageSize,
+	resp.Transactions = make([]GetWalletTransactionResponse, 0, len(transactions))
+	for _, txn := range transactions {
+		txnResp, err := newTransactionResponse(txn)
+		if err != nil {
+			h.logger.Error("get wallet transactions history handler err", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
+		resp.Transactions = append(resp.Transactions, txnResp)
 	}
 
-	for _, txn := range transactions {
-		resp.Transactions = append(resp.Transactions, GetWalletTransactionResponse{
-			ID:                    txn.ID,
-			InitiatorWalletUserID: txn.InitiatorWalletUserId,
-			Amount:                domainwallet.ConvertFromCentsToDollarsString(txn.Amount),
-			Type:                  string(txn.Type),
-			Status:                string(txn.Status),
-			RecipientWalletUserID: txn.RecipientWalletUserId,
-			CreatedAt:             txn.CreatedAt,
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// GetTransaction godoc
+// @Summary      Get wallet transaction
+// @Description  Retrieves a single transaction the user is a party to, with the idempotency key it was recorded under and the hold it was captured from
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        transactionID path string true "Transaction ID (UUID)"
+// @Success      200 {object} GetWalletTransactionDetailResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/transactions/{transactionID} [get]
+func (h *Handler) GetTransaction(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	transactionID := c.Param(models.TransactionIDPathParams)
+	if err := uuid.Validate(transactionID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid transaction id",
 		})
+		return
 	}
 
-	c.AbortWithStatusJSON(http.StatusOK, resp)
+	txn, err := h.walletService.GetWalletTransaction(c, userID, transactionID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get wallet transaction handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	txnResp, err := newTransactionResponse(txn)
+	if err != nil {
+		h.logger.Error("get wallet transaction handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, GetWalletTransactionDetailResponse{
+		GetWalletTransactionResponse: txnResp,
+		IdempotencyKey:               txn.IdempotencyKey,
+		HoldID:                       txn.HoldID,
+	})
 }
@@ -9,11 +9,14 @@ import (
 type Handler struct {
 	logger        *slog.Logger
 	walletService wallet.IWalletService
+	// apiKeySecret derives the secrets of API keys
+	apiKeySecret string
 }
 
-func New(logger *slog.Logger, walletService wallet.IWalletService) *Handler {
+func New(logger *slog.Logger, walletService wallet.IWalletService, apiKeySecret string) *Handler {
 	return &Handler{
 		logger:        logger,
 		walletService: walletService,
+		apiKeySecret:  apiKeySecret,
 	}
 }
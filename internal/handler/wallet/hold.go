@@ -0,0 +1,315 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type CreateHoldRequest struct {
+	Asset            string `json:"asset"`
+	Amount           uint64 `json:"amount"             binding:"required,gt=0"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds" binding:"required,gt=0"`
+}
+
+type CreateHoldResponse struct {
+	HoldID    string `json:"hold_id"`
+	Asset     string `json:"asset"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type CaptureHoldRequest struct {
+	// RecipientUserID captures the hold into a transfer to the recipient, or into a withdrawal when empty
+	RecipientUserID string `json:"recipient_user_id" binding:"omitempty,uuid"`
+	Amount          uint64 `json:"amount"            binding:"required,gt=0"`
+}
+
+type CaptureHoldResponse struct {
+	TransactionID string `json:"transaction_id"`
+	HoldID        string `json:"hold_id"`
+	Status        string `json:"status"`
+	// Fee is omitted on replays of captures recorded before they were charged
+	Fee *FeeResponse `json:"fee,omitempty"`
+}
+
+type HoldResponse struct {
+	ID             string  `json:"id"`
+	Asset          string  `json:"asset"`
+	Amount         string  `json:"amount"`
+	CapturedAmount string  `json:"captured_amount"`
+	Status         string  `json:"status"`
+	TransactionID  *string `json:"transaction_id,omitempty"`
+	ExpiresAt      string  `json:"expires_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+func newHoldResponse(hold domainwallet.Hold) (HoldResponse, error) {
+	amount, err := domainwallet.FormatAmount(hold.Asset, hold.Amount)
+	if err != nil {
+		return HoldResponse{}, err
+	}
+
+	capturedAmount, err := domainwallet.FormatAmount(hold.Asset, hold.CapturedAmount)
+	if err != nil {
+		return HoldResponse{}, err
+	}
+
+	return HoldResponse{
+		ID:             hold.ID,
+		Asset:          hold.Asset,
+		Amount:         amount,
+		CapturedAmount: capturedAmount,
+		Status:         string(hold.Status),
+		TransactionID:  hold.TransactionID,
+		ExpiresAt:      hold.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:      hold.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// CreateHold godoc
+// @Summary      Create hold
+// @Description  Reserves an amount (in the asset's minor unit) of the user's available balance without moving it, until captured, released or expired. Asset defaults to USD
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
+// @Param        request body CreateHoldRequest true "Hold asset, amount in minor unit and expiry"
+// @Success      200 {object} CreateHoldResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/holds [post]
+func (h *Handler) CreateHold(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
+	if err := uuid.Validate(idempotencyKey); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid idempotency key",
+		})
+		return
+	}
+
+	var reqBody CreateHoldRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	if reqBody.Asset == "" {
+		reqBody.Asset = domainwallet.DefaultAsset
+	}
+
+	if reqBody.ExpiresInSeconds > int64(domainwallet.MaxHoldDuration/time.Second) {
+		abortWithDomainError(c, domainwallet.ErrInvalidHoldExpiry)
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(reqBody.ExpiresInSeconds) * time.Second).Truncate(time.Second)
+
+	key, err := newIdempotencyKey(idempotencyKey, string(domainwallet.HoldOperation), reqBody, func(holdID string) any {
+		return CreateHoldResponse{
+			HoldID:    holdID,
+			Asset:     reqBody.Asset,
+			ExpiresAt: expiresAt.Format(time.RFC3339),
+		}
+	})
+	if err != nil {
+		h.logger.Error("create hold handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := h.walletService.CreateHold(c, userID, key, reqBody.Asset, reqBody.Amount, expiresAt)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("create hold handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	respondIdempotent(c, resp)
+}
+
+// CaptureHold godoc
+// @Summary      Capture hold
+// @Description  Captures a hold fully or partially into a withdrawal, or into a transfer when a recipient is given. The remainder of a partial capture is released. The fee of the withdraw or transfer fee schedule is debited on top of the captured amount, from the held and available balance together, and returned as fee. Fraud rules may block the capture (403, recorded as failed with reason fraud_blocked, the hold stays active) or hold it for review (202, status pending_review, its total stays held until an admin approves or rejects it)
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
+// @Param        holdID path string true "Hold ID (UUID)"
+// @Param        request body CaptureHoldRequest true "Captured amount in minor unit and optional transfer recipient"
+// @Success      200 {object} CaptureHoldResponse
+// @Success      202 {object} CaptureHoldResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/holds/{holdID}/capture [post]
+func (h *Handler) CaptureHold(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
+	if err := uuid.Validate(idempotencyKey); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid idempotency key",
+		})
+		return
+	}
+
+	holdID := c.Param(models.HoldIDPathParams)
+	if err := uuid.Validate(holdID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid hold id",
+		})
+		return
+	}
+
+	var reqBody CaptureHoldRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	// The captured hold is part of the request fingerprint
+	req := struct {
+		HoldID string `json:"hold_id"`
+		CaptureHoldRequest
+	}{
+		HoldID:             holdID,
+		CaptureHoldRequest: reqBody,
+	}
+
+	key, err := newChargedIdempotencyKey(
+		idempotencyKey,
+		string(domainwallet.CaptureOperation),
+		req,
+		func(transactionID string, status domainwallet.TransactionStatus, fee domainwallet.FeeBreakdown) (any, error) {
+			resp := CaptureHoldResponse{
+				TransactionID: transactionID,
+				HoldID:        holdID,
+				Status:        string(status),
+			}
+
+			if fee.Asset != "" {
+				feeResp, err := newFeeResponse(fee)
+				if err != nil {
+					return nil, err
+				}
+
+				resp.Fee = &feeResp
+			}
+
+			return resp, nil
+		},
+	)
+	if err != nil {
+		h.logger.Error("capture hold handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := h.walletService.CaptureHold(c, userID, holdID, key, reqBody.RecipientUserID, reqBody.Amount)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("capture hold handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	respondIdempotent(c, resp)
+}
+
+// ReleaseHold godoc
+// @Summary      Release hold
+// @Description  Releases an active hold back to the available balance. Releasing an already released hold returns it as is
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        holdID path string true "Hold ID (UUID)"
+// @Success      200 {object} HoldResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/holds/{holdID}/release [post]
+func (h *Handler) ReleaseHold(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	holdID := c.Param(models.HoldIDPathParams)
+	if err := uuid.Validate(holdID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid hold id",
+		})
+		return
+	}
+
+	hold, err := h.walletService.ReleaseHold(c, userID, holdID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("release hold handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newHoldResponse(hold)
+	if err != nil {
+		h.logger.Error("hold response err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// newIdempotencyKey fingerprints the operation and its bound request body, and renders the outcome
+// of the money movement as the handler would: the success response or the mapped domain error response.
+func newIdempotencyKey(
+	key, operation string,
+	req any,
+	success func(transactionID string) any,
+) (domainwallet.IdempotencyKey, error) {
+	return newChargedIdempotencyKey(
+		key,
+		operation,
+		req,
+		func(transactionID string, _ domainwallet.TransactionStatus, _ domainwallet.FeeBreakdown) (any, error) {
+			return success(transactionID), nil
+		},
+	)
+}
+
+// newChargedIdempotencyKey is newIdempotencyKey for operations charged a fee, rendered in the success response.
+// Transactions a fraud rule held for review render the success response with status pending_review as 202 Accepted.
+func newChargedIdempotencyKey(
+	key, operation string,
+	req any,
+	success func(transactionID string, status domainwallet.TransactionStatus, fee domainwallet.FeeBreakdown) (any, error),
+) (domainwallet.IdempotencyKey, error) {
+	fingerprint, err := json.Marshal(struct {
+		Operation string `json:"operation"`
+		Request   any    `json:"request"`
+	}{
+		Operation: operation,
+		Request:   req,
+	})
+	if err != nil {
+		return domainwallet.IdempotencyKey{}, fmt.Errorf("failed to fingerprint request: %w", err)
+	}
+
+	hash := sha256.Sum256(fingerprint)
+
+	return domainwallet.IdempotencyKey{
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash[:]),
+		Render: func(transactionID string, fee domainwallet.FeeBreakdown, outcome error) (domainwallet.IdempotentResponse, error) {
+			if errors.Is(outcome, domainwallet.ErrTransactionPendingReview) {
+				body, err := success(transactionID, domainwallet.PendingReview, fee)
+				if err != nil {
+					return domainwallet.IdempotentResponse{}, err
+				}
+
+				return renderIdempotent(http.StatusAccepted, body)
+			}
+
+			if outcome != nil {
+				status, resp, ok := domainErrorResponse(outcome)
+				if !ok {
+					return domainwallet.IdempotentResponse{}, fmt.Errorf("unmapped outcome: %w", outcome)
+				}
+
+				return renderIdempotent(status, resp)
+			}
+
+			body, err := success(transactionID, domainwallet.Success, fee)
+			if err != nil {
+				return domainwallet.IdempotentResponse{}, err
+			}
+
+			return renderIdempotent(http.StatusOK, body)
+		},
+	}, nil
+}
+
+func renderIdempotent(status int, body any) (domainwallet.IdempotentResponse, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	return domainwallet.IdempotentResponse{StatusCode: status, Body: b}, nil
+}
+
+// respondIdempotent writes the response recorded for the idempotency key verbatim.
+func respondIdempotent(c *gin.Context, resp domainwallet.IdempotentResponse) {
+	c.Data(resp.StatusCode, gin.MIMEJSON+"; charset=utf-8", resp.Body)
+	c.Abort()
+}
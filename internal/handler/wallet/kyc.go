@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type SetWalletKYCTierRequest struct {
+	Tier string `json:"tier" binding:"required"`
+}
+
+// SetWalletKYCTier godoc
+// @Summary      Set wallet KYC tier
+// @Description  Sets the KYC tier of the wallet of the given user. Unverified wallets can only receive funds, basic wallets can withdraw and transfer within the lower basic limits, full wallets within the default limits
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Param        request body SetWalletKYCTierRequest true "KYC tier, unverified, basic or full"
+// @Success      200 {object} GetWalletResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/kyc-tier [put]
+func (h *Handler) SetWalletKYCTier(c *gin.Context) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	var reqBody SetWalletKYCTierRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	userWallet, err := h.walletService.SetWalletKYCTier(c, userID, reqBody.Tier)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("set wallet kyc tier handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	h.respondWallet(c, http.StatusOK, userWallet)
+}
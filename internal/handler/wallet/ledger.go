@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type GetWalletLedgerResponse struct {
+	UserID   string                  `json:"user_id"`
+	Balanced bool                    `json:"balanced"`
+	Balances []LedgerBalanceResponse `json:"balances"`
+}
+
+type LedgerBalanceResponse struct {
+	Asset         string `json:"asset"`
+	Balance       string `json:"balance"`
+	LedgerBalance string `json:"ledger_balance"`
+	Matches       bool   `json:"matches"`
+}
+
+// GetWalletLedger godoc
+// @Summary      Verify wallet ledger
+// @Description  Compares the stored balance of every asset of the given user's wallet with the balance derived from its double-entry ledger entries
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Success      200 {object} GetWalletLedgerResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/ledger [get]
+func (h *Handler) GetWalletLedger(c *gin.Context) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	balances, err := h.walletService.GetWalletLedgerBalances(c, userID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get wallet ledger handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := GetWalletLedgerResponse{
+		UserID:   userID,
+		Balanced: true,
+		Balances: make([]LedgerBalanceResponse, 0, len(balances)),
+	}
+	for _, b := range balances {
+		item := newLedgerBalanceResponse(b)
+		resp.Balanced = resp.Balanced && item.Matches
+		resp.Balances = append(resp.Balances, item)
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+func newLedgerBalanceResponse(b domainwallet.LedgerBalance) LedgerBalanceResponse {
+	resp := LedgerBalanceResponse{
+		Asset:         b.Asset,
+		Balance:       strconv.FormatUint(b.Balance, 10),
+		LedgerBalance: strconv.FormatInt(b.LedgerBalance, 10),
+		Matches:       b.Matches(),
+	}
+
+	// amounts of assets no longer in the registry are reported in minor units
+	asset, err := domainwallet.LookupAsset(b.Asset)
+	if err != nil {
+		return resp
+	}
+
+	resp.Balance = asset.FormatAmount(b.Balance)
+	resp.LedgerBalance = asset.FormatSignedAmount(b.LedgerBalance)
+
+	return resp
+}
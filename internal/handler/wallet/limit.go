@@ -0,0 +1,289 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// SetTransactionLimitRequest is a wallet's own limit of a type and asset, amounts in the asset's minor unit.
+// Omitted caps allow any amount or count.
+type SetTransactionLimitRequest struct {
+	Type                 string  `json:"type" binding:"required"`
+	Asset                string  `json:"asset"`
+	PerTransactionAmount *uint64 `json:"per_transaction_amount"`
+	DailyAmount          *uint64 `json:"daily_amount"`
+	DailyCount           *uint64 `json:"daily_count"`
+	WeeklyAmount         *uint64 `json:"weekly_amount"`
+	WeeklyCount          *uint64 `json:"weekly_count"`
+	MonthlyAmount        *uint64 `json:"monthly_amount"`
+	MonthlyCount         *uint64 `json:"monthly_count"`
+}
+
+type TransactionLimitsResponse struct {
+	Limits []TransactionLimitResponse `json:"limits"`
+}
+
+// TransactionLimitResponse is a limit in effect for the wallet, the wallet's own when overridden or the default,
+// of the KYC tier named when set. Uncapped amounts and counts are null.
+type TransactionLimitResponse struct {
+	Type                 string                `json:"type"`
+	Asset                string                `json:"asset"`
+	Overridden           bool                  `json:"overridden"`
+	KYCTier              *string               `json:"kyc_tier"`
+	PerTransactionAmount *string               `json:"per_transaction_amount"`
+	Windows              []LimitWindowResponse `json:"windows"`
+}
+
+// LimitWindowResponse is what the wallet has used of a rolling window and the headroom left.
+type LimitWindowResponse struct {
+	Window          string  `json:"window"`
+	MaxAmount       *string `json:"max_amount"`
+	UsedAmount      string  `json:"used_amount"`
+	RemainingAmount *string `json:"remaining_amount"`
+	MaxCount        *uint64 `json:"max_count"`
+	UsedCount       uint64  `json:"used_count"`
+	RemainingCount  *uint64 `json:"remaining_count"`
+}
+
+func newTransactionLimitsResponse(statuses []domainwallet.LimitStatus) (TransactionLimitsResponse, error) {
+	resp := TransactionLimitsResponse{
+		Limits: make([]TransactionLimitResponse, 0, len(statuses)),
+	}
+
+	for _, status := range statuses {
+		asset, err := domainwallet.LookupAsset(status.Limit.Asset)
+		if err != nil {
+			return TransactionLimitsResponse{}, err
+		}
+
+		limit := TransactionLimitResponse{
+			Type:                 string(status.Limit.Type),
+			Asset:                asset.Code,
+			Overridden:           status.Limit.WalletID != nil,
+			PerTransactionAmount: formatLimitAmount(asset, status.Limit.PerTransaction),
+		}
+
+		if status.Limit.KYCTier != nil {
+			tier := string(*status.Limit.KYCTier)
+			limit.KYCTier = &tier
+		}
+
+		for _, h := range status.Limit.Headroom(status.Usage) {
+			limit.Windows = append(limit.Windows, LimitWindowResponse{
+				Window:          string(h.Window),
+				MaxAmount:       formatLimitAmount(asset, h.MaxAmount),
+				UsedAmount:      asset.FormatAmount(h.UsedAmount),
+				RemainingAmount: formatLimitAmount(asset, h.RemainingAmount),
+				MaxCount:        h.MaxCount,
+				UsedCount:       h.UsedCount,
+				RemainingCount:  h.RemainingCount,
+			})
+		}
+
+		resp.Limits = append(resp.Limits, limit)
+	}
+
+	return resp, nil
+}
+
+// formatLimitAmount formats a capped amount with the asset's precision, nil when uncapped.
+func formatLimitAmount(asset domainwallet.Asset, amount *uint64) *string {
+	if amount == nil {
+		return nil
+	}
+
+	formatted := asset.FormatAmount(*amount)
+	return &formatted
+}
+
+// GetTransactionLimits godoc
+// @Summary      Get wallet transaction limits
+// @Description  Get the withdrawal and transfer limits in effect for the user's wallet, per asset, with what is used and remains of the daily, weekly and monthly rolling windows. Types and assets not listed are not limited
+// @Tags         Wallet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Success      200 {object} TransactionLimitsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/limits [get]
+func (h *Handler) GetTransactionLimits(c *gin.Context) {
+	h.respondTransactionLimits(c, c.GetString(models.UserIDContextKey))
+}
+
+// GetWalletTransactionLimits godoc
+// @Summary      Get wallet transaction limits
+// @Description  Get the withdrawal and transfer limits in effect for the wallet of the given user, with what is used and remains of each rolling window
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Success      200 {object} TransactionLimitsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/limits [get]
+func (h *Handler) GetWalletTransactionLimits(c *gin.Context) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	h.respondTransactionLimits(c, userID)
+}
+
+// SetWalletTransactionLimit godoc
+// @Summary      Set wallet transaction limit
+// @Description  Sets the wallet's own limit of a transaction type (withdraw or transfer) and asset, overriding the default as a whole. Amounts are in the asset's minor unit, omitted caps allow any amount or count. Asset defaults to USD
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Param        request body SetTransactionLimitRequest true "Limit"
+// @Success      200 {object} TransactionLimitsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/limits [put]
+func (h *Handler) SetWalletTransactionLimit(c *gin.Context) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	var reqBody SetTransactionLimitRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	if reqBody.Asset == "" {
+		reqBody.Asset = domainwallet.DefaultAsset
+	}
+
+	_, err := h.walletService.SetTransactionLimit(c, userID, domainwallet.TransactionLimit{
+		Type:           domainwallet.TransactionType(reqBody.Type),
+		Asset:          reqBody.Asset,
+		PerTransaction: reqBody.PerTransactionAmount,
+		DailyAmount:    reqBody.DailyAmount,
+		DailyCount:     reqBody.DailyCount,
+		WeeklyAmount:   reqBody.WeeklyAmount,
+		WeeklyCount:    reqBody.WeeklyCount,
+		MonthlyAmount:  reqBody.MonthlyAmount,
+		MonthlyCount:   reqBody.MonthlyCount,
+	})
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("set transaction limit handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	h.respondTransactionLimits(c, userID)
+}
+
+// DeleteWalletTransactionLimit godoc
+// @Summary      Delete wallet transaction limit
+// @Description  Deletes the wallet's own limit of a transaction type and asset, the default limit applies again
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Param        type path string true "Transaction type, withdraw or transfer"
+// @Param        asset path string true "Asset code"
+// @Success      200 {object} TransactionLimitsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/limits/{type}/{asset} [delete]
+func (h *Handler) DeleteWalletTransactionLimit(c *gin.Context) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	err := h.walletService.DeleteTransactionLimit(
+		c,
+		userID,
+		domainwallet.TransactionType(c.Param(models.TransactionTypePathParams)),
+		c.Param(models.AssetPathParams),
+	)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("delete transaction limit handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	h.respondTransactionLimits(c, userID)
+}
+
+func (h *Handler) respondTransactionLimits(c *gin.Context, userID string) {
+	statuses, err := h.walletService.GetTransactionLimits(c, userID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get transaction limits handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newTransactionLimitsResponse(statuses)
+	if err != nil {
+		h.logger.Error("transaction limits response err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
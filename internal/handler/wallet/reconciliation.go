@@ -0,0 +1,179 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type ReconciliationResponse struct {
+	ID             string  `json:"id"`
+	Status         string  `json:"status"`
+	Freeze         bool    `json:"freeze"`
+	WalletsChecked int     `json:"wallets_checked"`
+	MismatchCount  int     `json:"mismatch_count"`
+	StartedAt      string  `json:"started_at"`
+	FinishedAt     *string `json:"finished_at,omitempty"`
+	// Mismatches is only returned by GetReconciliation
+	Mismatches []BalanceMismatchResponse `json:"mismatches,omitempty"`
+}
+
+type BalanceMismatchResponse struct {
+	WalletID        string `json:"wallet_id"`
+	UserID          string `json:"user_id"`
+	WalletStatus    string `json:"wallet_status"`
+	Asset           string `json:"asset"`
+	Balance         string `json:"balance"`
+	ComputedBalance string `json:"computed_balance"`
+	Difference      string `json:"difference"`
+	Frozen          bool   `json:"frozen"`
+}
+
+type ListReconciliationsResponse struct {
+	Reconciliations []ReconciliationResponse `json:"reconciliations"`
+}
+
+func newReconciliationResponse(r domainwallet.Reconciliation) (ReconciliationResponse, error) {
+	resp := ReconciliationResponse{
+		ID:             r.ID,
+		Status:         string(r.Status),
+		Freeze:         r.Freeze,
+		WalletsChecked: r.WalletsChecked,
+		MismatchCount:  r.MismatchCount,
+		StartedAt:      r.StartedAt.Format(time.RFC3339),
+	}
+
+	if r.FinishedAt != nil {
+		finishedAt := r.FinishedAt.Format(time.RFC3339)
+		resp.FinishedAt = &finishedAt
+	}
+
+	for _, m := range r.Mismatches {
+		asset, err := domainwallet.LookupAsset(m.Asset)
+		if err != nil {
+			return ReconciliationResponse{}, err
+		}
+
+		resp.Mismatches = append(resp.Mismatches, BalanceMismatchResponse{
+			WalletID:        m.WalletID,
+			UserID:          m.UserID,
+			WalletStatus:    string(m.WalletStatus),
+			Asset:           m.Asset,
+			Balance:         asset.FormatSignedAmount(m.Balance),
+			ComputedBalance: asset.FormatSignedAmount(m.ComputedBalance),
+			Difference:      asset.FormatSignedAmount(m.Difference()),
+			Frozen:          m.Frozen,
+		})
+	}
+
+	return resp, nil
+}
+
+// ListReconciliations godoc
+// @Summary      List balance reconciliations
+// @Description  Returns the latest balance reconciliations, most recent first, without their mismatches.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        limit query int false "Number of reconciliations (default is 20, max 100)"
+// @Success      200 {object} ListReconciliationsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/reconciliations [get]
+func (h *Handler) ListReconciliations(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery(models.LimitQueryParams, "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid limit parameter",
+		})
+		return
+	}
+
+	reconciliations, err := h.walletService.ListReconciliations(c, limit)
+	if err != nil {
+		h.logger.Error("list reconciliations handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListReconciliationsResponse{
+		Reconciliations: make([]ReconciliationResponse, 0, len(reconciliations)),
+	}
+	for _, r := range reconciliations {
+		reconciliationResp, err := newReconciliationResponse(r)
+		if err != nil {
+			h.logger.Error("list reconciliations handler err", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
+		resp.Reconciliations = append(resp.Reconciliations, reconciliationResp)
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// GetReconciliation godoc
+// @Summary      Get balance reconciliation
+// @Description  Returns a balance reconciliation with every wallet balance that did not match the balance recomputed from the wallet's opening balances and successful transactions.
+// @Description  Wallet status is the current one, frozen tells whether the reconciliation froze the wallet.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        reconciliationID path string true "Reconciliation ID (UUID)"
+// @Success      200 {object} ReconciliationResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/reconciliations/{reconciliationID} [get]
+func (h *Handler) GetReconciliation(c *gin.Context) {
+	reconciliationID := c.Param(models.ReconciliationIDPathParams)
+	if err := uuid.Validate(reconciliationID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid reconciliation id",
+		})
+		return
+	}
+
+	reconciliation, err := h.walletService.GetReconciliation(c, reconciliationID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get reconciliation handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newReconciliationResponse(reconciliation)
+	if err != nil {
+		h.logger.Error("get reconciliation handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
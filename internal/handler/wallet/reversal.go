@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type ReverseTransactionRequest struct {
+	// Amount refunds part of the transaction, the whole remainder is reversed when omitted
+	Amount uint64 `json:"amount"`
+}
+
+type ReverseTransactionResponse struct {
+	TransactionID       string `json:"transaction_id"`
+	ParentTransactionID string `json:"parent_transaction_id"`
+	// Refund is omitted on replays of reversals recorded before fees were refunded
+	Refund *ReversalRefundResponse `json:"refund,omitempty"`
+}
+
+// ReversalRefundResponse is what a reversal moved back: amount of the transaction and fee of its fee, total in all.
+type ReversalRefundResponse struct {
+	Asset  string `json:"asset"`
+	Amount string `json:"amount"`
+	Fee    string `json:"fee"`
+	Total  string `json:"total"`
+}
+
+// ReverseTransaction godoc
+// @Summary      Reverse transaction
+// @Description  Moves a successful deposit, withdrawal or transfer back to where it came from, fully or as a partial refund (in the asset's minor unit). The reversal is recorded as a new transaction linked to the original, and reversals never exceed the original amount in total. The initiator is refunded the fee of a withdrawal or transfer in proportion to the amount reversed so far, rounded down, so reversing the whole remainder refunds the rest of the fee, returned as refund
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
+// @Param        transactionID path string true "Transaction ID (UUID)"
+// @Param        request body ReverseTransactionRequest false "Refunded amount in minor unit, the whole remainder when omitted"
+// @Success      200 {object} ReverseTransactionResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/transactions/{transactionID}/reverse [post]
+func (h *Handler) ReverseTransaction(c *gin.Context) {
+	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
+	if err := uuid.Validate(idempotencyKey); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid idempotency key",
+		})
+		return
+	}
+
+	transactionID := c.Param(models.TransactionIDPathParams)
+	if err := uuid.Validate(transactionID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid transaction id",
+		})
+		return
+	}
+
+	// The body is optional, an empty body reverses the whole remainder
+	var reqBody ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	// The reversed transaction is part of the request fingerprint
+	req := struct {
+		TransactionID string `json:"transaction_id"`
+		ReverseTransactionRequest
+	}{
+		TransactionID:             transactionID,
+		ReverseTransactionRequest: reqBody,
+	}
+
+	key, err := newChargedIdempotencyKey(
+		idempotencyKey,
+		string(domainwallet.ReverseOperation),
+		req,
+		func(reversalID string, _ domainwallet.TransactionStatus, refund domainwallet.FeeBreakdown) (any, error) {
+			resp := ReverseTransactionResponse{
+				TransactionID:       reversalID,
+				ParentTransactionID: transactionID,
+			}
+
+			if refund.Asset != "" {
+				asset, err := domainwallet.LookupAsset(refund.Asset)
+				if err != nil {
+					return nil, err
+				}
+
+				resp.Refund = &ReversalRefundResponse{
+					Asset:  asset.Code,
+					Amount: asset.FormatAmount(refund.Amount),
+					Fee:    asset.FormatAmount(refund.Fee),
+					Total:  asset.FormatAmount(refund.Total),
+				}
+			}
+
+			return resp, nil
+		},
+	)
+	if err != nil {
+		h.logger.Error("reverse transaction handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := h.walletService.ReverseTransaction(c, transactionID, key, reqBody.Amount)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("reverse transaction handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	respondIdempotent(c, resp)
+}
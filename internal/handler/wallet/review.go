@@ -0,0 +1,194 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type ListTransactionReviewsResponse struct {
+	Reviews []TransactionReviewResponse `json:"reviews"`
+}
+
+// TransactionReviewResponse is a withdrawal or transfer held for review by rule. Its total, the amount and the fee
+// quoted when it was held, is reserved on the initiator wallet until it is approved or rejected.
+type TransactionReviewResponse struct {
+	GetWalletTransactionResponse
+	Fee       string  `json:"fee"`
+	Total     string  `json:"total"`
+	Rule      string  `json:"rule"`
+	Decision  *string `json:"decision,omitempty"`
+	DecidedAt *string `json:"decided_at,omitempty"`
+}
+
+func newTransactionReviewResponse(review domainwallet.TransactionReview) (TransactionReviewResponse, error) {
+	txnResp, err := newTransactionResponse(review.Transaction)
+	if err != nil {
+		return TransactionReviewResponse{}, err
+	}
+
+	asset, err := domainwallet.LookupAsset(review.Asset)
+	if err != nil {
+		return TransactionReviewResponse{}, err
+	}
+
+	fee := review.FeeBreakdown()
+	resp := TransactionReviewResponse{
+		GetWalletTransactionResponse: txnResp,
+		Fee:                          asset.FormatAmount(fee.Fee),
+		Total:                        asset.FormatAmount(fee.Total),
+		Rule:                         review.Rule,
+	}
+
+	if review.Decision != nil {
+		decision := string(*review.Decision)
+		resp.Decision = &decision
+	}
+
+	if review.DecidedAt != nil {
+		decidedAt := review.DecidedAt.Format(time.RFC3339)
+		resp.DecidedAt = &decidedAt
+	}
+
+	return resp, nil
+}
+
+// ListTransactionReviews godoc
+// @Summary      List transactions pending review
+// @Description  Returns the withdrawals and transfers a fraud rule held for review, oldest first, with the rule that flagged them. Their total stays reserved on the initiator wallet until approved or rejected.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        limit query int false "Number of reviews (default is 20, max 100)"
+// @Success      200 {object} ListTransactionReviewsResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/reviews [get]
+func (h *Handler) ListTransactionReviews(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery(models.LimitQueryParams, "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid limit parameter",
+		})
+		return
+	}
+
+	reviews, err := h.walletService.ListTransactionReviews(c, limit)
+	if err != nil {
+		h.logger.Error("list transaction reviews handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListTransactionReviewsResponse{
+		Reviews: make([]TransactionReviewResponse, 0, len(reviews)),
+	}
+	for _, review := range reviews {
+		reviewResp, err := newTransactionReviewResponse(review)
+		if err != nil {
+			h.logger.Error("list transaction reviews handler err", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
+		resp.Reviews = append(resp.Reviews, reviewResp)
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// ApproveTransactionReview godoc
+// @Summary      Approve transaction pending review
+// @Description  Executes a withdrawal or transfer held for review: its reserved total is debited from the initiator, the amount reaches the recipient or leaves the wallet system and the fee the house fee account. Frozen initiators and closed wallets cannot be approved (409), the review stays pending.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        transactionID path string true "Transaction ID (UUID)"
+// @Success      200 {object} TransactionReviewResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/reviews/{transactionID}/approve [post]
+func (h *Handler) ApproveTransactionReview(c *gin.Context) {
+	h.decideTransactionReview(c, "approve transaction review", h.walletService.ApproveTransactionReview)
+}
+
+// RejectTransactionReview godoc
+// @Summary      Reject transaction pending review
+// @Description  Fails a withdrawal or transfer held for review with failure reason review_rejected and releases its reserved total on the initiator wallet.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        transactionID path string true "Transaction ID (UUID)"
+// @Success      200 {object} TransactionReviewResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/reviews/{transactionID}/reject [post]
+func (h *Handler) RejectTransactionReview(c *gin.Context) {
+	h.decideTransactionReview(c, "reject transaction review", h.walletService.RejectTransactionReview)
+}
+
+// decideTransactionReview records the decision on the review of the transaction in the path and responds with it.
+func (h *Handler) decideTransactionReview(
+	c *gin.Context,
+	operation string,
+	decide func(ctx context.Context, transactionID string) (domainwallet.TransactionReview, error),
+) {
+	transactionID := c.Param(models.TransactionIDPathParams)
+	if err := uuid.Validate(transactionID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid transaction id",
+		})
+		return
+	}
+
+	review, err := decide(c, transactionID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error(operation+" handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newTransactionReviewResponse(review)
+	if err != nil {
+		h.logger.Error(operation+" handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+const (
+	jsonStatementFormat = "json"
+	htmlStatementFormat = "html"
+)
+
+//go:embed templates/statement.html
+var statementHTML string
+
+var statementTemplate = template.Must(template.New("statement").Parse(statementHTML))
+
+type GetWalletStatementResponse struct {
+	UserID      string                   `json:"user_id"`
+	Period      string                   `json:"period"`
+	PeriodStart string                   `json:"period_start"`
+	PeriodEnd   string                   `json:"period_end"`
+	Assets      []AssetStatementResponse `json:"assets"`
+}
+
+type AssetStatementResponse struct {
+	Asset          string                      `json:"asset"`
+	OpeningBalance string                      `json:"opening_balance"`
+	ClosingBalance string                      `json:"closing_balance"`
+	Totals         []StatementTotalResponse    `json:"totals"`
+	Transactions   []ExportTransactionResponse `json:"transactions"`
+}
+
+type StatementTotalResponse struct {
+	Type     string `json:"type"`
+	Count    int    `json:"count"`
+	Credited string `json:"credited"`
+	Debited  string `json:"debited"`
+}
+
+func newStatementResponse(statement *domainwallet.Statement) (GetWalletStatementResponse, error) {
+	resp := GetWalletStatementResponse{
+		UserID:      statement.UserID,
+		Period:      statement.Period.String(),
+		PeriodStart: statement.Period.Start.Format(time.RFC3339),
+		PeriodEnd:   statement.Period.End.Format(time.RFC3339),
+		Assets:      make([]AssetStatementResponse, 0, len(statement.Assets)),
+	}
+
+	for _, a := range statement.Assets {
+		asset, err := domainwallet.LookupAsset(a.Asset)
+		if err != nil {
+			return GetWalletStatementResponse{}, err
+		}
+
+		assetResp := AssetStatementResponse{
+			Asset:          a.Asset,
+			OpeningBalance: asset.FormatSignedAmount(a.OpeningBalance),
+			ClosingBalance: asset.FormatSignedAmount(a.ClosingBalance),
+			Totals:         make([]StatementTotalResponse, 0, len(a.Totals)),
+			Transactions:   make([]ExportTransactionResponse, 0, len(a.Lines)),
+		}
+
+		for _, total := range a.Totals {
+			assetResp.Totals = append(assetResp.Totals, StatementTotalResponse{
+				Type:     string(total.Type),
+				Count:    total.Count,
+				Credited: asset.FormatAmount(total.Credited),
+				Debited:  asset.FormatAmount(total.Debited),
+			})
+		}
+
+		for _, line := range a.Lines {
+			lineResp, err := newExportTransactionResponse(statement.UserID, line)
+			if err != nil {
+				return GetWalletStatementResponse{}, err
+			}
+
+			assetResp.Transactions = append(assetResp.Transactions, lineResp)
+		}
+
+		resp.Assets = append(resp.Assets, assetResp)
+	}
+
+	return resp, nil
+}
+
+// GetStatement godoc
+// @Summary      Get wallet monthly statement
+// @Description  Generates the wallet statement of a calendar month (UTC) per asset, with the opening balance, every transaction with its running balance, totals per transaction type and the closing balance.
+// @Description  Statements of past months are reproducible. The current month covers the transactions so far.
+// @Description  format=html renders a printable document, which browsers can save as PDF.
+// @Tags         Wallet
+// @Produce      json
+// @Produce      html
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Param        period path string true "Statement month, yyyy-mm"
+// @Param        asset query string false "Only include this asset, eg BTC"
+// @Param        format query string false "Statement format (default is json)" Enums(json, html)
+// @Success      200 {object} GetWalletStatementResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/statements/{period} [get]
+func (h *Handler) GetStatement(c *gin.Context) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	period, err := domainwallet.ParseStatementPeriod(c.Param(models.PeriodPathParams), time.Now().UTC())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid statement period",
+		})
+		return
+	}
+
+	format := c.DefaultQuery(models.FormatQueryParams, jsonStatementFormat)
+	if format != jsonStatementFormat && format != htmlStatementFormat {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid format parameter",
+		})
+		return
+	}
+
+	statement, err := h.walletService.GetWalletStatement(c, userID, period, c.Query(models.AssetQueryParams))
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("get wallet statement handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := newStatementResponse(statement)
+	if err != nil {
+		h.logger.Error("get wallet statement handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	if format == jsonStatementFormat {
+		c.AbortWithStatusJSON(http.StatusOK, resp)
+		return
+	}
+
+	// Render to a buffer first, so a template error is still returned as a 500
+	var buf bytes.Buffer
+	if err := statementTemplate.Execute(&buf, resp); err != nil {
+		h.logger.Error("get wallet statement handler render err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	c.Abort()
+}
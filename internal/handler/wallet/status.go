@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+// FreezeWallet godoc
+// @Summary      Freeze wallet
+// @Description  Freezes the wallet of the given user. Frozen wallets can receive funds but reject withdrawals and outgoing transfers
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Success      200 {object} GetWalletResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/freeze [post]
+func (h *Handler) FreezeWallet(c *gin.Context) {
+	h.updateWalletStatus(c, "freeze", h.walletService.FreezeWallet)
+}
+
+// UnfreezeWallet godoc
+// @Summary      Unfreeze wallet
+// @Description  Returns a frozen wallet of the given user to active
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Success      200 {object} GetWalletResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/unfreeze [post]
+func (h *Handler) UnfreezeWallet(c *gin.Context) {
+	h.updateWalletStatus(c, "unfreeze", h.walletService.UnfreezeWallet)
+}
+
+// CloseWallet godoc
+// @Summary      Close wallet
+// @Description  Permanently closes the wallet of the given user. The wallet must have zero balance
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        userID path string true "User ID (UUID)"
+// @Success      200 {object} GetWalletResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/wallets/{userID}/close [post]
+func (h *Handler) CloseWallet(c *gin.Context) {
+	h.updateWalletStatus(c, "close", h.walletService.CloseWallet)
+}
+
+func (h *Handler) updateWalletStatus(
+	c *gin.Context,
+	action string,
+	update func(ctx context.Context, userID string) (domainwallet.Wallet, error),
+) {
+	userID := c.Param(models.UserIDPathParams)
+	if err := uuid.Validate(userID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid user id",
+		})
+		return
+	}
+
+	userWallet, err := update(c, userID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error(
+			"update wallet status handler err",
+			slog.String("action", action),
+			slog.Any("error", err),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	h.respondWallet(c, http.StatusOK, userWallet)
+}
@@ -0,0 +1,286 @@
+package wallet
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+const (
+	// streamHeartbeatInterval keeps idle streams from being closed by proxies, and detects WebSocket clients gone away.
+	streamHeartbeatInterval = 15 * time.Second
+	// streamWriteTimeout bounds writing a WebSocket message to a slow client.
+	streamWriteTimeout = 10 * time.Second
+	// sseRetry is how long SSE clients wait before reconnecting, in milliseconds.
+	sseRetry = 3000
+
+	balanceStreamMessage     = "balance"
+	transactionStreamMessage = "transaction"
+)
+
+var walletStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// WalletStreamMessage is a message of the wallet stream, Type is either "balance" with a BalanceResponse
+// or "transaction" with a GetWalletTransactionResponse as Data. Over SSE, Type is the event name and Data its data.
+type WalletStreamMessage struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// newWalletSnapshotMessages returns the wallet's current balance of every asset, sent first on every stream.
+func newWalletSnapshotMessages(w domainwallet.Wallet) ([]WalletStreamMessage, error) {
+	messages := make([]WalletStreamMessage, 0, len(w.Balances))
+	for _, b := range w.Balances {
+		balance, err := newBalanceResponse(b)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, WalletStreamMessage{Type: balanceStreamMessage, Data: balance})
+	}
+
+	return messages, nil
+}
+
+// newWalletUpdateMessages returns the transaction of the update followed by the wallet's balance of its asset right after it.
+func newWalletUpdateMessages(update domainwallet.TransactionCreated) ([]WalletStreamMessage, error) {
+	txn, err := newTransactionResponse(domainwallet.Transaction{
+		ID:                    update.Transaction.ID,
+		InitiatorWalletUserId: update.Transaction.InitiatorUserID,
+		Type:                  update.Transaction.Type,
+		Status:                update.Transaction.Status,
+		Asset:                 update.Transaction.Asset,
+		Amount:                update.Transaction.Amount,
+		RecipientWalletUserId: update.Transaction.RecipientUserID,
+		FailureReason:         update.Transaction.FailureReason,
+		ParentTransactionID:   update.Transaction.ParentTransactionID,
+		CreatedAt:             update.Transaction.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := newBalanceResponse(domainwallet.Balance{
+		Asset:   update.Balance.Asset,
+		Balance: update.Balance.Balance,
+		Held:    update.Balance.Held,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []WalletStreamMessage{
+		{Type: transactionStreamMessage, Data: txn},
+		{Type: balanceStreamMessage, Data: balance},
+	}, nil
+}
+
+// subscribeWalletUpdates subscribes to the user's wallet updates with its current balances as the first messages,
+// aborting the request and returning false on failure.
+func (h *Handler) subscribeWalletUpdates(
+	ctx context.Context,
+	c *gin.Context,
+) (<-chan domainwallet.TransactionCreated, []WalletStreamMessage, bool) {
+	userID := c.GetString(models.UserIDContextKey)
+
+	userWallet, updates, err := h.walletService.SubscribeWalletUpdates(ctx, userID)
+	if err == nil {
+		var snapshot []WalletStreamMessage
+		if snapshot, err = newWalletSnapshotMessages(userWallet); err == nil {
+			return updates, snapshot, true
+		}
+	}
+
+	if abortWithDomainError(c, err) {
+		return nil, nil, false
+	}
+
+	h.logger.Error("stream wallet handler err", slog.Any("error", err))
+	c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+		Message: "internal server error",
+	})
+	return nil, nil, false
+}
+
+// StreamWallet godoc
+// @Summary      Stream wallet updates
+// @Description  Server-Sent Events stream of the user's wallet. The current balance of every asset is sent first as "balance" events,
+// @Description  then every transaction involving the wallet as a "transaction" event followed by the "balance" of its asset right after it, as soon as it is committed.
+// @Description  Event data are the JSON of a BalanceResponse or a GetWalletTransactionResponse. Comments are sent every 15 seconds to keep the connection alive.
+// @Tags         Wallet
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Success      200 {object} WalletStreamMessage
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/stream [get]
+func (h *Handler) StreamWallet(c *gin.Context) {
+	ctx := c.Request.Context()
+	updates, snapshot, ok := h.subscribeWalletUpdates(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// disable response buffering by nginx
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(messages []WalletStreamMessage) {
+		for _, m := range messages {
+			c.SSEvent(m.Type, m.Data)
+		}
+		c.Writer.Flush()
+	}
+
+	_, _ = c.Writer.WriteString("retry: " + strconv.Itoa(sseRetry) + "\n\n")
+	send(snapshot)
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				// the subscription was lost, the client reconnects
+				return
+			}
+
+			messages, err := newWalletUpdateMessages(update)
+			if err != nil {
+				h.logger.Error("stream wallet handler err", slog.Any("error", err))
+				continue
+			}
+
+			send(messages)
+		case <-heartbeat.C:
+			_, _ = c.Writer.WriteString(": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// StreamWalletWebSocket godoc
+// @Summary      Stream wallet updates over WebSocket
+// @Description  WebSocket equivalent of the wallet stream. Every message is a JSON WalletStreamMessage: the current balance of every asset first,
+// @Description  then every transaction involving the wallet followed by the balance of its asset right after it, as soon as it is committed.
+// @Description  Messages sent by the client are ignored. The server pings every 15 seconds and closes connections not answering.
+// @Tags         Wallet
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
+// @Success      101 {object} WalletStreamMessage
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/wallet/stream/ws [get]
+func (h *Handler) StreamWalletWebSocket(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "websocket upgrade required",
+		})
+		return
+	}
+
+	// The request context is not cancelled when a hijacked connection closes, the read loop below cancels it instead
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	updates, snapshot, ok := h.subscribeWalletUpdates(ctx, c)
+	if !ok {
+		return
+	}
+
+	// Upgrade replies with the error itself
+	conn, err := walletStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Read until the client goes away, which also handles its pongs and close frame
+	_ = conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeatInterval))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(messages []WalletStreamMessage) bool {
+		for _, m := range messages {
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(m); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !send(snapshot) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				_ = conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscription lost"),
+					time.Now().Add(streamWriteTimeout),
+				)
+				return
+			}
+
+			messages, err := newWalletUpdateMessages(update)
+			if err != nil {
+				h.logger.Error("stream wallet websocket handler err", slog.Any("error", err))
+				continue
+			}
+
+			if !send(messages) {
+				return
+			}
+		case <-heartbeat.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package wallet
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 
@@ -13,36 +12,41 @@ import (
 
 type TransferRequest struct {
 	RecipientUserID string `json:"recipient_user_id" binding:"required,uuid"`
+	Asset           string `json:"asset"`
 	Amount          uint64 `json:"amount"            binding:"required,gt=0"`
 }
 
 type TransferResponse struct {
-	TransactionID string `json:"transaction_id"`
+	TransactionID string      `json:"transaction_id"`
+	Status        string      `json:"status"`
+	Asset         string      `json:"asset"`
+	Fee           FeeResponse `json:"fee"`
 }
 
 // Transfer godoc
 // @Summary      Transfer money to another user
-// @Description  Transfers money from the initiator user to the recipient user.
+// @Description  Transfers an asset (amount in minor unit) from the initiator user to the recipient user. Asset defaults to USD. Unverified KYC tier initiators cannot transfer (403). The fee of the transfer fee schedule is debited from the initiator on top of the amount and returned as fee. Fraud rules may block the transfer (403, recorded as failed with reason fraud_blocked) or hold it for review (202, status pending_review, its total reserved until an admin approves or rejects it)
 // @Tags         Wallet
 // @Accept       json
 // @Produce      json
-// @Param        X-USER-ID header string true "Initiator's User ID (UUID)"
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "Initiator's User ID (UUID), must match the bearer token's subject, required with an API key"
 // @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
 // @Param        transferRequest body TransferRequest true "Transfer request payload"
 // @Success      200 {object} TransferResponse
+// @Success      202 {object} TransferResponse
 // @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
 // @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
 // @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
 // @Failure      500 {object} models.ErrorResponse
 // @Router       /api/v1/wallet/transfer [post]
 func (h *Handler) Transfer(c *gin.Context) {
-	userID := c.GetHeader(models.UserIDHeader)
-	if err := uuid.Validate(userID); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-			Message: "invalid user id",
-		})
-		return
-	}
+	userID := c.GetString(models.UserIDContextKey)
 
 	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
 	if err := uuid.Validate(idempotencyKey); err != nil {
@@ -60,25 +64,51 @@ func (h *Handler) Transfer(c *gin.Context) {
 		return
 	}
 
-	txID, err := h.walletService.Transfer(
+	if reqBody.Asset == "" {
+		reqBody.Asset = domainwallet.DefaultAsset
+	}
+
+	key, err := newChargedIdempotencyKey(
+		idempotencyKey,
+		string(domainwallet.Transfer),
+		reqBody,
+		func(transactionID string, status domainwallet.TransactionStatus, fee domainwallet.FeeBreakdown) (any, error) {
+			if fee.Asset == "" {
+				// replays of records cached before fees were charged
+				fee = domainwallet.FeeBreakdown{Asset: reqBody.Asset, Amount: reqBody.Amount, Total: reqBody.Amount}
+			}
+
+			feeResp, err := newFeeResponse(fee)
+			if err != nil {
+				return nil, err
+			}
+
+			return TransferResponse{
+				TransactionID: transactionID,
+				Status:        string(status),
+				Asset:         reqBody.Asset,
+				Fee:           feeResp,
+			}, nil
+		},
+	)
+	if err != nil {
+		h.logger.Error("transfer handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp, err := h.walletService.Transfer(
 		c,
 		userID,
 		reqBody.RecipientUserID,
-		idempotencyKey,
+		key,
+		reqBody.Asset,
 		reqBody.Amount,
 	)
 	if err != nil {
-		if errors.Is(err, domainwallet.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Message: domainwallet.ErrWalletNotFound.Error(),
-			})
-			return
-		}
-
-		if errors.Is(err, domainwallet.ErrWalletInsufficientBalance) {
-			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-				Message: domainwallet.ErrWalletInsufficientBalance.Error(),
-			})
+		if abortWithDomainError(c, err) {
 			return
 		}
 
@@ -89,7 +119,5 @@ func (h *Handler) Transfer(c *gin.Context) {
 		return
 	}
 
-	c.AbortWithStatusJSON(http.StatusOK, TransferResponse{
-		TransactionID: txID,
-	})
+	respondIdempotent(c, resp)
 }
@@ -0,0 +1,330 @@
+package wallet
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/handler/models"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"         binding:"required"`
+	// Secret signs the webhook requests, it is never returned
+	Secret     string   `json:"secret"      binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	// UserID only sends the events of the user's wallet, every wallet's when empty
+	UserID string `json:"user_id" binding:"omitempty,uuid"`
+}
+
+type WebhookResponse struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	UserID     *string  `json:"user_id,omitempty"`
+	Active     bool     `json:"active"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string  `json:"id"`
+	EventID        int64   `json:"event_id"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int    `json:"last_status_code,omitempty"`
+	LastError      *string `json:"last_error,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+	DeliveredAt    *string `json:"delivered_at,omitempty"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+var webhookDeliveryStatuses = []domainwallet.WebhookDeliveryStatus{
+	domainwallet.WebhookPending,
+	domainwallet.WebhookSucceeded,
+	domainwallet.WebhookDead,
+}
+
+func newWebhookResponse(sub domainwallet.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		EventTypes: sub.EventTypes,
+		UserID:     sub.UserID,
+		Active:     sub.Active,
+		CreatedAt:  sub.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func newWebhookDeliveryResponse(d domainwallet.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+	}
+
+	if d.Status == domainwallet.WebhookPending {
+		nextAttemptAt := d.NextAttemptAt.Format(time.RFC3339)
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+
+	if d.DeliveredAt != nil {
+		deliveredAt := d.DeliveredAt.Format(time.RFC3339)
+		resp.DeliveredAt = &deliveredAt
+	}
+
+	return resp
+}
+
+// CreateWebhook godoc
+// @Summary      Create webhook subscription
+// @Description  Subscribes a URL to wallet events, of every wallet or of a user's wallet. Events are POSTed as JSON with the X-Webhook-Id, X-Webhook-Event, X-Webhook-Timestamp and X-Webhook-Signature headers,
+// @Description  the signature being "sha256=" followed by the hex HMAC-SHA256 of "{timestamp}.{body}" keyed with the secret (16 characters at least).
+// @Description  Deliveries not acknowledged with a 2xx response are retried with exponential backoff, and dead-lettered after 8 attempts.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        request body CreateWebhookRequest true "Webhook URL, secret and event types"
+// @Success      201 {object} WebhookResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var reqBody CreateWebhookRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid request",
+		})
+		return
+	}
+
+	var userID *string
+	if reqBody.UserID != "" {
+		userID = &reqBody.UserID
+	}
+
+	sub, err := h.walletService.CreateWebhookSubscription(c, reqBody.URL, reqBody.Secret, reqBody.EventTypes, userID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("create webhook handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusCreated, newWebhookResponse(sub))
+}
+
+// ListWebhooks godoc
+// @Summary      List webhook subscriptions
+// @Description  Returns every webhook subscription, most recent first, without their secrets.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Success      200 {object} ListWebhooksResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/webhooks [get]
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	subs, err := h.walletService.ListWebhookSubscriptions(c)
+	if err != nil {
+		h.logger.Error("list webhooks handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListWebhooksResponse{
+		Webhooks: make([]WebhookResponse, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		resp.Webhooks = append(resp.Webhooks, newWebhookResponse(sub))
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// DeleteWebhook godoc
+// @Summary      Deactivate webhook subscription
+// @Description  Stops sending events to the webhook, including its pending deliveries. Deliveries are kept and can still be listed and replayed.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        webhookID path string true "Webhook ID (UUID)"
+// @Success      200 {object} WebhookResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/webhooks/{webhookID} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	webhookID := c.Param(models.WebhookIDPathParams)
+	if err := uuid.Validate(webhookID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid webhook id",
+		})
+		return
+	}
+
+	sub, err := h.walletService.DeactivateWebhookSubscription(c, webhookID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("delete webhook handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, newWebhookResponse(sub))
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Returns the latest deliveries of the webhook, most recent first. Dead deliveries exhausted their attempts and are only sent again when replayed.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        webhookID path string true "Webhook ID (UUID)"
+// @Param        status query string false "Delivery status (pending, succeeded or dead)"
+// @Param        limit query int false "Number of deliveries (default is 50, max 200)"
+// @Success      200 {object} ListWebhookDeliveriesResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/webhooks/{webhookID}/deliveries [get]
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	webhookID := c.Param(models.WebhookIDPathParams)
+	if err := uuid.Validate(webhookID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid webhook id",
+		})
+		return
+	}
+
+	status := domainwallet.WebhookDeliveryStatus(c.Query(models.StatusQueryParams))
+	if status != "" && !slices.Contains(webhookDeliveryStatuses, status) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid status parameter",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery(models.LimitQueryParams, "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid limit parameter",
+		})
+		return
+	}
+
+	deliveries, err := h.walletService.ListWebhookDeliveries(c, webhookID, status, limit)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("list webhook deliveries handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	resp := ListWebhookDeliveriesResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, newWebhookDeliveryResponse(d))
+	}
+
+	c.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary      Replay webhook delivery
+// @Description  Queues the delivery to be sent again right away, whatever its status. Dead deliveries get their 8 attempts back.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     APIKeyAuth
+// @Param        webhookID path string true "Webhook ID (UUID)"
+// @Param        deliveryID path string true "Delivery ID (UUID)"
+// @Success      202 {object} WebhookDeliveryResponse
+// @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
+// @Failure      404 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
+// @Failure      500 {object} models.ErrorResponse
+// @Router       /api/v1/admin/webhooks/{webhookID}/deliveries/{deliveryID}/replay [post]
+func (h *Handler) ReplayWebhookDelivery(c *gin.Context) {
+	webhookID := c.Param(models.WebhookIDPathParams)
+	if err := uuid.Validate(webhookID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid webhook id",
+		})
+		return
+	}
+
+	deliveryID := c.Param(models.DeliveryIDPathParams)
+	if err := uuid.Validate(deliveryID); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+			Message: "invalid delivery id",
+		})
+		return
+	}
+
+	delivery, err := h.walletService.ReplayWebhookDelivery(c, webhookID, deliveryID)
+	if err != nil {
+		if abortWithDomainError(c, err) {
+			return
+		}
+
+		h.logger.Error("replay webhook delivery handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
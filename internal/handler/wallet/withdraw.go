@@ -1,7 +1,6 @@
 package wallet
 
 import (
-	"errors"
 	"log/slog"
 	"net/http"
 
@@ -12,36 +11,41 @@ import (
 )
 
 type WithdrawWalletRequest struct {
+	Asset  string `json:"asset"`
 	Amount uint64 `json:"amount" binding:"required"`
 }
 
 type WithdrawWalletResponse struct {
-	TransactionID string `json:"transaction_id"`
+	TransactionID string      `json:"transaction_id"`
+	Status        string      `json:"status"`
+	Asset         string      `json:"asset"`
+	Fee           FeeResponse `json:"fee"`
 }
 
 // WithdrawWallet godoc
 // @Summary      Withdraw from wallet
-// @Description  Withdraw a specific amount (in cents) from the user's wallet
+// @Description  Withdraw a specific amount (in the asset's minor unit) from the user's wallet. Asset defaults to USD. Unverified KYC tier wallets cannot withdraw (403). The fee of the withdraw fee schedule is debited on top of the amount and returned as fee. Fraud rules may block the withdrawal (403, recorded as failed with reason fraud_blocked) or hold it for review (202, status pending_review, its total reserved until an admin approves or rejects it)
 // @Tags         Wallet
 // @Accept       json
 // @Produce      json
-// @Param        X-USER-ID header string true "User ID (UUID)"
+// @Security     BearerAuth
+// @Security     APIKeyAuth
+// @Param        X-USER-ID header string false "User ID (UUID), must match the bearer token's subject, required with an API key"
 // @Param        X-IDEMPOTENCY-KEY header string true "Idempotency Key (UUID)"
-// @Param        request body WithdrawWalletRequest true "Withdraw amount in cents"
+// @Param        request body WithdrawWalletRequest true "Withdraw asset and amount in minor unit"
 // @Success      200 {object} WithdrawWalletResponse
+// @Success      202 {object} WithdrawWalletResponse
 // @Failure      400 {object} models.ErrorResponse
+// @Failure      401 {object} models.ErrorResponse
+// @Failure      403 {object} models.ErrorResponse
 // @Failure      404 {object} models.ErrorResponse
+// @Failure      409 {object} models.ErrorResponse
 // @Failure      422 {object} models.ErrorResponse
+// @Failure      429 {object} models.ErrorResponse
 // @Failure      500 {object} models.ErrorResponse
 // @Router       /api/v1/wallet/withdraw [post]
 func (h *Handler) WithdrawWallet(c *gin.Context) {
-	userID := c.GetHeader(models.UserIDHeader)
-	if err := uuid.Validate(userID); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-			Message: "invalid user id",
-		})
-		return
-	}
+	userID := c.GetString(models.UserIDContextKey)
 
 	idempotencyKey := c.GetHeader(models.IdempotencyKeyHeader)
 	if err := uuid.Validate(idempotencyKey); err != nil {
@@ -59,19 +63,50 @@ func (h *Handler) WithdrawWallet(c *gin.Context) {
 		return
 	}
 
-	transactionID, err := h.walletService.WithdrawWallet(c, userID, idempotencyKey, reqBody.Amount)
+	if reqBody.Asset == "" {
+		reqBody.Asset = domainwallet.DefaultAsset
+	}
+
+	key, err := newChargedIdempotencyKey(
+		idempotencyKey,
+		string(domainwallet.Withdraw),
+		reqBody,
+		func(transactionID string, status domainwallet.TransactionStatus, fee domainwallet.FeeBreakdown) (any, error) {
+			if fee.Asset == "" {
+				// replays of records cached before fees were charged
+				fee = domainwallet.FeeBreakdown{Asset: reqBody.Asset, Amount: reqBody.Amount, Total: reqBody.Amount}
+			}
+
+			feeResp, err := newFeeResponse(fee)
+			if err != nil {
+				return nil, err
+			}
+
+			return WithdrawWalletResponse{
+				TransactionID: transactionID,
+				Status:        string(status),
+				Asset:         reqBody.Asset,
+				Fee:           feeResp,
+			}, nil
+		},
+	)
 	if err != nil {
-		if errors.Is(err, domainwallet.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Message: domainwallet.ErrWalletNotFound.Error(),
-			})
-			return
-		}
+		h.logger.Error("withdraw wallet handler err", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
 
-		if errors.Is(err, domainwallet.ErrWalletInsufficientBalance) {
-			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-				Message: domainwallet.ErrWalletInsufficientBalance.Error(),
-			})
+	resp, err := h.walletService.WithdrawWallet(
+		c,
+		userID,
+		key,
+		reqBody.Asset,
+		reqBody.Amount,
+	)
+	if err != nil {
+		if abortWithDomainError(c, err) {
 			return
 		}
 
@@ -82,7 +117,5 @@ func (h *Handler) WithdrawWallet(c *gin.Context) {
 		return
 	}
 
-	c.AbortWithStatusJSON(http.StatusOK, WithdrawWalletResponse{
-		TransactionID: transactionID,
-	})
+	respondIdempotent(c, resp)
 }
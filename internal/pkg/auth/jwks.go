@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksMinRefreshInterval bounds how often keys are fetched again, so tokens with unknown key ids
+	// or an unreachable JWKS cannot make every request fetch it.
+	jwksMinRefreshInterval = time.Minute
+	jwksFetchTimeout       = 10 * time.Second
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwk is a JSON Web Key, only the members of RSA and P-256 signing keys are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet is a JSON Web Key Set read from a URL or a file, fetched again once older than its refresh interval
+// or when a token is signed with a key id it does not know, eg after the issuer rotated its keys.
+// When fetching fails, the keys fetched last keep being used.
+type KeySet struct {
+	fetch           func(ctx context.Context) ([]byte, error)
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+
+	refreshMu sync.Mutex
+}
+
+// NewKeySet fetches the key set from url, or from file when url is empty.
+func NewKeySet(ctx context.Context, url, file string, refreshInterval time.Duration) (*KeySet, error) {
+	s := &KeySet{refreshInterval: refreshInterval}
+	switch {
+	case url != "":
+		client := &http.Client{Timeout: jwksFetchTimeout}
+		s.fetch = func(ctx context.Context) ([]byte, error) {
+			return fetchURL(ctx, client, url)
+		}
+	case file != "":
+		s.fetch = func(context.Context) ([]byte, error) {
+			return os.ReadFile(file)
+		}
+	default:
+		return nil, errors.New("no jwks url or file")
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Key returns the public key of kid. An empty kid is only accepted when the set holds a single key.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, stale := s.lookup(kid)
+	if ok && !stale {
+		return key, nil
+	}
+
+	if s.refreshDue() {
+		if err := s.refresh(ctx); err != nil && !ok {
+			return nil, err
+		}
+
+		key, ok, _ = s.lookup(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("kid %q: %w", kid, ErrUnknownKey)
+	}
+
+	return key, nil
+}
+
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stale := time.Since(s.fetchedAt) >= s.refreshInterval
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true, stale
+		}
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok, stale
+}
+
+func (s *KeySet) refreshDue() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return time.Since(s.attemptedAt) >= jwksMinRefreshInterval
+}
+
+// refresh fetches the key set once, concurrent callers wait for the fetch in progress instead of fetching it again.
+func (s *KeySet) refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	attemptedAt := s.attemptedAt
+	s.mu.RUnlock()
+	if !attemptedAt.IsZero() && time.Since(attemptedAt) < jwksMinRefreshInterval {
+		return nil
+	}
+
+	body, err := s.fetch(ctx)
+	if err == nil {
+		var keys map[string]crypto.PublicKey
+		if keys, err = parseKeySet(body); err == nil {
+			s.mu.Lock()
+			s.keys = keys
+			s.fetchedAt = time.Now()
+			s.attemptedAt = s.fetchedAt
+			s.mu.Unlock()
+			return nil
+		}
+	}
+
+	s.mu.Lock()
+	s.attemptedAt = time.Now()
+	s.mu.Unlock()
+
+	return fmt.Errorf("failed to fetch jwks: %w", err)
+}
+
+func fetchURL(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseKeySet decodes the RSA and P-256 signing keys of a JWKS, skipping keys of other types or uses.
+func parseKeySet(body []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaPublicKey()
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			key, err = k.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no rsa or p-256 signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) < 256 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("unsupported rsa key, at least 2048 bits are required")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func (k jwk) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x coordinate: %w", err)
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y coordinate: %w", err)
+	}
+
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid p-256 coordinates")
+	}
+
+	// Reject points off the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
@@ -0,0 +1,276 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
+)
+
+// testJWK is a JSON Web Key as served by an issuer.
+type testJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) testJWK {
+	return testJWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) testJWK {
+	return testJWK{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: key.Curve.Params().Name,
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwksBody(t *testing.T, keys ...testJWK) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(map[string][]testJWK{"keys": keys})
+	require.NoError(t, err)
+
+	return body
+}
+
+// newJWKSServer serves body as the JWKS and counts the times it was fetched.
+func newJWKSServer(t *testing.T, status int, body []byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &fetches
+}
+
+func generateRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+
+	return key
+}
+
+func generateECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestNewKeySet(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+	ecKey := generateECKey(t)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	offCurve := ecJWK("ec-off-curve", &ecKey.PublicKey)
+	offCurve.Y = offCurve.X
+
+	encryption := rsaJWK("rsa-enc", &rsaKey.PublicKey)
+	encryption.Use = "enc"
+
+	p384 := testJWK{
+		Kty: "EC",
+		Kid: "ec-p384",
+		Crv: "P-384",
+		X:   base64.RawURLEncoding.EncodeToString(p384Key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(p384Key.Y.Bytes()),
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		body         []byte
+		expectedKids []string
+		expectError  bool
+	}{
+		{
+			name:         "rsa and p-256 signing keys",
+			status:       http.StatusOK,
+			body:         jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)),
+			expectedKids: []string{"rsa-1", "ec-1"},
+		},
+		{
+			name:         "encryption keys, other curves and key types are skipped",
+			status:       http.StatusOK,
+			body:         jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey), encryption, p384, testJWK{Kty: "oct", Kid: "hmac"}),
+			expectedKids: []string{"rsa-1"},
+		},
+		{
+			name:        "no signing keys",
+			status:      http.StatusOK,
+			body:        jwksBody(t, encryption, p384),
+			expectError: true,
+		},
+		{
+			name:        "rsa key shorter than 2048 bits",
+			status:      http.StatusOK,
+			body:        jwksBody(t, rsaJWK("rsa-1024", &generateRSAKey(t, 1024).PublicKey)),
+			expectError: true,
+		},
+		{
+			name:        "p-256 point off the curve",
+			status:      http.StatusOK,
+			body:        jwksBody(t, offCurve),
+			expectError: true,
+		},
+		{
+			name:        "invalid json",
+			status:      http.StatusOK,
+			body:        []byte(`{"keys":`),
+			expectError: true,
+		},
+		{
+			name:        "unexpected status",
+			status:      http.StatusInternalServerError,
+			body:        jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey)),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newJWKSServer(t, tt.status, tt.body)
+
+			keys, err := auth.NewKeySet(context.Background(), server.URL, "", time.Minute)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			for _, kid := range tt.expectedKids {
+				_, err := keys.Key(context.Background(), kid)
+				assert.NoError(t, err, kid)
+			}
+		})
+	}
+}
+
+func TestNewKeySetFromFile(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey)), 0o600))
+
+	keys, err := auth.NewKeySet(context.Background(), "", file, time.Minute)
+	require.NoError(t, err)
+
+	key, err := keys.Key(context.Background(), "rsa-1")
+	require.NoError(t, err)
+	assert.Equal(t, &rsaKey.PublicKey, key)
+
+	_, err = auth.NewKeySet(context.Background(), "", "", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestKeySetKey(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+	ecKey := generateECKey(t)
+
+	tests := []struct {
+		name          string
+		keys          []testJWK
+		kid           string
+		expectedKey   any
+		expectedError error
+	}{
+		{
+			name:        "known kid",
+			keys:        []testJWK{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)},
+			kid:         "ec-1",
+			expectedKey: &ecKey.PublicKey,
+		},
+		{
+			name:        "no kid with a single key",
+			keys:        []testJWK{rsaJWK("rsa-1", &rsaKey.PublicKey)},
+			expectedKey: &rsaKey.PublicKey,
+		},
+		{
+			name:          "no kid with several keys",
+			keys:          []testJWK{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)},
+			expectedError: auth.ErrUnknownKey,
+		},
+		{
+			name:          "unknown kid",
+			keys:          []testJWK{rsaJWK("rsa-1", &rsaKey.PublicKey)},
+			kid:           "rsa-2",
+			expectedError: auth.ErrUnknownKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, fetches := newJWKSServer(t, http.StatusOK, jwksBody(t, tt.keys...))
+
+			keys, err := auth.NewKeySet(context.Background(), server.URL, "", time.Minute)
+			require.NoError(t, err)
+
+			key, err := keys.Key(context.Background(), tt.kid)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedKey, key)
+
+			// Unknown kids do not fetch the keys again within the minimum refresh interval
+			assert.Equal(t, int32(1), fetches.Load())
+		})
+	}
+}
+
+func TestKeySetKeyStale(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+	server, fetches := newJWKSServer(t, http.StatusOK, jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey)))
+
+	// Keys are stale right away, but fetched at most once per minimum refresh interval
+	keys, err := auth.NewKeySet(context.Background(), server.URL, "", 0)
+	require.NoError(t, err)
+
+	for range 3 {
+		key, err := keys.Key(context.Background(), "rsa-1")
+		require.NoError(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, key)
+	}
+
+	assert.Equal(t, int32(1), fetches.Load())
+}
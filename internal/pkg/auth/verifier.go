@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jennwah/crypto-assignment/internal/config"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier verifies RS256 and ES256 JWTs signed with a key of its JWKS, issued by its issuer to its audience and not expired.
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier fetches the JWKS of cfg, an issuer and an audience are required.
+func NewVerifier(ctx context.Context, cfg config.Auth) (*Verifier, error) {
+	if cfg.JWTIssuer == "" || cfg.JWTAudience == "" {
+		return nil, errors.New("jwt issuer and audience are required")
+	}
+
+	keys, err := NewKeySet(ctx, cfg.JWKSURL, cfg.JWKSFile, cfg.JWKSRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+			jwt.WithIssuer(cfg.JWTIssuer),
+			jwt.WithAudience(cfg.JWTAudience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.JWTLeeway),
+		),
+	}, nil
+}
+
+// Verify verifies the raw token and returns its sub claim.
+func (v *Verifier) Verify(ctx context.Context, raw string) (string, error) {
+	var claims jwt.RegisteredClaims
+	_, err := v.parser.ParseWithClaims(raw, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return "", fmt.Errorf("%w: no sub claim", ErrInvalidToken)
+	}
+
+	return claims.Subject, nil
+}
@@ -0,0 +1,219 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	"github.com/jennwah/crypto-assignment/internal/pkg/auth"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "crypto-wallet"
+	testSubject  = "8e9f5a4e-2a43-4a59-9d5b-4a4f1f0b7c11"
+)
+
+// signToken signs claims with key using method, setting kid in the header when not empty.
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Subject:   testSubject,
+		Audience:  jwt.ClaimStrings{testAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+	server, _ := newJWKSServer(t, http.StatusOK, jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey)))
+
+	tests := []struct {
+		name        string
+		cfg         config.Auth
+		expectError bool
+	}{
+		{
+			name: "issuer, audience and jwks",
+			cfg:  config.Auth{JWKSURL: server.URL, JWTIssuer: testIssuer, JWTAudience: testAudience},
+		},
+		{
+			name:        "no issuer",
+			cfg:         config.Auth{JWKSURL: server.URL, JWTAudience: testAudience},
+			expectError: true,
+		},
+		{
+			name:        "no audience",
+			cfg:         config.Auth{JWKSURL: server.URL, JWTIssuer: testIssuer},
+			expectError: true,
+		},
+		{
+			name:        "no jwks",
+			cfg:         config.Auth{JWTIssuer: testIssuer, JWTAudience: testAudience},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := auth.NewVerifier(context.Background(), tt.cfg)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, verifier)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, verifier)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey := generateRSAKey(t, 2048)
+	ecKey := generateECKey(t)
+	otherKey := generateRSAKey(t, 2048)
+
+	server, _ := newJWKSServer(t, http.StatusOK, jwksBody(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)))
+	verifier, err := auth.NewVerifier(context.Background(), config.Auth{
+		JWKSURL:             server.URL,
+		JWKSRefreshInterval: time.Minute,
+		JWTIssuer:           testIssuer,
+		JWTAudience:         testAudience,
+		JWTLeeway:           30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	withClaims := func(update func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := validClaims()
+		update(&claims)
+		return claims
+	}
+
+	tests := []struct {
+		name            string
+		token           string
+		expectedSubject string
+		expectedError   error
+	}{
+		{
+			name:            "rs256",
+			token:           signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()),
+			expectedSubject: testSubject,
+		},
+		{
+			name:            "es256",
+			token:           signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, validClaims()),
+			expectedSubject: testSubject,
+		},
+		{
+			name: "expired within leeway",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+			})),
+			expectedSubject: testSubject,
+		},
+		{
+			name: "expired",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			})),
+			expectedError: jwt.ErrTokenExpired,
+		},
+		{
+			name: "no expiry",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.ExpiresAt = nil
+			})),
+			expectedError: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name: "wrong audience",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.Audience = jwt.ClaimStrings{"another-service"}
+			})),
+			expectedError: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name: "wrong issuer",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.Issuer = "https://attacker.example.com"
+			})),
+			expectedError: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "no sub claim",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, withClaims(func(c *jwt.RegisteredClaims) {
+				c.Subject = ""
+			})),
+			expectedError: auth.ErrInvalidToken,
+		},
+		{
+			name:          "unknown kid",
+			token:         signToken(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, validClaims()),
+			expectedError: auth.ErrUnknownKey,
+		},
+		{
+			name:          "signed with another key of a known kid",
+			token:         signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, validClaims()),
+			expectedError: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:          "rs256 kid used for es256",
+			token:         signToken(t, jwt.SigningMethodES256, "rsa-1", ecKey, validClaims()),
+			expectedError: auth.ErrInvalidToken,
+		},
+		{
+			// the public key is known to anyone, a verifier keyed by alg would accept it as an HMAC secret
+			name:          "hs256 signed with the public rsa modulus",
+			token:         signToken(t, jwt.SigningMethodHS256, "rsa-1", rsaKey.N.Bytes(), validClaims()),
+			expectedError: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:          "none algorithm",
+			token:         signToken(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+			expectedError: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:          "malformed",
+			token:         "not.a.token",
+			expectedError: jwt.ErrTokenMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := verifier.Verify(context.Background(), tt.token)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, auth.ErrInvalidToken)
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, subject)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
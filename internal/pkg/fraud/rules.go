@@ -0,0 +1,48 @@
+package fraud
+
+import (
+	"fmt"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// NewRules builds the fraud rules enabled in cfg, rules with an empty or zero threshold are left out.
+func NewRules(cfg config.Fraud) (domainwallet.FraudRules, error) {
+	var rules domainwallet.FraudRules
+
+	if len(cfg.FraudNewRecipientAmounts) > 0 {
+		for asset := range cfg.FraudNewRecipientAmounts {
+			if _, err := domainwallet.LookupAsset(asset); err != nil {
+				return nil, fmt.Errorf("new recipient fraud rule: %w", err)
+			}
+		}
+
+		decision, err := domainwallet.ParseFraudDecision(cfg.FraudNewRecipientDecision)
+		if err != nil {
+			return nil, fmt.Errorf("new recipient fraud rule: %w", err)
+		}
+
+		rules = append(rules, domainwallet.NewRecipientRule{Thresholds: cfg.FraudNewRecipientAmounts, Decision: decision})
+	}
+
+	if cfg.FraudMaxHourlyWithdrawals > 0 {
+		decision, err := domainwallet.ParseFraudDecision(cfg.FraudWithdrawalVelocityDecision)
+		if err != nil {
+			return nil, fmt.Errorf("withdrawal velocity fraud rule: %w", err)
+		}
+
+		rules = append(rules, domainwallet.WithdrawalVelocityRule{Max: cfg.FraudMaxHourlyWithdrawals, Decision: decision})
+	}
+
+	if cfg.FraudNewWalletAge > 0 {
+		decision, err := domainwallet.ParseFraudDecision(cfg.FraudNewWalletDrainDecision)
+		if err != nil {
+			return nil, fmt.Errorf("new wallet drain fraud rule: %w", err)
+		}
+
+		rules = append(rules, domainwallet.NewWalletDrainRule{MinAge: cfg.FraudNewWalletAge, Decision: decision})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,165 @@
+package fraud_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/fraud"
+)
+
+// testFraudConfig is the default configuration, every rule enabled.
+var testFraudConfig = config.Fraud{
+	FraudNewRecipientAmounts:        map[string]uint64{"USD": 100000},
+	FraudNewRecipientDecision:       "review",
+	FraudMaxHourlyWithdrawals:       10,
+	FraudWithdrawalVelocityDecision: "block",
+	FraudNewWalletAge:               24 * time.Hour,
+	FraudNewWalletDrainDecision:     "review",
+}
+
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           config.Fraud
+		expectedRules domainwallet.FraudRules
+		expectedError error
+	}{
+		{
+			name: "every rule",
+			cfg:  testFraudConfig,
+			expectedRules: domainwallet.FraudRules{
+				domainwallet.NewRecipientRule{Thresholds: map[string]uint64{"USD": 100000}, Decision: domainwallet.FraudReview},
+				domainwallet.WithdrawalVelocityRule{Max: 10, Decision: domainwallet.FraudBlock},
+				domainwallet.NewWalletDrainRule{MinAge: 24 * time.Hour, Decision: domainwallet.FraudReview},
+			},
+		},
+		{
+			name: "rules with an empty or zero threshold are off",
+			cfg: config.Fraud{
+				FraudNewRecipientDecision:       "review",
+				FraudWithdrawalVelocityDecision: "block",
+				FraudNewWalletDrainDecision:     "review",
+			},
+		},
+		{
+			name: "decisions of rules off are not parsed",
+			cfg: config.Fraud{
+				FraudMaxHourlyWithdrawals:       3,
+				FraudWithdrawalVelocityDecision: "allow",
+				FraudNewRecipientDecision:       "flag",
+				FraudNewWalletDrainDecision:     "",
+			},
+			expectedRules: domainwallet.FraudRules{
+				domainwallet.WithdrawalVelocityRule{Max: 3, Decision: domainwallet.FraudAllow},
+			},
+		},
+		{
+			name: "new recipient threshold of an unsupported asset",
+			cfg: config.Fraud{
+				FraudNewRecipientAmounts:  map[string]uint64{"DOGE": 100},
+				FraudNewRecipientDecision: "review",
+			},
+			expectedError: domainwallet.ErrUnsupportedAsset,
+		},
+		{
+			name: "invalid new recipient decision",
+			cfg: config.Fraud{
+				FraudNewRecipientAmounts:  map[string]uint64{"USD": 100000},
+				FraudNewRecipientDecision: "flag",
+			},
+			expectedError: domainwallet.ErrInvalidFraudDecision,
+		},
+		{
+			name: "invalid withdrawal velocity decision",
+			cfg: config.Fraud{
+				FraudMaxHourlyWithdrawals:       10,
+				FraudWithdrawalVelocityDecision: "",
+			},
+			expectedError: domainwallet.ErrInvalidFraudDecision,
+		},
+		{
+			name: "invalid new wallet drain decision",
+			cfg: config.Fraud{
+				FraudNewWalletAge:           24 * time.Hour,
+				FraudNewWalletDrainDecision: "Review",
+			},
+			expectedError: domainwallet.ErrInvalidFraudDecision,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := fraud.NewRules(tt.cfg)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestRulesScreen(t *testing.T) {
+	rules, err := fraud.NewRules(testFraudConfig)
+	require.NoError(t, err)
+
+	transfer := func(amount uint64, newRecipient bool) domainwallet.FraudSignals {
+		return domainwallet.FraudSignals{
+			FraudCheck:   domainwallet.FraudCheck{Type: domainwallet.Transfer, Asset: "USD", Amount: amount},
+			Total:        amount,
+			Available:    500000,
+			WalletAge:    48 * time.Hour,
+			NewRecipient: newRecipient,
+		}
+	}
+	withdraw := func(total, available uint64, age time.Duration, recent int) domainwallet.FraudSignals {
+		return domainwallet.FraudSignals{
+			FraudCheck:        domainwallet.FraudCheck{Type: domainwallet.Withdraw, Asset: "USD", Amount: total},
+			Total:             total,
+			Available:         available,
+			WalletAge:         age,
+			RecentWithdrawals: recent,
+		}
+	}
+
+	allow := domainwallet.FraudVerdict{Decision: domainwallet.FraudAllow}
+	reviewNewRecipient := domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "new_recipient"}
+	blockVelocity := domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "withdrawal_velocity"}
+	reviewDrain := domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "new_wallet_drain"}
+
+	tests := []struct {
+		name     string
+		signals  domainwallet.FraudSignals
+		expected domainwallet.FraudVerdict
+	}{
+		{name: "new recipient below the threshold", signals: transfer(99999, true), expected: allow},
+		{name: "new recipient at the threshold", signals: transfer(100000, true), expected: allow},
+		{name: "new recipient above the threshold", signals: transfer(100001, true), expected: reviewNewRecipient},
+		{name: "known recipient above the threshold", signals: transfer(100001, false), expected: allow},
+		{name: "withdrawals below the hourly maximum", signals: withdraw(100, 500000, 48*time.Hour, 9), expected: allow},
+		{name: "withdrawals at the hourly maximum", signals: withdraw(100, 500000, 48*time.Hour, 10), expected: blockVelocity},
+		{name: "withdrawals above the hourly maximum", signals: withdraw(100, 500000, 48*time.Hour, 11), expected: blockVelocity},
+		{name: "new wallet withdrawing less than available", signals: withdraw(9999, 10000, time.Hour, 0), expected: allow},
+		{name: "new wallet withdrawing everything available", signals: withdraw(10000, 10000, time.Hour, 0), expected: reviewDrain},
+		{
+			name:     "wallet just under the new wallet age withdrawing everything",
+			signals:  withdraw(10000, 10000, 24*time.Hour-time.Second, 0),
+			expected: reviewDrain,
+		},
+		{name: "wallet at the new wallet age withdrawing everything", signals: withdraw(10000, 10000, 24*time.Hour, 0), expected: allow},
+		{name: "block wins over review", signals: withdraw(10000, 10000, time.Hour, 10), expected: blockVelocity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rules.Screen(tt.signals))
+		})
+	}
+}
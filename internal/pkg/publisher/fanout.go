@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// FanoutPublisher publishes every event to each of its publishers in turn, failing at the first one failing.
+// An event failing on a later publisher is published again to the earlier ones when retried.
+type FanoutPublisher struct {
+	publishers []Publisher
+}
+
+func NewFanoutPublisher(publishers ...Publisher) *FanoutPublisher {
+	return &FanoutPublisher{publishers: publishers}
+}
+
+func (p *FanoutPublisher) Publish(ctx context.Context, event domainwallet.OutboxEvent) error {
+	for _, pub := range p.publishers {
+		if err := pub.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
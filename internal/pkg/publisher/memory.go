@@ -0,0 +1,47 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// MemoryPublisher keeps published events in memory, for tests and local runs without a broker.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []domainwallet.OutboxEvent
+	err    error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event domainwallet.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+// FailWith makes every following Publish fail with err, until called with nil.
+func (p *MemoryPublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+// Events returns the events published so far, in publish order.
+func (p *MemoryPublisher) Events() []domainwallet.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]domainwallet.OutboxEvent(nil), p.events...)
+}
@@ -0,0 +1,13 @@
+package publisher
+
+import (
+	"context"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// Publisher publishes outbox events to downstream services. Events are published one at a time in order,
+// an event is only published once the previous one was, and may be published again after a failure.
+type Publisher interface {
+	Publish(ctx context.Context, event domainwallet.OutboxEvent) error
+}
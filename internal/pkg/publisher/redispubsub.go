@@ -0,0 +1,34 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPubSubPublisher fans events out to the instances streaming them live, publishing each event's envelope
+// on its wallet's domainwallet.WalletUpdatesChannel. Pub/sub keeps nothing, events published while nobody
+// is subscribed are dropped.
+type RedisPubSubPublisher struct {
+	client *redis.Client
+}
+
+func NewRedisPubSubPublisher(client *redis.Client) *RedisPubSubPublisher {
+	return &RedisPubSubPublisher{client: client}
+}
+
+func (p *RedisPubSubPublisher) Publish(ctx context.Context, event domainwallet.OutboxEvent) error {
+	envelope, err := event.Envelope()
+	if err != nil {
+		return err
+	}
+
+	channel := domainwallet.WalletUpdatesChannel(event.WalletID)
+	if err := p.client.Publish(ctx, channel, envelope).Err(); err != nil {
+		return fmt.Errorf("failed to publish event to redis channel %s: %w", channel, err)
+	}
+
+	return nil
+}
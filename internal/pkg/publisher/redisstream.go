@@ -0,0 +1,48 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamPublisher appends events to a single Redis stream, so consumer groups read them in publish order.
+// Entries have the event id, type, wallet id, creation time and JSON payload as fields, consumers skip ids they already processed.
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+	// maxLen approximately caps the stream length, older entries are trimmed
+	maxLen int64
+}
+
+func NewRedisStreamPublisher(client *redis.Client, stream string, maxLen int64) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event domainwallet.OutboxEvent) error {
+	err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":         strconv.FormatInt(event.ID, 10),
+			"type":       event.Type,
+			"wallet_id":  event.WalletID,
+			"created_at": event.CreatedAt.UTC().Format(time.RFC3339Nano),
+			"payload":    string(event.Payload),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add event to redis stream %s: %w", p.stream, err)
+	}
+
+	return nil
+}
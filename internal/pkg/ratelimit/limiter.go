@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// gcraScript implements the generic cell rate algorithm, a token bucket storing only the theoretical arrival time (TAT)
+// of the next request, in microseconds of the Redis clock so every instance shares the same clock.
+// KEYS[1] is the bucket, ARGV[1] the burst and ARGV[2] the emission interval in microseconds, the period divided by the rate.
+// It returns whether the request is allowed, the requests remaining, and in microseconds how long to wait before
+// retrying and before the bucket is full again.
+const gcraScript = `
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allowed_at = new_tat - burst * interval
+local diff = now - allowed_at
+if diff < 0 then
+	return {0, 0, -diff, tat - now}
+end
+
+redis.call("SET", KEYS[1], new_tat, "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor(diff / interval), 0, new_tat - now}
+`
+
+// Limit allows Rate requests per Period, in bursts of up to Rate requests. The zero Limit allows every request.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+}
+
+// Unlimited reports whether the limit allows every request.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Period <= 0
+}
+
+// Result is the outcome of counting a request against a limit.
+type Result struct {
+	Limit     Limit
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long to wait before a denied request is allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the limit is fully available again
+	ResetAfter time.Duration
+}
+
+// Limiter counts requests against limits in Redis, shared by every API instance.
+type Limiter struct {
+	client *redis.Client
+	script *redis.Script
+}
+
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{
+		client: client,
+		script: redis.NewScript(gcraScript),
+	}
+}
+
+// Allow counts a request against the limit of key, denied requests are not counted.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Limit: limit, Allowed: true}, nil
+	}
+
+	interval := limit.Period.Microseconds() / int64(limit.Rate)
+	values, err := l.script.Run(ctx, l.client, []string{keyPrefix + key}, limit.Rate, max(interval, 1)).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script on %s: %w", key, err)
+	}
+
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return Result{
+		Limit:      limit,
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,121 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/pkg/ratelimit"
+)
+
+// expectGCRA expects the rate limit script to run on the bucket of key with the burst and emission interval in microseconds.
+func expectGCRA(redisMock redismock.ClientMock, key string, burst int, interval int64) *redismock.ExpectedCmd {
+	return redisMock.Regexp().ExpectEvalSha(`^[0-9a-f]{40}$`, []string{regexp.QuoteMeta("ratelimit:" + key)}, burst, interval)
+}
+
+func TestLimiterAllow(t *testing.T) {
+	redisClient, redisMock := redismock.NewClientMock()
+	limiter := ratelimit.NewLimiter(redisClient)
+
+	errRedis := errors.New("connection refused")
+
+	tests := []struct {
+		name           string
+		key            string
+		limit          ratelimit.Limit
+		prepareRedis   func()
+		expectedResult ratelimit.Result
+		expectError    bool
+	}{
+		{
+			name:  "allowed",
+			key:   "ip:10.0.0.1",
+			limit: ratelimit.Limit{Rate: 10, Period: time.Second},
+			prepareRedis: func() {
+				expectGCRA(redisMock, "ip:10.0.0.1", 10, 100000).
+					SetVal([]interface{}{int64(1), int64(9), int64(0), int64(100000)})
+			},
+			expectedResult: ratelimit.Result{
+				Limit:      ratelimit.Limit{Rate: 10, Period: time.Second},
+				Allowed:    true,
+				Remaining:  9,
+				ResetAfter: 100 * time.Millisecond,
+			},
+		},
+		{
+			name:  "denied",
+			key:   "user:user123",
+			limit: ratelimit.Limit{Rate: 300, Period: time.Minute},
+			prepareRedis: func() {
+				expectGCRA(redisMock, "user:user123", 300, 200000).
+					SetVal([]interface{}{int64(0), int64(0), int64(150000), int64(60000000)})
+			},
+			expectedResult: ratelimit.Result{
+				Limit:      ratelimit.Limit{Rate: 300, Period: time.Minute},
+				RetryAfter: 150 * time.Millisecond,
+				ResetAfter: time.Minute,
+			},
+		},
+		{
+			name:  "emission interval is at least a microsecond",
+			key:   "apikey:key1",
+			limit: ratelimit.Limit{Rate: 10, Period: 5 * time.Microsecond},
+			prepareRedis: func() {
+				expectGCRA(redisMock, "apikey:key1", 10, 1).
+					SetVal([]interface{}{int64(1), int64(9), int64(0), int64(1)})
+			},
+			expectedResult: ratelimit.Result{
+				Limit:      ratelimit.Limit{Rate: 10, Period: 5 * time.Microsecond},
+				Allowed:    true,
+				Remaining:  9,
+				ResetAfter: time.Microsecond,
+			},
+		},
+		{
+			name:           "unlimited does not call redis",
+			key:            "ip:10.0.0.1",
+			prepareRedis:   func() {},
+			expectedResult: ratelimit.Result{Allowed: true},
+		},
+		{
+			name:  "redis error",
+			key:   "ip:10.0.0.2",
+			limit: ratelimit.Limit{Rate: 10, Period: time.Second},
+			prepareRedis: func() {
+				expectGCRA(redisMock, "ip:10.0.0.2", 10, 100000).SetErr(errRedis)
+			},
+			expectError: true,
+		},
+		{
+			name:  "unexpected script result",
+			key:   "ip:10.0.0.3",
+			limit: ratelimit.Limit{Rate: 10, Period: time.Second},
+			prepareRedis: func() {
+				expectGCRA(redisMock, "ip:10.0.0.3", 10, 100000).SetVal([]interface{}{int64(1)})
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareRedis()
+
+			result, err := limiter.Allow(context.Background(), tt.key, tt.limit)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
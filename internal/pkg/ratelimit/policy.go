@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+)
+
+// Policy is the limits of every kind of caller. Each request counts against the limit of its IP, then of the user
+// or API key it is authenticated as, and of the user or API key on its route when the route has a limit.
+type Policy struct {
+	IP     Limit
+	User   Limit
+	APIKey Limit
+	// Routes limit every caller on a route, keyed by method and gin route path, eg "POST /api/v1/wallet/transfer"
+	Routes map[string]Limit
+}
+
+// NewPolicy parses the limits of cfg, each "{rate}/{period}" such as "10/1s", or empty to allow every request.
+// Route limits are ";" separated "{method} {path}={rate}/{period}".
+func NewPolicy(cfg config.RateLimit) (Policy, error) {
+	var (
+		policy = Policy{Routes: map[string]Limit{}}
+		err    error
+	)
+
+	if policy.IP, err = ParseLimit(cfg.RateLimitIP); err != nil {
+		return Policy{}, fmt.Errorf("ip rate limit: %w", err)
+	}
+
+	if policy.User, err = ParseLimit(cfg.RateLimitUser); err != nil {
+		return Policy{}, fmt.Errorf("user rate limit: %w", err)
+	}
+
+	if policy.APIKey, err = ParseLimit(cfg.RateLimitAPIKey); err != nil {
+		return Policy{}, fmt.Errorf("api key rate limit: %w", err)
+	}
+
+	for _, entry := range strings.Split(cfg.RateLimitRoutes, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath {
+			return Policy{}, fmt.Errorf("route rate limit %q is not {method} {path}={rate}/{period}", entry)
+		}
+
+		routeLimit, err := ParseLimit(limit)
+		if err != nil {
+			return Policy{}, fmt.Errorf("route rate limit %q: %w", entry, err)
+		}
+
+		policy.Routes[RouteKey(method, path)] = routeLimit
+	}
+
+	return policy, nil
+}
+
+// RouteKey returns the key of a route in Policy.Routes.
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + strings.TrimSpace(path)
+}
+
+// ParseLimit parses "{rate}/{period}", eg "100/1m", an empty limit allows every request.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Limit{}, nil
+	}
+
+	rate, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q is not {rate}/{period}", value)
+	}
+
+	r, err := strconv.Atoi(strings.TrimSpace(rate))
+	if err != nil || r <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: rate must be a positive integer", value)
+	}
+
+	p, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || p <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: period must be a positive duration, eg 1m", value)
+	}
+
+	return Limit{Rate: r, Period: p}, nil
+}
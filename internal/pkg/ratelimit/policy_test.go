@@ -0,0 +1,163 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jennwah/crypto-assignment/internal/config"
+	"github.com/jennwah/crypto-assignment/internal/pkg/ratelimit"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedLimit ratelimit.Limit
+		expectError   bool
+	}{
+		{
+			name:          "per minute",
+			value:         "100/1m",
+			expectedLimit: ratelimit.Limit{Rate: 100, Period: time.Minute},
+		},
+		{
+			name:          "spaces around rate and period",
+			value:         " 10 / 1s ",
+			expectedLimit: ratelimit.Limit{Rate: 10, Period: time.Second},
+		},
+		{
+			name:          "empty allows every request",
+			value:         "  ",
+			expectedLimit: ratelimit.Limit{},
+		},
+		{
+			name:        "no period",
+			value:       "100",
+			expectError: true,
+		},
+		{
+			name:        "zero rate",
+			value:       "0/1m",
+			expectError: true,
+		},
+		{
+			name:        "negative rate",
+			value:       "-1/1m",
+			expectError: true,
+		},
+		{
+			name:        "rate not an integer",
+			value:       "1.5/1m",
+			expectError: true,
+		},
+		{
+			name:        "period without unit",
+			value:       "100/60",
+			expectError: true,
+		},
+		{
+			name:        "zero period",
+			value:       "100/0s",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := ratelimit.ParseLimit(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLimit, limit)
+		})
+	}
+}
+
+func TestLimitUnlimited(t *testing.T) {
+	assert.True(t, ratelimit.Limit{}.Unlimited())
+	assert.True(t, ratelimit.Limit{Rate: 10}.Unlimited())
+	assert.True(t, ratelimit.Limit{Period: time.Minute}.Unlimited())
+	assert.False(t, ratelimit.Limit{Rate: 10, Period: time.Minute}.Unlimited())
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.RateLimit
+		expectedPolicy ratelimit.Policy
+		expectError    bool
+	}{
+		{
+			name: "every limit",
+			cfg: config.RateLimit{
+				RateLimitIP:     "600/1m",
+				RateLimitUser:   "300/1m",
+				RateLimitAPIKey: "3000/1m",
+				RateLimitRoutes: "POST /api/v1/wallet/transfer=30/1m; post /api/v1/wallet/withdraw = 5/1s;",
+			},
+			expectedPolicy: ratelimit.Policy{
+				IP:     ratelimit.Limit{Rate: 600, Period: time.Minute},
+				User:   ratelimit.Limit{Rate: 300, Period: time.Minute},
+				APIKey: ratelimit.Limit{Rate: 3000, Period: time.Minute},
+				Routes: map[string]ratelimit.Limit{
+					"POST /api/v1/wallet/transfer": {Rate: 30, Period: time.Minute},
+					"POST /api/v1/wallet/withdraw": {Rate: 5, Period: time.Second},
+				},
+			},
+		},
+		{
+			name: "no limits",
+			expectedPolicy: ratelimit.Policy{
+				Routes: map[string]ratelimit.Limit{},
+			},
+		},
+		{
+			name:        "invalid ip limit",
+			cfg:         config.RateLimit{RateLimitIP: "600"},
+			expectError: true,
+		},
+		{
+			name:        "invalid user limit",
+			cfg:         config.RateLimit{RateLimitUser: "a/1m"},
+			expectError: true,
+		},
+		{
+			name:        "invalid api key limit",
+			cfg:         config.RateLimit{RateLimitAPIKey: "10/forever"},
+			expectError: true,
+		},
+		{
+			name:        "route without method",
+			cfg:         config.RateLimit{RateLimitRoutes: "/api/v1/wallet/transfer=30/1m"},
+			expectError: true,
+		},
+		{
+			name:        "route without limit",
+			cfg:         config.RateLimit{RateLimitRoutes: "POST /api/v1/wallet/transfer"},
+			expectError: true,
+		},
+		{
+			name:        "route with invalid limit",
+			cfg:         config.RateLimit{RateLimitRoutes: "POST /api/v1/wallet/transfer=0/1m"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ratelimit.NewPolicy(tt.cfg)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPolicy, policy)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// maxResponseBytes is the most of a receiver's response read before closing it, so connections can be reused.
+const maxResponseBytes = 64 << 10
+
+// Sender sends webhook deliveries to their subscription's URL.
+type Sender interface {
+	Send(ctx context.Context, dispatch domainwallet.WebhookDispatch) domainwallet.WebhookAttempt
+}
+
+// HTTPSender POSTs deliveries as JSON, signed with the subscription's secret.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, dispatch domainwallet.WebhookDispatch) domainwallet.WebhookAttempt {
+	body, err := dispatch.Body()
+	if err != nil {
+		return domainwallet.WebhookAttempt{Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return domainwallet.WebhookAttempt{Err: fmt.Errorf("failed to build webhook request: %w", err)}
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(domainwallet.WebhookIDHeader, dispatch.ID)
+	req.Header.Set(domainwallet.WebhookEventHeader, dispatch.Event.Type)
+	req.Header.Set(domainwallet.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(domainwallet.WebhookSignatureHeader, domainwallet.SignWebhook(dispatch.Secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return domainwallet.WebhookAttempt{Err: fmt.Errorf("failed to send webhook: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	return domainwallet.WebhookAttempt{StatusCode: resp.StatusCode}
+}
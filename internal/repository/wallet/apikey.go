@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+const apiKeyColumns = `id, name, secret_hash, scopes, created_at, revoked_at`
+
+// apiKeyRow is an API key as stored, its scopes are a JSON array.
+type apiKeyRow struct {
+	domainwallet.APIKey
+	ScopesJSON []byte `db:"scopes"`
+}
+
+func (row apiKeyRow) apiKey() (domainwallet.APIKey, error) {
+	key := row.APIKey
+	if err := json.Unmarshal(row.ScopesJSON, &key.Scopes); err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("failed to unmarshal api key scopes: %w", err)
+	}
+
+	return key, nil
+}
+
+// CreateAPIKey inserts the key and returns it with its creation time.
+func (r *Repository) CreateAPIKey(ctx context.Context, key domainwallet.APIKey) (domainwallet.APIKey, error) {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("failed to marshal api key scopes: %w", err)
+	}
+
+	var row apiKeyRow
+	insert := `
+		INSERT INTO api_keys (id, name, secret_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + apiKeyColumns
+	err = r.db.GetContext(ctx, &row, insert, key.ID, key.Name, key.SecretHash, string(scopes))
+	if err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("failed to insert api key: %w", err)
+	}
+
+	return row.apiKey()
+}
+
+// ListAPIKeys returns every key, revoked ones included, most recent first.
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]domainwallet.APIKey, error) {
+	var rows []apiKeyRow
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &rows, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]domainwallet.APIKey, 0, len(rows))
+	for _, row := range rows {
+		key, err := row.apiKey()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// GetAPIKey returns the key, revoked or not.
+func (r *Repository) GetAPIKey(ctx context.Context, keyID string) (domainwallet.APIKey, error) {
+	var row apiKeyRow
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	err := r.db.GetContext(ctx, &row, query, keyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.APIKey{}, fmt.Errorf("api key %s: %w", keyID, domainwallet.ErrAPIKeyNotFound)
+		}
+
+		return domainwallet.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return row.apiKey()
+}
+
+// RevokeAPIKey revokes the key for good, revoking a revoked key keeps its revocation time.
+func (r *Repository) RevokeAPIKey(ctx context.Context, keyID string) (domainwallet.APIKey, error) {
+	var row apiKeyRow
+	update := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+	err := r.db.GetContext(ctx, &row, update, keyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.APIKey{}, fmt.Errorf("api key %s: %w", keyID, domainwallet.ErrAPIKeyNotFound)
+		}
+
+		return domainwallet.APIKey{}, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return row.apiKey()
+}
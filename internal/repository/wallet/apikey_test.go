@@ -0,0 +1,156 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	insertAPIKeyQuery = `INSERT INTO api_keys \(id, name, secret_hash, scopes\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id, name, secret_hash, scopes, created_at, revoked_at`
+	getAPIKeyQuery    = `SELECT id, name, secret_hash, scopes, created_at, revoked_at FROM api_keys WHERE id = \$1`
+	revokeAPIKeyQuery = `UPDATE api_keys SET revoked_at = COALESCE\(revoked_at, NOW\(\)\) WHERE id = \$1 RETURNING .*`
+)
+
+var apiKeyColumns = []string{"id", "name", "secret_hash", "scopes", "created_at", "revoked_at"}
+
+func TestCreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	key := domainwallet.APIKey{
+		ID:         "key-1",
+		Name:       "payment-gateway",
+		SecretHash: "hash",
+		Scopes:     []string{domainwallet.ScopeWalletDeposit, domainwallet.ScopeWalletWithdraw},
+	}
+
+	mock.ExpectQuery(insertAPIKeyQuery).
+		WithArgs(key.ID, key.Name, key.SecretHash, `["wallet:deposit","wallet:withdraw"]`).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(key.ID, key.Name, key.SecretHash, []byte(`["wallet:deposit","wallet:withdraw"]`), createdAt, nil))
+
+	created, err := r.CreateAPIKey(context.Background(), key)
+
+	require.NoError(t, err)
+	key.CreatedAt = createdAt
+	assert.Equal(t, key, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		prepareMock func()
+		expectedKey domainwallet.APIKey
+		expectedErr error
+	}{
+		{
+			name: "key found",
+			prepareMock: func() {
+				mock.ExpectQuery(getAPIKeyQuery).
+					WithArgs("key-1").
+					WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+						AddRow("key-1", "back-office", "hash", []byte(`["admin"]`), createdAt, nil))
+			},
+			expectedKey: domainwallet.APIKey{
+				ID:         "key-1",
+				Name:       "back-office",
+				SecretHash: "hash",
+				Scopes:     []string{domainwallet.ScopeAdmin},
+				CreatedAt:  createdAt,
+			},
+		},
+		{
+			name: "key not found",
+			prepareMock: func() {
+				mock.ExpectQuery(getAPIKeyQuery).WithArgs("key-1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			key, err := r.GetAPIKey(context.Background(), "key-1")
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedKey, key)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		prepareMock func()
+		expectedErr error
+	}{
+		{
+			name: "key revoked",
+			prepareMock: func() {
+				mock.ExpectQuery(revokeAPIKeyQuery).
+					WithArgs("key-1").
+					WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+						AddRow("key-1", "back-office", "hash", []byte(`["admin"]`), createdAt, revokedAt))
+			},
+		},
+		{
+			name: "key not found",
+			prepareMock: func() {
+				mock.ExpectQuery(revokeAPIKeyQuery).WithArgs("key-1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			key, err := r.RevokeAPIKey(context.Background(), "key-1")
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				require.NotNil(t, key.RevokedAt)
+				assert.Equal(t, revokedAt, *key.RevokedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+// lockWalletQuery holds a row-level lock on the user wallet and reads its balance of a single asset.
+// Balances are only mutated while holding their wallet's row lock, so the wallet row lock
+// serializes money movement across all assets of the wallet.
+const lockWalletQuery = `
+	SELECT w.id, w.status, COALESCE(b.balance, 0) AS balance, COALESCE(b.held, 0) AS held
+	FROM wallets w
+	LEFT JOIN balances b ON b.wallet_id = w.id AND b.asset = $2
+	WHERE w.user_id = $1
+	FOR UPDATE OF w
+`
+
+// lockWalletPairQuery holds row-level locks on two user wallets in a single statement and reads their balances of a single asset.
+// Rows are locked in wallet id order, so concurrent transfers between the same wallets in opposite directions
+// acquire their locks in the same order instead of deadlocking on each other.
+const lockWalletPairQuery = `
+	SELECT w.id, w.user_id, w.status, COALESCE(b.balance, 0) AS balance, COALESCE(b.held, 0) AS held
+	FROM wallets w
+	LEFT JOIN balances b ON b.wallet_id = w.id AND b.asset = $3
+	WHERE w.user_id IN ($1, $2)
+	ORDER BY w.id
+	FOR UPDATE OF w
+`
+
+// creditBalanceQuery adds to a wallet's asset balance, creating the balance on first credit.
+const creditBalanceQuery = `
+	INSERT INTO balances (wallet_id, asset, balance)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (wallet_id, asset) DO UPDATE SET balance = balances.balance + EXCLUDED.balance
+`
+
+const debitBalanceQuery = `UPDATE balances SET balance = balance - $1 WHERE wallet_id = $2 AND asset = $3`
+
+// holdBalanceQuery reserves part of the balance for a hold, releaseHeldQuery releases it.
+const (
+	holdBalanceQuery = `UPDATE balances SET held = held + $1 WHERE wallet_id = $2 AND asset = $3`
+	releaseHeldQuery = `UPDATE balances SET held = held - $1 WHERE wallet_id = $2 AND asset = $3`
+)
+
+// assetBalanceQuery reads a wallet's balance of a single asset, once the asset is known while holding the wallet's row lock.
+const assetBalanceQuery = `SELECT asset, balance, held FROM balances WHERE wallet_id = $1 AND asset = $2`
+
+// lockWalletByIDQuery holds a row-level lock on the wallet, before mutating any of its balances.
+const lockWalletByIDQuery = `SELECT id FROM wallets WHERE id = $1 FOR UPDATE`
+
+type userWallet struct {
+	ID      string                    `db:"id"`
+	UserID  string                    `db:"user_id"`
+	Balance uint64                    `db:"balance"`
+	Status  domainwallet.WalletStatus `db:"status"`
+	Held    uint64                    `db:"held"`
+}
+
+// available returns the balance not reserved by holds, which can be withdrawn or transferred.
+func (w userWallet) available() uint64 {
+	return domainwallet.Balance{Balance: w.Balance, Held: w.Held}.Available()
+}
+
+func getBalances(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	walletID string,
+) ([]domainwallet.Balance, error) {
+	const query = `SELECT asset, balance, held FROM balances WHERE wallet_id = $1 ORDER BY asset`
+
+	var balances []domainwallet.Balance
+	if err := sqlx.SelectContext(ctx, q, &balances, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to get wallet balances: %w", err)
+	}
+
+	return balances, nil
+}
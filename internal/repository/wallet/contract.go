@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"context"
+	"time"
 
 	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
 )
@@ -9,15 +10,122 @@ import (
 type IWalletRepository interface {
 	GetWallet(ctx context.Context, userID string) (wallet.Wallet, error)
 	GetWalletTransactionsHistory(
-		ctx context.Context, userID string, offset, pageSize int,
+		ctx context.Context, userID string, filter wallet.TransactionFilter, offset, pageSize int,
 	) ([]wallet.Transaction, int, error)
-	DepositWallet(ctx context.Context, userID, idempotencyKey string, amount uint64) (string, error)
+	GetWalletTransactionsAfter(
+		ctx context.Context, userID string, filter wallet.TransactionFilter, after *wallet.TransactionCursor, limit int,
+	) ([]wallet.Transaction, error)
+	GetWalletTransaction(ctx context.Context, userID, transactionID string) (wallet.Transaction, error)
+	GetWalletBalancesAsOf(ctx context.Context, userID string, asOf time.Time) (wallet.WalletBalancesAt, error)
+	ListWalletBalancesAsOf(
+		ctx context.Context, asOf time.Time, afterWalletID string, limit int,
+	) ([]wallet.WalletBalancesAt, error)
+	SnapshotBalances(ctx context.Context, at time.Time) (int, error)
+	StartReconciliation(ctx context.Context, freeze bool) (wallet.Reconciliation, error)
+	ReconcileWallets(ctx context.Context, afterWalletID string, limit int) (wallet.ReconciliationPage, error)
+	RecordReconciliationMismatches(ctx context.Context, reconciliationID string, mismatches []wallet.BalanceMismatch) error
+	FinishReconciliation(
+		ctx context.Context,
+		reconciliationID string,
+		status wallet.ReconciliationStatus,
+		walletsChecked, mismatches int,
+	) (wallet.Reconciliation, error)
+	GetReconciliation(ctx context.Context, reconciliationID string) (wallet.Reconciliation, error)
+	ListReconciliations(ctx context.Context, limit int) ([]wallet.Reconciliation, error)
+	PublishOutboxEvents(ctx context.Context, limit int, publish func(wallet.OutboxEvent) error) (int, error)
+	SubscribeWalletUpdates(ctx context.Context, walletID string) (<-chan wallet.TransactionCreated, error)
+	CreateWebhookSubscription(ctx context.Context, sub wallet.WebhookSubscription) (wallet.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]wallet.WebhookSubscription, error)
+	DeactivateWebhookSubscription(ctx context.Context, subscriptionID string) (wallet.WebhookSubscription, error)
+	ListWebhookDeliveries(
+		ctx context.Context, subscriptionID string, status wallet.WebhookDeliveryStatus, limit int,
+	) ([]wallet.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, subscriptionID, deliveryID string) (wallet.WebhookDelivery, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]wallet.WebhookDispatch, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery wallet.WebhookDelivery) error
+	CreateAPIKey(ctx context.Context, key wallet.APIKey) (wallet.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]wallet.APIKey, error)
+	GetAPIKey(ctx context.Context, keyID string) (wallet.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string) (wallet.APIKey, error)
+	GetWalletKYCTier(ctx context.Context, userID string) (wallet.KYCTier, error)
+	SetWalletKYCTier(ctx context.Context, userID string, tier wallet.KYCTier) (wallet.Wallet, error)
+	GetTransactionLimits(ctx context.Context, userID string) ([]wallet.LimitStatus, error)
+	SetTransactionLimit(ctx context.Context, userID string, limit wallet.TransactionLimit) (wallet.TransactionLimit, error)
+	DeleteTransactionLimit(ctx context.Context, userID string, txnType wallet.TransactionType, asset string) error
+	GetFeeSchedules(ctx context.Context) ([]wallet.FeeSchedule, error)
+	GetFeeSchedule(ctx context.Context, txnType wallet.TransactionType, asset string) (wallet.FeeSchedule, error)
+	SetFeeSchedule(ctx context.Context, schedule wallet.FeeSchedule) (wallet.FeeSchedule, error)
+	DeleteFeeSchedule(ctx context.Context, txnType wallet.TransactionType, asset string) error
+	GetFraudSignals(ctx context.Context, check wallet.FraudCheck) (wallet.FraudSignals, error)
+	ListTransactionReviews(ctx context.Context, limit int) ([]wallet.TransactionReview, error)
+	DecideTransactionReview(
+		ctx context.Context,
+		transactionID string,
+		decision wallet.ReviewDecision,
+	) (wallet.TransactionReview, error)
+	StreamWalletTransactions(
+		ctx context.Context, userID string, filter wallet.TransactionFilter, fn func(wallet.StatementLine) error,
+	) error
+	GetWalletBalancesAt(ctx context.Context, userID string, at time.Time) (map[string]int64, error)
+	GetIdempotentResponse(
+		ctx context.Context,
+		userID string,
+		operation wallet.IdempotentOperation,
+		key wallet.IdempotencyKey,
+	) (wallet.IdempotentResponse, bool, error)
+	DepositWallet(
+		ctx context.Context,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
 	WithdrawWallet(
 		ctx context.Context,
-		userID, idempotencyKey string,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
 		amount uint64,
-	) (string, error)
+		verdict wallet.FraudVerdict,
+	) (wallet.IdempotentResponse, error)
 	Transfer(
-		ctx context.Context, initiatorUserID, recipientUserID, idempotencyKey string, amount uint64,
-	) (string, error)
+		ctx context.Context,
+		initiatorUserID, recipientUserID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+		verdict wallet.FraudVerdict,
+	) (wallet.IdempotentResponse, error)
+	CreateWallet(ctx context.Context, userID string) (wallet.Wallet, bool, error)
+	UpdateWalletStatus(
+		ctx context.Context,
+		userID string,
+		status wallet.WalletStatus,
+	) (wallet.Wallet, error)
+	GetWalletLedgerBalances(ctx context.Context, userID string) ([]wallet.LedgerBalance, error)
+	CreateHold(
+		ctx context.Context,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+		expiresAt time.Time,
+	) (wallet.IdempotentResponse, error)
+	CaptureHold(
+		ctx context.Context,
+		userID, holdID string,
+		key wallet.IdempotencyKey,
+		recipientUserID string,
+		amount uint64,
+		now time.Time,
+		verdict wallet.FraudVerdict,
+	) (wallet.IdempotentResponse, error)
+	ReleaseHold(ctx context.Context, userID, holdID string) (wallet.Hold, error)
+	ReleaseExpiredHolds(ctx context.Context, now time.Time, limit int) (int, error)
+	ReverseTransaction(
+		ctx context.Context,
+		transactionID string,
+		key wallet.IdempotencyKey,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
 }
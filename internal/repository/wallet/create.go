@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// CreateWallet provisions an empty active wallet for the user. It is idempotent on userID:
+// if the user already owns a wallet, that wallet is returned with created set to false.
+func (r *Repository) CreateWallet(
+	ctx context.Context,
+	userID string,
+) (domainwallet.Wallet, bool, error) {
+	const query = `
+		INSERT INTO wallets (user_id, status)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING id, user_id, status, kyc_tier, created_at;
+	`
+
+	var dst domainwallet.Wallet
+	err := r.db.GetContext(ctx, &dst, query, userID, domainwallet.Active)
+	if err == nil {
+		return dst, true, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return domainwallet.Wallet{}, false, fmt.Errorf("failed to insert wallet: %w", err)
+	}
+
+	// Conflict on user_id, the user already owns a wallet
+	existing, err := r.GetWallet(ctx, userID)
+	if err != nil {
+		return domainwallet.Wallet{}, false, fmt.Errorf("failed to get existing wallet: %w", err)
+	}
+
+	return existing, false, nil
+}
@@ -0,0 +1,109 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	insertQuery := regexp.QuoteMeta(`INSERT INTO wallets (user_id, status) VALUES ($1, $2) ON CONFLICT (user_id) DO NOTHING RETURNING id, user_id, status, kyc_tier, created_at;`)
+	selectQuery := regexp.QuoteMeta(`SELECT id, user_id, status, kyc_tier, created_at FROM wallets WHERE user_id = $1 LIMIT 1;`)
+	balancesQuery := regexp.QuoteMeta(`SELECT asset, balance, held FROM balances WHERE wallet_id = $1 ORDER BY asset`)
+	walletColumns := []string{"id", "user_id", "status", "kyc_tier", "created_at"}
+
+	tests := []struct {
+		name            string
+		userID          string
+		prepareMock     func()
+		expected        domainwallet.Wallet
+		expectedCreated bool
+		expectedError   error
+	}{
+		{
+			name:   "new wallet created",
+			userID: "user123",
+			prepareMock: func() {
+				mock.ExpectQuery(insertQuery).
+					WithArgs("user123", "active").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet-1", "user123", "active", "full", time.Now()))
+			},
+			expected: domainwallet.Wallet{
+				ID:     "wallet-1",
+				UserID: "user123",
+				Status: domainwallet.Active,
+			},
+			expectedCreated: true,
+		},
+		{
+			name:   "existing wallet returned",
+			userID: "user456",
+			prepareMock: func() {
+				mock.ExpectQuery(insertQuery).
+					WithArgs("user456", "active").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(selectQuery).
+					WithArgs("user456").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet-2", "user456", "frozen", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet-2").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance"}).AddRow("USD", 500))
+			},
+			expected: domainwallet.Wallet{
+				ID:       "wallet-2",
+				UserID:   "user456",
+				Balances: []domainwallet.Balance{{Asset: "USD", Balance: 500}},
+				Status:   domainwallet.Frozen,
+			},
+			expectedCreated: false,
+		},
+		{
+			name:   "insert error",
+			userID: "user789",
+			prepareMock: func() {
+				mock.ExpectQuery(insertQuery).
+					WithArgs("user789", "active").
+					WillReturnError(errors.New("db error"))
+			},
+			expected:      domainwallet.Wallet{},
+			expectedError: errors.New("failed to insert wallet: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			w, created, err := r.CreateWallet(context.Background(), tt.userID)
+			assert.Equal(t, tt.expected.ID, w.ID)
+			assert.Equal(t, tt.expected.UserID, w.UserID)
+			assert.Equal(t, tt.expected.Balances, w.Balances)
+			assert.Equal(t, tt.expected.Status, w.Status)
+			assert.Equal(t, tt.expectedCreated, created)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -5,98 +5,116 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log/slog"
 
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
-	"github.com/redis/go-redis/v9"
 )
 
 const depositCacheKey = `deposit-%s-%s` // deposit-userID-idempotencyKey
 
 // DepositWallet does the following:
-// 1. Check from redis cache on key = deposit-{userID}-{idempotencyKey}, if exists we just return nil error
-// 2. If not, proceed with deposit amount into user wallet
-// 3. Cache if successful and return appriopriate errors
+// 1. Check from redis cache on key = deposit-{userID}-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock the user wallet and check the idempotency key recorded in postgres
+// 3. Proceed with deposit amount of the asset into user wallet and post the balanced ledger journal, or record the deposit as failed if the wallet is closed
+// 4. Record the response on the idempotency key in the same db transaction, cache it and return appriopriate errors
 func (r *Repository) DepositWallet(
 	ctx context.Context,
-	userID, idempotencyKey string,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	cacheKey := fmt.Sprintf(depositCacheKey, userID, idempotencyKey)
-	cachedTxId, err := r.cache.Get(ctx, cacheKey).Result()
+) (domainwallet.IdempotentResponse, error) {
+	cacheKey := fmt.Sprintf(depositCacheKey, userID, key.Key)
 	// Idempotent: already processed
-	if err == nil {
-		return cachedTxId, nil
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
 	}
 
-	if err != nil && err != redis.Nil {
-		return "", fmt.Errorf("redis get failed: %w", err)
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
 	}
+	defer release()
 
 	// it's a new deposit operation
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to begin database tx: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Hold row-level lock on user wallet
-	var walletID string
-	query := `SELECT id FROM wallets WHERE user_id = $1 FOR UPDATE`
-	err = tx.GetContext(ctx, &walletID, query, userID)
+	var dbWallet userWallet
+	err = tx.GetContext(ctx, &dbWallet, lockWalletQuery, userID, asset)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
 		}
-		return "", fmt.Errorf(
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
 			"failed to hold row-level lock on wallet: %w, userID: %s",
 			err,
 			userID,
 		)
 	}
 
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupIdempotencyKey(ctx, tx, cacheKey, userID, domainwallet.Deposit.Operation(), key); err != nil || found {
+		return resp, err
+	}
+
+	// Closed wallets cannot be credited, recorded as a failed deposit
+	if err := dbWallet.Status.CanCredit(); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attemptedTransaction{
+			Operation:         domainwallet.Deposit.Operation(),
+			InitiatorWalletID: dbWallet.ID,
+			Type:              domainwallet.Deposit,
+			Asset:             asset,
+			Amount:            amount,
+		}, err)
+	}
+
+	journal, err := domainwallet.NewJournal(
+		asset,
+		amount,
+		domainwallet.SystemLedgerAccount(domainwallet.ExternalCashIn),
+		domainwallet.WalletAccount(dbWallet.ID),
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+	}
+
 	// Update balance
-	update := `UPDATE wallets SET balance = balance + $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, update, amount, walletID)
+	_, err = tx.ExecContext(ctx, creditBalanceQuery, dbWallet.ID, asset, amount)
 	if err != nil {
-		return "", fmt.Errorf("failed to update balance: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
 	}
 
 	// Insert transaction record
 	var transactionID string
 	insertTxn := `
-		INSERT INTO transactions (initiator_wallet_id, type, status, amount, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
+		INSERT INTO transactions (initiator_wallet_id, type, status, asset, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
 		RETURNING id
 	`
 	err = tx.GetContext(
 		ctx,
 		&transactionID,
 		insertTxn,
-		walletID,
+		dbWallet.ID,
 		domainwallet.Deposit,
 		domainwallet.Success,
+		asset,
 		amount,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert transaction record: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert transaction record: %w", err)
 	}
 
-	// Commit transaction
-	err = tx.Commit()
-	if err != nil {
-		return "", fmt.Errorf("failed to commit tx: %w", err)
-	}
-
-	// Cache idempotency Key
-	if err := r.cache.Set(ctx, cacheKey, transactionID, ttl).Err(); err != nil {
-		r.logger.Error(
-			"failed to cache idempotency key on DepositWallet",
-			slog.String("userID", userID),
-			slog.String("idempotencyKey", idempotencyKey),
-			slog.String("transactionID", transactionID),
-		)
+	// Post balanced ledger entries for the transaction
+	if err := postJournal(ctx, tx, transactionID, journal); err != nil {
+		return domainwallet.IdempotentResponse{}, err
 	}
 
-	return transactionID, nil
+	// Record the response on the idempotency key and commit transaction
+	return r.commitIdempotent(ctx, tx, cacheKey, userID, domainwallet.Deposit.Operation(), key, transactionID, domainwallet.FeeBreakdown{}, nil)
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	redismock "github.com/go-redis/redismock/v9"
@@ -29,81 +30,199 @@ func TestDepositWallet(t *testing.T) {
 	repo := wallet.New(sqlxDB, redisClient, logger)
 
 	tests := []struct {
-		name           string
-		userID         string
-		idempotencyKey string
-		amount         uint64
-		prepareRedis   func()
-		prepareSQL     func()
-		expectedError  error
-		expectTxnID    string
+		name             string
+		userID           string
+		idempotencyKey   string
+		asset            string
+		amount           uint64
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
 	}{
 		{
 			name:           "already processed idempotent request",
 			userID:         "user123",
 			idempotencyKey: "idem123",
+			asset:          "USD",
 			amount:         100,
 			prepareRedis: func() {
-				redisMock.ExpectGet("deposit-user123-idem123").SetVal("tx-already")
+				redisMock.ExpectGet("deposit-user123-idem123").SetVal(cachedRecord("hash-idem123", okResponse("tx-already")))
 			},
-			prepareSQL:    func() {},
-			expectTxnID:   "tx-already",
-			expectedError: nil,
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("tx-already"),
 		},
 		{
 			name:           "wallet not found",
 			userID:         "user123",
 			idempotencyKey: "idem124",
+			asset:          "USD",
 			amount:         200,
 			prepareRedis: func() {
 				redisMock.ExpectGet("deposit-user123-idem124").RedisNil()
+				expectReserveInFlight(redisMock, "deposit-user123-idem124").SetVal(true)
+				expectReleaseInFlight(redisMock, "deposit-user123-idem124")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user123").
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user123", "USD").
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
 		},
+		{
+			name:           "closed wallet",
+			userID:         "user321",
+			idempotencyKey: "idem321",
+			asset:          "USD",
+			amount:         200,
+			prepareRedis: func() {
+				redisMock.ExpectGet("deposit-user321-idem321").RedisNil()
+				expectReserveInFlight(redisMock, "deposit-user321-idem321").SetVal(true)
+				redisMock.ExpectSet("deposit-user321-idem321", cachedRecord("hash-idem321", failedResponse("tx321", domainwallet.FailureWalletClosed)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "deposit-user321-idem321")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user321", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet321", 0, "closed"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user321", "idem321", domainwallet.Deposit.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet321", nil, domainwallet.Deposit, domainwallet.Failed, domainwallet.FailureWalletClosed, "USD", 200).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx321"))
+				expectTransactionEvents(mock, "tx321")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user321", "idem321", domainwallet.Deposit, "hash-idem321", "tx321", nil, 422, failedResponse("tx321", domainwallet.FailureWalletClosed).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx321", domainwallet.FailureWalletClosed),
+		},
 		{
 			name:           "successful deposit",
 			userID:         "user456",
 			idempotencyKey: "idem456",
+			asset:          "USD",
 			amount:         500,
 			prepareRedis: func() {
 				redisMock.ExpectGet("deposit-user456-idem456").RedisNil()
+				expectReserveInFlight(redisMock, "deposit-user456-idem456").SetVal(true)
+				redisMock.ExpectSet("deposit-user456-idem456", cachedRecord("hash-idem456", okResponse("tx456")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "deposit-user456-idem456")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user456").
-					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet456"))
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user456", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet456", 0, "active"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user456", "idem456", domainwallet.Deposit.Operation()).
+					WillReturnError(sql.ErrNoRows)
 
-				mock.ExpectExec(`UPDATE wallets SET balance = balance \+ \$1 WHERE id = \$2`).
-					WithArgs(500, "wallet456").
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet456", "USD", 500).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
-					WithArgs("wallet456", "deposit", "success", 500).
+					WithArgs("wallet456", "deposit", "success", "USD", 500).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx456"))
 
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx456").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal456"))
+
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal456", nil, "external_cash_in", "USD", -500, "journal456", "wallet456", nil, "USD", 500).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+
+				// the deposit is published to its wallet with the balance right after it
+				mock.ExpectQuery(transactionWalletsQuery).
+					WithArgs("tx456").
+					WillReturnRows(sqlmock.NewRows(transactionWalletColumns).
+						AddRow("wallet456", "user456", "tx456", "user456", "deposit", "success", "USD", 500, nil, nil, nil, "2026-10-17T00:00:00Z", 500, 0))
+				mock.ExpectExec(insertOutboxEvents).
+					WithArgs(domainwallet.TransactionCreatedEvent, "wallet456", depositEventPayload(t)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user456", "idem456", domainwallet.Deposit, "hash-idem456", "tx456", nil, 200, []byte("tx456")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
-			expectTxnID:   "tx456",
-			expectedError: nil,
+			expectedResponse: okResponse("tx456"),
 		},
 		{
-			name:           "get idempotency key cache error",
+			name:           "successful deposit of an asset not held yet",
+			userID:         "user654",
+			idempotencyKey: "idem654",
+			asset:          "BTC",
+			amount:         150000000,
+			prepareRedis: func() {
+				redisMock.ExpectGet("deposit-user654-idem654").RedisNil()
+				expectReserveInFlight(redisMock, "deposit-user654-idem654").SetVal(true)
+				redisMock.ExpectSet("deposit-user654-idem654", cachedRecord("hash-idem654", okResponse("tx654")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "deposit-user654-idem654")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user654", "BTC").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet654", 0, "frozen"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user654", "idem654", domainwallet.Deposit.Operation()).
+					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet654", "BTC", 150000000).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
+					WithArgs("wallet654", "deposit", "success", "BTC", 150000000).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx654"))
+
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx654").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal654"))
+
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal654", nil, "external_cash_in", "BTC", -150000000, "journal654", "wallet654", nil, "BTC", 150000000).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+
+				expectTransactionEvents(mock, "tx654")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user654", "idem654", domainwallet.Deposit, "hash-idem654", "tx654", nil, 200, []byte("tx654")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("tx654"),
+		},
+		{
+			name:           "get idempotency key cache error falls back to postgres",
 			userID:         "user789",
 			idempotencyKey: "idem789",
+			asset:          "USD",
 			amount:         300,
 			prepareRedis: func() {
 				redisMock.ExpectGet("deposit-user789-idem789").SetErr(errors.New("redis down"))
+				expectReserveInFlight(redisMock, "deposit-user789-idem789").SetVal(true)
+				expectReleaseInFlight(redisMock, "deposit-user789-idem789")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user789", "USD").
+					WillReturnError(sql.ErrNoRows)
 			},
-			prepareSQL:    func() {},
-			expectedError: fmt.Errorf("redis get failed: %w", errors.New("redis down")),
+			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
 		},
 	}
 
@@ -112,14 +231,20 @@ func TestDepositWallet(t *testing.T) {
 			tt.prepareRedis()
 			tt.prepareSQL()
 
-			txID, err := repo.DepositWallet(context.Background(), tt.userID, tt.idempotencyKey, tt.amount)
+			resp, err := repo.DepositWallet(
+				context.Background(),
+				tt.userID,
+				testIdempotencyKey(tt.idempotencyKey),
+				tt.asset,
+				tt.amount,
+			)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.expectTxnID, txID)
+				assert.Equal(t, tt.expectedResponse, resp)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -127,3 +252,21 @@ func TestDepositWallet(t *testing.T) {
 		})
 	}
 }
+
+func depositEventPayload(t *testing.T) []byte {
+	txn := domainwallet.Transaction{
+		ID:                    "tx456",
+		InitiatorWalletUserId: "user456",
+		Type:                  domainwallet.Deposit,
+		Status:                domainwallet.Success,
+		Asset:                 "USD",
+		Amount:                500,
+		CreatedAt:             "2026-10-17T00:00:00Z",
+	}
+	balance := domainwallet.Balance{Asset: "USD", Balance: 500}
+
+	event, err := domainwallet.NewTransactionCreated("wallet456", "user456", txn, balance).Event()
+	require.NoError(t, err)
+
+	return event.Payload
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// walletRunningBalancesQuery computes, for every transaction of wallet $1 of asset $2 created before $6,
+// the change it posted to the wallet's ledger and the wallet's balance of that asset right after it.
+// Balances start from the wallet's opening balance journals, which are not linked to a transaction,
+// and the window runs over every transaction so filters do not break the running balance.
+const walletRunningBalancesQuery = `
+		WITH changes AS (
+			SELECT t.id, t.asset, t.created_at, COALESCE(SUM(e.amount), 0)::BIGINT AS change
+			FROM transactions t
+			LEFT JOIN ledger_journals j ON j.transaction_id = t.id
+			LEFT JOIN ledger_entries e ON e.journal_id = j.id AND e.wallet_id = $1
+			WHERE (t.initiator_wallet_id = $1 OR t.recipient_wallet_id = $1)
+			AND ($2 = '' OR t.asset = $2)
+			AND ($6::timestamp IS NULL OR t.created_at < $6::timestamp)
+			GROUP BY t.id
+		),
+		openings AS (
+			SELECT e.asset, SUM(e.amount) AS opening
+			FROM ledger_entries e
+			JOIN ledger_journals j ON j.id = e.journal_id
+			WHERE e.wallet_id = $1 AND j.transaction_id IS NULL
+			GROUP BY e.asset
+		),
+		running AS (
+			SELECT
+				c.id,
+				c.change,
+				(COALESCE(o.opening, 0) + SUM(c.change) OVER (PARTITION BY c.asset ORDER BY c.created_at, c.id))::BIGINT AS balance
+			FROM changes c
+			LEFT JOIN openings o ON o.asset = c.asset
+		)`
+
+// StreamWalletTransactions does the following:
+// 1. Compute the user wallet's running balance after each of its transactions from the ledger
+// 2. Select the transactions matching the filter with their balance change and running balance, oldest first
+// 3. Scan and pass them to fn one at a time, so the history is never loaded in memory as a whole
+// Iteration stops at the first error returned by fn, which is returned as is.
+func (r *Repository) StreamWalletTransactions(
+	ctx context.Context,
+	userID string,
+	filter domainwallet.TransactionFilter,
+	fn func(domainwallet.StatementLine) error,
+) error {
+	walletID, err := r.getUserWalletID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	query := walletRunningBalancesQuery + `
+		SELECT h.*, r.change, r.balance
+		FROM (` + transactionHistoryColumns + transactionHistoryFrom + `
+		) h
+		JOIN running r ON r.id = h.id
+		ORDER BY h.created_at, h.id;`
+
+	rows, err := r.db.QueryxContext(ctx, query, transactionHistoryArgs(walletID, filter)...)
+	if err != nil {
+		return fmt.Errorf("failed to stream transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domainwallet.StatementLine
+		if err := rows.StructScan(&line); err != nil {
+			return fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to stream transactions: %w", err)
+	}
+
+	return nil
+}
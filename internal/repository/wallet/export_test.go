@@ -0,0 +1,137 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamTransactionsQuery = `WITH changes AS \(.*\), openings AS \(.*\), running AS \(.*\) SELECT h.\*, r.change, r.balance FROM \( SELECT t.id, .* \) h JOIN running r ON r.id = h.id ORDER BY h.created_at, h.id;`
+
+func TestStreamWalletTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	recipientUserID := "user456"
+	columns := []string{"id", "initiator_wallet_user_id", "type", "status", "asset", "amount", "recipient_wallet_user_id", "failure_reason", "parent_transaction_id", "reversed_amount", "created_at", "change", "balance"}
+	errWrite := errors.New("client went away")
+
+	tests := []struct {
+		name          string
+		fnErr         error
+		prepareMock   func()
+		expectedLines []domainwallet.StatementLine
+		expectedError error
+	}{
+		{
+			name: "streams lines oldest first",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(streamTransactionsQuery).
+					WithArgs(noFilterArgs...).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "user123", "deposit", "success", "USD", 500, nil, nil, nil, 0, "2025-05-13T12:44:41Z", 500, 1500).
+						AddRow("tx2", "user123", "transfer", "success", "USD", 200, "user456", nil, nil, 0, "2025-05-13T12:46:53Z", -200, 1300))
+			},
+			expectedLines: []domainwallet.StatementLine{
+				{
+					Transaction: domainwallet.Transaction{
+						ID:                    "tx1",
+						InitiatorWalletUserId: "user123",
+						Type:                  "deposit",
+						Status:                "success",
+						Asset:                 "USD",
+						Amount:                500,
+						CreatedAt:             "2025-05-13T12:44:41Z",
+					},
+					Change:  500,
+					Balance: 1500,
+				},
+				{
+					Transaction: domainwallet.Transaction{
+						ID:                    "tx2",
+						InitiatorWalletUserId: "user123",
+						Type:                  "transfer",
+						Status:                "success",
+						Asset:                 "USD",
+						Amount:                200,
+						RecipientWalletUserId: &recipientUserID,
+						CreatedAt:             "2025-05-13T12:46:53Z",
+					},
+					Change:  -200,
+					Balance: 1300,
+				},
+			},
+		},
+		{
+			name:  "stops on write error",
+			fnErr: errWrite,
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(streamTransactionsQuery).
+					WithArgs(noFilterArgs...).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "user123", "deposit", "success", "USD", 500, nil, nil, nil, 0, "2025-05-13T12:44:41Z", 500, 500).
+						AddRow("tx2", "user123", "withdraw", "success", "USD", 200, nil, nil, nil, 0, "2025-05-13T12:46:53Z", -200, 300))
+			},
+			expectedLines: []domainwallet.StatementLine{
+				{
+					Transaction: domainwallet.Transaction{
+						ID:                    "tx1",
+						InitiatorWalletUserId: "user123",
+						Type:                  "deposit",
+						Status:                "success",
+						Asset:                 "USD",
+						Amount:                500,
+						CreatedAt:             "2025-05-13T12:44:41Z",
+					},
+					Change:  500,
+					Balance: 500,
+				},
+			},
+			expectedError: errWrite,
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			var streamed []domainwallet.StatementLine
+			err := r.StreamWalletTransactions(context.Background(), "user123", domainwallet.TransactionFilter{}, func(line domainwallet.StatementLine) error {
+				streamed = append(streamed, line)
+				return tt.fnErr
+			})
+			assert.Equal(t, tt.expectedLines, streamed)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+type attemptedTransaction struct {
+	Operation         domainwallet.IdempotentOperation
+	InitiatorWalletID string
+	RecipientWalletID *string
+	Type              domainwallet.TransactionType
+	Asset             string
+	Amount            uint64
+}
+
+// failTransaction does the following when cause is a business rule violation:
+// 1. Insert the transaction record with status failed and the failure reason, no balance is moved and no journal is posted
+// 2. Record the rendered FailedTransactionError response on the idempotency key and commit the db transaction
+// 3. Return the recorded response, so retries of the key replay the same failure
+// Any other cause is returned as is, leaving the db transaction to be rolled back.
+func (r *Repository) failTransaction(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	cacheKey, userID string,
+	key domainwallet.IdempotencyKey,
+	txn attemptedTransaction,
+	cause error,
+) (domainwallet.IdempotentResponse, error) {
+	reason, ok := domainwallet.FailureReasonOf(cause)
+	if !ok {
+		return domainwallet.IdempotentResponse{}, cause
+	}
+
+	var transactionID string
+	insertTxn := `
+		INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, type, status, failure_reason, asset, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id
+	`
+	err := tx.GetContext(
+		ctx,
+		&transactionID,
+		insertTxn,
+		txn.InitiatorWalletID,
+		txn.RecipientWalletID,
+		txn.Type,
+		domainwallet.Failed,
+		reason,
+		txn.Asset,
+		txn.Amount,
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert failed transaction record: %w", err)
+	}
+
+	return r.commitIdempotent(
+		ctx,
+		tx,
+		cacheKey,
+		userID,
+		txn.Operation,
+		key,
+		transactionID,
+		domainwallet.FeeBreakdown{},
+		&domainwallet.FailedTransactionError{
+			TransactionID: transactionID,
+			Reason:        reason,
+		},
+	)
+}
@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+const feeTierColumns = `type, asset, min_amount, flat_fee, basis_points, min_fee, max_fee`
+
+// GetFeeSchedules returns the fee schedule of every transaction type and asset with one.
+func (r *Repository) GetFeeSchedules(ctx context.Context) ([]domainwallet.FeeSchedule, error) {
+	var tiers []domainwallet.FeeTier
+	query := `SELECT ` + feeTierColumns + ` FROM fee_tiers ORDER BY type, asset, min_amount`
+	if err := r.db.SelectContext(ctx, &tiers, query); err != nil {
+		return nil, fmt.Errorf("failed to select fee tiers: %w", err)
+	}
+
+	schedules := make([]domainwallet.FeeSchedule, 0)
+	for i, tier := range tiers {
+		if i == 0 || tier.Type != tiers[i-1].Type || tier.Asset != tiers[i-1].Asset {
+			schedules = append(schedules, domainwallet.FeeSchedule{})
+		}
+
+		schedules[len(schedules)-1] = append(schedules[len(schedules)-1], tier)
+	}
+
+	return schedules, nil
+}
+
+// GetFeeSchedule returns the fee schedule of the transaction type and asset, empty when its transactions are free.
+func (r *Repository) GetFeeSchedule(
+	ctx context.Context,
+	txnType domainwallet.TransactionType,
+	asset string,
+) (domainwallet.FeeSchedule, error) {
+	return feeSchedule(ctx, r.db, txnType, asset)
+}
+
+// SetFeeSchedule replaces the fee schedule of the schedule's transaction type and asset as a whole.
+func (r *Repository) SetFeeSchedule(
+	ctx context.Context,
+	schedule domainwallet.FeeSchedule,
+) (domainwallet.FeeSchedule, error) {
+	txnType, asset := schedule[0].Type, schedule[0].Asset
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM fee_tiers WHERE type = $1 AND asset = $2`, txnType, asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete fee tiers: %w", err)
+	}
+
+	insertTiers := `
+		INSERT INTO fee_tiers (` + feeTierColumns + `)
+		VALUES (:type, :asset, :min_amount, :flat_fee, :basis_points, :min_fee, :max_fee)
+	`
+	if _, err = tx.NamedExecContext(ctx, insertTiers, []domainwallet.FeeTier(schedule)); err != nil {
+		return nil, fmt.Errorf("failed to insert fee tiers: %w", err)
+	}
+
+	schedule, err = feeSchedule(ctx, tx, txnType, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteFeeSchedule deletes the fee schedule of the transaction type and asset, its transactions are free again.
+func (r *Repository) DeleteFeeSchedule(
+	ctx context.Context,
+	txnType domainwallet.TransactionType,
+	asset string,
+) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM fee_tiers WHERE type = $1 AND asset = $2`, txnType, asset)
+	if err != nil {
+		return fmt.Errorf("failed to delete fee schedule: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted fee tiers: %w", err)
+	}
+
+	if deleted == 0 {
+		return domainwallet.ErrFeeScheduleNotFound
+	}
+
+	return nil
+}
+
+// quoteFee prices a transaction of amount with the fee schedule of its type and asset, read within the caller's
+// db tx so the fee charged is the one of the schedule in effect when the transaction is recorded.
+func quoteFee(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	txnType domainwallet.TransactionType,
+	asset string,
+	amount uint64,
+) (domainwallet.FeeBreakdown, error) {
+	schedule, err := feeSchedule(ctx, tx, txnType, asset)
+	if err != nil {
+		return domainwallet.FeeBreakdown{}, err
+	}
+
+	fee, err := schedule.Quote(asset, amount)
+	if err != nil {
+		return domainwallet.FeeBreakdown{}, fmt.Errorf("failed to quote fee: %w", err)
+	}
+
+	return fee, nil
+}
+
+// feeSchedule selects the tiers of the fee schedule of the transaction type and asset.
+func feeSchedule(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	txnType domainwallet.TransactionType,
+	asset string,
+) (domainwallet.FeeSchedule, error) {
+	var schedule domainwallet.FeeSchedule
+	query := `SELECT ` + feeTierColumns + ` FROM fee_tiers WHERE type = $1 AND asset = $2 ORDER BY min_amount`
+	if err := sqlx.SelectContext(ctx, q, &schedule, query, txnType, asset); err != nil {
+		return nil, fmt.Errorf("failed to select fee schedule: %w", err)
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,209 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	feeScheduleQuery  = `SELECT type, asset, min_amount, flat_fee, basis_points, min_fee, max_fee FROM fee_tiers WHERE type = \$1 AND asset = \$2 ORDER BY min_amount`
+	feeSchedulesQuery = `SELECT type, asset, min_amount, flat_fee, basis_points, min_fee, max_fee FROM fee_tiers ORDER BY type, asset, min_amount`
+	deleteFeeTiers    = `DELETE FROM fee_tiers WHERE type = \$1 AND asset = \$2`
+	insertFeeTiers    = `INSERT INTO fee_tiers \(type, asset, min_amount, flat_fee, basis_points, min_fee, max_fee\)`
+)
+
+var feeTierColumns = []string{"type", "asset", "min_amount", "flat_fee", "basis_points", "min_fee", "max_fee"}
+
+// expectNoFeeSchedule expects the fee of a transaction of the type and asset to be priced with no fee schedule.
+func expectNoFeeSchedule(mock sqlmock.Sqlmock, txnType, asset string) {
+	mock.ExpectQuery(feeScheduleQuery).
+		WithArgs(txnType, asset).
+		WillReturnRows(sqlmock.NewRows(feeTierColumns))
+}
+
+// expectFeeSchedule expects the fee of a transaction of the type and asset to be priced with a flat fee.
+func expectFeeSchedule(mock sqlmock.Sqlmock, txnType, asset string, flatFee int) {
+	mock.ExpectQuery(feeScheduleQuery).
+		WithArgs(txnType, asset).
+		WillReturnRows(sqlmock.NewRows(feeTierColumns).AddRow(txnType, asset, 0, flatFee, 0, nil, nil))
+}
+
+func TestGetFeeSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	r := wallet.New(sqlx.NewDb(db, "postgres"), nil, nil)
+
+	tests := []struct {
+		name              string
+		prepareMock       func()
+		expectedSchedules []domainwallet.FeeSchedule
+		expectedErr       error
+	}{
+		{
+			name: "tiers grouped by type and asset",
+			prepareMock: func() {
+				mock.ExpectQuery(feeSchedulesQuery).
+					WillReturnRows(sqlmock.NewRows(feeTierColumns).
+						AddRow("withdraw", "BTC", 0, 1000, 0, nil, nil).
+						AddRow("withdraw", "USD", 0, 100, 0, nil, nil).
+						AddRow("withdraw", "USD", 100000, 0, 50, 100, 2500))
+			},
+			expectedSchedules: []domainwallet.FeeSchedule{
+				{{Type: domainwallet.Withdraw, Asset: "BTC", FlatFee: 1000}},
+				{
+					{Type: domainwallet.Withdraw, Asset: "USD", FlatFee: 100},
+					{Type: domainwallet.Withdraw, Asset: "USD", MinAmount: 100000, BasisPoints: 50, MinFee: limitOf(100), MaxFee: limitOf(2500)},
+				},
+			},
+		},
+		{
+			name: "no fee schedules",
+			prepareMock: func() {
+				mock.ExpectQuery(feeSchedulesQuery).WillReturnRows(sqlmock.NewRows(feeTierColumns))
+			},
+			expectedSchedules: []domainwallet.FeeSchedule{},
+		},
+		{
+			name: "db error",
+			prepareMock: func() {
+				mock.ExpectQuery(feeSchedulesQuery).WillReturnError(errors.New("db error"))
+			},
+			expectedErr: errors.New("failed to select fee tiers: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			schedules, err := r.GetFeeSchedules(context.Background())
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedSchedules, schedules)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetFeeSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	r := wallet.New(sqlx.NewDb(db, "postgres"), nil, nil)
+
+	schedule := domainwallet.FeeSchedule{
+		{Type: domainwallet.Transfer, Asset: "USD", FlatFee: 25},
+		{Type: domainwallet.Transfer, Asset: "USD", MinAmount: 10000, BasisPoints: 10, MaxFee: limitOf(500)},
+	}
+
+	tests := []struct {
+		name             string
+		prepareMock      func()
+		expectedSchedule domainwallet.FeeSchedule
+		expectedErr      error
+	}{
+		{
+			name: "schedule replaced",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteFeeTiers).
+					WithArgs("transfer", "USD").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertFeeTiers).
+					WithArgs("transfer", "USD", 0, 25, 0, nil, nil, "transfer", "USD", 10000, 0, 10, nil, 500).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery(feeScheduleQuery).
+					WithArgs("transfer", "USD").
+					WillReturnRows(sqlmock.NewRows(feeTierColumns).
+						AddRow("transfer", "USD", 0, 25, 0, nil, nil).
+						AddRow("transfer", "USD", 10000, 0, 10, nil, 500))
+				mock.ExpectCommit()
+			},
+			expectedSchedule: schedule,
+		},
+		{
+			name: "insert error",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteFeeTiers).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertFeeTiers).WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New("failed to insert fee tiers: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			set, err := r.SetFeeSchedule(context.Background(), schedule)
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedSchedule, set)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteFeeSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	r := wallet.New(sqlx.NewDb(db, "postgres"), nil, nil)
+
+	tests := []struct {
+		name        string
+		prepareMock func()
+		expectedErr error
+	}{
+		{
+			name: "schedule deleted",
+			prepareMock: func() {
+				mock.ExpectExec(deleteFeeTiers).
+					WithArgs("withdraw", "BTC").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			name: "no schedule",
+			prepareMock: func() {
+				mock.ExpectExec(deleteFeeTiers).
+					WithArgs("withdraw", "BTC").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: domainwallet.ErrFeeScheduleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			err := r.DeleteFeeSchedule(context.Background(), domainwallet.Withdraw, "BTC")
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
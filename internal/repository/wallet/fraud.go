@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// fraudSignalsQuery reads what the fraud rules know of user $1's wallet: its age in seconds, its available balance of
+// asset $2, its successful and pending review withdrawals in the last $3 seconds, and for a transfer to user $4 whether
+// it never transferred to them successfully.
+const fraudSignalsQuery = `
+	SELECT
+		EXTRACT(EPOCH FROM NOW() - w.created_at)::BIGINT AS wallet_age,
+		COALESCE(b.balance - b.held, 0) AS available,
+		(
+			SELECT COUNT(*) FROM transactions t
+			WHERE t.initiator_wallet_id = w.id AND t.type = 'withdraw' AND t.status IN ('success', 'pending_review')
+			AND t.created_at > NOW() - make_interval(secs => $3)
+		) AS recent_withdrawals,
+		$4::uuid IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM transactions t
+			JOIN wallets rw ON rw.id = t.recipient_wallet_id
+			WHERE t.initiator_wallet_id = w.id AND t.type = 'transfer' AND t.status = 'success' AND rw.user_id = $4::uuid
+		) AS new_recipient
+	FROM wallets w
+	LEFT JOIN balances b ON b.wallet_id = w.id AND b.asset = $2
+	WHERE w.user_id = $1
+`
+
+// fraudHoldQuery reads the hold $1 of user $2's wallet, captured into the withdrawal or transfer screened.
+const fraudHoldQuery = `
+	SELECT h.asset, h.amount, h.status FROM holds h
+	JOIN wallets w ON w.id = h.wallet_id
+	WHERE h.id = $1 AND w.user_id = $2
+`
+
+type fraudSignals struct {
+	WalletAge         int64  `db:"wallet_age"`
+	Available         uint64 `db:"available"`
+	RecentWithdrawals int    `db:"recent_withdrawals"`
+	NewRecipient      bool   `db:"new_recipient"`
+}
+
+// GetFraudSignals does the following:
+// 1. For the capture of a hold, read the hold for its asset and, while still active, its held amount the capture releases
+// 2. Price the withdrawal or transfer with the fee schedule of its type and asset
+// 3. Read the initiator wallet's age, available balance, recent withdrawals and, for transfers, whether the recipient is new
+// Signals are read without locking the wallet, the transaction itself re-checks balances and limits when it executes.
+func (r *Repository) GetFraudSignals(
+	ctx context.Context,
+	check domainwallet.FraudCheck,
+) (domainwallet.FraudSignals, error) {
+	var held uint64
+	if check.HoldID != "" {
+		var hold domainwallet.Hold
+		if err := r.db.GetContext(ctx, &hold, fraudHoldQuery, check.HoldID, check.UserID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domainwallet.FraudSignals{}, fmt.Errorf("hold not found: %w", domainwallet.ErrHoldNotFound)
+			}
+
+			return domainwallet.FraudSignals{}, fmt.Errorf("failed to get hold: %w", err)
+		}
+
+		check.Asset = hold.Asset
+		if hold.Status == domainwallet.HoldActive {
+			held = hold.Amount
+		}
+	}
+
+	schedule, err := feeSchedule(ctx, r.db, check.Type, check.Asset)
+	if err != nil {
+		return domainwallet.FraudSignals{}, err
+	}
+
+	fee, err := schedule.Quote(check.Asset, check.Amount)
+	if err != nil {
+		return domainwallet.FraudSignals{}, fmt.Errorf("failed to quote fee: %w", err)
+	}
+
+	var recipientUserID *string
+	if check.RecipientUserID != "" {
+		recipientUserID = &check.RecipientUserID
+	}
+
+	var signals fraudSignals
+	err = r.db.GetContext(
+		ctx,
+		&signals,
+		fraudSignalsQuery,
+		check.UserID,
+		check.Asset,
+		domainwallet.WithdrawalVelocityWindow.Seconds(),
+		recipientUserID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.FraudSignals{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return domainwallet.FraudSignals{}, fmt.Errorf("failed to get fraud signals: %w", err)
+	}
+
+	return domainwallet.FraudSignals{
+		FraudCheck:        check,
+		Total:             fee.Total,
+		Available:         signals.Available + held,
+		WalletAge:         time.Duration(signals.WalletAge) * time.Second,
+		RecentWithdrawals: signals.RecentWithdrawals,
+		NewRecipient:      signals.NewRecipient,
+	}, nil
+}
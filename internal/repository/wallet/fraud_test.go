@@ -0,0 +1,172 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fraudSignalsQuery = `SELECT EXTRACT\(EPOCH FROM NOW\(\) - w.created_at\)::BIGINT AS wallet_age, .* FROM wallets w .* WHERE w.user_id = \$1`
+
+var fraudSignalsColumns = []string{"wallet_age", "available", "recent_withdrawals", "new_recipient"}
+
+const fraudHoldQuery = `SELECT h.asset, h.amount, h.status FROM holds h JOIN wallets w ON w.id = h.wallet_id WHERE h.id = \$1 AND w.user_id = \$2`
+
+func TestGetFraudSignals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	withdraw := domainwallet.FraudCheck{Type: domainwallet.Withdraw, UserID: "user1", Asset: "USD", Amount: 200}
+	transfer := domainwallet.FraudCheck{
+		Type:            domainwallet.Transfer,
+		UserID:          "user1",
+		RecipientUserID: "user2",
+		Asset:           "USD",
+		Amount:          500,
+	}
+	capture := domainwallet.FraudCheck{Type: domainwallet.Withdraw, UserID: "user1", HoldID: "hold1", Amount: 300}
+	captureOfHold := capture
+	captureOfHold.Asset = "USD"
+
+	tests := []struct {
+		name            string
+		check           domainwallet.FraudCheck
+		prepareSQL      func()
+		expectedSignals domainwallet.FraudSignals
+		expectedError   error
+	}{
+		{
+			name:  "withdraw of a new wallet with its fee",
+			check: withdraw,
+			prepareSQL: func() {
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(fraudSignalsQuery).
+					WithArgs("user1", "USD", float64(3600), nil).
+					WillReturnRows(sqlmock.NewRows(fraudSignalsColumns).AddRow(600, 210, 2, false))
+			},
+			expectedSignals: domainwallet.FraudSignals{
+				FraudCheck:        withdraw,
+				Total:             210,
+				Available:         210,
+				WalletAge:         10 * time.Minute,
+				RecentWithdrawals: 2,
+			},
+		},
+		{
+			name:  "transfer to a new recipient",
+			check: transfer,
+			prepareSQL: func() {
+				expectNoFeeSchedule(mock, "transfer", "USD")
+				mock.ExpectQuery(fraudSignalsQuery).
+					WithArgs("user1", "USD", float64(3600), "user2").
+					WillReturnRows(sqlmock.NewRows(fraudSignalsColumns).AddRow(172800, 1000, 0, true))
+			},
+			expectedSignals: domainwallet.FraudSignals{
+				FraudCheck:   transfer,
+				Total:        500,
+				Available:    1000,
+				WalletAge:    48 * time.Hour,
+				NewRecipient: true,
+			},
+		},
+		{
+			name:  "capture of an active hold counts its held amount as available",
+			check: capture,
+			prepareSQL: func() {
+				mock.ExpectQuery(fraudHoldQuery).
+					WithArgs("hold1", "user1").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "amount", "status"}).AddRow("USD", 500, "active"))
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(fraudSignalsQuery).
+					WithArgs("user1", "USD", float64(3600), nil).
+					WillReturnRows(sqlmock.NewRows(fraudSignalsColumns).AddRow(600, 100, 0, false))
+			},
+			expectedSignals: domainwallet.FraudSignals{
+				FraudCheck: captureOfHold,
+				Total:      310,
+				Available:  600,
+				WalletAge:  10 * time.Minute,
+			},
+		},
+		{
+			name:  "capture of a released hold",
+			check: capture,
+			prepareSQL: func() {
+				mock.ExpectQuery(fraudHoldQuery).
+					WithArgs("hold1", "user1").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "amount", "status"}).AddRow("USD", 500, "released"))
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(fraudSignalsQuery).
+					WithArgs("user1", "USD", float64(3600), nil).
+					WillReturnRows(sqlmock.NewRows(fraudSignalsColumns).AddRow(600, 100, 0, false))
+			},
+			expectedSignals: domainwallet.FraudSignals{
+				FraudCheck: captureOfHold,
+				Total:      300,
+				Available:  100,
+				WalletAge:  10 * time.Minute,
+			},
+		},
+		{
+			name:  "hold not found",
+			check: capture,
+			prepareSQL: func() {
+				mock.ExpectQuery(fraudHoldQuery).
+					WithArgs("hold1", "user1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: fmt.Errorf("hold not found: %w", domainwallet.ErrHoldNotFound),
+		},
+		{
+			name:  "wallet not found",
+			check: withdraw,
+			prepareSQL: func() {
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(fraudSignalsQuery).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
+		},
+		{
+			name:  "fee schedule error",
+			check: withdraw,
+			prepareSQL: func() {
+				mock.ExpectQuery(feeScheduleQuery).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to select fee schedule: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			signals, err := r.GetFraudSignals(context.Background(), tt.check)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedSignals, signals)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
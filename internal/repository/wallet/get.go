@@ -5,13 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 )
 
 func (r *Repository) GetWallet(ctx context.Context, userID string) (domainwallet.Wallet, error) {
 	const query = `
-		SELECT id, user_id, balance, created_at
+		SELECT id, user_id, status, kyc_tier, created_at
 		FROM wallets
 		WHERE user_id = $1
 		LIMIT 1;
@@ -26,34 +27,112 @@ func (r *Repository) GetWallet(ctx context.Context, userID string) (domainwallet
 		return domainwallet.Wallet{}, fmt.Errorf("failed to get wallet by userID: %w", err)
 	}
 
+	dst.Balances, err = getBalances(ctx, r.db, dst.ID)
+	if err != nil {
+		return domainwallet.Wallet{}, err
+	}
+
 	return dst, nil
 }
 
-func (r *Repository) GetWalletTransactionsHistory(
-	ctx context.Context,
-	userID string,
-	offset, pageSize int,
-) ([]domainwallet.Transaction, int, error) {
+// transactionHistoryFrom selects the wallet's transactions matching a TransactionFilter, with args:
+// $1 wallet ID, $2 asset, $3 type, $4 status, $5 from, $6 to, $7 min amount, $8 max amount, $9 counterparty user ID.
+// Transactions are matched on wallet IDs rather than user IDs, so both sides use the (wallet_id, created_at, id) indexes.
+const transactionHistoryFrom = `
+		FROM transactions t
+		JOIN wallets iw ON t.initiator_wallet_id = iw.id
+		LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id
+		WHERE (t.initiator_wallet_id = $1 OR t.recipient_wallet_id = $1)
+		AND ($2 = '' OR t.asset = $2)
+		AND ($3 = '' OR t.type::text = $3)
+		AND ($4 = '' OR t.status::text = $4)
+		AND ($5::timestamp IS NULL OR t.created_at >= $5::timestamp)
+		AND ($6::timestamp IS NULL OR t.created_at < $6::timestamp)
+		AND ($7::bigint = 0 OR t.amount >= $7::bigint)
+		AND ($8::bigint = 0 OR t.amount <= $8::bigint)
+		AND (
+			$9 = ''
+			OR (t.initiator_wallet_id = $1 AND rw.user_id::text = $9)
+			OR (t.recipient_wallet_id = $1 AND iw.user_id::text = $9)
+		)`
+
+const transactionHistoryColumns = `
+		SELECT
+			t.id,
+			iw.user_id AS initiator_wallet_user_id,
+			t.type,
+			t.status,
+			t.asset,
+			t.amount,
+			rw.user_id AS recipient_wallet_user_id,
+			t.failure_reason,
+			t.parent_transaction_id,
+			(
+				SELECT COALESCE(SUM(r.amount), 0) FROM transactions r
+				WHERE r.parent_transaction_id = t.id AND r.status = 'success'
+			) AS reversed_amount,
+			t.created_at`
+
+// transactionHistoryArgs returns the args of transactionHistoryFrom.
+func transactionHistoryArgs(walletID string, filter domainwallet.TransactionFilter) []any {
+	return []any{
+		walletID,
+		filter.Asset,
+		string(filter.Type),
+		string(filter.Status),
+		nullTime(filter.From),
+		nullTime(filter.To),
+		filter.MinAmount,
+		filter.MaxAmount,
+		filter.CounterpartyUserID,
+	}
+}
+
+// nullTime returns nil for the zero time, so it is passed to postgres as NULL.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+	return &t
+}
+
+func (r *Repository) getUserWalletID(ctx context.Context, userID string) (string, error) {
 	var walletID string
 	err := r.db.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1`, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, 0, fmt.Errorf(
+			return "", fmt.Errorf(
 				"failed get user wallet: %w",
 				domainwallet.ErrWalletNotFound,
 			)
 		}
-		return nil, 0, fmt.Errorf("failed to get wallet for user %s: %w", userID, err)
+		return "", fmt.Errorf("failed to get wallet for user %s: %w", userID, err)
 	}
 
-	var total int
-	const countQuery = `
-		SELECT COUNT(*) FROM transactions t
-		JOIN wallets iw ON t.initiator_wallet_id = iw.id
-		LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id
-		WHERE iw.user_id = $1 OR rw.user_id = $1;`
+	return walletID, nil
+}
 
-	err = r.db.GetContext(ctx, &total, countQuery, userID)
+// GetWalletTransactionsHistory does the following:
+// 1. Count the user wallet's transactions matching the filter
+// 2. Return the page of them at offset, ordered by (created_at, id) descending
+// Offset pagination is kept for older clients, GetWalletTransactionsAfter pages with a cursor instead.
+func (r *Repository) GetWalletTransactionsHistory(
+	ctx context.Context,
+	userID string,
+	filter domainwallet.TransactionFilter,
+	offset, pageSize int,
+) ([]domainwallet.Transaction, int, error) {
+	walletID, err := r.getUserWalletID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args := transactionHistoryArgs(walletID, filter)
+
+	var total int
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+transactionHistoryFrom+`;`, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
 	}
@@ -61,28 +140,101 @@ func (r *Repository) GetWalletTransactionsHistory(
 		return nil, 0, nil
 	}
 
+	query := transactionHistoryColumns + transactionHistoryFrom + `
+		ORDER BY t.created_at DESC, t.id DESC
+		OFFSET $10 LIMIT $11;`
+
+	var transactions []domainwallet.Transaction
+	err = r.db.SelectContext(ctx, &transactions, query, append(args, offset, pageSize)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+
+	return transactions, total, nil
+}
+
+// GetWalletTransactionsAfter does the following:
+// 1. Seek the user wallet's transactions matching the filter past the cursor, or from the latest one without a cursor
+// 2. Return up to limit of them ordered by (created_at, id) descending, without counting the total
+func (r *Repository) GetWalletTransactionsAfter(
+	ctx context.Context,
+	userID string,
+	filter domainwallet.TransactionFilter,
+	after *domainwallet.TransactionCursor,
+	limit int,
+) ([]domainwallet.Transaction, error) {
+	walletID, err := r.getUserWalletID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var afterCreatedAt *time.Time
+	var afterID *string
+	if after != nil {
+		afterCreatedAt, afterID = nullTime(after.CreatedAt), &after.ID
+	}
+
+	query := transactionHistoryColumns + transactionHistoryFrom + `
+		AND ($10::timestamp IS NULL OR (t.created_at, t.id) < ($10::timestamp, $11::uuid))
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $12;`
+
+	args := append(transactionHistoryArgs(walletID, filter), afterCreatedAt, afterID, limit)
+
+	var transactions []domainwallet.Transaction
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetWalletTransaction does the following:
+// 1. Get the transaction with both parties' user IDs, only if the user is one of them so other users' transactions are not found
+// 2. Join the idempotency key it was recorded under and the hold it was captured from, if any
+func (r *Repository) GetWalletTransaction(
+	ctx context.Context,
+	userID, transactionID string,
+) (domainwallet.Transaction, error) {
 	const query = `
-		SELECT 
+		SELECT
 			t.id,
 			iw.user_id AS initiator_wallet_user_id,
 			t.type,
 			t.status,
+			t.asset,
 			t.amount,
 			rw.user_id AS recipient_wallet_user_id,
+			t.failure_reason,
+			t.parent_transaction_id,
+			(
+				SELECT COALESCE(SUM(r.amount), 0) FROM transactions r
+				WHERE r.parent_transaction_id = t.id AND r.status = 'success'
+			) AS reversed_amount,
+			k.idempotency_key,
+			h.id AS hold_id,
 			t.created_at
 		FROM transactions t
 		JOIN wallets iw ON t.initiator_wallet_id = iw.id
 		LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id
-		WHERE iw.user_id = $1 OR rw.user_id = $1
-		ORDER BY t.created_at DESC
-		OFFSET $2 LIMIT $3;
+		LEFT JOIN idempotency_keys k ON k.transaction_id = t.id
+		LEFT JOIN holds h ON h.transaction_id = t.id
+		WHERE t.id = $1
+		AND (iw.user_id = $2 OR rw.user_id = $2);
 	`
 
-	var transactions []domainwallet.Transaction
-	err = r.db.SelectContext(ctx, &transactions, query, userID, offset, pageSize)
+	var transaction domainwallet.Transaction
+	err := r.db.GetContext(ctx, &transaction, query, transactionID, userID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch transactions: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Transaction{}, fmt.Errorf(
+				"failed get user transaction: %w",
+				domainwallet.ErrTransactionNotFound,
+			)
+		}
+		return domainwallet.Transaction{}, fmt.Errorf("failed to get transaction %s: %w", transactionID, err)
 	}
 
-	return transactions, total, nil
+	return transaction, nil
 }
@@ -3,6 +3,7 @@ package wallet_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"regexp"
@@ -14,6 +15,7 @@ import (
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetWallet(t *testing.T) {
@@ -24,6 +26,9 @@ func TestGetWallet(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "postgres")
 	r := wallet.New(sqlxDB, nil, nil)
 
+	walletQuery := regexp.QuoteMeta(`SELECT id, user_id, status, kyc_tier, created_at FROM wallets WHERE user_id = $1 LIMIT 1;`)
+	balancesQuery := regexp.QuoteMeta(`SELECT asset, balance, held FROM balances WHERE wallet_id = $1 ORDER BY asset`)
+
 	tests := []struct {
 		name          string
 		prepareMock   func()
@@ -33,22 +38,30 @@ func TestGetWallet(t *testing.T) {
 		{
 			name: "wallet found",
 			prepareMock: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, balance, created_at FROM wallets WHERE user_id = $1 LIMIT 1;`)).
+				mock.ExpectQuery(walletQuery).
 					WithArgs("user123").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "created_at"}).
-						AddRow("wallet-1", "user123", 1000, time.Now()))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "kyc_tier", "created_at"}).
+						AddRow("wallet-1", "user123", "active", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet-1").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance"}).
+						AddRow("BTC", 2500).
+						AddRow("USD", 1000))
 			},
 			expected: domainwallet.Wallet{
-				ID:      "wallet-1",
-				UserID:  "user123",
-				Balance: 1000,
+				ID:     "wallet-1",
+				UserID: "user123",
+				Balances: []domainwallet.Balance{
+					{Asset: "BTC", Balance: 2500},
+					{Asset: "USD", Balance: 1000},
+				},
 			},
 			expectedError: nil,
 		},
 		{
 			name: "wallet not found",
 			prepareMock: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, balance, created_at FROM wallets WHERE user_id = $1 LIMIT 1;`)).
+				mock.ExpectQuery(walletQuery).
 					WithArgs("").
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -58,34 +71,61 @@ func TestGetWallet(t *testing.T) {
 		{
 			name: "db error",
 			prepareMock: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, balance, created_at FROM wallets WHERE user_id = $1 LIMIT 1;`)).
+				mock.ExpectQuery(walletQuery).
 					WithArgs("").
 					WillReturnError(errors.New("db error"))
 			},
 			expected:      domainwallet.Wallet{},
 			expectedError: errors.New("failed to get wallet by userID: db error"),
 		},
+		{
+			name: "balances error",
+			prepareMock: func() {
+				mock.ExpectQuery(walletQuery).
+					WithArgs("user456").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "kyc_tier", "created_at"}).
+						AddRow("wallet-2", "user456", "active", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet-2").
+					WillReturnError(errors.New("db error"))
+			},
+			expected:      domainwallet.Wallet{UserID: "user456"},
+			expectedError: errors.New("failed to get wallet balances: db error"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepareMock()
 			wallet, err := r.GetWallet(context.Background(), tt.expected.UserID)
-			assert.Equal(t, tt.expected.ID, wallet.ID)
-			assert.Equal(t, tt.expected.UserID, wallet.UserID)
-			assert.Equal(t, tt.expected.Balance, wallet.Balance)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				assert.Equal(t, domainwallet.Wallet{}, wallet)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected.ID, wallet.ID)
+				assert.Equal(t, tt.expected.UserID, wallet.UserID)
+				assert.Equal(t, tt.expected.Balances, wallet.Balances)
 			}
 		})
 	}
 }
 
+const (
+	countTransactionsHistoryQuery = `SELECT COUNT\(\*\) FROM transactions t .* WHERE \(t.initiator_wallet_id = \$1 OR t.recipient_wallet_id = \$1\) .*;`
+	transactionsHistoryQuery      = `SELECT t.id, .* WHERE \(t.initiator_wallet_id = \$1 OR t.recipient_wallet_id = \$1\) .* ORDER BY t.created_at DESC, t.id DESC OFFSET \$10 LIMIT \$11;`
+	transactionsAfterQuery        = `SELECT t.id, .* AND \(\$10::timestamp IS NULL OR \(t.created_at, t.id\) < \(\$10::timestamp, \$11::uuid\)\) ORDER BY t.created_at DESC, t.id DESC LIMIT \$12;`
+)
+
+// noFilterArgs are the transactionHistoryFrom args of wallet-1 without any filter.
+var noFilterArgs = []driver.Value{"wallet-1", "", "", "", nil, nil, 0, 0, ""}
+
 func TestGetWalletTransactionsHistory(t *testing.T) {
 	testTime := time.Now()
+	recipientUserID := "user456"
+	insufficientBalance := domainwallet.FailureInsufficientBalance
+	parentTransactionID := "tx1"
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
@@ -107,27 +147,52 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 					WithArgs("user123").
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1;`)).
-					WithArgs("user123").
-					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(countTransactionsHistoryQuery).
+					WithArgs(noFilterArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, iw.user_id AS initiator_wallet_user_id, t.type, t.status, t.amount, rw.user_id AS recipient_wallet_user_id, t.created_at FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1 ORDER BY t.created_at DESC OFFSET $2 LIMIT $3;`)).
-					WithArgs("user123", 0, 10).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "initiator_wallet_user_id", "type", "status", "amount", "recipient_wallet_user_id", "created_at"}).
-						AddRow("tx1", "user123", "deposit", "success", 100, nil, testTime.String()))
+				mock.ExpectQuery(transactionsHistoryQuery).
+					WithArgs(append(noFilterArgs, 0, 10)...).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "initiator_wallet_user_id", "type", "status", "asset", "amount", "recipient_wallet_user_id", "failure_reason", "parent_transaction_id", "reversed_amount", "created_at"}).
+						AddRow("tx3", "user123", "reversal", "success", "USD", 40, nil, nil, "tx1", 0, testTime.String()).
+						AddRow("tx1", "user123", "deposit", "success", "USD", 100, nil, nil, nil, 40, testTime.String()).
+						AddRow("tx2", "user123", "transfer", "failed", "USD", 5000, "user456", "insufficient_balance", nil, 0, testTime.String()))
 			},
 			expectedTxs: []domainwallet.Transaction{
+				{
+					ID:                    "tx3",
+					InitiatorWalletUserId: "user123",
+					Type:                  "reversal",
+					Status:                "success",
+					Asset:                 "USD",
+					Amount:                40,
+					ParentTransactionID:   &parentTransactionID,
+					CreatedAt:             testTime.String(),
+				},
 				{
 					ID:                    "tx1",
 					InitiatorWalletUserId: "user123",
 					Type:                  "deposit",
 					Status:                "success",
+					Asset:                 "USD",
 					Amount:                100,
 					RecipientWalletUserId: nil,
+					ReversedAmount:        40,
+					CreatedAt:             testTime.String(),
+				},
+				{
+					ID:                    "tx2",
+					InitiatorWalletUserId: "user123",
+					Type:                  "transfer",
+					Status:                "failed",
+					Asset:                 "USD",
+					Amount:                5000,
+					RecipientWalletUserId: &recipientUserID,
+					FailureReason:         &insufficientBalance,
 					CreatedAt:             testTime.String(),
 				},
 			},
-			expectedTotal: 1,
+			expectedTotal: 3,
 			expectedError: nil,
 		},
 		{
@@ -159,8 +224,8 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 					WithArgs("user123").
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1;`)).
-					WithArgs("user123").
+				mock.ExpectQuery(countTransactionsHistoryQuery).
+					WithArgs(noFilterArgs...).
 					WillReturnError(fmt.Errorf("count error"))
 			},
 			expectedTxs:   nil,
@@ -174,8 +239,8 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 					WithArgs("user123").
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1;`)).
-					WithArgs("user123").
+				mock.ExpectQuery(countTransactionsHistoryQuery).
+					WithArgs(noFilterArgs...).
 					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 			},
 			expectedTxs:   nil,
@@ -189,12 +254,12 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 					WithArgs("user123").
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1;`)).
-					WithArgs("user123").
+				mock.ExpectQuery(countTransactionsHistoryQuery).
+					WithArgs(noFilterArgs...).
 					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, iw.user_id AS initiator_wallet_user_id, t.type, t.status, t.amount, rw.user_id AS recipient_wallet_user_id, t.created_at FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id WHERE iw.user_id = $1 OR rw.user_id = $1 ORDER BY t.created_at DESC OFFSET $2 LIMIT $3;`)).
-					WithArgs("user123", 0, 10).
+				mock.ExpectQuery(transactionsHistoryQuery).
+					WithArgs(append(noFilterArgs, 0, 10)...).
 					WillReturnError(fmt.Errorf("fetch error"))
 			},
 			expectedTxs:   nil,
@@ -206,7 +271,7 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepareMock()
-			txs, total, err := r.GetWalletTransactionsHistory(context.Background(), "user123", 0, 10)
+			txs, total, err := r.GetWalletTransactionsHistory(context.Background(), "user123", domainwallet.TransactionFilter{}, 0, 10)
 			assert.Equal(t, tt.expectedTxs, txs)
 			assert.Equal(t, tt.expectedTotal, total)
 			if tt.expectedError != nil {
@@ -218,3 +283,188 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWalletTransaction(t *testing.T) {
+	testTime := time.Now()
+	recipientUserID := "user456"
+	idempotencyKey := "idem1"
+	holdID := "hold1"
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	const query = `SELECT t.id, .* k.idempotency_key, h.id AS hold_id, t.created_at FROM transactions t JOIN wallets iw ON t.initiator_wallet_id = iw.id LEFT JOIN wallets rw ON t.recipient_wallet_id = rw.id LEFT JOIN idempotency_keys k ON k.transaction_id = t.id LEFT JOIN holds h ON h.transaction_id = t.id WHERE t.id = \$1 AND \(iw.user_id = \$2 OR rw.user_id = \$2\);`
+
+	tests := []struct {
+		name          string
+		userID        string
+		prepareMock   func()
+		expectedTx    domainwallet.Transaction
+		expectedError error
+	}{
+		{
+			name:   "recipient fetches transfer captured from hold",
+			userID: "user456",
+			prepareMock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("tx1", "user456").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "initiator_wallet_user_id", "type", "status", "asset", "amount", "recipient_wallet_user_id", "failure_reason", "parent_transaction_id", "reversed_amount", "idempotency_key", "hold_id", "created_at"}).
+						AddRow("tx1", "user123", "transfer", "success", "USD", 500, "user456", nil, nil, 0, "idem1", "hold1", testTime.String()))
+			},
+			expectedTx: domainwallet.Transaction{
+				ID:                    "tx1",
+				InitiatorWalletUserId: "user123",
+				Type:                  "transfer",
+				Status:                "success",
+				Asset:                 "USD",
+				Amount:                500,
+				RecipientWalletUserId: &recipientUserID,
+				IdempotencyKey:        &idempotencyKey,
+				HoldID:                &holdID,
+				CreatedAt:             testTime.String(),
+			},
+		},
+		{
+			name:   "not a party to the transaction",
+			userID: "user789",
+			prepareMock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("tx1", "user789").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrTransactionNotFound,
+		},
+		{
+			name:   "db error",
+			userID: "user123",
+			prepareMock: func() {
+				mock.ExpectQuery(query).
+					WithArgs("tx1", "user123").
+					WillReturnError(fmt.Errorf("db error"))
+			},
+			expectedError: fmt.Errorf("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			tx, err := r.GetWalletTransaction(context.Background(), tt.userID, "tx1")
+			assert.Equal(t, tt.expectedTx, tx)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetWalletTransactionsAfter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	after := &domainwallet.TransactionCursor{CreatedAt: time.Date(2025, 5, 13, 12, 50, 39, 101388000, time.UTC), ID: "tx2"}
+	recipientUserID := "user456"
+	columns := []string{"id", "initiator_wallet_user_id", "type", "status", "asset", "amount", "recipient_wallet_user_id", "failure_reason", "parent_transaction_id", "reversed_amount", "created_at"}
+
+	tests := []struct {
+		name          string
+		filter        domainwallet.TransactionFilter
+		after         *domainwallet.TransactionCursor
+		prepareMock   func()
+		expectedTxs   []domainwallet.Transaction
+		expectedError error
+	}{
+		{
+			name: "first page",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(transactionsAfterQuery).
+					WithArgs(append(noFilterArgs, nil, nil, 3)...).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "user123", "deposit", "success", "USD", 100, nil, nil, nil, 0, "2025-05-13T12:51:00Z"))
+			},
+			expectedTxs: []domainwallet.Transaction{
+				{
+					ID:                    "tx1",
+					InitiatorWalletUserId: "user123",
+					Type:                  "deposit",
+					Status:                "success",
+					Asset:                 "USD",
+					Amount:                100,
+					CreatedAt:             "2025-05-13T12:51:00Z",
+				},
+			},
+		},
+		{
+			name: "filtered page after cursor",
+			filter: domainwallet.TransactionFilter{
+				Asset:              "USD",
+				Type:               domainwallet.Transfer,
+				Status:             domainwallet.Success,
+				From:               from,
+				To:                 to,
+				MinAmount:          100,
+				MaxAmount:          1000,
+				CounterpartyUserID: "user456",
+			},
+			after: after,
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(transactionsAfterQuery).
+					WithArgs("wallet-1", "USD", "transfer", "success", from, to, 100, 1000, "user456", after.CreatedAt, "tx2", 3).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx3", "user123", "transfer", "success", "USD", 500, "user456", nil, nil, 0, "2025-05-13T12:40:00Z"))
+			},
+			expectedTxs: []domainwallet.Transaction{
+				{
+					ID:                    "tx3",
+					InitiatorWalletUserId: "user123",
+					Type:                  "transfer",
+					Status:                "success",
+					Asset:                 "USD",
+					Amount:                500,
+					RecipientWalletUserId: &recipientUserID,
+					CreatedAt:             "2025-05-13T12:40:00Z",
+				},
+			},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			txs, err := r.GetWalletTransactionsAfter(context.Background(), "user123", tt.filter, tt.after, 3)
+			assert.Equal(t, tt.expectedTxs, txs)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,540 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	holdCacheKey    = `hold-%s-%s`    // hold-userID-idempotencyKey
+	captureCacheKey = `capture-%s-%s` // capture-userID-idempotencyKey
+)
+
+const holdColumns = `id, wallet_id, asset, amount, captured_amount, status, transaction_id, expires_at, created_at`
+
+// lockHoldQuery holds a row-level lock on a hold of the wallet, always taken after the wallet row lock.
+const lockHoldQuery = `SELECT ` + holdColumns + ` FROM holds WHERE id = $1 AND wallet_id = $2 FOR UPDATE`
+
+// captureHoldQuery marks a hold captured into the transaction that moved its captured amount, or holds it for review.
+const captureHoldQuery = `
+	UPDATE holds SET status = $1, captured_amount = $2, transaction_id = $3, updated_at = NOW()
+	WHERE id = $4
+`
+
+// CreateHold does the following:
+// 1. Check from redis cache on key = hold-{userID}-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock the user wallet and check the idempotency key recorded in postgres
+// 3. Reserve amount of the available asset balance as held and insert the hold, rejecting frozen/closed wallet or insufficient available balance
+// 4. Record the response on the idempotency key in the same db transaction, cache it and return appriopriate errors
+// No money is moved, so rejected holds are not recorded as failed transactions.
+func (r *Repository) CreateHold(
+	ctx context.Context,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
+	amount uint64,
+	expiresAt time.Time,
+) (domainwallet.IdempotentResponse, error) {
+	cacheKey := fmt.Sprintf(holdCacheKey, userID, key.Key)
+	// Idempotent: already processed
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
+	}
+
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+	defer release()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Hold row-level lock on user wallet
+	var dbWallet userWallet
+	err = tx.GetContext(ctx, &dbWallet, lockWalletQuery, userID, asset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
+			"failed to hold row-level lock on wallet: %w, userID: %s",
+			err,
+			userID,
+		)
+	}
+
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupIdempotencyKey(ctx, tx, cacheKey, userID, domainwallet.HoldOperation, key); err != nil || found {
+		return resp, err
+	}
+
+	// Held funds are captured as a debit, so wallets that cannot be debited cannot hold funds either
+	if err := dbWallet.Status.CanDebit(); err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	if dbWallet.available() < amount {
+		return domainwallet.IdempotentResponse{}, domainwallet.ErrWalletInsufficientBalance
+	}
+
+	_, err = tx.ExecContext(ctx, holdBalanceQuery, amount, dbWallet.ID, asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to hold balance: %w", err)
+	}
+
+	var holdID string
+	insertHold := `
+		INSERT INTO holds (wallet_id, asset, amount, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id
+	`
+	err = tx.GetContext(ctx, &holdID, insertHold, dbWallet.ID, asset, amount, domainwallet.HoldActive, expiresAt)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert hold: %w", err)
+	}
+
+	// Record the response on the idempotency key and commit transaction
+	return r.commitIdempotent(ctx, tx, cacheKey, userID, domainwallet.HoldOperation, key, holdID, domainwallet.FeeBreakdown{}, nil)
+}
+
+// CaptureHold does the following:
+// 1. Check from redis cache on key = capture-{userID}-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock the user wallet (and recipient wallet in wallet id order), check the idempotency key recorded in postgres and lock the hold
+// 3. Price the withdrawal or transfer with its fee schedule, release the whole held amount, then withdraw amount of the hold's asset from the user wallet, or transfer it to recipientUser wallet when given, debiting the fee on top, and post the balanced ledger journal crediting the fee to the house fee account
+// 4. Record the withdrawal or transfer as failed with its reason on frozen/closed wallet, held and available balance not covering the amount and fee, exceeded limit or fraud block, leaving the hold active
+// 5. Captures the fraud rules flag keep their amount and fee held and are held for review instead, see captureForReview
+// 6. Record the response on the idempotency key in the same db transaction, cache it and return appriopriate errors
+// 7. Retry the db transaction with jittered backoff on deadlock or serialization failure
+func (r *Repository) CaptureHold(
+	ctx context.Context,
+	userID, holdID string,
+	key domainwallet.IdempotencyKey,
+	recipientUserID string,
+	amount uint64,
+	now time.Time,
+	verdict domainwallet.FraudVerdict,
+) (domainwallet.IdempotentResponse, error) {
+	cacheKey := fmt.Sprintf(captureCacheKey, userID, key.Key)
+	// Idempotent: already processed
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
+	}
+
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+	defer release()
+
+	// Deadlocks and serialization failures roll back the whole db transaction, so it is retried from the start
+	return r.withTxRetry(ctx, domainwallet.CaptureOperation, func() (domainwallet.IdempotentResponse, error) {
+		return r.captureHold(ctx, cacheKey, userID, holdID, key, recipientUserID, amount, now, verdict)
+	})
+}
+
+// captureHold runs a single attempt of the capture db transaction.
+func (r *Repository) captureHold(
+	ctx context.Context,
+	cacheKey string,
+	userID, holdID string,
+	key domainwallet.IdempotencyKey,
+	recipientUserID string,
+	amount uint64,
+	now time.Time,
+	verdict domainwallet.FraudVerdict,
+) (domainwallet.IdempotentResponse, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Hold row-level lock on user wallet, and on recipient user wallet of a transfer acquired in wallet id order
+	dbWallet, dbRecipientWallet, err := lockCaptureWallets(ctx, tx, userID, recipientUserID)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupIdempotencyKey(ctx, tx, cacheKey, userID, domainwallet.CaptureOperation, key); err != nil || found {
+		return resp, err
+	}
+
+	var hold domainwallet.Hold
+	err = tx.GetContext(ctx, &hold, lockHoldQuery, holdID, dbWallet.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("hold not found: %w", domainwallet.ErrHoldNotFound)
+		}
+
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to hold row-level lock on hold: %w, holdID: %s", err, holdID)
+	}
+
+	if err := hold.CanCapture(amount, now); err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	txnType := domainwallet.Withdraw
+	counterparty := domainwallet.SystemLedgerAccount(domainwallet.ExternalCashOut)
+	var recipientWalletID *string
+	if dbRecipientWallet != nil {
+		txnType = domainwallet.Transfer
+		counterparty = domainwallet.WalletAccount(dbRecipientWallet.ID)
+		recipientWalletID = &dbRecipientWallet.ID
+	}
+
+	attempt := attemptedTransaction{
+		Operation:         domainwallet.CaptureOperation,
+		InitiatorWalletID: dbWallet.ID,
+		RecipientWalletID: recipientWalletID,
+		Type:              txnType,
+		Asset:             hold.Asset,
+		Amount:            amount,
+	}
+
+	// Frozen wallets may still receive funds, closed wallets may not move funds at all
+	if err := dbWallet.Status.CanDebit(); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, err)
+	}
+
+	if dbRecipientWallet != nil {
+		if err := dbRecipientWallet.Status.CanCredit(); err != nil {
+			return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, err)
+		}
+	}
+
+	// Captures are withdrawals or transfers, charged the fee of their schedule on top of the captured amount
+	fee, err := quoteFee(ctx, tx, txnType, hold.Asset, amount)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	var balance domainwallet.Balance
+	if err := tx.GetContext(ctx, &balance, assetBalanceQuery, dbWallet.ID, hold.Asset); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+
+	// The whole hold is released, so the amount and its fee are covered by the held and available balance together
+	if hold.Amount+balance.Available() < fee.Total {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, domainwallet.ErrWalletInsufficientBalance)
+	}
+
+	// Captures are limited as withdrawals or transfers when the funds leave the wallet
+	if err := checkTransactionLimit(ctx, tx, dbWallet.ID, txnType, hold.Asset, amount); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, err)
+	}
+
+	// Captures the fraud rules block are recorded as failed leaving the hold active, the ones they flag are held for review instead
+	switch verdict.Decision {
+	case domainwallet.FraudBlock:
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, domainwallet.ErrTransactionBlocked)
+	case domainwallet.FraudReview:
+		return r.captureForReview(ctx, tx, cacheKey, userID, key, hold, attempt, fee, verdict.Rule)
+	}
+
+	journal, err := fee.Journal(domainwallet.WalletAccount(dbWallet.ID), counterparty)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+	}
+
+	// Release the whole hold and debit the captured amount and its fee, a partial capture releases the remainder
+	_, err = tx.ExecContext(ctx, releaseHeldQuery, hold.Amount, dbWallet.ID, hold.Asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to release held balance: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, debitBalanceQuery, fee.Total, dbWallet.ID, hold.Asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	if dbRecipientWallet != nil {
+		_, err = tx.ExecContext(ctx, creditBalanceQuery, dbRecipientWallet.ID, hold.Asset, amount)
+		if err != nil {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
+		}
+	}
+
+	// Insert transaction record
+	var transactionID string
+	insertTxn := `
+		INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id
+	`
+	err = tx.GetContext(
+		ctx,
+		&transactionID,
+		insertTxn,
+		dbWallet.ID,
+		recipientWalletID,
+		txnType,
+		domainwallet.Success,
+		hold.Asset,
+		amount,
+		fee.Fee,
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert transaction record: %w", err)
+	}
+
+	// Post balanced ledger entries for the transaction
+	if err := postJournal(ctx, tx, transactionID, journal); err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, captureHoldQuery, domainwallet.HoldCaptured, amount, transactionID, hold.ID)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to capture hold: %w", err)
+	}
+
+	// Record the response on the idempotency key and commit transaction
+	return r.commitIdempotent(ctx, tx, cacheKey, userID, domainwallet.CaptureOperation, key, transactionID, fee, nil)
+}
+
+// captureForReview does the following when a fraud rule flags the capture of a hold:
+// 1. Release the whole hold, then reserve the captured amount and its fee and insert the capture pending review, see insertPendingReview
+// 2. Mark the hold captured into the transaction pending review, so it can neither be captured again nor expire while ops review it
+// 3. Record the ErrTransactionPendingReview response on the idempotency key and commit the db transaction
+// The funds stay held until the review is decided, and the remainder of a partial capture is released as on capture.
+func (r *Repository) captureForReview(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	cacheKey, userID string,
+	key domainwallet.IdempotencyKey,
+	hold domainwallet.Hold,
+	txn attemptedTransaction,
+	fee domainwallet.FeeBreakdown,
+	rule string,
+) (domainwallet.IdempotentResponse, error) {
+	_, err := tx.ExecContext(ctx, releaseHeldQuery, hold.Amount, txn.InitiatorWalletID, hold.Asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to release held balance: %w", err)
+	}
+
+	transactionID, err := insertPendingReview(ctx, tx, txn, fee, rule)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, captureHoldQuery, domainwallet.HoldCaptured, txn.Amount, transactionID, hold.ID)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to capture hold: %w", err)
+	}
+
+	return r.commitIdempotent(
+		ctx,
+		tx,
+		cacheKey,
+		userID,
+		domainwallet.CaptureOperation,
+		key,
+		transactionID,
+		fee,
+		domainwallet.ErrTransactionPendingReview,
+	)
+}
+
+// lockCaptureWallets locks the wallet of the user capturing a hold, and of the recipient user for a capture into a transfer.
+// The recipient wallet is nil for a capture into a withdrawal.
+func lockCaptureWallets(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	userID, recipientUserID string,
+) (*userWallet, *userWallet, error) {
+	// The asset is only known once the hold is locked, balances are read from the hold instead
+	const anyAsset = ""
+
+	if recipientUserID == "" {
+		var dbWallet userWallet
+		err := tx.GetContext(ctx, &dbWallet, lockWalletQuery, userID, anyAsset)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+			}
+
+			return nil, nil, fmt.Errorf("failed to hold row-level lock on wallet: %w, userID: %s", err, userID)
+		}
+
+		return &dbWallet, nil, nil
+	}
+
+	var dbWallets []userWallet
+	err := tx.SelectContext(ctx, &dbWallets, lockWalletPairQuery, userID, recipientUserID, anyAsset)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to hold row-level lock on wallets: %w, userIDs: %s, %s",
+			err,
+			userID,
+			recipientUserID,
+		)
+	}
+
+	var dbWallet, dbRecipientWallet *userWallet
+	for i := range dbWallets {
+		switch dbWallets[i].UserID {
+		case userID:
+			dbWallet = &dbWallets[i]
+		case recipientUserID:
+			dbRecipientWallet = &dbWallets[i]
+		}
+	}
+
+	if dbWallet == nil || dbRecipientWallet == nil {
+		return nil, nil, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+	}
+
+	return dbWallet, dbRecipientWallet, nil
+}
+
+// ReleaseHold does the following:
+// 1. Lock the user wallet and then the hold, so the held balance is only mutated while holding the wallet's row lock
+// 2. Release the held amount back to the available balance and mark the hold released
+// Releasing an already released hold returns it as is, so retries are safe without an idempotency key.
+func (r *Repository) ReleaseHold(ctx context.Context, userID, holdID string) (domainwallet.Hold, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.Hold{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var walletID string
+	err = tx.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Hold{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return domainwallet.Hold{}, fmt.Errorf("failed to hold row-level lock on wallet: %w, userID: %s", err, userID)
+	}
+
+	var hold domainwallet.Hold
+	err = tx.GetContext(ctx, &hold, lockHoldQuery, holdID, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Hold{}, fmt.Errorf("hold not found: %w", domainwallet.ErrHoldNotFound)
+		}
+
+		return domainwallet.Hold{}, fmt.Errorf("failed to hold row-level lock on hold: %w, holdID: %s", err, holdID)
+	}
+
+	if err := hold.CanRelease(); err != nil {
+		return domainwallet.Hold{}, err
+	}
+
+	if hold.Status == domainwallet.HoldReleased {
+		return hold, nil
+	}
+
+	hold, err = releaseHold(ctx, tx, hold, domainwallet.HoldReleased)
+	if err != nil {
+		return domainwallet.Hold{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainwallet.Hold{}, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ReleaseExpiredHolds does the following:
+// 1. Find up to limit active holds expired at the given time, oldest expiry first
+// 2. Release each of them in its own db transaction, locking its wallet and then the hold
+// 3. Skip holds captured or released since they were found, and return how many holds expired
+func (r *Repository) ReleaseExpiredHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	const query = `
+		SELECT id, wallet_id FROM holds
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY expires_at
+		LIMIT $3
+	`
+
+	var expired []domainwallet.Hold
+	if err := r.db.SelectContext(ctx, &expired, query, domainwallet.HoldActive, now, limit); err != nil {
+		return 0, fmt.Errorf("failed to get expired holds: %w", err)
+	}
+
+	var released int
+	for _, hold := range expired {
+		ok, err := r.expireHold(ctx, hold.ID, hold.WalletID, now)
+		if err != nil {
+			return released, err
+		}
+
+		if ok {
+			released++
+		}
+	}
+
+	return released, nil
+}
+
+// expireHold releases a single expired hold, reporting false if it is no longer active.
+func (r *Repository) expireHold(ctx context.Context, holdID, walletID string, now time.Time) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockWalletByIDQuery, walletID); err != nil {
+		return false, fmt.Errorf("failed to hold row-level lock on wallet: %w, walletID: %s", err, walletID)
+	}
+
+	var hold domainwallet.Hold
+	err = tx.GetContext(ctx, &hold, lockHoldQuery, holdID, walletID)
+	if err != nil {
+		return false, fmt.Errorf("failed to hold row-level lock on hold: %w, holdID: %s", err, holdID)
+	}
+
+	// Captured or released meanwhile
+	if hold.Status != domainwallet.HoldActive || now.Before(hold.ExpiresAt) {
+		return false, nil
+	}
+
+	if _, err := releaseHold(ctx, tx, hold, domainwallet.HoldExpired); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return true, nil
+}
+
+// releaseHold releases the held amount of an active hold back to the available balance, setting the hold's final status.
+func releaseHold(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	hold domainwallet.Hold,
+	status domainwallet.HoldStatus,
+) (domainwallet.Hold, error) {
+	_, err := tx.ExecContext(ctx, releaseHeldQuery, hold.Amount, hold.WalletID, hold.Asset)
+	if err != nil {
+		return domainwallet.Hold{}, fmt.Errorf("failed to release held balance: %w", err)
+	}
+
+	query := `UPDATE holds SET status = $1, updated_at = NOW() WHERE id = $2 RETURNING ` + holdColumns
+
+	var released domainwallet.Hold
+	if err := tx.GetContext(ctx, &released, query, status, hold.ID); err != nil {
+		return domainwallet.Hold{}, fmt.Errorf("failed to release hold: %w", err)
+	}
+
+	return released, nil
+}
@@ -0,0 +1,844 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	redismock "github.com/go-redis/redismock/v9"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+)
+
+const (
+	holdBalanceQuery    = `UPDATE balances SET held = held \+ \$1 WHERE wallet_id = \$2 AND asset = \$3`
+	releaseHeldQuery    = `UPDATE balances SET held = held - \$1 WHERE wallet_id = \$2 AND asset = \$3`
+	insertHoldQuery     = `INSERT INTO holds \(wallet_id, asset, amount, status, expires_at, created_at, updated_at\)`
+	lockHoldQuery       = `SELECT id, wallet_id, asset, amount, captured_amount, status, transaction_id, expires_at, created_at FROM holds WHERE id = \$1 AND wallet_id = \$2 FOR UPDATE`
+	captureHoldQuery    = `UPDATE holds SET status = \$1, captured_amount = \$2, transaction_id = \$3, updated_at = NOW\(\) WHERE id = \$4`
+	releaseHoldQuery    = `UPDATE holds SET status = \$1, updated_at = NOW\(\) WHERE id = \$2 RETURNING`
+	expiredHoldsQuery   = `SELECT id, wallet_id FROM holds WHERE status = \$1 AND expires_at <= \$2 ORDER BY expires_at LIMIT \$3`
+	lockWalletByUserID  = `SELECT id FROM wallets WHERE user_id = \$1 FOR UPDATE`
+	lockWalletByIDQuery = `SELECT id FROM wallets WHERE id = \$1 FOR UPDATE`
+	assetBalanceQuery   = `SELECT asset, balance, held FROM balances WHERE wallet_id = \$1 AND asset = \$2`
+)
+
+var assetBalanceColumns = []string{"asset", "balance", "held"}
+
+var holdColumns = []string{"id", "wallet_id", "asset", "amount", "captured_amount", "status", "transaction_id", "expires_at", "created_at"}
+
+func TestCreateHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, redisMock := redismock.NewClientMock()
+	logger := slog.Default()
+	repo := wallet.New(sqlxDB, redisClient, logger)
+
+	expiresAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		userID           string
+		idempotencyKey   string
+		amount           uint64
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
+	}{
+		{
+			name:           "already processed idempotent request",
+			userID:         "user1",
+			idempotencyKey: "idem1",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user1-idem1").SetVal(cachedRecord("hash-idem1", okResponse("hold-already")))
+			},
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("hold-already"),
+		},
+		{
+			name:           "successful hold",
+			userID:         "user2",
+			idempotencyKey: "idem2",
+			amount:         400,
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user2-idem2").RedisNil()
+				expectReserveInFlight(redisMock, "hold-user2-idem2").SetVal(true)
+				redisMock.ExpectSet("hold-user2-idem2", cachedRecord("hash-idem2", okResponse("hold2")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "hold-user2-idem2")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user2", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet2", 1000, 600, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user2", "idem2", domainwallet.HoldOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(holdBalanceQuery).
+					WithArgs(400, "wallet2", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertHoldQuery).
+					WithArgs("wallet2", "USD", 400, domainwallet.HoldActive, expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("hold2"))
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user2", "idem2", domainwallet.HoldOperation, "hash-idem2", nil, "hold2", 200, []byte("hold2")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("hold2"),
+		},
+		{
+			name:           "insufficient available balance",
+			userID:         "user3",
+			idempotencyKey: "idem3",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user3-idem3").RedisNil()
+				expectReserveInFlight(redisMock, "hold-user3-idem3").SetVal(true)
+				expectReleaseInFlight(redisMock, "hold-user3-idem3")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user3", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet3", 1000, 600, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user3", "idem3", domainwallet.HoldOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrWalletInsufficientBalance,
+		},
+		{
+			name:           "frozen wallet",
+			userID:         "user4",
+			idempotencyKey: "idem4",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user4-idem4").RedisNil()
+				expectReserveInFlight(redisMock, "hold-user4-idem4").SetVal(true)
+				expectReleaseInFlight(redisMock, "hold-user4-idem4")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user4", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet4", 1000, 0, "frozen"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user4", "idem4", domainwallet.HoldOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrWalletFrozen,
+		},
+		{
+			name:           "wallet not found",
+			userID:         "user5",
+			idempotencyKey: "idem5",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user5-idem5").RedisNil()
+				expectReserveInFlight(redisMock, "hold-user5-idem5").SetVal(true)
+				expectReleaseInFlight(redisMock, "hold-user5-idem5")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user5", "USD").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareRedis()
+			tt.prepareSQL()
+
+			resp, err := repo.CreateHold(
+				context.Background(),
+				tt.userID,
+				testIdempotencyKey(tt.idempotencyKey),
+				"USD",
+				tt.amount,
+				expiresAt,
+			)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, redisMock := redismock.NewClientMock()
+	logger := slog.Default()
+	repo := wallet.New(sqlxDB, redisClient, logger)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	createdAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		userID           string
+		recipientUserID  string
+		idempotencyKey   string
+		amount           uint64
+		verdict          domainwallet.FraudVerdict
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
+	}{
+		{
+			name:           "partial capture into withdrawal",
+			userID:         "user1",
+			idempotencyKey: "idem1",
+			amount:         300,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user1-idem1").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user1-idem1").SetVal(true)
+				redisMock.ExpectSet("capture-user1-idem1", cachedRecord("hash-idem1", okResponse("tx1")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user1-idem1")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user1", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet1", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user1", "idem1", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet1", "USD").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("USD", 500, 500))
+				expectNoTransactionLimit(mock, "wallet1", "withdraw", "USD")
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(300, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
+					WithArgs("wallet1", nil, "withdraw", "success", "USD", 300, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx1"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal1"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal1", "wallet1", nil, "USD", -300, "journal1", nil, "external_cash_out", "USD", 300).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				mock.ExpectExec(captureHoldQuery).
+					WithArgs(domainwallet.HoldCaptured, 300, "tx1", "hold1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx1")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user1", "idem1", domainwallet.CaptureOperation, "hash-idem1", "tx1", nil, 200, []byte("tx1")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("tx1"),
+		},
+		{
+			name:            "full capture into transfer",
+			userID:          "user2",
+			recipientUserID: "user3",
+			idempotencyKey:  "idem2",
+			amount:          500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user2-idem2").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user2-idem2").SetVal(true)
+				redisMock.ExpectSet("capture-user2-idem2", cachedRecord("hash-idem2", okResponse("tx2")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user2-idem2")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user2", "user3", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "held", "status"}).
+						AddRow("wallet2", "user2", 0, 0, "active").
+						AddRow("wallet3", "user3", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user2", "idem2", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet2").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet2", "BTC", 500, 0, "active", nil, expiresAt, createdAt))
+				expectNoFeeSchedule(mock, "transfer", "BTC")
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet2", "BTC").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("BTC", 500, 500))
+				expectNoTransactionLimit(mock, "wallet2", "transfer", "BTC")
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet2", "BTC").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(500, "wallet2", "BTC").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet3", "BTC", 500).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
+					WithArgs("wallet2", "wallet3", "transfer", "success", "BTC", 500, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx2"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx2").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal2"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal2", "wallet2", nil, "BTC", -500, "journal2", "wallet3", nil, "BTC", 500).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				mock.ExpectExec(captureHoldQuery).
+					WithArgs(domainwallet.HoldCaptured, 500, "tx2", "hold1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx2")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user2", "idem2", domainwallet.CaptureOperation, "hash-idem2", "tx2", nil, 200, []byte("tx2")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("tx2"),
+		},
+		{
+			name:           "partial capture into withdrawal charged a fee",
+			userID:         "user8",
+			idempotencyKey: "idem8",
+			amount:         300,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user8-idem8").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user8-idem8").SetVal(true)
+				redisMock.ExpectSet("capture-user8-idem8", cachedRecord("hash-idem8", okResponse("tx8 fee 10 of 310")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user8-idem8")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user8", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet8", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user8", "idem8", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet8").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet8", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet8", "USD").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("USD", 500, 500))
+				expectNoTransactionLimit(mock, "wallet8", "withdraw", "USD")
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet8", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(310, "wallet8", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
+					WithArgs("wallet8", nil, "withdraw", "success", "USD", 300, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx8"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx8").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal8"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal8", "wallet8", nil, "USD", -300, "journal8", nil, "external_cash_out", "USD", 300,
+						"journal8", "wallet8", nil, "USD", -10, "journal8", nil, "fees", "USD", 10).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+				mock.ExpectExec(captureHoldQuery).
+					WithArgs(domainwallet.HoldCaptured, 300, "tx8", "hold1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx8")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user8", "idem8", domainwallet.CaptureOperation, "hash-idem8", "tx8", nil, 200, []byte("tx8 fee 10 of 310")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("tx8 fee 10 of 310"),
+		},
+		{
+			name:           "blocked by a fraud rule",
+			userID:         "user10",
+			idempotencyKey: "idem10",
+			amount:         300,
+			verdict:        domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "withdrawal_velocity"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user10-idem10").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user10-idem10").SetVal(true)
+				redisMock.ExpectSet("capture-user10-idem10", cachedRecord("hash-idem10", failedResponse("tx10", domainwallet.FailureFraudBlocked)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user10-idem10")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user10", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet10", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user10", "idem10", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet10").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet10", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet10", "USD").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("USD", 500, 500))
+				expectNoTransactionLimit(mock, "wallet10", "withdraw", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet10", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureFraudBlocked, "USD", 300).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx10"))
+				expectTransactionEvents(mock, "tx10")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user10", "idem10", domainwallet.CaptureOperation, "hash-idem10", "tx10", nil, 422, failedResponse("tx10", domainwallet.FailureFraudBlocked).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx10", domainwallet.FailureFraudBlocked),
+		},
+		{
+			name:           "held for review by a fraud rule",
+			userID:         "user11",
+			idempotencyKey: "idem11",
+			amount:         300,
+			verdict:        domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "new_wallet_drain"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user11-idem11").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user11-idem11").SetVal(true)
+				redisMock.ExpectSet("capture-user11-idem11", cachedRecord("hash-idem11", pendingReviewResponse("tx11")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user11-idem11")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user11", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet11", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user11", "idem11", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet11").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet11", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet11", "USD").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("USD", 500, 500))
+				expectNoTransactionLimit(mock, "wallet11", "withdraw", "USD")
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet11", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(holdBalanceQuery).
+					WithArgs(310, "wallet11", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertPendingReviewTxn).
+					WithArgs("wallet11", nil, domainwallet.Withdraw, domainwallet.PendingReview, "USD", 300, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx11"))
+				mock.ExpectExec(insertTransactionReview).
+					WithArgs("tx11", "new_wallet_drain").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(captureHoldQuery).
+					WithArgs(domainwallet.HoldCaptured, 300, "tx11", "hold1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx11")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user11", "idem11", domainwallet.CaptureOperation, "hash-idem11", "tx11", nil, 202, []byte("tx11")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: pendingReviewResponse("tx11"),
+		},
+		{
+			name:           "held and available balance not covering the fee",
+			userID:         "user9",
+			idempotencyKey: "idem9",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user9-idem9").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user9-idem9").SetVal(true)
+				redisMock.ExpectSet("capture-user9-idem9", cachedRecord("hash-idem9", failedResponse("tx9", domainwallet.FailureInsufficientBalance)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user9-idem9")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user9", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet9", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user9", "idem9", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet9").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet9", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(assetBalanceQuery).
+					WithArgs("wallet9", "USD").
+					WillReturnRows(sqlmock.NewRows(assetBalanceColumns).AddRow("USD", 505, 500))
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet9", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureInsufficientBalance, "USD", 500).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx9"))
+				expectTransactionEvents(mock, "tx9")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user9", "idem9", domainwallet.CaptureOperation, "hash-idem9", "tx9", nil, 422, failedResponse("tx9", domainwallet.FailureInsufficientBalance).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx9", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:            "capture into transfer to closed wallet",
+			userID:          "user4",
+			recipientUserID: "user5",
+			idempotencyKey:  "idem4",
+			amount:          500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user4-idem4").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user4-idem4").SetVal(true)
+				redisMock.ExpectSet("capture-user4-idem4", cachedRecord("hash-idem4", failedResponse("tx4", domainwallet.FailureWalletClosed)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "capture-user4-idem4")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user4", "user5", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "held", "status"}).
+						AddRow("wallet4", "user4", 0, 0, "active").
+						AddRow("wallet5", "user5", 0, 0, "closed"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user4", "idem4", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet4").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet4", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet4", "wallet5", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureWalletClosed, "USD", 500).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx4"))
+				expectTransactionEvents(mock, "tx4")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user4", "idem4", domainwallet.CaptureOperation, "hash-idem4", "tx4", nil, 422, failedResponse("tx4", domainwallet.FailureWalletClosed).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx4", domainwallet.FailureWalletClosed),
+		},
+		{
+			name:           "expired hold",
+			userID:         "user6",
+			idempotencyKey: "idem6",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user6-idem6").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user6-idem6").SetVal(true)
+				expectReleaseInFlight(redisMock, "capture-user6-idem6")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user6", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet6", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user6", "idem6", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet6").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet6", "USD", 500, 0, "active", nil, now, createdAt))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrHoldExpired,
+		},
+		{
+			name:           "hold of another wallet",
+			userID:         "user7",
+			idempotencyKey: "idem7",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user7-idem7").RedisNil()
+				expectReserveInFlight(redisMock, "capture-user7-idem7").SetVal(true)
+				expectReleaseInFlight(redisMock, "capture-user7-idem7")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user7", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet7", 0, 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user7", "idem7", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet7").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrHoldNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareRedis()
+			tt.prepareSQL()
+
+			resp, err := repo.CaptureHold(
+				context.Background(),
+				tt.userID,
+				"hold1",
+				testIdempotencyKey(tt.idempotencyKey),
+				tt.recipientUserID,
+				tt.amount,
+				now,
+				tt.verdict,
+			)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReleaseHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, _ := redismock.NewClientMock()
+	logger := slog.Default()
+	repo := wallet.New(sqlxDB, redisClient, logger)
+
+	expiresAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		prepareSQL    func()
+		expectedHold  domainwallet.Hold
+		expectedError error
+	}{
+		{
+			name: "release active hold",
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletByUserID).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet1"))
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "active", nil, expiresAt, createdAt))
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(releaseHoldQuery).
+					WithArgs(domainwallet.HoldReleased, "hold1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "released", nil, expiresAt, createdAt))
+				mock.ExpectCommit()
+			},
+			expectedHold: domainwallet.Hold{
+				ID:        "hold1",
+				WalletID:  "wallet1",
+				Asset:     "USD",
+				Amount:    500,
+				Status:    domainwallet.HoldReleased,
+				ExpiresAt: expiresAt,
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "already released hold",
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletByUserID).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet1"))
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "released", nil, expiresAt, createdAt))
+				mock.ExpectRollback()
+			},
+			expectedHold: domainwallet.Hold{
+				ID:        "hold1",
+				WalletID:  "wallet1",
+				Asset:     "USD",
+				Amount:    500,
+				Status:    domainwallet.HoldReleased,
+				ExpiresAt: expiresAt,
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "captured hold",
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletByUserID).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet1"))
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 500, "captured", "tx1", expiresAt, createdAt))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrHoldNotActive,
+		},
+		{
+			name: "wallet not found",
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletByUserID).
+					WithArgs("user1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			hold, err := repo.ReleaseHold(context.Background(), "user1", "hold1")
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedHold, hold)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, _ := redismock.NewClientMock()
+	logger := slog.Default()
+	repo := wallet.New(sqlxDB, redisClient, logger)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	createdAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		prepareSQL       func()
+		expectedReleased int
+		expectedError    error
+	}{
+		{
+			name: "expired holds released, captured meanwhile skipped",
+			prepareSQL: func() {
+				mock.ExpectQuery(expiredHoldsQuery).
+					WithArgs(domainwallet.HoldActive, now, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id"}).
+						AddRow("hold1", "wallet1").
+						AddRow("hold2", "wallet2"))
+
+				mock.ExpectBegin()
+				mock.ExpectExec(lockWalletByIDQuery).
+					WithArgs("wallet1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold1", "wallet1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "active", nil, now, createdAt))
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(releaseHoldQuery).
+					WithArgs(domainwallet.HoldExpired, "hold1").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold1", "wallet1", "USD", 500, 0, "expired", nil, now, createdAt))
+				mock.ExpectCommit()
+
+				mock.ExpectBegin()
+				mock.ExpectExec(lockWalletByIDQuery).
+					WithArgs("wallet2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(lockHoldQuery).
+					WithArgs("hold2", "wallet2").
+					WillReturnRows(sqlmock.NewRows(holdColumns).
+						AddRow("hold2", "wallet2", "USD", 500, 500, "captured", "tx2", now, createdAt))
+				mock.ExpectRollback()
+			},
+			expectedReleased: 1,
+		},
+		{
+			name: "error getting expired holds",
+			prepareSQL: func() {
+				mock.ExpectQuery(expiredHoldsQuery).
+					WithArgs(domainwallet.HoldActive, now, 100).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			released, err := repo.ReleaseExpiredHolds(context.Background(), now, 100)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedReleased, released)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
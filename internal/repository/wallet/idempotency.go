@@ -0,0 +1,321 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	inFlightCacheKey = `%s-inflight` // {operation cache key}-inflight
+	// inFlightLease bounds how long a crashed request keeps its idempotency key reserved.
+	inFlightLease = 30 * time.Second
+)
+
+// releaseInFlightScript deletes the reservation only if it is still owned by the releasing request,
+// a request outliving its lease must not release the reservation of the next one.
+const releaseInFlightScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// userCacheKeys are the redis cache key formats of the operations whose idempotency keys are scoped to the user.
+var userCacheKeys = map[domainwallet.IdempotentOperation]string{
+	domainwallet.Deposit.Operation():  depositCacheKey,
+	domainwallet.Withdraw.Operation(): withdrawCacheKey,
+	domainwallet.Transfer.Operation(): transferCacheKey,
+	domainwallet.HoldOperation:        holdCacheKey,
+	domainwallet.CaptureOperation:     captureCacheKey,
+}
+
+// legacyFailedCacheSeparator separates the transaction ID from the failure reason in idempotency cache values
+// written before responses were recorded in the idempotency_keys table.
+const legacyFailedCacheSeparator = ":"
+
+// idempotencyRecord is an idempotency_keys row, also cached in redis as JSON on the operation's cache key.
+type idempotencyRecord struct {
+	RequestHash string `db:"request_hash" json:"request_hash"`
+	domainwallet.IdempotentResponse
+}
+
+// matches checks the request reusing the idempotency key is the one it was recorded for.
+func (rec idempotencyRecord) matches(key domainwallet.IdempotencyKey) (domainwallet.IdempotentResponse, error) {
+	if rec.RequestHash != key.RequestHash {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
+			"idempotency key %s: %w",
+			key.Key,
+			domainwallet.ErrIdempotencyKeyReused,
+		)
+	}
+
+	return rec.IdempotentResponse, nil
+}
+
+// cachedResponse does the following:
+// 1. Get the idempotency record cached in redis on cacheKey, redis errors are logged and treated as a miss since postgres is the source of truth
+// 2. If found, return the recorded response unless the key was recorded for a different request
+// 3. Values cached before responses were recorded (transactionID or transactionID:failureReason) are rendered with the key
+func (r *Repository) cachedResponse(
+	ctx context.Context,
+	cacheKey string,
+	key domainwallet.IdempotencyKey,
+) (domainwallet.IdempotentResponse, bool, error) {
+	value, err := r.cache.Get(ctx, cacheKey).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.logger.Error(
+				"failed to get cached idempotency key",
+				slog.String("cacheKey", cacheKey),
+				slog.Any("error", err),
+			)
+		}
+
+		return domainwallet.IdempotentResponse{}, false, nil
+	}
+
+	var rec idempotencyRecord
+	if err := json.Unmarshal([]byte(value), &rec); err == nil {
+		resp, err := rec.matches(key)
+		return resp, true, err
+	}
+
+	transactionID, reason, failed := strings.Cut(value, legacyFailedCacheSeparator)
+	var outcome error
+	if failed {
+		outcome = &domainwallet.FailedTransactionError{
+			TransactionID: transactionID,
+			Reason:        domainwallet.FailureReason(reason),
+		}
+	}
+
+	// Legacy records predate fees
+	resp, err := key.Render(transactionID, domainwallet.FeeBreakdown{}, outcome)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, false, fmt.Errorf("failed to render cached response: %w", err)
+	}
+
+	return resp, true, nil
+}
+
+// reserveInFlight does the following:
+// 1. Reserve the idempotency key with SET NX for inFlightLease, a concurrent duplicate finding it reserved is rejected with ErrIdempotencyKeyInProgress
+// 2. Return the func releasing the reservation, to be called once the response is recorded and cached
+// Redis errors are logged and the request proceeds unreserved, duplicates are then serialized by the wallet row lock and the idempotency_keys primary key.
+func (r *Repository) reserveInFlight(ctx context.Context, cacheKey string) (func(), error) {
+	key := fmt.Sprintf(inFlightCacheKey, cacheKey)
+	token := uuid.NewString()
+
+	reserved, err := r.cache.SetNX(ctx, key, token, inFlightLease).Result()
+	if err != nil {
+		r.logger.Error(
+			"failed to reserve in-flight idempotency key",
+			slog.String("cacheKey", cacheKey),
+			slog.Any("error", err),
+		)
+
+		return func() {}, nil
+	}
+
+	if !reserved {
+		return nil, fmt.Errorf("idempotency key %s: %w", cacheKey, domainwallet.ErrIdempotencyKeyInProgress)
+	}
+
+	return func() {
+		// release even if the request was cancelled, the lease would otherwise block retries until it expires
+		err := r.cache.Eval(context.WithoutCancel(ctx), releaseInFlightScript, []string{key}, token).Err()
+		if err != nil {
+			r.logger.Error(
+				"failed to release in-flight idempotency key",
+				slog.String("cacheKey", cacheKey),
+				slog.Any("error", err),
+			)
+		}
+	}, nil
+}
+
+// GetIdempotentResponse does the following:
+// 1. Check from redis cache on the operation's key, if exists we just return the recorded response
+// 2. If not, get the idempotency key recorded for the user in postgres without locking the wallet, and cache it if found
+// It lets retries replay their recorded response before pre-checks whose outcome may have changed since, such as
+// the KYC tier and fraud rules. The operation itself still checks the key under the wallet row lock.
+func (r *Repository) GetIdempotentResponse(
+	ctx context.Context,
+	userID string,
+	operation domainwallet.IdempotentOperation,
+	key domainwallet.IdempotencyKey,
+) (domainwallet.IdempotentResponse, bool, error) {
+	cacheKeyFormat, ok := userCacheKeys[operation]
+	if !ok {
+		return domainwallet.IdempotentResponse{}, false, fmt.Errorf("no user idempotency keys for operation %q", operation)
+	}
+
+	cacheKey := fmt.Sprintf(cacheKeyFormat, userID, key.Key)
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, found, err
+	}
+
+	return r.lookupIdempotencyKey(ctx, r.db, cacheKey, userID, operation, key)
+}
+
+// lookupIdempotencyKey does the following:
+// 1. Get the idempotency key recorded for the user and operation in postgres, called while holding the wallet row lock so a concurrent retry waits for the first request to commit, or unlocked by GetIdempotentResponse
+// 2. If found, cache it in redis and return the recorded response unless the key was recorded for a different request
+// Keys of users are unique per operation, like their redis cache keys.
+func (r *Repository) lookupIdempotencyKey(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	cacheKey, userID string,
+	operation domainwallet.IdempotentOperation,
+	key domainwallet.IdempotencyKey,
+) (domainwallet.IdempotentResponse, bool, error) {
+	query := `
+		SELECT request_hash, response_status, response_body FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND scope = 'user' AND operation = $3
+	`
+	return r.lookupIdempotencyRecord(ctx, q, cacheKey, key, query, userID, key.Key, operation)
+}
+
+// lookupAdminIdempotencyKey is lookupIdempotencyKey for keys of admin operations, reversals, which are unique across admins
+// rather than per user. The scope is derived from the operation when the key is recorded.
+func (r *Repository) lookupAdminIdempotencyKey(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	cacheKey string,
+	key domainwallet.IdempotencyKey,
+) (domainwallet.IdempotentResponse, bool, error) {
+	query := `
+		SELECT request_hash, response_status, response_body FROM idempotency_keys
+		WHERE idempotency_key = $1 AND scope = 'admin'
+	`
+	return r.lookupIdempotencyRecord(ctx, tx, cacheKey, key, query, key.Key)
+}
+
+// lookupIdempotencyRecord gets the idempotency record selected by query and args, and returns its response.
+func (r *Repository) lookupIdempotencyRecord(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	cacheKey string,
+	key domainwallet.IdempotencyKey,
+	query string,
+	args ...any,
+) (domainwallet.IdempotentResponse, bool, error) {
+	var rec idempotencyRecord
+	err := sqlx.GetContext(ctx, q, &rec, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.IdempotentResponse{}, false, nil
+		}
+
+		return domainwallet.IdempotentResponse{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	resp, err := rec.matches(key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, true, err
+	}
+
+	r.cacheIdempotencyRecord(ctx, cacheKey, rec)
+
+	return resp, true, nil
+}
+
+// commitIdempotent does the following:
+// 1. Record a TransactionCreatedEvent per wallet of the transaction in the outbox, holds record no transaction
+// 2. Render the response for the outcome of the operation and record it with the request hash in idempotency_keys
+// 3. Commit the db transaction, so the idempotency key and events are durable exactly when the money movement or hold is
+// 4. Cache the record in redis and return the response
+// A key recorded concurrently by another request fails the insert with ErrIdempotencyKeyReused, rolling the operation back.
+// id is the ID of the transaction the operation recorded, or of the hold for HoldOperation,
+// and fee what the transaction was charged, or refunded for ReverseOperation.
+func (r *Repository) commitIdempotent(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	cacheKey, userID string,
+	operation domainwallet.IdempotentOperation,
+	key domainwallet.IdempotencyKey,
+	id string,
+	fee domainwallet.FeeBreakdown,
+	outcome error,
+) (domainwallet.IdempotentResponse, error) {
+	resp, err := key.Render(id, fee, outcome)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to render response: %w", err)
+	}
+
+	transactionID, holdID := &id, (*string)(nil)
+	if operation == domainwallet.HoldOperation {
+		transactionID, holdID = nil, &id
+	}
+
+	if transactionID != nil {
+		if err := recordTransactionEvents(ctx, tx, domainwallet.TransactionCreatedEvent, *transactionID); err != nil {
+			return domainwallet.IdempotentResponse{}, err
+		}
+	}
+
+	insertKey := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, operation, request_hash, transaction_id, hold_id, response_status, response_body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+	`
+	_, err = tx.ExecContext(
+		ctx,
+		insertKey,
+		userID,
+		key.Key,
+		operation,
+		key.RequestHash,
+		transactionID,
+		holdID,
+		resp.StatusCode,
+		resp.Body,
+	)
+	if err != nil {
+		// Recorded meanwhile by a request the wallet row locks did not serialize with this one, eg a reversal of another
+		// transaction with the same admin key
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf(
+				"idempotency key %s: %w",
+				key.Key,
+				domainwallet.ErrIdempotencyKeyReused,
+			)
+		}
+
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert idempotency key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	r.cacheIdempotencyRecord(ctx, cacheKey, idempotencyRecord{
+		RequestHash:        key.RequestHash,
+		IdempotentResponse: resp,
+	})
+
+	return resp, nil
+}
+
+// cacheIdempotencyRecord caches the record for ttl, failures are only logged as postgres is the source of truth.
+func (r *Repository) cacheIdempotencyRecord(ctx context.Context, cacheKey string, rec idempotencyRecord) {
+	value, err := json.Marshal(rec)
+	if err == nil {
+		err = r.cache.Set(ctx, cacheKey, string(value), ttl).Err()
+	}
+
+	if err != nil {
+		r.logger.Error(
+			"failed to cache idempotency key",
+			slog.String("cacheKey", cacheKey),
+			slog.Any("error", err),
+		)
+	}
+}
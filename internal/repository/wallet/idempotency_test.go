@@ -0,0 +1,211 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	redismock "github.com/go-redis/redismock/v9"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const releaseInFlightScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// testIdempotencyKey renders successful outcomes as the transaction ID, transactions held for review as accepted
+// and failed outcomes as the error.
+func testIdempotencyKey(key string) domainwallet.IdempotencyKey {
+	return domainwallet.IdempotencyKey{
+		Key:         key,
+		RequestHash: "hash-" + key,
+		Render: func(transactionID string, fee domainwallet.FeeBreakdown, outcome error) (domainwallet.IdempotentResponse, error) {
+			if errors.Is(outcome, domainwallet.ErrTransactionPendingReview) {
+				return pendingReviewResponse(transactionID), nil
+			}
+
+			if outcome != nil {
+				return domainwallet.IdempotentResponse{
+					StatusCode: http.StatusUnprocessableEntity,
+					Body:       []byte(outcome.Error()),
+				}, nil
+			}
+
+			if fee.Fee > 0 {
+				transactionID = fmt.Sprintf("%s fee %d of %d", transactionID, fee.Fee, fee.Total)
+			}
+
+			return domainwallet.IdempotentResponse{
+				StatusCode: http.StatusOK,
+				Body:       []byte(transactionID),
+			}, nil
+		},
+	}
+}
+
+func okResponse(transactionID string) domainwallet.IdempotentResponse {
+	return domainwallet.IdempotentResponse{StatusCode: http.StatusOK, Body: []byte(transactionID)}
+}
+
+func pendingReviewResponse(transactionID string) domainwallet.IdempotentResponse {
+	return domainwallet.IdempotentResponse{StatusCode: http.StatusAccepted, Body: []byte(transactionID)}
+}
+
+func failedResponse(transactionID string, reason domainwallet.FailureReason) domainwallet.IdempotentResponse {
+	return domainwallet.IdempotentResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Body:       []byte(fmt.Sprintf("transaction %s failed: %s", transactionID, reason.Err())),
+	}
+}
+
+// cachedRecord is the redis value of the idempotency record of a response.
+func cachedRecord(requestHash string, resp domainwallet.IdempotentResponse) string {
+	value, _ := json.Marshal(struct {
+		RequestHash string `json:"request_hash"`
+		domainwallet.IdempotentResponse
+	}{
+		RequestHash:        requestHash,
+		IdempotentResponse: resp,
+	})
+
+	return string(value)
+}
+
+// expectReserveInFlight expects the in-flight reservation of the operation cache key with a random lease token.
+func expectReserveInFlight(redisMock redismock.ClientMock, cacheKey string) *redismock.ExpectedBool {
+	return redisMock.Regexp().ExpectSetNX(cacheKey+"-inflight", `.+`, 30*time.Second)
+}
+
+// expectReleaseInFlight expects the in-flight reservation of the operation cache key to be released.
+func expectReleaseInFlight(redisMock redismock.ClientMock, cacheKey string) {
+	redisMock.Regexp().
+		ExpectEval(regexp.QuoteMeta(releaseInFlightScript), []string{cacheKey + "-inflight"}, `.+`).
+		SetVal(int64(1))
+}
+
+func TestGetIdempotentResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, redisMock := redismock.NewClientMock()
+	repo := wallet.New(sqlxDB, redisClient, slog.Default())
+
+	tests := []struct {
+		name             string
+		operation        domainwallet.IdempotentOperation
+		idempotencyKey   string
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedResponse domainwallet.IdempotentResponse
+		expectedFound    bool
+		expectedError    error
+	}{
+		{
+			name:           "cached",
+			operation:      domainwallet.Withdraw.Operation(),
+			idempotencyKey: "idem1",
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user1-idem1").SetVal(cachedRecord("hash-idem1", pendingReviewResponse("tx1")))
+			},
+			prepareSQL:       func() {},
+			expectedResponse: pendingReviewResponse("tx1"),
+			expectedFound:    true,
+		},
+		{
+			name:           "recorded in postgres and cached",
+			operation:      domainwallet.Transfer.Operation(),
+			idempotencyKey: "idem2",
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user1-idem2").RedisNil()
+				redisMock.ExpectSet("transfer-user1-idem2", cachedRecord("hash-idem2", okResponse("tx2")), time.Hour*24).SetVal("OK")
+			},
+			prepareSQL: func() {
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user1", "idem2", domainwallet.Transfer.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("hash-idem2", 200, []byte("tx2")))
+			},
+			expectedResponse: okResponse("tx2"),
+			expectedFound:    true,
+		},
+		{
+			name:           "not recorded",
+			operation:      domainwallet.CaptureOperation,
+			idempotencyKey: "idem3",
+			prepareRedis: func() {
+				redisMock.ExpectGet("capture-user1-idem3").RedisNil()
+			},
+			prepareSQL: func() {
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user1", "idem3", domainwallet.CaptureOperation).
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name:           "recorded for a different request",
+			operation:      domainwallet.Withdraw.Operation(),
+			idempotencyKey: "idem4",
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user1-idem4").SetVal(cachedRecord("hash-other", okResponse("tx4")))
+			},
+			prepareSQL:    func() {},
+			expectedFound: true,
+			expectedError: domainwallet.ErrIdempotencyKeyReused,
+		},
+		{
+			name:           "postgres error",
+			operation:      domainwallet.HoldOperation,
+			idempotencyKey: "idem5",
+			prepareRedis: func() {
+				redisMock.ExpectGet("hold-user1-idem5").RedisNil()
+			},
+			prepareSQL: func() {
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user1", "idem5", domainwallet.HoldOperation).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to get idempotency key: db error"),
+		},
+		{
+			name:           "operation not scoped to the user",
+			operation:      domainwallet.ReverseOperation,
+			idempotencyKey: "idem6",
+			prepareRedis:   func() {},
+			prepareSQL:     func() {},
+			expectedError:  errors.New(`no user idempotency keys for operation "reverse"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareRedis()
+			tt.prepareSQL()
+
+			resp, found, err := repo.GetIdempotentResponse(context.Background(), "user1", tt.operation, testIdempotencyKey(tt.idempotencyKey))
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+			assert.Equal(t, tt.expectedFound, found)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// GetWalletKYCTier returns the KYC tier of the user's wallet.
+func (r *Repository) GetWalletKYCTier(ctx context.Context, userID string) (domainwallet.KYCTier, error) {
+	var tier domainwallet.KYCTier
+	err := r.db.GetContext(ctx, &tier, `SELECT kyc_tier FROM wallets WHERE user_id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return "", fmt.Errorf("failed to get wallet kyc tier: %w", err)
+	}
+
+	return tier, nil
+}
+
+// SetWalletKYCTier sets the KYC tier of the user's wallet and returns the updated wallet.
+func (r *Repository) SetWalletKYCTier(
+	ctx context.Context,
+	userID string,
+	tier domainwallet.KYCTier,
+) (domainwallet.Wallet, error) {
+	update := `
+		UPDATE wallets SET kyc_tier = $1 WHERE user_id = $2
+		RETURNING id, user_id, status, kyc_tier, created_at
+	`
+
+	var dst domainwallet.Wallet
+	err := r.db.GetContext(ctx, &dst, update, tier, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Wallet{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return domainwallet.Wallet{}, fmt.Errorf("failed to update wallet kyc tier: %w", err)
+	}
+
+	dst.Balances, err = getBalances(ctx, r.db, dst.ID)
+	if err != nil {
+		return domainwallet.Wallet{}, err
+	}
+
+	return dst, nil
+}
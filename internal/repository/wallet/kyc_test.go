@@ -0,0 +1,145 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWalletKYCTier(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	tierQuery := regexp.QuoteMeta(`SELECT kyc_tier FROM wallets WHERE user_id = $1`)
+
+	tests := []struct {
+		name         string
+		prepareMock  func()
+		expectedTier domainwallet.KYCTier
+		expectedErr  error
+	}{
+		{
+			name: "tier found",
+			prepareMock: func() {
+				mock.ExpectQuery(tierQuery).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"kyc_tier"}).AddRow("basic"))
+			},
+			expectedTier: domainwallet.BasicKYC,
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(tierQuery).WithArgs("user1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrWalletNotFound,
+		},
+		{
+			name: "db error",
+			prepareMock: func() {
+				mock.ExpectQuery(tierQuery).WithArgs("user1").WillReturnError(errors.New("db error"))
+			},
+			expectedErr: errors.New("failed to get wallet kyc tier: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			tier, err := r.GetWalletKYCTier(context.Background(), "user1")
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedTier, tier)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetWalletKYCTier(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	updateQuery := regexp.QuoteMeta(`UPDATE wallets SET kyc_tier = $1 WHERE user_id = $2 RETURNING id, user_id, status, kyc_tier, created_at`)
+	balancesQuery := regexp.QuoteMeta(`SELECT asset, balance, held FROM balances WHERE wallet_id = $1 ORDER BY asset`)
+	walletColumns := []string{"id", "user_id", "status", "kyc_tier", "created_at"}
+
+	tests := []struct {
+		name           string
+		prepareMock    func()
+		expectedWallet domainwallet.Wallet
+		expectedErr    error
+	}{
+		{
+			name: "tier set",
+			prepareMock: func() {
+				mock.ExpectQuery(updateQuery).
+					WithArgs("full", "user1").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet1", "user1", "active", "full", "2026-10-17T00:00:00Z"))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet1").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance"}).AddRow("USD", 100))
+			},
+			expectedWallet: domainwallet.Wallet{
+				ID:        "wallet1",
+				UserID:    "user1",
+				Status:    domainwallet.Active,
+				KYCTier:   domainwallet.FullKYC,
+				CreatedAt: "2026-10-17T00:00:00Z",
+				Balances:  []domainwallet.Balance{{Asset: "USD", Balance: 100}},
+			},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(updateQuery).WithArgs("full", "user1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrWalletNotFound,
+		},
+		{
+			name: "db error",
+			prepareMock: func() {
+				mock.ExpectQuery(updateQuery).WithArgs("full", "user1").WillReturnError(errors.New("db error"))
+			},
+			expectedErr: errors.New("failed to update wallet kyc tier: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			w, err := r.SetWalletKYCTier(context.Background(), "user1", domainwallet.FullKYC)
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedWallet, w)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+type ledgerEntry struct {
+	JournalID string `db:"journal_id"`
+	domainwallet.LedgerEntry
+}
+
+// postJournal records the journal of a transaction in the ledger within the caller's db tx.
+// The journal is validated here and the balanced invariant is enforced again by a deferred
+// constraint trigger when the db tx commits.
+func postJournal(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	transactionID string,
+	journal domainwallet.Journal,
+) error {
+	if err := journal.Validate(); err != nil {
+		return fmt.Errorf("invalid journal: %w", err)
+	}
+
+	var journalID string
+	insertJournal := `INSERT INTO ledger_journals (transaction_id) VALUES ($1) RETURNING id`
+	err := tx.GetContext(ctx, &journalID, insertJournal, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to insert ledger journal: %w", err)
+	}
+
+	entries := make([]ledgerEntry, 0, len(journal))
+	for _, e := range journal {
+		entries = append(entries, ledgerEntry{JournalID: journalID, LedgerEntry: e})
+	}
+
+	insertEntries := `
+		INSERT INTO ledger_entries (journal_id, wallet_id, system_account, asset, amount)
+		VALUES (:journal_id, :wallet_id, :system_account, :asset, :amount)
+	`
+	_, err = tx.NamedExecContext(ctx, insertEntries, entries)
+	if err != nil {
+		return fmt.Errorf("failed to insert ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+// GetWalletLedgerBalances returns, for every asset of the user wallet, the stored balance
+// next to the balance derived from the wallet's ledger entries.
+func (r *Repository) GetWalletLedgerBalances(
+	ctx context.Context,
+	userID string,
+) ([]domainwallet.LedgerBalance, error) {
+	var walletID string
+	err := r.db.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed get user wallet: %w", domainwallet.ErrWalletNotFound)
+		}
+		return nil, fmt.Errorf("failed to get wallet for user %s: %w", userID, err)
+	}
+
+	const query = `
+		SELECT
+			COALESCE(b.asset, l.asset) AS asset,
+			COALESCE(b.balance, 0) AS balance,
+			COALESCE(l.ledger_balance, 0) AS ledger_balance
+		FROM (
+			SELECT asset, balance FROM balances WHERE wallet_id = $1
+		) b
+		FULL OUTER JOIN (
+			SELECT asset, SUM(amount)::BIGINT AS ledger_balance
+			FROM ledger_entries
+			WHERE wallet_id = $1
+			GROUP BY asset
+		) l ON l.asset = b.asset
+		ORDER BY 1;
+	`
+
+	var balances []domainwallet.LedgerBalance
+	err = r.db.SelectContext(ctx, &balances, query, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet ledger balances: %w", err)
+	}
+
+	return balances, nil
+}
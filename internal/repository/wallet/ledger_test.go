@@ -0,0 +1,86 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWalletLedgerBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	tests := []struct {
+		name          string
+		prepareMock   func()
+		expected      []domainwallet.LedgerBalance
+		expectedError error
+	}{
+		{
+			name: "balances compared with ledger",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(`FROM \( SELECT asset, balance FROM balances WHERE wallet_id = \$1 \) b FULL OUTER JOIN`).
+					WithArgs("wallet-1").
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance", "ledger_balance"}).
+						AddRow("BTC", 500, 500).
+						AddRow("USD", 150, 100))
+			},
+			expected: []domainwallet.LedgerBalance{
+				{Asset: "BTC", Balance: 500, LedgerBalance: 500},
+				{Asset: "USD", Balance: 150, LedgerBalance: 100},
+			},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+		{
+			name: "error comparing balances",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(`FROM \( SELECT asset, balance FROM balances WHERE wallet_id = \$1 \) b FULL OUTER JOIN`).
+					WithArgs("wallet-1").
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to get wallet ledger balances: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			balances, err := r.GetWalletLedgerBalances(context.Background(), "user123")
+			assert.Equal(t, tt.expected, balances)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+const transactionLimitColumns = `wallet_id, kyc_tier, type, asset, per_transaction_amount, daily_amount, daily_count, ` +
+	`weekly_amount, weekly_count, monthly_amount, monthly_count`
+
+// walletLimitsQuery selects the limits in effect for wallet $1 of each type and asset, its own limit, else the default
+// of its KYC tier, else the default of every wallet, optionally only of type $2 and asset $3.
+const walletLimitsQuery = `
+	SELECT DISTINCT ON (l.type, l.asset) l.wallet_id, l.kyc_tier, l.type, l.asset, l.per_transaction_amount,
+		l.daily_amount, l.daily_count, l.weekly_amount, l.weekly_count, l.monthly_amount, l.monthly_count
+	FROM transaction_limits l
+	JOIN wallets w ON w.id = $1
+	WHERE (l.wallet_id = w.id OR (l.wallet_id IS NULL AND (l.kyc_tier IS NULL OR l.kyc_tier = w.kyc_tier)))
+	AND ($2 = '' OR l.type::text = $2)
+	AND ($3 = '' OR l.asset = $3)
+	ORDER BY l.type, l.asset, l.wallet_id NULLS LAST, l.kyc_tier NULLS LAST
+`
+
+// limitUsageQuery totals and counts the successful and pending review transactions of type $2 and asset $3 wallet $1
+// initiated in the daily, weekly and monthly windows, $4, $5 and $6 seconds back from now.
+// Only the wallet's transactions of the widest window are read, through the (initiator_wallet_id, created_at) index.
+const limitUsageQuery = `
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE created_at > NOW() - make_interval(secs => $4)), 0)::BIGINT AS daily_amount,
+		COUNT(*) FILTER (WHERE created_at > NOW() - make_interval(secs => $4)) AS daily_count,
+		COALESCE(SUM(amount) FILTER (WHERE created_at > NOW() - make_interval(secs => $5)), 0)::BIGINT AS weekly_amount,
+		COUNT(*) FILTER (WHERE created_at > NOW() - make_interval(secs => $5)) AS weekly_count,
+		COALESCE(SUM(amount), 0)::BIGINT AS monthly_amount,
+		COUNT(*) AS monthly_count
+	FROM transactions
+	WHERE initiator_wallet_id = $1 AND type = $2 AND asset = $3 AND status IN ('success', 'pending_review')
+	AND created_at > NOW() - make_interval(secs => $6)
+`
+
+// GetTransactionLimits returns the limits in effect for the user's wallet with its usage of each.
+// Types and assets without a limit are not limited.
+func (r *Repository) GetTransactionLimits(ctx context.Context, userID string) ([]domainwallet.LimitStatus, error) {
+	var walletID string
+	err := r.db.GetContext(ctx, &walletID, `SELECT id FROM wallets WHERE user_id = $1`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return nil, fmt.Errorf("failed to get wallet by userID: %w", err)
+	}
+
+	var limits []domainwallet.TransactionLimit
+	err = r.db.SelectContext(ctx, &limits, walletLimitsQuery, walletID, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select transaction limits: %w", err)
+	}
+
+	statuses := make([]domainwallet.LimitStatus, 0, len(limits))
+	for _, limit := range limits {
+		usage, err := limitUsage(ctx, r.db, walletID, limit.Type, limit.Asset)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, domainwallet.LimitStatus{Limit: limit, Usage: usage})
+	}
+
+	return statuses, nil
+}
+
+// SetTransactionLimit creates or replaces the user's wallet own limit of the limit's type and asset,
+// overriding the default.
+func (r *Repository) SetTransactionLimit(
+	ctx context.Context,
+	userID string,
+	limit domainwallet.TransactionLimit,
+) (domainwallet.TransactionLimit, error) {
+	upsert := `
+		INSERT INTO transaction_limits (wallet_id, type, asset, per_transaction_amount, daily_amount, daily_count,
+			weekly_amount, weekly_count, monthly_amount, monthly_count)
+		SELECT id, $2::transaction_type, $3, $4::bigint, $5::bigint, $6::bigint, $7::bigint, $8::bigint, $9::bigint, $10::bigint
+		FROM wallets WHERE user_id = $1
+		ON CONFLICT (wallet_id, type, asset) WHERE wallet_id IS NOT NULL DO UPDATE SET
+			per_transaction_amount = EXCLUDED.per_transaction_amount,
+			daily_amount = EXCLUDED.daily_amount,
+			daily_count = EXCLUDED.daily_count,
+			weekly_amount = EXCLUDED.weekly_amount,
+			weekly_count = EXCLUDED.weekly_count,
+			monthly_amount = EXCLUDED.monthly_amount,
+			monthly_count = EXCLUDED.monthly_count,
+			updated_at = NOW()
+		RETURNING ` + transactionLimitColumns
+
+	var dst domainwallet.TransactionLimit
+	err := r.db.GetContext(
+		ctx,
+		&dst,
+		upsert,
+		userID,
+		limit.Type,
+		limit.Asset,
+		limit.PerTransaction,
+		limit.DailyAmount,
+		limit.DailyCount,
+		limit.WeeklyAmount,
+		limit.WeeklyCount,
+		limit.MonthlyAmount,
+		limit.MonthlyCount,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.TransactionLimit{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		return domainwallet.TransactionLimit{}, fmt.Errorf("failed to upsert transaction limit: %w", err)
+	}
+
+	return dst, nil
+}
+
+// DeleteTransactionLimit deletes the user's wallet own limit of the type and asset, the default applies again.
+func (r *Repository) DeleteTransactionLimit(
+	ctx context.Context,
+	userID string,
+	txnType domainwallet.TransactionType,
+	asset string,
+) error {
+	query := `
+		DELETE FROM transaction_limits l USING wallets w
+		WHERE l.wallet_id = w.id AND w.user_id = $1 AND l.type = $2 AND l.asset = $3
+	`
+	res, err := r.db.ExecContext(ctx, query, userID, txnType, asset)
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction limit: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get deleted transaction limits: %w", err)
+	}
+
+	if deleted == 0 {
+		return domainwallet.ErrTransactionLimitNotFound
+	}
+
+	return nil
+}
+
+// checkTransactionLimit returns ErrTransactionLimitExceeded when a transaction of amount would exceed the wallet's
+// limit of its type and asset. It must run while holding the wallet's row lock, so concurrent transactions of the
+// wallet cannot both fit in the same headroom.
+func checkTransactionLimit(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	walletID string,
+	txnType domainwallet.TransactionType,
+	asset string,
+	amount uint64,
+) error {
+	var limit domainwallet.TransactionLimit
+	err := tx.GetContext(ctx, &limit, walletLimitsQuery, walletID, txnType, asset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get transaction limit: %w", err)
+	}
+
+	usage, err := limitUsage(ctx, tx, walletID, txnType, asset)
+	if err != nil {
+		return err
+	}
+
+	return limit.Check(usage, amount)
+}
+
+// limitUsage totals and counts the wallet's successful and pending review transactions of the type and asset in every limit window.
+func limitUsage(
+	ctx context.Context,
+	q sqlx.QueryerContext,
+	walletID string,
+	txnType domainwallet.TransactionType,
+	asset string,
+) (domainwallet.LimitUsage, error) {
+	var usage domainwallet.LimitUsage
+	err := sqlx.GetContext(
+		ctx,
+		q,
+		&usage,
+		limitUsageQuery,
+		walletID,
+		txnType,
+		asset,
+		domainwallet.DailyWindow.Duration().Seconds(),
+		domainwallet.WeeklyWindow.Duration().Seconds(),
+		domainwallet.MonthlyWindow.Duration().Seconds(),
+	)
+	if err != nil {
+		return domainwallet.LimitUsage{}, fmt.Errorf("failed to get transaction limit usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,274 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	walletLimitsQuery      = `SELECT DISTINCT ON \(l.type, l.asset\) l.wallet_id, l.kyc_tier, .* FROM transaction_limits l JOIN wallets w ON w.id = \$1 .* ORDER BY l.type, l.asset, l.wallet_id NULLS LAST, l.kyc_tier NULLS LAST`
+	limitUsageQuery        = `SELECT COALESCE\(SUM\(amount\) FILTER .* FROM transactions WHERE initiator_wallet_id = \$1 AND type = \$2 AND asset = \$3`
+	upsertLimitQuery       = `INSERT INTO transaction_limits .* FROM wallets WHERE user_id = \$1 ON CONFLICT \(wallet_id, type, asset\) WHERE wallet_id IS NOT NULL DO UPDATE`
+	deleteLimitQuery       = `DELETE FROM transaction_limits l USING wallets w WHERE l.wallet_id = w.id AND w.user_id = \$1`
+	walletIDByUserIDQuery  = `SELECT id FROM wallets WHERE user_id = \$1`
+	dailySeconds           = float64(24 * 60 * 60)
+	weeklySeconds          = 7 * dailySeconds
+	monthlySeconds         = 30 * dailySeconds
+	perTransactionUSDLimit = 1000
+)
+
+var (
+	transactionLimitColumns = []string{
+		"wallet_id", "kyc_tier", "type", "asset", "per_transaction_amount", "daily_amount", "daily_count",
+		"weekly_amount", "weekly_count", "monthly_amount", "monthly_count",
+	}
+	limitUsageColumns = []string{
+		"daily_amount", "daily_count", "weekly_amount", "weekly_count", "monthly_amount", "monthly_count",
+	}
+)
+
+func limitOf(v uint64) *uint64 {
+	return &v
+}
+
+// expectNoTransactionLimit expects the limit check of a wallet's transaction of the type and asset to find no limit.
+func expectNoTransactionLimit(mock sqlmock.Sqlmock, walletID, txnType, asset string) {
+	mock.ExpectQuery(walletLimitsQuery).
+		WithArgs(walletID, txnType, asset).
+		WillReturnRows(sqlmock.NewRows(transactionLimitColumns))
+}
+
+// expectTransactionLimit expects the limit check of a wallet's transaction of the type and asset to find the default
+// limit of perTransactionUSDLimit per transaction and a daily count of 5, the wallet having made dailyCount already.
+func expectTransactionLimit(mock sqlmock.Sqlmock, walletID, txnType, asset string, dailyCount int) {
+	mock.ExpectQuery(walletLimitsQuery).
+		WithArgs(walletID, txnType, asset).
+		WillReturnRows(sqlmock.NewRows(transactionLimitColumns).
+			AddRow(nil, nil, txnType, asset, perTransactionUSDLimit, nil, 5, nil, nil, nil, nil))
+	mock.ExpectQuery(limitUsageQuery).
+		WithArgs(walletID, txnType, asset, dailySeconds, weeklySeconds, monthlySeconds).
+		WillReturnRows(sqlmock.NewRows(limitUsageColumns).AddRow(100*dailyCount, dailyCount, 0, 0, 0, 0))
+}
+
+func TestGetTransactionLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	walletID := "wallet1"
+	basicTier := domainwallet.BasicKYC
+
+	tests := []struct {
+		name             string
+		prepareMock      func()
+		expectedStatuses []domainwallet.LimitStatus
+		expectedErr      error
+	}{
+		{
+			name: "overridden and kyc tier default limits with usage",
+			prepareMock: func() {
+				mock.ExpectQuery(walletIDByUserIDQuery).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(walletID))
+				mock.ExpectQuery(walletLimitsQuery).
+					WithArgs(walletID, "", "").
+					WillReturnRows(sqlmock.NewRows(transactionLimitColumns).
+						AddRow(walletID, nil, "transfer", "USD", nil, 50000, nil, nil, nil, nil, nil).
+						AddRow(nil, "basic", "withdraw", "USD", 1000, 2000, 3, nil, nil, 5000, nil))
+				mock.ExpectQuery(limitUsageQuery).
+					WithArgs(walletID, "transfer", "USD", dailySeconds, weeklySeconds, monthlySeconds).
+					WillReturnRows(sqlmock.NewRows(limitUsageColumns).AddRow(0, 0, 0, 0, 0, 0))
+				mock.ExpectQuery(limitUsageQuery).
+					WithArgs(walletID, "withdraw", "USD", dailySeconds, weeklySeconds, monthlySeconds).
+					WillReturnRows(sqlmock.NewRows(limitUsageColumns).AddRow(500, 1, 700, 2, 900, 3))
+			},
+			expectedStatuses: []domainwallet.LimitStatus{
+				{
+					Limit: domainwallet.TransactionLimit{
+						WalletID:    &walletID,
+						Type:        domainwallet.Transfer,
+						Asset:       "USD",
+						DailyAmount: limitOf(50000),
+					},
+				},
+				{
+					Limit: domainwallet.TransactionLimit{
+						KYCTier:        &basicTier,
+						Type:           domainwallet.Withdraw,
+						Asset:          "USD",
+						PerTransaction: limitOf(1000),
+						DailyAmount:    limitOf(2000),
+						DailyCount:     limitOf(3),
+						MonthlyAmount:  limitOf(5000),
+					},
+					Usage: domainwallet.LimitUsage{
+						DailyAmount:   500,
+						DailyCount:    1,
+						WeeklyAmount:  700,
+						WeeklyCount:   2,
+						MonthlyAmount: 900,
+						MonthlyCount:  3,
+					},
+				},
+			},
+		},
+		{
+			name: "no limits",
+			prepareMock: func() {
+				mock.ExpectQuery(walletIDByUserIDQuery).
+					WithArgs("user1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(walletID))
+				mock.ExpectQuery(walletLimitsQuery).
+					WithArgs(walletID, "", "").
+					WillReturnRows(sqlmock.NewRows(transactionLimitColumns))
+			},
+			expectedStatuses: []domainwallet.LimitStatus{},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(walletIDByUserIDQuery).WithArgs("user1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			statuses, err := r.GetTransactionLimits(context.Background(), "user1")
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedStatuses, statuses)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetTransactionLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	walletID := "wallet1"
+	limit := domainwallet.TransactionLimit{
+		Type:           domainwallet.Withdraw,
+		Asset:          "USD",
+		PerTransaction: limitOf(1000),
+		DailyCount:     limitOf(0),
+	}
+
+	tests := []struct {
+		name          string
+		prepareMock   func()
+		expectedLimit domainwallet.TransactionLimit
+		expectedErr   error
+	}{
+		{
+			name: "limit upserted",
+			prepareMock: func() {
+				mock.ExpectQuery(upsertLimitQuery).
+					WithArgs("user1", "withdraw", "USD", 1000, nil, 0, nil, nil, nil, nil).
+					WillReturnRows(sqlmock.NewRows(transactionLimitColumns).
+						AddRow(walletID, nil, "withdraw", "USD", 1000, nil, 0, nil, nil, nil, nil))
+			},
+			expectedLimit: domainwallet.TransactionLimit{
+				WalletID:       &walletID,
+				Type:           domainwallet.Withdraw,
+				Asset:          "USD",
+				PerTransaction: limitOf(1000),
+				DailyCount:     limitOf(0),
+			},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(upsertLimitQuery).WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			upserted, err := r.SetTransactionLimit(context.Background(), "user1", limit)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedLimit, upserted)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteTransactionLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	tests := []struct {
+		name        string
+		prepareMock func()
+		expectedErr error
+	}{
+		{
+			name: "limit deleted",
+			prepareMock: func() {
+				mock.ExpectExec(deleteLimitQuery).
+					WithArgs("user1", "transfer", "BTC").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "no limit of the wallet",
+			prepareMock: func() {
+				mock.ExpectExec(deleteLimitQuery).
+					WithArgs("user1", "transfer", "BTC").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: domainwallet.ErrTransactionLimitNotFound,
+		},
+		{
+			name: "db error",
+			prepareMock: func() {
+				mock.ExpectExec(deleteLimitQuery).WillReturnError(errors.New("db error"))
+			},
+			expectedErr: errors.New("failed to delete transaction limit: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			err := r.DeleteTransactionLimit(context.Background(), "user1", domainwallet.Transfer, "BTC")
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
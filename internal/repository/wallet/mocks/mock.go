@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	wallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
@@ -35,19 +36,289 @@ func (m *MockIWalletRepository) EXPECT() *MockIWalletRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CaptureHold mocks base method.
+func (m *MockIWalletRepository) CaptureHold(ctx context.Context, userID, holdID string, key wallet.IdempotencyKey, recipientUserID string, amount uint64, now time.Time, verdict wallet.FraudVerdict) (wallet.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID, key, recipientUserID, amount, now, verdict)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockIWalletRepositoryMockRecorder) CaptureHold(ctx, userID, holdID, key, recipientUserID, amount, now, verdict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockIWalletRepository)(nil).CaptureHold), ctx, userID, holdID, key, recipientUserID, amount, now, verdict)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockIWalletRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]wallet.WebhookDispatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]wallet.WebhookDispatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockIWalletRepositoryMockRecorder) ClaimWebhookDeliveries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockIWalletRepository)(nil).ClaimWebhookDeliveries), ctx, now, leaseUntil, limit)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockIWalletRepository) CreateAPIKey(ctx context.Context, key wallet.APIKey) (wallet.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
+	ret0, _ := ret[0].(wallet.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockIWalletRepositoryMockRecorder) CreateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockIWalletRepository)(nil).CreateAPIKey), ctx, key)
+}
+
+// CreateHold mocks base method.
+func (m *MockIWalletRepository) CreateHold(ctx context.Context, userID string, key wallet.IdempotencyKey, asset string, amount uint64, expiresAt time.Time) (wallet.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, userID, key, asset, amount, expiresAt)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockIWalletRepositoryMockRecorder) CreateHold(ctx, userID, key, asset, amount, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockIWalletRepository)(nil).CreateHold), ctx, userID, key, asset, amount, expiresAt)
+}
+
+// CreateWallet mocks base method.
+func (m *MockIWalletRepository) CreateWallet(ctx context.Context, userID string) (wallet.Wallet, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, userID)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockIWalletRepositoryMockRecorder) CreateWallet(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockIWalletRepository)(nil).CreateWallet), ctx, userID)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockIWalletRepository) CreateWebhookSubscription(ctx context.Context, sub wallet.WebhookSubscription) (wallet.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, sub)
+	ret0, _ := ret[0].(wallet.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockIWalletRepositoryMockRecorder) CreateWebhookSubscription(ctx, sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockIWalletRepository)(nil).CreateWebhookSubscription), ctx, sub)
+}
+
+// DeactivateWebhookSubscription mocks base method.
+func (m *MockIWalletRepository) DeactivateWebhookSubscription(ctx context.Context, subscriptionID string) (wallet.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateWebhookSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(wallet.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateWebhookSubscription indicates an expected call of DeactivateWebhookSubscription.
+func (mr *MockIWalletRepositoryMockRecorder) DeactivateWebhookSubscription(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateWebhookSubscription", reflect.TypeOf((*MockIWalletRepository)(nil).DeactivateWebhookSubscription), ctx, subscriptionID)
+}
+
+// DecideTransactionReview mocks base method.
+func (m *MockIWalletRepository) DecideTransactionReview(ctx context.Context, transactionID string, decision wallet.ReviewDecision) (wallet.TransactionReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideTransactionReview", ctx, transactionID, decision)
+	ret0, _ := ret[0].(wallet.TransactionReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideTransactionReview indicates an expected call of DecideTransactionReview.
+func (mr *MockIWalletRepositoryMockRecorder) DecideTransactionReview(ctx, transactionID, decision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideTransactionReview", reflect.TypeOf((*MockIWalletRepository)(nil).DecideTransactionReview), ctx, transactionID, decision)
+}
+
+// DeleteFeeSchedule mocks base method.
+func (m *MockIWalletRepository) DeleteFeeSchedule(ctx context.Context, txnType wallet.TransactionType, asset string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeeSchedule", ctx, txnType, asset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeeSchedule indicates an expected call of DeleteFeeSchedule.
+func (mr *MockIWalletRepositoryMockRecorder) DeleteFeeSchedule(ctx, txnType, asset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeeSchedule", reflect.TypeOf((*MockIWalletRepository)(nil).DeleteFeeSchedule), ctx, txnType, asset)
+}
+
+// DeleteTransactionLimit mocks base method.
+func (m *MockIWalletRepository) DeleteTransactionLimit(ctx context.Context, userID string, txnType wallet.TransactionType, asset string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransactionLimit", ctx, userID, txnType, asset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransactionLimit indicates an expected call of DeleteTransactionLimit.
+func (mr *MockIWalletRepositoryMockRecorder) DeleteTransactionLimit(ctx, userID, txnType, asset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransactionLimit", reflect.TypeOf((*MockIWalletRepository)(nil).DeleteTransactionLimit), ctx, userID, txnType, asset)
+}
+
 // DepositWallet mocks base method.
-func (m *MockIWalletRepository) DepositWallet(ctx context.Context, userID, idempotencyKey string, amount uint64) (string, error) {
+func (m *MockIWalletRepository) DepositWallet(ctx context.Context, userID string, key wallet.IdempotencyKey, asset string, amount uint64) (wallet.IdempotentResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DepositWallet", ctx, userID, idempotencyKey, amount)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "DepositWallet", ctx, userID, key, asset, amount)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DepositWallet indicates an expected call of DepositWallet.
-func (mr *MockIWalletRepositoryMockRecorder) DepositWallet(ctx, userID, idempotencyKey, amount interface{}) *gomock.Call {
+func (mr *MockIWalletRepositoryMockRecorder) DepositWallet(ctx, userID, key, asset, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositWallet", reflect.TypeOf((*MockIWalletRepository)(nil).DepositWallet), ctx, userID, key, asset, amount)
+}
+
+// FinishReconciliation mocks base method.
+func (m *MockIWalletRepository) FinishReconciliation(ctx context.Context, reconciliationID string, status wallet.ReconciliationStatus, walletsChecked, mismatches int) (wallet.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishReconciliation", ctx, reconciliationID, status, walletsChecked, mismatches)
+	ret0, _ := ret[0].(wallet.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishReconciliation indicates an expected call of FinishReconciliation.
+func (mr *MockIWalletRepositoryMockRecorder) FinishReconciliation(ctx, reconciliationID, status, walletsChecked, mismatches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishReconciliation", reflect.TypeOf((*MockIWalletRepository)(nil).FinishReconciliation), ctx, reconciliationID, status, walletsChecked, mismatches)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockIWalletRepository) GetAPIKey(ctx context.Context, keyID string) (wallet.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, keyID)
+	ret0, _ := ret[0].(wallet.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockIWalletRepositoryMockRecorder) GetAPIKey(ctx, keyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DepositWallet", reflect.TypeOf((*MockIWalletRepository)(nil).DepositWallet), ctx, userID, idempotencyKey, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockIWalletRepository)(nil).GetAPIKey), ctx, keyID)
+}
+
+// GetFeeSchedule mocks base method.
+func (m *MockIWalletRepository) GetFeeSchedule(ctx context.Context, txnType wallet.TransactionType, asset string) (wallet.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedule", ctx, txnType, asset)
+	ret0, _ := ret[0].(wallet.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedule indicates an expected call of GetFeeSchedule.
+func (mr *MockIWalletRepositoryMockRecorder) GetFeeSchedule(ctx, txnType, asset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedule", reflect.TypeOf((*MockIWalletRepository)(nil).GetFeeSchedule), ctx, txnType, asset)
+}
+
+// GetFeeSchedules mocks base method.
+func (m *MockIWalletRepository) GetFeeSchedules(ctx context.Context) ([]wallet.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedules", ctx)
+	ret0, _ := ret[0].([]wallet.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedules indicates an expected call of GetFeeSchedules.
+func (mr *MockIWalletRepositoryMockRecorder) GetFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedules", reflect.TypeOf((*MockIWalletRepository)(nil).GetFeeSchedules), ctx)
+}
+
+// GetFraudSignals mocks base method.
+func (m *MockIWalletRepository) GetFraudSignals(ctx context.Context, check wallet.FraudCheck) (wallet.FraudSignals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudSignals", ctx, check)
+	ret0, _ := ret[0].(wallet.FraudSignals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudSignals indicates an expected call of GetFraudSignals.
+func (mr *MockIWalletRepositoryMockRecorder) GetFraudSignals(ctx, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudSignals", reflect.TypeOf((*MockIWalletRepository)(nil).GetFraudSignals), ctx, check)
+}
+
+// GetIdempotentResponse mocks base method.
+func (m *MockIWalletRepository) GetIdempotentResponse(ctx context.Context, userID string, operation wallet.IdempotentOperation, key wallet.IdempotencyKey) (wallet.IdempotentResponse, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotentResponse", ctx, userID, operation, key)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetIdempotentResponse indicates an expected call of GetIdempotentResponse.
+func (mr *MockIWalletRepositoryMockRecorder) GetIdempotentResponse(ctx, userID, operation, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotentResponse", reflect.TypeOf((*MockIWalletRepository)(nil).GetIdempotentResponse), ctx, userID, operation, key)
+}
+
+// GetReconciliation mocks base method.
+func (m *MockIWalletRepository) GetReconciliation(ctx context.Context, reconciliationID string) (wallet.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliation", ctx, reconciliationID)
+	ret0, _ := ret[0].(wallet.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliation indicates an expected call of GetReconciliation.
+func (mr *MockIWalletRepositoryMockRecorder) GetReconciliation(ctx, reconciliationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliation", reflect.TypeOf((*MockIWalletRepository)(nil).GetReconciliation), ctx, reconciliationID)
+}
+
+// GetTransactionLimits mocks base method.
+func (m *MockIWalletRepository) GetTransactionLimits(ctx context.Context, userID string) ([]wallet.LimitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionLimits", ctx, userID)
+	ret0, _ := ret[0].([]wallet.LimitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionLimits indicates an expected call of GetTransactionLimits.
+func (mr *MockIWalletRepositoryMockRecorder) GetTransactionLimits(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionLimits", reflect.TypeOf((*MockIWalletRepository)(nil).GetTransactionLimits), ctx, userID)
 }
 
 // GetWallet mocks base method.
@@ -65,10 +336,100 @@ func (mr *MockIWalletRepositoryMockRecorder) GetWallet(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockIWalletRepository)(nil).GetWallet), ctx, userID)
 }
 
+// GetWalletBalancesAsOf mocks base method.
+func (m *MockIWalletRepository) GetWalletBalancesAsOf(ctx context.Context, userID string, asOf time.Time) (wallet.WalletBalancesAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletBalancesAsOf", ctx, userID, asOf)
+	ret0, _ := ret[0].(wallet.WalletBalancesAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletBalancesAsOf indicates an expected call of GetWalletBalancesAsOf.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletBalancesAsOf(ctx, userID, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletBalancesAsOf", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletBalancesAsOf), ctx, userID, asOf)
+}
+
+// GetWalletBalancesAt mocks base method.
+func (m *MockIWalletRepository) GetWalletBalancesAt(ctx context.Context, userID string, at time.Time) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletBalancesAt", ctx, userID, at)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletBalancesAt indicates an expected call of GetWalletBalancesAt.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletBalancesAt(ctx, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletBalancesAt", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletBalancesAt), ctx, userID, at)
+}
+
+// GetWalletKYCTier mocks base method.
+func (m *MockIWalletRepository) GetWalletKYCTier(ctx context.Context, userID string) (wallet.KYCTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletKYCTier", ctx, userID)
+	ret0, _ := ret[0].(wallet.KYCTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletKYCTier indicates an expected call of GetWalletKYCTier.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletKYCTier(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletKYCTier", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletKYCTier), ctx, userID)
+}
+
+// GetWalletLedgerBalances mocks base method.
+func (m *MockIWalletRepository) GetWalletLedgerBalances(ctx context.Context, userID string) ([]wallet.LedgerBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLedgerBalances", ctx, userID)
+	ret0, _ := ret[0].([]wallet.LedgerBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLedgerBalances indicates an expected call of GetWalletLedgerBalances.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletLedgerBalances(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLedgerBalances", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletLedgerBalances), ctx, userID)
+}
+
+// GetWalletTransaction mocks base method.
+func (m *MockIWalletRepository) GetWalletTransaction(ctx context.Context, userID, transactionID string) (wallet.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletTransaction", ctx, userID, transactionID)
+	ret0, _ := ret[0].(wallet.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletTransaction indicates an expected call of GetWalletTransaction.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletTransaction(ctx, userID, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransaction", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletTransaction), ctx, userID, transactionID)
+}
+
+// GetWalletTransactionsAfter mocks base method.
+func (m *MockIWalletRepository) GetWalletTransactionsAfter(ctx context.Context, userID string, filter wallet.TransactionFilter, after *wallet.TransactionCursor, limit int) ([]wallet.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletTransactionsAfter", ctx, userID, filter, after, limit)
+	ret0, _ := ret[0].([]wallet.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletTransactionsAfter indicates an expected call of GetWalletTransactionsAfter.
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletTransactionsAfter(ctx, userID, filter, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransactionsAfter", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletTransactionsAfter), ctx, userID, filter, after, limit)
+}
+
 // GetWalletTransactionsHistory mocks base method.
-func (m *MockIWalletRepository) GetWalletTransactionsHistory(ctx context.Context, userID string, offset, pageSize int) ([]wallet.Transaction, int, error) {
+func (m *MockIWalletRepository) GetWalletTransactionsHistory(ctx context.Context, userID string, filter wallet.TransactionFilter, offset, pageSize int) ([]wallet.Transaction, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletTransactionsHistory", ctx, userID, offset, pageSize)
+	ret := m.ctrl.Call(m, "GetWalletTransactionsHistory", ctx, userID, filter, offset, pageSize)
 	ret0, _ := ret[0].([]wallet.Transaction)
 	ret1, _ := ret[1].(int)
 	ret2, _ := ret[2].(error)
@@ -76,37 +437,379 @@ func (m *MockIWalletRepository) GetWalletTransactionsHistory(ctx context.Context
 }
 
 // GetWalletTransactionsHistory indicates an expected call of GetWalletTransactionsHistory.
-func (mr *MockIWalletRepositoryMockRecorder) GetWalletTransactionsHistory(ctx, userID, offset, pageSize interface{}) *gomock.Call {
+func (mr *MockIWalletRepositoryMockRecorder) GetWalletTransactionsHistory(ctx, userID, filter, offset, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransactionsHistory", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletTransactionsHistory), ctx, userID, filter, offset, pageSize)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockIWalletRepository) ListAPIKeys(ctx context.Context) ([]wallet.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]wallet.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockIWalletRepositoryMockRecorder) ListAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockIWalletRepository)(nil).ListAPIKeys), ctx)
+}
+
+// ListReconciliations mocks base method.
+func (m *MockIWalletRepository) ListReconciliations(ctx context.Context, limit int) ([]wallet.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReconciliations", ctx, limit)
+	ret0, _ := ret[0].([]wallet.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReconciliations indicates an expected call of ListReconciliations.
+func (mr *MockIWalletRepositoryMockRecorder) ListReconciliations(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletTransactionsHistory", reflect.TypeOf((*MockIWalletRepository)(nil).GetWalletTransactionsHistory), ctx, userID, offset, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReconciliations", reflect.TypeOf((*MockIWalletRepository)(nil).ListReconciliations), ctx, limit)
+}
+
+// ListTransactionReviews mocks base method.
+func (m *MockIWalletRepository) ListTransactionReviews(ctx context.Context, limit int) ([]wallet.TransactionReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactionReviews", ctx, limit)
+	ret0, _ := ret[0].([]wallet.TransactionReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactionReviews indicates an expected call of ListTransactionReviews.
+func (mr *MockIWalletRepositoryMockRecorder) ListTransactionReviews(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactionReviews", reflect.TypeOf((*MockIWalletRepository)(nil).ListTransactionReviews), ctx, limit)
+}
+
+// ListWalletBalancesAsOf mocks base method.
+func (m *MockIWalletRepository) ListWalletBalancesAsOf(ctx context.Context, asOf time.Time, afterWalletID string, limit int) ([]wallet.WalletBalancesAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletBalancesAsOf", ctx, asOf, afterWalletID, limit)
+	ret0, _ := ret[0].([]wallet.WalletBalancesAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletBalancesAsOf indicates an expected call of ListWalletBalancesAsOf.
+func (mr *MockIWalletRepositoryMockRecorder) ListWalletBalancesAsOf(ctx, asOf, afterWalletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletBalancesAsOf", reflect.TypeOf((*MockIWalletRepository)(nil).ListWalletBalancesAsOf), ctx, asOf, afterWalletID, limit)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockIWalletRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID string, status wallet.WebhookDeliveryStatus, limit int) ([]wallet.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID, status, limit)
+	ret0, _ := ret[0].([]wallet.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockIWalletRepositoryMockRecorder) ListWebhookDeliveries(ctx, subscriptionID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockIWalletRepository)(nil).ListWebhookDeliveries), ctx, subscriptionID, status, limit)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockIWalletRepository) ListWebhookSubscriptions(ctx context.Context) ([]wallet.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", ctx)
+	ret0, _ := ret[0].([]wallet.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockIWalletRepositoryMockRecorder) ListWebhookSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockIWalletRepository)(nil).ListWebhookSubscriptions), ctx)
+}
+
+// PublishOutboxEvents mocks base method.
+func (m *MockIWalletRepository) PublishOutboxEvents(ctx context.Context, limit int, publish func(wallet.OutboxEvent) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOutboxEvents", ctx, limit, publish)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishOutboxEvents indicates an expected call of PublishOutboxEvents.
+func (mr *MockIWalletRepositoryMockRecorder) PublishOutboxEvents(ctx, limit, publish interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOutboxEvents", reflect.TypeOf((*MockIWalletRepository)(nil).PublishOutboxEvents), ctx, limit, publish)
+}
+
+// ReconcileWallets mocks base method.
+func (m *MockIWalletRepository) ReconcileWallets(ctx context.Context, afterWalletID string, limit int) (wallet.ReconciliationPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileWallets", ctx, afterWalletID, limit)
+	ret0, _ := ret[0].(wallet.ReconciliationPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileWallets indicates an expected call of ReconcileWallets.
+func (mr *MockIWalletRepositoryMockRecorder) ReconcileWallets(ctx, afterWalletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileWallets", reflect.TypeOf((*MockIWalletRepository)(nil).ReconcileWallets), ctx, afterWalletID, limit)
+}
+
+// RecordReconciliationMismatches mocks base method.
+func (m *MockIWalletRepository) RecordReconciliationMismatches(ctx context.Context, reconciliationID string, mismatches []wallet.BalanceMismatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordReconciliationMismatches", ctx, reconciliationID, mismatches)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordReconciliationMismatches indicates an expected call of RecordReconciliationMismatches.
+func (mr *MockIWalletRepositoryMockRecorder) RecordReconciliationMismatches(ctx, reconciliationID, mismatches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordReconciliationMismatches", reflect.TypeOf((*MockIWalletRepository)(nil).RecordReconciliationMismatches), ctx, reconciliationID, mismatches)
+}
+
+// ReleaseExpiredHolds mocks base method.
+func (m *MockIWalletRepository) ReleaseExpiredHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredHolds", ctx, now, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredHolds indicates an expected call of ReleaseExpiredHolds.
+func (mr *MockIWalletRepositoryMockRecorder) ReleaseExpiredHolds(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredHolds", reflect.TypeOf((*MockIWalletRepository)(nil).ReleaseExpiredHolds), ctx, now, limit)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockIWalletRepository) ReleaseHold(ctx context.Context, userID, holdID string) (wallet.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(wallet.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockIWalletRepositoryMockRecorder) ReleaseHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockIWalletRepository)(nil).ReleaseHold), ctx, userID, holdID)
+}
+
+// ReplayWebhookDelivery mocks base method.
+func (m *MockIWalletRepository) ReplayWebhookDelivery(ctx context.Context, subscriptionID, deliveryID string) (wallet.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDelivery", ctx, subscriptionID, deliveryID)
+	ret0, _ := ret[0].(wallet.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayWebhookDelivery indicates an expected call of ReplayWebhookDelivery.
+func (mr *MockIWalletRepositoryMockRecorder) ReplayWebhookDelivery(ctx, subscriptionID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDelivery", reflect.TypeOf((*MockIWalletRepository)(nil).ReplayWebhookDelivery), ctx, subscriptionID, deliveryID)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockIWalletRepository) ReverseTransaction(ctx context.Context, transactionID string, key wallet.IdempotencyKey, amount uint64) (wallet.IdempotentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransaction", ctx, transactionID, key, amount)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockIWalletRepositoryMockRecorder) ReverseTransaction(ctx, transactionID, key, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockIWalletRepository)(nil).ReverseTransaction), ctx, transactionID, key, amount)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockIWalletRepository) RevokeAPIKey(ctx context.Context, keyID string) (wallet.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, keyID)
+	ret0, _ := ret[0].(wallet.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockIWalletRepositoryMockRecorder) RevokeAPIKey(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockIWalletRepository)(nil).RevokeAPIKey), ctx, keyID)
+}
+
+// SetFeeSchedule mocks base method.
+func (m *MockIWalletRepository) SetFeeSchedule(ctx context.Context, schedule wallet.FeeSchedule) (wallet.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeeSchedule", ctx, schedule)
+	ret0, _ := ret[0].(wallet.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeeSchedule indicates an expected call of SetFeeSchedule.
+func (mr *MockIWalletRepositoryMockRecorder) SetFeeSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeSchedule", reflect.TypeOf((*MockIWalletRepository)(nil).SetFeeSchedule), ctx, schedule)
+}
+
+// SetTransactionLimit mocks base method.
+func (m *MockIWalletRepository) SetTransactionLimit(ctx context.Context, userID string, limit wallet.TransactionLimit) (wallet.TransactionLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransactionLimit", ctx, userID, limit)
+	ret0, _ := ret[0].(wallet.TransactionLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransactionLimit indicates an expected call of SetTransactionLimit.
+func (mr *MockIWalletRepositoryMockRecorder) SetTransactionLimit(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransactionLimit", reflect.TypeOf((*MockIWalletRepository)(nil).SetTransactionLimit), ctx, userID, limit)
+}
+
+// SetWalletKYCTier mocks base method.
+func (m *MockIWalletRepository) SetWalletKYCTier(ctx context.Context, userID string, tier wallet.KYCTier) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletKYCTier", ctx, userID, tier)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletKYCTier indicates an expected call of SetWalletKYCTier.
+func (mr *MockIWalletRepositoryMockRecorder) SetWalletKYCTier(ctx, userID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletKYCTier", reflect.TypeOf((*MockIWalletRepository)(nil).SetWalletKYCTier), ctx, userID, tier)
+}
+
+// SnapshotBalances mocks base method.
+func (m *MockIWalletRepository) SnapshotBalances(ctx context.Context, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotBalances", ctx, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotBalances indicates an expected call of SnapshotBalances.
+func (mr *MockIWalletRepositoryMockRecorder) SnapshotBalances(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotBalances", reflect.TypeOf((*MockIWalletRepository)(nil).SnapshotBalances), ctx, at)
+}
+
+// StartReconciliation mocks base method.
+func (m *MockIWalletRepository) StartReconciliation(ctx context.Context, freeze bool) (wallet.Reconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartReconciliation", ctx, freeze)
+	ret0, _ := ret[0].(wallet.Reconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartReconciliation indicates an expected call of StartReconciliation.
+func (mr *MockIWalletRepositoryMockRecorder) StartReconciliation(ctx, freeze interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartReconciliation", reflect.TypeOf((*MockIWalletRepository)(nil).StartReconciliation), ctx, freeze)
+}
+
+// StreamWalletTransactions mocks base method.
+func (m *MockIWalletRepository) StreamWalletTransactions(ctx context.Context, userID string, filter wallet.TransactionFilter, fn func(wallet.StatementLine) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamWalletTransactions", ctx, userID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamWalletTransactions indicates an expected call of StreamWalletTransactions.
+func (mr *MockIWalletRepositoryMockRecorder) StreamWalletTransactions(ctx, userID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamWalletTransactions", reflect.TypeOf((*MockIWalletRepository)(nil).StreamWalletTransactions), ctx, userID, filter, fn)
+}
+
+// SubscribeWalletUpdates mocks base method.
+func (m *MockIWalletRepository) SubscribeWalletUpdates(ctx context.Context, walletID string) (<-chan wallet.TransactionCreated, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeWalletUpdates", ctx, walletID)
+	ret0, _ := ret[0].(<-chan wallet.TransactionCreated)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeWalletUpdates indicates an expected call of SubscribeWalletUpdates.
+func (mr *MockIWalletRepositoryMockRecorder) SubscribeWalletUpdates(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeWalletUpdates", reflect.TypeOf((*MockIWalletRepository)(nil).SubscribeWalletUpdates), ctx, walletID)
 }
 
 // Transfer mocks base method.
-func (m *MockIWalletRepository) Transfer(ctx context.Context, initiatorUserID, recipientUserID, idempotencyKey string, amount uint64) (string, error) {
+func (m *MockIWalletRepository) Transfer(ctx context.Context, initiatorUserID, recipientUserID string, key wallet.IdempotencyKey, asset string, amount uint64, verdict wallet.FraudVerdict) (wallet.IdempotentResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Transfer", ctx, initiatorUserID, recipientUserID, idempotencyKey, amount)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Transfer", ctx, initiatorUserID, recipientUserID, key, asset, amount, verdict)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Transfer indicates an expected call of Transfer.
-func (mr *MockIWalletRepositoryMockRecorder) Transfer(ctx, initiatorUserID, recipientUserID, idempotencyKey, amount interface{}) *gomock.Call {
+func (mr *MockIWalletRepositoryMockRecorder) Transfer(ctx, initiatorUserID, recipientUserID, key, asset, amount, verdict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockIWalletRepository)(nil).Transfer), ctx, initiatorUserID, recipientUserID, key, asset, amount, verdict)
+}
+
+// UpdateWalletStatus mocks base method.
+func (m *MockIWalletRepository) UpdateWalletStatus(ctx context.Context, userID string, status wallet.WalletStatus) (wallet.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletStatus", ctx, userID, status)
+	ret0, _ := ret[0].(wallet.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWalletStatus indicates an expected call of UpdateWalletStatus.
+func (mr *MockIWalletRepositoryMockRecorder) UpdateWalletStatus(ctx, userID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletStatus", reflect.TypeOf((*MockIWalletRepository)(nil).UpdateWalletStatus), ctx, userID, status)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockIWalletRepository) UpdateWebhookDelivery(ctx context.Context, delivery wallet.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockIWalletRepositoryMockRecorder) UpdateWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockIWalletRepository)(nil).Transfer), ctx, initiatorUserID, recipientUserID, idempotencyKey, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockIWalletRepository)(nil).UpdateWebhookDelivery), ctx, delivery)
 }
 
 // WithdrawWallet mocks base method.
-func (m *MockIWalletRepository) WithdrawWallet(ctx context.Context, userID, idempotencyKey string, amount uint64) (string, error) {
+func (m *MockIWalletRepository) WithdrawWallet(ctx context.Context, userID string, key wallet.IdempotencyKey, asset string, amount uint64, verdict wallet.FraudVerdict) (wallet.IdempotentResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithdrawWallet", ctx, userID, idempotencyKey, amount)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "WithdrawWallet", ctx, userID, key, asset, amount, verdict)
+	ret0, _ := ret[0].(wallet.IdempotentResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // WithdrawWallet indicates an expected call of WithdrawWallet.
-func (mr *MockIWalletRepositoryMockRecorder) WithdrawWallet(ctx, userID, idempotencyKey, amount interface{}) *gomock.Call {
+func (mr *MockIWalletRepositoryMockRecorder) WithdrawWallet(ctx, userID, key, asset, amount, verdict interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawWallet", reflect.TypeOf((*MockIWalletRepository)(nil).WithdrawWallet), ctx, userID, idempotencyKey, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawWallet", reflect.TypeOf((*MockIWalletRepository)(nil).WithdrawWallet), ctx, userID, key, asset, amount, verdict)
 }
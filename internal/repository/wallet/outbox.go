@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+// outboxRelayLockKey is the postgres advisory lock held while relaying outbox events, so a single relay publishes at a time
+// and events are published in order.
+const outboxRelayLockKey = 7_403_118_009
+
+// transactionWalletsQuery selects a transaction once per wallet it involves, with the wallet's balance of its asset.
+// Failed transactions and transactions pending review moved no money, so only their initiator is selected.
+const transactionWalletsQuery = `
+	SELECT w.id AS wallet_id, w.user_id,
+		t.id, iw.user_id AS initiator_wallet_user_id, t.type, t.status, t.asset, t.amount,
+		rw.user_id AS recipient_wallet_user_id, t.failure_reason, t.parent_transaction_id, t.created_at,
+		COALESCE(b.balance, 0) AS balance, COALESCE(b.held, 0) AS held
+	FROM transactions t
+	JOIN wallets iw ON iw.id = t.initiator_wallet_id
+	LEFT JOIN wallets rw ON rw.id = t.recipient_wallet_id
+	JOIN wallets w ON w.id = t.initiator_wallet_id OR (w.id = t.recipient_wallet_id AND t.status = 'success')
+	LEFT JOIN balances b ON b.wallet_id = w.id AND b.asset = t.asset
+	WHERE t.id = $1
+	ORDER BY w.id
+`
+
+type transactionWallet struct {
+	WalletID string `db:"wallet_id"`
+	UserID   string `db:"user_id"`
+	domainwallet.Transaction
+	Balance uint64 `db:"balance"`
+	Held    uint64 `db:"held"`
+}
+
+// recordTransactionEvents does the following:
+// 1. Select the transaction once per wallet it involves, with the wallet's balance right after it, read within the db transaction recording it
+// 2. Insert an event of eventType per wallet into the outbox, so events are durable exactly when the transaction is
+// 3. Queue a delivery of each event to every active webhook subscription to its type and wallet
+func recordTransactionEvents(ctx context.Context, tx *sqlx.Tx, eventType, transactionID string) error {
+	var rows []transactionWallet
+	err := tx.SelectContext(ctx, &rows, transactionWalletsQuery, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction wallets: %w", err)
+	}
+
+	events := make([]domainwallet.OutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		balance := domainwallet.Balance{Asset: row.Asset, Balance: row.Balance, Held: row.Held}
+		event, err := domainwallet.NewTransactionCreated(row.WalletID, row.UserID, row.Transaction, balance).EventOf(eventType)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Deliveries to the active webhook subscriptions of the events are queued with them
+	insertEvents := `
+		WITH events AS (
+			INSERT INTO outbox_events (event_type, wallet_id, payload)
+			VALUES (:event_type, :wallet_id, :payload)
+			RETURNING id, event_type, wallet_id
+		)
+		INSERT INTO webhook_deliveries (subscription_id, event_id)
+		SELECT s.id, e.id
+		FROM events e
+		JOIN wallets w ON w.id = e.wallet_id
+		JOIN webhook_subscriptions s ON s.active
+			AND s.event_types @> jsonb_build_array(e.event_type)
+			AND (s.user_id IS NULL OR s.user_id = w.user_id)
+	`
+	_, err = tx.NamedExecContext(ctx, insertEvents, events)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox events: %w", err)
+	}
+
+	return nil
+}
+
+// PublishOutboxEvents does the following:
+// 1. Try to take the outbox relay advisory lock, returning right away when another relay holds it
+// 2. Select up to limit unpublished events in id order and publish them one by one, stopping at the first failure so later events are not published ahead of it
+// 3. Mark the events published and return how many were
+// Events published right before a crash are published again, so delivery is at least once.
+func (r *Repository) PublishOutboxEvents(
+	ctx context.Context,
+	limit int,
+	publish func(domainwallet.OutboxEvent) error,
+) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock outbox relay: %w", err)
+	}
+
+	if !locked {
+		return 0, nil
+	}
+
+	var events []domainwallet.OutboxEvent
+	query := `
+		SELECT id, event_type, wallet_id, payload, created_at
+		FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`
+	err = tx.SelectContext(ctx, &events, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unpublished outbox events: %w", err)
+	}
+
+	published := make([]int64, 0, len(events))
+	var publishErr error
+	for _, event := range events {
+		if publishErr = publish(event); publishErr != nil {
+			publishErr = fmt.Errorf("failed to publish outbox event %d: %w", event.ID, publishErr)
+			break
+		}
+
+		published = append(published, event.ID)
+	}
+
+	if len(published) > 0 {
+		markPublished, args, err := sqlx.In(`UPDATE outbox_events SET published_at = NOW() WHERE id IN (?)`, published)
+		if err != nil {
+			return 0, fmt.Errorf("failed to build mark outbox events published query: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(markPublished), args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark outbox events published: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to commit tx: %w", err)
+		}
+	}
+
+	return len(published), publishErr
+}
@@ -0,0 +1,134 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	transactionWalletsQuery = `SELECT w.id AS wallet_id, w.user_id, .* FROM transactions t .* WHERE t.id = \$1 ORDER BY w.id`
+	insertOutboxEvents      = `WITH events AS \( INSERT INTO outbox_events \(event_type, wallet_id, payload\) VALUES \(\$1, \$2, \$3\) .* INSERT INTO webhook_deliveries \(subscription_id, event_id\)`
+	outboxRelayLockQuery    = `SELECT pg_try_advisory_xact_lock\(\$1\)`
+	unpublishedEventsQuery  = `SELECT id, event_type, wallet_id, payload, created_at FROM outbox_events WHERE published_at IS NULL ORDER BY id LIMIT \$1`
+	markPublishedQuery      = `UPDATE outbox_events SET published_at = NOW\(\) WHERE id IN \(.*\)`
+)
+
+var transactionWalletColumns = []string{
+	"wallet_id", "user_id", "id", "initiator_wallet_user_id", "type", "status", "asset", "amount",
+	"recipient_wallet_user_id", "failure_reason", "parent_transaction_id", "created_at", "balance", "held",
+}
+
+// expectTransactionEvents expects the outbox events of a transaction to be recorded, for a transaction whose wallets are not checked by the test.
+func expectTransactionEvents(mock sqlmock.Sqlmock, transactionID string) {
+	mock.ExpectQuery(transactionWalletsQuery).
+		WithArgs(transactionID).
+		WillReturnRows(sqlmock.NewRows(transactionWalletColumns))
+}
+
+func TestPublishOutboxEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "event_type", "wallet_id", "payload", "created_at"}
+	events := []domainwallet.OutboxEvent{
+		{ID: 1, Type: domainwallet.TransactionCreatedEvent, WalletID: "wallet-1", Payload: []byte(`{"n":1}`), CreatedAt: createdAt},
+		{ID: 2, Type: domainwallet.TransactionCreatedEvent, WalletID: "wallet-2", Payload: []byte(`{"n":2}`), CreatedAt: createdAt},
+		{ID: 3, Type: domainwallet.TransactionCreatedEvent, WalletID: "wallet-1", Payload: []byte(`{"n":3}`), CreatedAt: createdAt},
+	}
+	eventRows := func() *sqlmock.Rows {
+		rows := sqlmock.NewRows(columns)
+		for _, e := range events {
+			rows.AddRow(e.ID, e.Type, e.WalletID, e.Payload, e.CreatedAt)
+		}
+		return rows
+	}
+	errPublish := errors.New("stream unavailable")
+
+	tests := []struct {
+		name              string
+		failAt            int64
+		prepareMock       func()
+		expectedPublished []domainwallet.OutboxEvent
+		expectedCount     int
+		expectedError     error
+	}{
+		{
+			name: "events published in order",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(outboxRelayLockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(unpublishedEventsQuery).WithArgs(10).WillReturnRows(eventRows())
+				mock.ExpectExec(markPublishedQuery).WithArgs(1, 2, 3).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			expectedPublished: events,
+			expectedCount:     3,
+		},
+		{
+			name:   "publishing stops at the first failure",
+			failAt: 2,
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(outboxRelayLockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(unpublishedEventsQuery).WithArgs(10).WillReturnRows(eventRows())
+				mock.ExpectExec(markPublishedQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedPublished: events[:1],
+			expectedCount:     1,
+			expectedError:     errPublish,
+		},
+		{
+			name: "another relay holds the lock",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(outboxRelayLockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "nothing to publish",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(outboxRelayLockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				mock.ExpectQuery(unpublishedEventsQuery).WithArgs(10).WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+
+			var published []domainwallet.OutboxEvent
+			count, err := r.PublishOutboxEvents(context.Background(), 10, func(e domainwallet.OutboxEvent) error {
+				if e.ID == tt.failAt {
+					return errPublish
+				}
+
+				published = append(published, e)
+				return nil
+			})
+
+			assert.Equal(t, tt.expectedPublished, published)
+			assert.Equal(t, tt.expectedCount, count)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// uniqueViolationCode is the Postgres error code of a unique constraint violation.
+const uniqueViolationCode = "23505"
+
+const reconciliationColumns = `id, status, freeze, wallets_checked, mismatches, started_at, finished_at`
+
+// reconcileWalletsQuery recomputes the balances of a page of wallets, in wallet id order after the $1 cursor, from their
+// opening balances and successful transactions next to their stored balances. Opening balances are the ledger journals
+// without transaction, posted for balances that predate the ledger or were seeded. Withdrawals and transfers debit their initiator the amount and fee.
+// A reversal moves its parent's funds back between the same wallets, so it debits the initiator of a deposit and credits
+// the initiator of a withdrawal or transfer the amount and the part of the fee refunded with it. Assets with a stored balance
+// but no transaction, or the other way round, are returned with a zero balance on the missing side, and wallets without
+// either with a NULL asset.
+const reconcileWalletsQuery = `
+	WITH page AS (
+		SELECT id, user_id, status FROM wallets
+		WHERE ($1 = '' OR id > $1::uuid)
+		ORDER BY id
+		LIMIT $2
+	),
+	movements AS (
+		SELECT t.initiator_wallet_id AS wallet_id, t.asset,
+			CASE
+				WHEN t.type = 'deposit' THEN t.amount
+				WHEN t.type IN ('withdraw', 'transfer') THEN -(t.amount + t.fee)
+				WHEN p.type = 'deposit' THEN -t.amount
+				ELSE t.amount + t.fee
+			END AS amount
+		FROM transactions t
+		LEFT JOIN transactions p ON p.id = t.parent_transaction_id
+		WHERE t.status = 'success'
+		AND t.initiator_wallet_id IN (SELECT id FROM page)
+		UNION ALL
+		SELECT t.recipient_wallet_id AS wallet_id, t.asset,
+			CASE WHEN t.type = 'transfer' THEN t.amount ELSE -t.amount END AS amount
+		FROM transactions t
+		WHERE t.status = 'success'
+		AND t.type IN ('transfer', 'reversal')
+		AND t.recipient_wallet_id IN (SELECT id FROM page)
+		UNION ALL
+		SELECT e.wallet_id, e.asset, e.amount
+		FROM ledger_entries e
+		JOIN ledger_journals j ON j.id = e.journal_id
+		WHERE j.transaction_id IS NULL
+		AND e.wallet_id IN (SELECT id FROM page)
+	),
+	computed AS (
+		SELECT wallet_id, asset, SUM(amount)::BIGINT AS balance FROM movements GROUP BY wallet_id, asset
+	),
+	stored AS (
+		SELECT wallet_id, asset, balance FROM balances WHERE wallet_id IN (SELECT id FROM page)
+	)
+	SELECT p.id AS wallet_id, p.user_id, p.status AS wallet_status,
+		COALESCE(s.asset, c.asset) AS asset,
+		COALESCE(s.balance, 0) AS balance,
+		COALESCE(c.balance, 0) AS computed_balance
+	FROM page p
+	LEFT JOIN (
+		stored s FULL OUTER JOIN computed c ON c.wallet_id = s.wallet_id AND c.asset = s.asset
+	) ON p.id = COALESCE(s.wallet_id, c.wallet_id)
+	ORDER BY p.id, asset
+`
+
+type reconciledBalance struct {
+	WalletID        string                    `db:"wallet_id"`
+	UserID          string                    `db:"user_id"`
+	WalletStatus    domainwallet.WalletStatus `db:"wallet_status"`
+	Asset           *string                   `db:"asset"`
+	Balance         int64                     `db:"balance"`
+	ComputedBalance int64                     `db:"computed_balance"`
+}
+
+type reconciliationMismatch struct {
+	ReconciliationID string `db:"reconciliation_id"`
+	domainwallet.BalanceMismatch
+}
+
+// StartReconciliation does the following:
+// 1. Fail runs left running for over an hour, which were interrupted before they could finish
+// 2. Insert a running reconciliation, rejecting it while another one is running
+func (r *Repository) StartReconciliation(ctx context.Context, freeze bool) (domainwallet.Reconciliation, error) {
+	failStale := `
+		UPDATE reconciliations SET status = 'failed', finished_at = NOW()
+		WHERE status = 'running' AND started_at < NOW() - INTERVAL '1 hour'
+	`
+	_, err := r.db.ExecContext(ctx, failStale)
+	if err != nil {
+		return domainwallet.Reconciliation{}, fmt.Errorf("failed to fail stale reconciliations: %w", err)
+	}
+
+	var dst domainwallet.Reconciliation
+	insert := `INSERT INTO reconciliations (freeze) VALUES ($1) RETURNING ` + reconciliationColumns
+	err = r.db.GetContext(ctx, &dst, insert, freeze)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return domainwallet.Reconciliation{}, domainwallet.ErrReconciliationInProgress
+		}
+
+		return domainwallet.Reconciliation{}, fmt.Errorf("failed to insert reconciliation: %w", err)
+	}
+
+	return dst, nil
+}
+
+// ReconcileWallets does the following:
+// 1. Select up to limit wallets in wallet id order after the afterWalletID cursor, from the first wallet when empty
+// 2. Recompute their balances from their opening balances and successful transactions and compare them with their stored balances
+// 3. Return the number of wallets checked, the last wallet id as the cursor of the next page and the mismatched balances
+// Balances are read in a single statement, so the stored balances and the transactions are consistent with each other.
+func (r *Repository) ReconcileWallets(
+	ctx context.Context,
+	afterWalletID string,
+	limit int,
+) (domainwallet.ReconciliationPage, error) {
+	var rows []reconciledBalance
+	err := r.db.SelectContext(ctx, &rows, reconcileWalletsQuery, afterWalletID, limit)
+	if err != nil {
+		return domainwallet.ReconciliationPage{}, fmt.Errorf("failed to reconcile wallets: %w", err)
+	}
+
+	var page domainwallet.ReconciliationPage
+	for _, row := range rows {
+		if row.WalletID != page.LastWalletID {
+			page.WalletsChecked++
+			page.LastWalletID = row.WalletID
+		}
+
+		if row.Asset == nil || row.Balance == row.ComputedBalance {
+			continue
+		}
+
+		page.Mismatches = append(page.Mismatches, domainwallet.BalanceMismatch{
+			WalletID:        row.WalletID,
+			UserID:          row.UserID,
+			WalletStatus:    row.WalletStatus,
+			Asset:           *row.Asset,
+			Balance:         row.Balance,
+			ComputedBalance: row.ComputedBalance,
+		})
+	}
+
+	return page, nil
+}
+
+// RecordReconciliationMismatches inserts the mismatched balances found by the reconciliation.
+func (r *Repository) RecordReconciliationMismatches(
+	ctx context.Context,
+	reconciliationID string,
+	mismatches []domainwallet.BalanceMismatch,
+) error {
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	rows := make([]reconciliationMismatch, 0, len(mismatches))
+	for _, m := range mismatches {
+		rows = append(rows, reconciliationMismatch{ReconciliationID: reconciliationID, BalanceMismatch: m})
+	}
+
+	insert := `
+		INSERT INTO reconciliation_mismatches (reconciliation_id, wallet_id, asset, balance, computed_balance, frozen)
+		VALUES (:reconciliation_id, :wallet_id, :asset, :balance, :computed_balance, :frozen)
+	`
+	_, err := r.db.NamedExecContext(ctx, insert, rows)
+	if err != nil {
+		return fmt.Errorf("failed to insert reconciliation mismatches: %w", err)
+	}
+
+	return nil
+}
+
+// FinishReconciliation records the outcome of a running reconciliation.
+func (r *Repository) FinishReconciliation(
+	ctx context.Context,
+	reconciliationID string,
+	status domainwallet.ReconciliationStatus,
+	walletsChecked, mismatches int,
+) (domainwallet.Reconciliation, error) {
+	var dst domainwallet.Reconciliation
+	update := `
+		UPDATE reconciliations
+		SET status = $2, wallets_checked = $3, mismatches = $4, finished_at = NOW()
+		WHERE id = $1 AND status = 'running'
+		RETURNING ` + reconciliationColumns
+	err := r.db.GetContext(ctx, &dst, update, reconciliationID, status, walletsChecked, mismatches)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Reconciliation{}, fmt.Errorf(
+				"running reconciliation not found: %w",
+				domainwallet.ErrReconciliationNotFound,
+			)
+		}
+
+		return domainwallet.Reconciliation{}, fmt.Errorf("failed to finish reconciliation: %w", err)
+	}
+
+	return dst, nil
+}
+
+// GetReconciliation returns the reconciliation with its mismatched balances, in wallet id order.
+func (r *Repository) GetReconciliation(ctx context.Context, reconciliationID string) (domainwallet.Reconciliation, error) {
+	var dst domainwallet.Reconciliation
+	query := `SELECT ` + reconciliationColumns + ` FROM reconciliations WHERE id = $1`
+	err := r.db.GetContext(ctx, &dst, query, reconciliationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Reconciliation{}, fmt.Errorf(
+				"reconciliation not found: %w",
+				domainwallet.ErrReconciliationNotFound,
+			)
+		}
+
+		return domainwallet.Reconciliation{}, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+
+	queryMismatches := `
+		SELECT m.wallet_id, w.user_id, w.status AS wallet_status, m.asset, m.balance, m.computed_balance, m.frozen
+		FROM reconciliation_mismatches m
+		JOIN wallets w ON w.id = m.wallet_id
+		WHERE m.reconciliation_id = $1
+		ORDER BY m.wallet_id, m.asset
+	`
+	err = r.db.SelectContext(ctx, &dst.Mismatches, queryMismatches, reconciliationID)
+	if err != nil {
+		return domainwallet.Reconciliation{}, fmt.Errorf("failed to get reconciliation mismatches: %w", err)
+	}
+
+	return dst, nil
+}
+
+// ListReconciliations returns the latest reconciliations, most recent first, without their mismatched balances.
+func (r *Repository) ListReconciliations(ctx context.Context, limit int) ([]domainwallet.Reconciliation, error) {
+	var dst []domainwallet.Reconciliation
+	query := `SELECT ` + reconciliationColumns + ` FROM reconciliations ORDER BY started_at DESC LIMIT $1`
+	err := r.db.SelectContext(ctx, &dst, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliations: %w", err)
+	}
+
+	return dst, nil
+}
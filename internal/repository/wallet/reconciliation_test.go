@@ -0,0 +1,364 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	failStaleReconciliationsQuery = `UPDATE reconciliations SET status = 'failed', finished_at = NOW\(\) WHERE status = 'running' AND started_at < NOW\(\) - INTERVAL '1 hour'`
+	insertReconciliationQuery     = `INSERT INTO reconciliations \(freeze\) VALUES \(\$1\) RETURNING id, status, freeze, wallets_checked, mismatches, started_at, finished_at`
+	reconcileWalletsQuery         = `WITH page AS \( SELECT id, user_id, status FROM wallets WHERE \(\$1 = '' OR id > \$1::uuid\) ORDER BY id LIMIT \$2 \), movements AS \(.*\) SELECT p.id AS wallet_id, .* ORDER BY p.id, asset`
+	insertMismatchesQuery         = `INSERT INTO reconciliation_mismatches \(reconciliation_id, wallet_id, asset, balance, computed_balance, frozen\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\),\(\$7, \$8, \$9, \$10, \$11, \$12\)`
+	finishReconciliationQuery     = `UPDATE reconciliations SET status = \$2, wallets_checked = \$3, mismatches = \$4, finished_at = NOW\(\) WHERE id = \$1 AND status = 'running' RETURNING .*`
+	getReconciliationQuery        = `SELECT id, status, freeze, wallets_checked, mismatches, started_at, finished_at FROM reconciliations WHERE id = \$1`
+	getMismatchesQuery            = `SELECT m.wallet_id, w.user_id, w.status AS wallet_status, m.asset, m.balance, m.computed_balance, m.frozen FROM reconciliation_mismatches m .* WHERE m.reconciliation_id = \$1`
+	listReconciliationsQuery      = `SELECT id, status, freeze, wallets_checked, mismatches, started_at, finished_at FROM reconciliations ORDER BY started_at DESC LIMIT \$1`
+)
+
+var reconciliationColumns = []string{"id", "status", "freeze", "wallets_checked", "mismatches", "started_at", "finished_at"}
+
+func TestStartReconciliation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	startedAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                   string
+		prepareMock            func()
+		expectedReconciliation domainwallet.Reconciliation
+		expectedError          error
+	}{
+		{
+			name: "reconciliation started",
+			prepareMock: func() {
+				mock.ExpectExec(failStaleReconciliationsQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(insertReconciliationQuery).
+					WithArgs(true).
+					WillReturnRows(sqlmock.NewRows(reconciliationColumns).
+						AddRow("rec-1", "running", true, 0, 0, startedAt, nil))
+			},
+			expectedReconciliation: domainwallet.Reconciliation{
+				ID:        "rec-1",
+				Status:    domainwallet.ReconciliationRunning,
+				Freeze:    true,
+				StartedAt: startedAt,
+			},
+		},
+		{
+			name: "another reconciliation running",
+			prepareMock: func() {
+				mock.ExpectExec(failStaleReconciliationsQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(insertReconciliationQuery).
+					WithArgs(true).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			expectedError: domainwallet.ErrReconciliationInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			reconciliation, err := r.StartReconciliation(context.Background(), true)
+			assert.Equal(t, tt.expectedReconciliation, reconciliation)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReconcileWallets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	columns := []string{"wallet_id", "user_id", "wallet_status", "asset", "balance", "computed_balance"}
+	errDB := errors.New("db down")
+
+	tests := []struct {
+		name          string
+		after         string
+		prepareMock   func()
+		expectedPage  domainwallet.ReconciliationPage
+		expectedError error
+	}{
+		{
+			name: "mismatched balances",
+			prepareMock: func() {
+				mock.ExpectQuery(reconcileWalletsQuery).
+					WithArgs("", 3).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("wallet-1", "user123", "active", "BTC", 5, 5).
+						AddRow("wallet-1", "user123", "active", "USD", 1500, 1000).
+						AddRow("wallet-2", "user456", "frozen", nil, 0, 0).
+						AddRow("wallet-3", "user789", "active", "USDT", 0, 20))
+			},
+			expectedPage: domainwallet.ReconciliationPage{
+				WalletsChecked: 3,
+				LastWalletID:   "wallet-3",
+				Mismatches: []domainwallet.BalanceMismatch{
+					{WalletID: "wallet-1", UserID: "user123", WalletStatus: domainwallet.Active, Asset: "USD", Balance: 1500, ComputedBalance: 1000},
+					{WalletID: "wallet-3", UserID: "user789", WalletStatus: domainwallet.Active, Asset: "USDT", ComputedBalance: 20},
+				},
+			},
+		},
+		{
+			name: "opening balance without transactions",
+			prepareMock: func() {
+				mock.ExpectQuery(`movements AS \(.* UNION ALL SELECT e.wallet_id, e.asset, e.amount FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id WHERE j.transaction_id IS NULL AND e.wallet_id IN \(SELECT id FROM page\) \)`).
+					WithArgs("", 3).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("wallet-4", "user321", "active", "USD", 10000, 10000))
+			},
+			expectedPage: domainwallet.ReconciliationPage{
+				WalletsChecked: 1,
+				LastWalletID:   "wallet-4",
+			},
+		},
+		{
+			name:  "last page",
+			after: "wallet-3",
+			prepareMock: func() {
+				mock.ExpectQuery(reconcileWalletsQuery).
+					WithArgs("wallet-3", 3).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name: "query error",
+			prepareMock: func() {
+				mock.ExpectQuery(reconcileWalletsQuery).
+					WithArgs("", 3).
+					WillReturnError(errDB)
+			},
+			expectedError: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			page, err := r.ReconcileWallets(context.Background(), tt.after, 3)
+			assert.Equal(t, tt.expectedPage, page)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecordReconciliationMismatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	mismatches := []domainwallet.BalanceMismatch{
+		{WalletID: "wallet-1", Asset: "USD", Balance: 1500, ComputedBalance: 1000, Frozen: true},
+		{WalletID: "wallet-3", Asset: "USDT", ComputedBalance: 20},
+	}
+
+	tests := []struct {
+		name        string
+		mismatches  []domainwallet.BalanceMismatch
+		prepareMock func()
+	}{
+		{
+			name:       "mismatches recorded",
+			mismatches: mismatches,
+			prepareMock: func() {
+				mock.ExpectExec(insertMismatchesQuery).
+					WithArgs("rec-1", "wallet-1", "USD", 1500, 1000, true, "rec-1", "wallet-3", "USDT", 0, 20, false).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			name:        "nothing to record",
+			prepareMock: func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			err := r.RecordReconciliationMismatches(context.Background(), "rec-1", tt.mismatches)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestFinishReconciliation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	startedAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Minute)
+
+	tests := []struct {
+		name                   string
+		prepareMock            func()
+		expectedReconciliation domainwallet.Reconciliation
+		expectedError          error
+	}{
+		{
+			name: "reconciliation completed",
+			prepareMock: func() {
+				mock.ExpectQuery(finishReconciliationQuery).
+					WithArgs("rec-1", domainwallet.ReconciliationCompleted, 3, 2).
+					WillReturnRows(sqlmock.NewRows(reconciliationColumns).
+						AddRow("rec-1", "completed", false, 3, 2, startedAt, finishedAt))
+			},
+			expectedReconciliation: domainwallet.Reconciliation{
+				ID:             "rec-1",
+				Status:         domainwallet.ReconciliationCompleted,
+				WalletsChecked: 3,
+				MismatchCount:  2,
+				StartedAt:      startedAt,
+				FinishedAt:     &finishedAt,
+			},
+		},
+		{
+			name: "reconciliation no longer running",
+			prepareMock: func() {
+				mock.ExpectQuery(finishReconciliationQuery).
+					WithArgs("rec-1", domainwallet.ReconciliationCompleted, 3, 2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrReconciliationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			reconciliation, err := r.FinishReconciliation(
+				context.Background(), "rec-1", domainwallet.ReconciliationCompleted, 3, 2,
+			)
+			assert.Equal(t, tt.expectedReconciliation, reconciliation)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetReconciliation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	startedAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Minute)
+	mismatchColumns := []string{"wallet_id", "user_id", "wallet_status", "asset", "balance", "computed_balance", "frozen"}
+
+	tests := []struct {
+		name                   string
+		prepareMock            func()
+		expectedReconciliation domainwallet.Reconciliation
+		expectedError          error
+	}{
+		{
+			name: "reconciliation with mismatches",
+			prepareMock: func() {
+				mock.ExpectQuery(getReconciliationQuery).
+					WithArgs("rec-1").
+					WillReturnRows(sqlmock.NewRows(reconciliationColumns).
+						AddRow("rec-1", "completed", true, 3, 1, startedAt, finishedAt))
+				mock.ExpectQuery(getMismatchesQuery).
+					WithArgs("rec-1").
+					WillReturnRows(sqlmock.NewRows(mismatchColumns).
+						AddRow("wallet-1", "user123", "frozen", "USD", 1500, 1000, true))
+			},
+			expectedReconciliation: domainwallet.Reconciliation{
+				ID:             "rec-1",
+				Status:         domainwallet.ReconciliationCompleted,
+				Freeze:         true,
+				WalletsChecked: 3,
+				MismatchCount:  1,
+				StartedAt:      startedAt,
+				FinishedAt:     &finishedAt,
+				Mismatches: []domainwallet.BalanceMismatch{
+					{
+						WalletID:        "wallet-1",
+						UserID:          "user123",
+						WalletStatus:    domainwallet.Frozen,
+						Asset:           "USD",
+						Balance:         1500,
+						ComputedBalance: 1000,
+						Frozen:          true,
+					},
+				},
+			},
+		},
+		{
+			name: "reconciliation not found",
+			prepareMock: func() {
+				mock.ExpectQuery(getReconciliationQuery).
+					WithArgs("rec-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrReconciliationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			reconciliation, err := r.GetReconciliation(context.Background(), "rec-1")
+			assert.Equal(t, tt.expectedReconciliation, reconciliation)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListReconciliations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	startedAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(listReconciliationsQuery).
+		WithArgs(20).
+		WillReturnRows(sqlmock.NewRows(reconciliationColumns).
+			AddRow("rec-2", "running", false, 0, 0, startedAt, nil))
+
+	reconciliations, err := r.ListReconciliations(context.Background(), 20)
+	assert.NoError(t, err)
+	assert.Equal(t, []domainwallet.Reconciliation{
+		{ID: "rec-2", Status: domainwallet.ReconciliationRunning, StartedAt: startedAt},
+	}, reconciliations)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
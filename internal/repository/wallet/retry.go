@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+const (
+	maxTxAttempts    = 3
+	txRetryBaseDelay = 20 * time.Millisecond
+)
+
+// Postgres error codes of transactions that are safe to retry from the start.
+const (
+	deadlockDetectedCode     = "40P01"
+	serializationFailureCode = "40001"
+)
+
+var (
+	// txRetries counts db transactions retried after a deadlock or serialization failure, keyed by {operation}.{sqlstate}.
+	txRetries = expvar.NewMap("wallet_tx_retries")
+	// txRetriesExhausted counts db transactions that still failed after the last attempt, keyed by {operation}.{sqlstate}.
+	txRetriesExhausted = expvar.NewMap("wallet_tx_retries_exhausted")
+)
+
+// retryableTxCode returns the Postgres error code of err if the db transaction it failed can be retried.
+func retryableTxCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	switch pgErr.Code {
+	case deadlockDetectedCode, serializationFailureCode:
+		return pgErr.Code, true
+	default:
+		return "", false
+	}
+}
+
+// withTxRetry runs a db transaction, retrying it with jittered exponential backoff on deadlocks and
+// serialization failures up to maxTxAttempts. Each attempt must begin and roll back its own db transaction.
+func (r *Repository) withTxRetry(
+	ctx context.Context,
+	operation domainwallet.IdempotentOperation,
+	attempt func() (domainwallet.IdempotentResponse, error),
+) (domainwallet.IdempotentResponse, error) {
+	for i := 1; ; i++ {
+		resp, err := attempt()
+		code, retryable := retryableTxCode(err)
+		if !retryable {
+			return resp, err
+		}
+
+		metricKey := string(operation) + "." + code
+		if i == maxTxAttempts {
+			txRetriesExhausted.Add(metricKey, 1)
+			return resp, err
+		}
+
+		txRetries.Add(metricKey, 1)
+		delay := rand.N(txRetryBaseDelay << (i - 1))
+		r.logger.Warn("retrying db transaction",
+			slog.String("operation", string(operation)),
+			slog.String("code", code),
+			slog.Int("attempt", i),
+			slog.Duration("backoff", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return domainwallet.IdempotentResponse{}, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// reverseCacheKey is scoped like the admin idempotency keys, unique across admins and transactions, so a key reused on
+// another transaction replays nothing and is rejected as reused.
+const reverseCacheKey = `reverse-admin-%s` // reverse-admin-idempotencyKey
+
+// lockWalletsByIDQuery holds row-level locks on up to two wallets by id in a single statement and reads their balances of a single asset.
+// Rows are locked in wallet id order, like lockWalletPairQuery.
+const lockWalletsByIDQuery = `
+	SELECT w.id, w.user_id, w.status, COALESCE(b.balance, 0) AS balance, COALESCE(b.held, 0) AS held
+	FROM wallets w
+	LEFT JOIN balances b ON b.wallet_id = w.id AND b.asset = $3
+	WHERE w.id IN ($1, $2)
+	ORDER BY w.id
+	FOR UPDATE OF w
+`
+
+type parentTransaction struct {
+	ID                string                         `db:"id"`
+	InitiatorWalletID string                         `db:"initiator_wallet_id"`
+	RecipientWalletID *string                        `db:"recipient_wallet_id"`
+	Type              domainwallet.TransactionType   `db:"type"`
+	Status            domainwallet.TransactionStatus `db:"status"`
+	Asset             string                         `db:"asset"`
+	Amount            uint64                         `db:"amount"`
+	Fee               uint64                         `db:"fee"`
+}
+
+// reversedTotals are the amount reversed out of a transaction so far and the part of its fee refunded with it.
+type reversedTotals struct {
+	Amount uint64 `db:"amount"`
+	Fee    uint64 `db:"fee"`
+}
+
+// ReverseTransaction does the following:
+// 1. Check from redis cache on key = reverse-admin-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock the wallets of the transaction in wallet id order and check the idempotency key recorded in postgres
+// 3. Move amount back in the opposite direction of the transaction, or its whole remainder when amount is zero, rejecting closed wallets or funds already spent
+// 4. Refund the initiator the fee of the transaction in proportion to the amount reversed so far, from the house fee account, see domainwallet.ReversalFee
+// 5. Insert the reversal transaction linked to its parent with the refunded fee, post the balanced ledger journal and record the response on the idempotency key in the same db transaction
+// 6. Retry the db transaction with jittered backoff on deadlock or serialization failure
+// Idempotency keys are recorded in the admin scope, unique across admins, with the transaction's initiator user. No money is moved on rejection, so rejected reversals are not recorded as failed transactions.
+func (r *Repository) ReverseTransaction(
+	ctx context.Context,
+	transactionID string,
+	key domainwallet.IdempotencyKey,
+	amount uint64,
+) (domainwallet.IdempotentResponse, error) {
+	cacheKey := fmt.Sprintf(reverseCacheKey, key.Key)
+	// Idempotent: already processed
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
+	}
+
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+	defer release()
+
+	return r.withTxRetry(ctx, domainwallet.ReverseOperation, func() (domainwallet.IdempotentResponse, error) {
+		return r.reverseTransaction(ctx, cacheKey, transactionID, key, amount)
+	})
+}
+
+// reverseTransaction runs a single attempt of the reversal db transaction.
+func (r *Repository) reverseTransaction(
+	ctx context.Context,
+	cacheKey, transactionID string,
+	key domainwallet.IdempotencyKey,
+	amount uint64,
+) (domainwallet.IdempotentResponse, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var parent parentTransaction
+	err = tx.GetContext(
+		ctx,
+		&parent,
+		`SELECT id, initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee FROM transactions WHERE id = $1`,
+		transactionID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("transaction %s: %w", transactionID, domainwallet.ErrTransactionNotFound)
+		}
+
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	recipientWalletID := parent.InitiatorWalletID
+	if parent.RecipientWalletID != nil {
+		recipientWalletID = *parent.RecipientWalletID
+	}
+
+	// Hold row-level lock on the wallets of the transaction, which also serializes concurrent reversals of it
+	var dbWallets []userWallet
+	err = tx.SelectContext(ctx, &dbWallets, lockWalletsByIDQuery, parent.InitiatorWalletID, recipientWalletID, parent.Asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
+			"failed to hold row-level lock on wallets: %w, transactionID: %s",
+			err,
+			transactionID,
+		)
+	}
+
+	walletsByID := make(map[string]*userWallet, len(dbWallets))
+	for i := range dbWallets {
+		walletsByID[dbWallets[i].ID] = &dbWallets[i]
+	}
+
+	initiatorWallet, ok := walletsByID[parent.InitiatorWalletID]
+	if !ok {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+	}
+
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupAdminIdempotencyKey(ctx, tx, cacheKey, key); err != nil || found {
+		return resp, err
+	}
+
+	if err := parent.Type.CanReverse(parent.Status); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transaction %s: %w", transactionID, err)
+	}
+
+	var reversed reversedTotals
+	err = tx.GetContext(
+		ctx,
+		&reversed,
+		`SELECT COALESCE(SUM(amount), 0) AS amount, COALESCE(SUM(fee), 0) AS fee FROM transactions WHERE parent_transaction_id = $1 AND status = $2`,
+		parent.ID,
+		domainwallet.Success,
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to sum reversed amount: %w", err)
+	}
+
+	amount, err = domainwallet.ReversalAmount(parent.Amount, reversed.Amount, amount)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transaction %s: %w", transactionID, err)
+	}
+
+	refundedFee := domainwallet.ReversalFee(parent.Amount, parent.Fee, reversed.Amount, reversed.Fee, amount)
+
+	from, to, err := domainwallet.TransactionAccounts(parent.Type, parent.InitiatorWalletID, parent.RecipientWalletID)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	// Funds move back from the account the transaction credited into the one it debited
+	journal, err := domainwallet.NewJournal(parent.Asset, amount, to, from)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+	}
+
+	for _, entry := range journal {
+		if entry.WalletID == nil {
+			continue
+		}
+
+		dbWallet, ok := walletsByID[*entry.WalletID]
+		if !ok {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		// Reversals correct mistakes, so frozen wallets can be reversed in both directions but closed wallets cannot
+		if err := dbWallet.Status.CanCredit(); err != nil {
+			return domainwallet.IdempotentResponse{}, err
+		}
+
+		if entry.Amount > 0 {
+			_, err = tx.ExecContext(ctx, creditBalanceQuery, dbWallet.ID, parent.Asset, amount)
+			if err != nil {
+				return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
+			}
+
+			continue
+		}
+
+		// The credited wallet may have spent the funds since
+		if dbWallet.available() < amount {
+			return domainwallet.IdempotentResponse{}, domainwallet.ErrReversalFundsSpent
+		}
+
+		_, err = tx.ExecContext(ctx, debitBalanceQuery, amount, dbWallet.ID, parent.Asset)
+		if err != nil {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
+		}
+	}
+
+	// The initiator paid the fee, its refund moves back from the house fee account
+	if refundedFee > 0 {
+		feeJournal, err := domainwallet.NewJournal(
+			parent.Asset,
+			refundedFee,
+			domainwallet.SystemLedgerAccount(domainwallet.Fees),
+			domainwallet.WalletAccount(parent.InitiatorWalletID),
+		)
+		if err != nil {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, creditBalanceQuery, parent.InitiatorWalletID, parent.Asset, refundedFee)
+		if err != nil {
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		journal = append(journal, feeJournal...)
+	}
+
+	// Insert reversal transaction record between the same wallets as its parent
+	var reversalID string
+	insertTxn := `
+		INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, parent_transaction_id, type, status, asset, amount, fee, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING id
+	`
+	err = tx.GetContext(
+		ctx,
+		&reversalID,
+		insertTxn,
+		parent.InitiatorWalletID,
+		parent.RecipientWalletID,
+		parent.ID,
+		domainwallet.Reversal,
+		domainwallet.Success,
+		parent.Asset,
+		amount,
+		refundedFee,
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert transaction record: %w", err)
+	}
+
+	// Post balanced ledger entries for the reversal
+	if err := postJournal(ctx, tx, reversalID, journal); err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	// Record the response on the idempotency key and commit transaction
+	refund := domainwallet.FeeBreakdown{Asset: parent.Asset, Amount: amount, Fee: refundedFee, Total: amount + refundedFee}
+
+	return r.commitIdempotent(ctx, tx, cacheKey, initiatorWallet.UserID, domainwallet.ReverseOperation, key, reversalID, refund, nil)
+}
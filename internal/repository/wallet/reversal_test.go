@@ -0,0 +1,463 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgconn"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+)
+
+const (
+	parentTransactionQuery   = `SELECT id, initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee FROM transactions WHERE id = \$1`
+	lockWalletsByIDQuery     = `SELECT w.id, w.user_id, w.status, COALESCE\(b.balance, 0\) AS balance, COALESCE\(b.held, 0\) AS held FROM wallets w .* WHERE w.id IN \(\$1, \$2\) ORDER BY w.id FOR UPDATE OF w`
+	reversedAmountQuery      = `SELECT COALESCE\(SUM\(amount\), 0\) AS amount, COALESCE\(SUM\(fee\), 0\) AS fee FROM transactions WHERE parent_transaction_id = \$1 AND status = \$2`
+	adminIdempotencyKeyQuery = `SELECT request_hash, response_status, response_body FROM idempotency_keys WHERE idempotency_key = \$1 AND scope = 'admin'`
+	insertReversalTxn        = `INSERT INTO transactions \(initiator_wallet_id, recipient_wallet_id, parent_transaction_id, type, status, asset, amount, fee, created_at\)`
+)
+
+var (
+	parentTransactionColumns = []string{"id", "initiator_wallet_id", "recipient_wallet_id", "type", "status", "asset", "amount", "fee"}
+	lockedWalletColumns      = []string{"id", "user_id", "balance", "held", "status"}
+	reversedTotalsColumns    = []string{"amount", "fee"}
+)
+
+func TestReverseTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	redisClient, redisMock := redismock.NewClientMock()
+	logger := slog.Default()
+	repo := wallet.New(sqlxDB, redisClient, logger)
+
+	tests := []struct {
+		name             string
+		transactionID    string
+		idempotencyKey   string
+		amount           uint64
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
+	}{
+		{
+			name:           "already processed idempotent request",
+			transactionID:  "tx1",
+			idempotencyKey: "idem1",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem1").SetVal(cachedRecord("hash-idem1", okResponse("rev-already")))
+			},
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("rev-already"),
+		},
+		{
+			name:           "full reversal of transfer",
+			transactionID:  "tx2",
+			idempotencyKey: "idem2",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem2").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem2").SetVal(true)
+				redisMock.ExpectSet("reverse-admin-idem2", cachedRecord("hash-idem2", okResponse("rev2")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "reverse-admin-idem2")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx2").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx2", "wallet1", "wallet2", "transfer", "success", "USD", 500, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet2", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).
+						AddRow("wallet1", "user1", 0, 0, "active").
+						AddRow("wallet2", "user2", 500, 0, "frozen"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem2").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx2", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(0, 0))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(500, "wallet2", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet1", "USD", 500).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertReversalTxn).
+					WithArgs("wallet1", "wallet2", "tx2", domainwallet.Reversal, domainwallet.Success, "USD", 500, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("rev2"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("rev2").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal2"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal2", "wallet2", nil, "USD", -500, "journal2", "wallet1", nil, "USD", 500).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				expectTransactionEvents(mock, "rev2")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user1", "idem2", domainwallet.ReverseOperation, "hash-idem2", "rev2", nil, 200, []byte("rev2")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("rev2"),
+		},
+		{
+			name:           "admin key recorded meanwhile for another transaction",
+			transactionID:  "tx11",
+			idempotencyKey: "idem11",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem11").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem11").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem11")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx11").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx11", "wallet11", nil, "deposit", "success", "USD", 100, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet11", "wallet11", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet11", "user11", 100, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem11").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx11", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(0, 0))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(100, "wallet11", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertReversalTxn).
+					WithArgs("wallet11", nil, "tx11", domainwallet.Reversal, domainwallet.Success, "USD", 100, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("rev11"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("rev11").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal11"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal11", "wallet11", nil, "USD", -100, "journal11", nil, "external_cash_in", "USD", 100).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				expectTransactionEvents(mock, "rev11")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user11", "idem11", domainwallet.ReverseOperation, "hash-idem11", "rev11", nil, 200, []byte("rev11")).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrIdempotencyKeyReused,
+		},
+		{
+			name:           "partial refund of partially refunded deposit",
+			transactionID:  "tx3",
+			idempotencyKey: "idem3",
+			amount:         40,
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem3").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem3").SetVal(true)
+				redisMock.ExpectSet("reverse-admin-idem3", cachedRecord("hash-idem3", okResponse("rev3")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "reverse-admin-idem3")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx3").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx3", "wallet3", nil, "deposit", "success", "BTC", 100, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet3", "wallet3", "BTC").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet3", "user3", 100, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem3").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx3", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(60, 0))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(40, "wallet3", "BTC").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertReversalTxn).
+					WithArgs("wallet3", nil, "tx3", domainwallet.Reversal, domainwallet.Success, "BTC", 40, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("rev3"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("rev3").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal3"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal3", "wallet3", nil, "BTC", -40, "journal3", nil, "external_cash_in", "BTC", 40).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				expectTransactionEvents(mock, "rev3")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user3", "idem3", domainwallet.ReverseOperation, "hash-idem3", "rev3", nil, 200, []byte("rev3")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("rev3"),
+		},
+		{
+			name:           "full reversal of withdrawal refunds the fee",
+			transactionID:  "tx9",
+			idempotencyKey: "idem9",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem9").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem9").SetVal(true)
+				redisMock.ExpectSet("reverse-admin-idem9", cachedRecord("hash-idem9", okResponse("rev9 fee 25 of 1025")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "reverse-admin-idem9")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx9").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx9", "wallet9", nil, "withdraw", "success", "USD", 1000, 25))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet9", "wallet9", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet9", "user9", 0, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem9").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx9", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(0, 0))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet9", "USD", 1000).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet9", "USD", 25).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertReversalTxn).
+					WithArgs("wallet9", nil, "tx9", domainwallet.Reversal, domainwallet.Success, "USD", 1000, 25).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("rev9"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("rev9").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal9"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal9", nil, "external_cash_out", "USD", -1000, "journal9", "wallet9", nil, "USD", 1000,
+						"journal9", nil, "fees", "USD", -25, "journal9", "wallet9", nil, "USD", 25).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+				expectTransactionEvents(mock, "rev9")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user9", "idem9", domainwallet.ReverseOperation, "hash-idem9", "rev9", nil, 200, []byte("rev9 fee 25 of 1025")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("rev9 fee 25 of 1025"),
+		},
+		{
+			name:           "partial refund of partially refunded transfer refunds its share of the fee",
+			transactionID:  "tx10",
+			idempotencyKey: "idem10",
+			amount:         300,
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem10").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem10").SetVal(true)
+				redisMock.ExpectSet("reverse-admin-idem10", cachedRecord("hash-idem10", okResponse("rev10 fee 8 of 308")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "reverse-admin-idem10")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx10").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx10", "wallet1", "wallet2", "transfer", "success", "USD", 1000, 25))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet2", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).
+						AddRow("wallet1", "user1", 0, 0, "active").
+						AddRow("wallet2", "user2", 700, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem10").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx10", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(300, 7))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(300, "wallet2", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet1", "USD", 300).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet1", "USD", 8).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertReversalTxn).
+					WithArgs("wallet1", "wallet2", "tx10", domainwallet.Reversal, domainwallet.Success, "USD", 300, 8).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("rev10"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("rev10").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal10"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal10", "wallet2", nil, "USD", -300, "journal10", "wallet1", nil, "USD", 300,
+						"journal10", nil, "fees", "USD", -8, "journal10", "wallet1", nil, "USD", 8).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+				expectTransactionEvents(mock, "rev10")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user1", "idem10", domainwallet.ReverseOperation, "hash-idem10", "rev10", nil, 200, []byte("rev10 fee 8 of 308")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: okResponse("rev10 fee 8 of 308"),
+		},
+		{
+			name:           "recipient already spent the funds",
+			transactionID:  "tx4",
+			idempotencyKey: "idem4",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem4").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem4").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem4")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx4").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx4", "wallet1", "wallet2", "transfer", "success", "USD", 500, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet2", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).
+						AddRow("wallet1", "user1", 0, 0, "active").
+						AddRow("wallet2", "user2", 500, 200, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem4").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx4", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrReversalFundsSpent,
+		},
+		{
+			name:           "already fully reversed",
+			transactionID:  "tx5",
+			idempotencyKey: "idem5",
+			amount:         1,
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem5").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem5").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem5")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx5").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx5", "wallet1", nil, "withdraw", "success", "USD", 500, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet1", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet1", "user1", 0, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem5").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(reversedAmountQuery).
+					WithArgs("tx5", domainwallet.Success).
+					WillReturnRows(sqlmock.NewRows(reversedTotalsColumns).AddRow(500, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrReversalExceedsAmount,
+		},
+		{
+			name:           "failed transaction",
+			transactionID:  "tx6",
+			idempotencyKey: "idem6",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem6").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem6").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem6")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx6").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx6", "wallet1", nil, "withdraw", "failed", "USD", 500, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet1", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet1", "user1", 0, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem6").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrTransactionNotReversible,
+		},
+		{
+			name:           "idempotency key recorded by an admin for a different reversal",
+			transactionID:  "tx8",
+			idempotencyKey: "idem8",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem8").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem8").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem8")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx8").
+					WillReturnRows(sqlmock.NewRows(parentTransactionColumns).
+						AddRow("tx8", "wallet1", nil, "withdraw", "success", "USD", 500, 0))
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet1", "USD").
+					WillReturnRows(sqlmock.NewRows(lockedWalletColumns).AddRow("wallet1", "user1", 0, 0, "active"))
+				mock.ExpectQuery(adminIdempotencyKeyQuery).
+					WithArgs("idem8").
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("other-hash", 200, []byte("rev-other")))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrIdempotencyKeyReused,
+		},
+		{
+			name:           "transaction not found",
+			transactionID:  "tx7",
+			idempotencyKey: "idem7",
+			prepareRedis: func() {
+				redisMock.ExpectGet("reverse-admin-idem7").RedisNil()
+				expectReserveInFlight(redisMock, "reverse-admin-idem7").SetVal(true)
+				expectReleaseInFlight(redisMock, "reverse-admin-idem7")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(parentTransactionQuery).
+					WithArgs("tx7").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrTransactionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareRedis()
+			tt.prepareSQL()
+
+			resp, err := repo.ReverseTransaction(
+				context.Background(),
+				tt.transactionID,
+				testIdempotencyKey(tt.idempotencyKey),
+				tt.amount,
+			)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.NoError(t, redisMock.ExpectationsWereMet())
+		})
+	}
+}
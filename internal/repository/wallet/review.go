@@ -0,0 +1,292 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jmoiron/sqlx"
+)
+
+// transactionReviewsQuery selects reviews with their transaction and both parties' user IDs, filtered by the caller.
+const transactionReviewsQuery = `
+	SELECT t.id, iw.user_id AS initiator_wallet_user_id, t.type, t.status, t.asset, t.amount,
+		rw.user_id AS recipient_wallet_user_id, t.failure_reason, t.parent_transaction_id, t.created_at,
+		t.initiator_wallet_id, t.recipient_wallet_id, t.fee, v.rule, v.decision, v.decided_at
+	FROM transaction_reviews v
+	JOIN transactions t ON t.id = v.transaction_id
+	JOIN wallets iw ON iw.id = t.initiator_wallet_id
+	LEFT JOIN wallets rw ON rw.id = t.recipient_wallet_id
+`
+
+type reviewedTransaction struct {
+	domainwallet.TransactionReview
+	InitiatorWalletID string  `db:"initiator_wallet_id"`
+	RecipientWalletID *string `db:"recipient_wallet_id"`
+}
+
+// holdForReview does the following when a fraud rule flags a withdrawal or transfer:
+// 1. Reserve its amount and fee and insert it pending review, see insertPendingReview
+// 2. Record the ErrTransactionPendingReview response on the idempotency key and commit the db transaction
+// No balance is moved and no journal is posted until the review is approved.
+func (r *Repository) holdForReview(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	cacheKey, userID string,
+	key domainwallet.IdempotencyKey,
+	txn attemptedTransaction,
+	fee domainwallet.FeeBreakdown,
+	rule string,
+) (domainwallet.IdempotentResponse, error) {
+	transactionID, err := insertPendingReview(ctx, tx, txn, fee, rule)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	return r.commitIdempotent(
+		ctx,
+		tx,
+		cacheKey,
+		userID,
+		txn.Operation,
+		key,
+		transactionID,
+		fee,
+		domainwallet.ErrTransactionPendingReview,
+	)
+}
+
+// insertPendingReview does the following:
+// 1. Reserve the amount and fee of the transaction on the initiator wallet, so they cannot be spent while ops review it
+// 2. Insert the transaction record with status pending_review and its fee, and its review with the rule that flagged it
+func insertPendingReview(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	txn attemptedTransaction,
+	fee domainwallet.FeeBreakdown,
+	rule string,
+) (string, error) {
+	_, err := tx.ExecContext(ctx, holdBalanceQuery, fee.Total, txn.InitiatorWalletID, txn.Asset)
+	if err != nil {
+		return "", fmt.Errorf("failed to update held balance: %w", err)
+	}
+
+	var transactionID string
+	insertTxn := `
+		INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id
+	`
+	err = tx.GetContext(
+		ctx,
+		&transactionID,
+		insertTxn,
+		txn.InitiatorWalletID,
+		txn.RecipientWalletID,
+		txn.Type,
+		domainwallet.PendingReview,
+		txn.Asset,
+		txn.Amount,
+		fee.Fee,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert pending review transaction record: %w", err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO transaction_reviews (transaction_id, rule, created_at) VALUES ($1, $2, NOW())`,
+		transactionID,
+		rule,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert transaction review: %w", err)
+	}
+
+	return transactionID, nil
+}
+
+// ListTransactionReviews returns up to limit transactions pending review, oldest first.
+func (r *Repository) ListTransactionReviews(ctx context.Context, limit int) ([]domainwallet.TransactionReview, error) {
+	var rows []reviewedTransaction
+	query := transactionReviewsQuery + ` WHERE v.decision IS NULL ORDER BY v.created_at, v.transaction_id LIMIT $1`
+	if err := r.db.SelectContext(ctx, &rows, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to select transaction reviews: %w", err)
+	}
+
+	reviews := make([]domainwallet.TransactionReview, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, row.TransactionReview)
+	}
+
+	return reviews, nil
+}
+
+// DecideTransactionReview does the following:
+// 1. Lock the review, then the wallets of its transaction in wallet id order, rejecting reviews already decided
+// 2. Release the amount and fee reserved on the initiator wallet
+// 3. On approval, move the amount and fee as the transaction would have, post its balanced ledger journal and mark it successful,
+// rejecting frozen or closed wallets and leaving the review pending
+// 4. On rejection, mark the transaction failed with reason review_rejected, no balance is moved
+// 5. Record the decision and a TransactionReviewedEvent per wallet of the transaction in the outbox, then commit
+func (r *Repository) DecideTransactionReview(
+	ctx context.Context,
+	transactionID string,
+	decision domainwallet.ReviewDecision,
+) (domainwallet.TransactionReview, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var review reviewedTransaction
+	err = tx.GetContext(ctx, &review, transactionReviewsQuery+` WHERE v.transaction_id = $1 FOR UPDATE OF v`, transactionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.TransactionReview{}, fmt.Errorf("transaction %s: %w", transactionID, domainwallet.ErrReviewNotFound)
+		}
+
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to get transaction review: %w", err)
+	}
+
+	if err := review.CanDecide(); err != nil {
+		return domainwallet.TransactionReview{}, err
+	}
+
+	recipientWalletID := review.InitiatorWalletID
+	if review.RecipientWalletID != nil {
+		recipientWalletID = *review.RecipientWalletID
+	}
+
+	// Hold row-level lock on the wallets of the transaction
+	var dbWallets []userWallet
+	err = tx.SelectContext(ctx, &dbWallets, lockWalletsByIDQuery, review.InitiatorWalletID, recipientWalletID, review.Asset)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf(
+			"failed to hold row-level lock on wallets: %w, transactionID: %s",
+			err,
+			transactionID,
+		)
+	}
+
+	walletsByID := make(map[string]*userWallet, len(dbWallets))
+	for i := range dbWallets {
+		walletsByID[dbWallets[i].ID] = &dbWallets[i]
+	}
+
+	initiatorWallet, ok := walletsByID[review.InitiatorWalletID]
+	if !ok {
+		return domainwallet.TransactionReview{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+	}
+
+	fee := review.FeeBreakdown()
+	_, err = tx.ExecContext(ctx, releaseHeldQuery, fee.Total, initiatorWallet.ID, review.Asset)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to update held balance: %w", err)
+	}
+
+	switch decision {
+	case domainwallet.ReviewApproved:
+		if err := r.executeReviewed(ctx, tx, review, walletsByID); err != nil {
+			return domainwallet.TransactionReview{}, err
+		}
+	case domainwallet.ReviewRejected:
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE transactions SET status = $1, failure_reason = $2 WHERE id = $3`,
+			domainwallet.Failed,
+			domainwallet.FailureReviewRejected,
+			transactionID,
+		)
+		if err != nil {
+			return domainwallet.TransactionReview{}, fmt.Errorf("failed to update transaction status: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE transaction_reviews SET decision = $1, decided_at = NOW() WHERE transaction_id = $2`,
+		decision,
+		transactionID,
+	)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to update transaction review: %w", err)
+	}
+
+	if err := recordTransactionEvents(ctx, tx, domainwallet.TransactionReviewedEvent, transactionID); err != nil {
+		return domainwallet.TransactionReview{}, err
+	}
+
+	err = tx.GetContext(ctx, &review, transactionReviewsQuery+` WHERE v.transaction_id = $1`, transactionID)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to get transaction review: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	return review.TransactionReview, nil
+}
+
+// executeReviewed moves the amount and fee of an approved transaction from its initiator wallet, posts its balanced
+// ledger journal and marks it successful. Its funds were reserved while pending, so the balance covers them.
+func (r *Repository) executeReviewed(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	review reviewedTransaction,
+	walletsByID map[string]*userWallet,
+) error {
+	initiatorWallet := walletsByID[review.InitiatorWalletID]
+	if err := initiatorWallet.Status.CanDebit(); err != nil {
+		return err
+	}
+
+	var recipientWallet *userWallet
+	if review.RecipientWalletID != nil {
+		var ok bool
+		recipientWallet, ok = walletsByID[*review.RecipientWalletID]
+		if !ok {
+			return fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+		}
+
+		if err := recipientWallet.Status.CanCredit(); err != nil {
+			return err
+		}
+	}
+
+	from, to, err := domainwallet.TransactionAccounts(review.Type, review.InitiatorWalletID, review.RecipientWalletID)
+	if err != nil {
+		return err
+	}
+
+	fee := review.FeeBreakdown()
+	journal, err := fee.Journal(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to build journal: %w", err)
+	}
+
+	// Update (deduct) initiator balance by the amount and its fee
+	_, err = tx.ExecContext(ctx, debitBalanceQuery, fee.Total, initiatorWallet.ID, review.Asset)
+	if err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	if recipientWallet != nil {
+		_, err = tx.ExecContext(ctx, creditBalanceQuery, recipientWallet.ID, review.Asset, review.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE transactions SET status = $1 WHERE id = $2`, domainwallet.Success, review.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	// Post balanced ledger entries for the transaction
+	return postJournal(ctx, tx, review.ID, journal)
+}
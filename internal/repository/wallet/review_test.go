@@ -0,0 +1,332 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	insertPendingReviewTxn  = `INSERT INTO transactions \(initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee, created_at\)`
+	insertTransactionReview = `INSERT INTO transaction_reviews \(transaction_id, rule, created_at\) VALUES \(\$1, \$2, NOW\(\)\)`
+	pendingReviewsQuery     = `SELECT t.id, .* FROM transaction_reviews v .* WHERE v.decision IS NULL ORDER BY v.created_at, v.transaction_id LIMIT \$1`
+	lockReviewQuery         = `SELECT t.id, .* FROM transaction_reviews v .* WHERE v.transaction_id = \$1 FOR UPDATE OF v`
+	reviewQuery             = `SELECT t.id, .* FROM transaction_reviews v .* WHERE v.transaction_id = \$1$`
+	updateReviewDecision    = `UPDATE transaction_reviews SET decision = \$1, decided_at = NOW\(\) WHERE transaction_id = \$2`
+)
+
+var transactionReviewColumns = []string{
+	"id", "initiator_wallet_user_id", "type", "status", "asset", "amount", "recipient_wallet_user_id", "failure_reason",
+	"parent_transaction_id", "created_at", "initiator_wallet_id", "recipient_wallet_id", "fee", "rule", "decision", "decided_at",
+}
+
+func TestListTransactionReviews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	recipientUserID := "user2"
+
+	tests := []struct {
+		name            string
+		prepareSQL      func()
+		expectedReviews []domainwallet.TransactionReview
+		expectedError   error
+	}{
+		{
+			name: "pending reviews oldest first",
+			prepareSQL: func() {
+				mock.ExpectQuery(pendingReviewsQuery).
+					WithArgs(50).
+					WillReturnRows(sqlmock.NewRows(transactionReviewColumns).
+						AddRow("tx1", "user1", "withdraw", "pending_review", "USD", 200, nil, nil, nil, "2026-10-17T00:00:00Z",
+							"wallet1", nil, 10, "new_wallet_drain", nil, nil).
+						AddRow("tx2", "user1", "transfer", "pending_review", "USD", 500, "user2", nil, nil, "2026-10-17T00:01:00Z",
+							"wallet1", "wallet2", 0, "new_recipient", nil, nil))
+			},
+			expectedReviews: []domainwallet.TransactionReview{
+				{
+					Transaction: domainwallet.Transaction{
+						ID:                    "tx1",
+						InitiatorWalletUserId: "user1",
+						Type:                  domainwallet.Withdraw,
+						Status:                domainwallet.PendingReview,
+						Asset:                 "USD",
+						Amount:                200,
+						CreatedAt:             "2026-10-17T00:00:00Z",
+					},
+					Fee:  10,
+					Rule: "new_wallet_drain",
+				},
+				{
+					Transaction: domainwallet.Transaction{
+						ID:                    "tx2",
+						InitiatorWalletUserId: "user1",
+						Type:                  domainwallet.Transfer,
+						Status:                domainwallet.PendingReview,
+						Asset:                 "USD",
+						Amount:                500,
+						RecipientWalletUserId: &recipientUserID,
+						CreatedAt:             "2026-10-17T00:01:00Z",
+					},
+					Rule: "new_recipient",
+				},
+			},
+		},
+		{
+			name: "no pending reviews",
+			prepareSQL: func() {
+				mock.ExpectQuery(pendingReviewsQuery).
+					WithArgs(50).
+					WillReturnRows(sqlmock.NewRows(transactionReviewColumns))
+			},
+			expectedReviews: []domainwallet.TransactionReview{},
+		},
+		{
+			name: "db error",
+			prepareSQL: func() {
+				mock.ExpectQuery(pendingReviewsQuery).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: errors.New("failed to select transaction reviews: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			reviews, err := r.ListTransactionReviews(context.Background(), 50)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedReviews, reviews)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDecideTransactionReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	decidedAt := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC)
+	approved := domainwallet.ReviewApproved
+	rejected := domainwallet.ReviewRejected
+	rejectedReason := domainwallet.FailureReviewRejected
+	recipientUserID := "user2"
+
+	pendingWithdraw := func() *sqlmock.Rows {
+		return sqlmock.NewRows(transactionReviewColumns).
+			AddRow("tx1", "user1", "withdraw", "pending_review", "USD", 200, nil, nil, nil, "2026-10-17T00:00:00Z",
+				"wallet1", nil, 10, "new_wallet_drain", nil, nil)
+	}
+	pendingTransfer := func() *sqlmock.Rows {
+		return sqlmock.NewRows(transactionReviewColumns).
+			AddRow("tx2", "user1", "transfer", "pending_review", "USD", 500, "user2", nil, nil, "2026-10-17T00:00:00Z",
+				"wallet1", "wallet2", 0, "new_recipient", nil, nil)
+	}
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		decision       domainwallet.ReviewDecision
+		prepareSQL     func()
+		expectedReview domainwallet.TransactionReview
+		expectedError  error
+	}{
+		{
+			name:          "review not found",
+			transactionID: "tx0",
+			decision:      domainwallet.ReviewApproved,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockReviewQuery).
+					WithArgs("tx0").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("transaction tx0: %w", domainwallet.ErrReviewNotFound),
+		},
+		{
+			name:          "review already decided",
+			transactionID: "tx1",
+			decision:      domainwallet.ReviewRejected,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockReviewQuery).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(transactionReviewColumns).
+						AddRow("tx1", "user1", "withdraw", "success", "USD", 200, nil, nil, nil, "2026-10-17T00:00:00Z",
+							"wallet1", nil, 10, "new_wallet_drain", "approved", decidedAt))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("transaction tx1 approved: %w", domainwallet.ErrReviewDecided),
+		},
+		{
+			name:          "approve withdraw with its fee",
+			transactionID: "tx1",
+			decision:      domainwallet.ReviewApproved,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockReviewQuery).
+					WithArgs("tx1").
+					WillReturnRows(pendingWithdraw())
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet1", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "balance", "held"}).
+						AddRow("wallet1", "user1", "active", 210, 210))
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(210, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(210, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1 WHERE id = \$2`).
+					WithArgs(domainwallet.Success, "tx1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal1"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal1", "wallet1", nil, "USD", -200, "journal1", nil, "external_cash_out", "USD", 200,
+						"journal1", "wallet1", nil, "USD", -10, "journal1", nil, "fees", "USD", 10).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+				mock.ExpectExec(updateReviewDecision).
+					WithArgs(domainwallet.ReviewApproved, "tx1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx1")
+				mock.ExpectQuery(reviewQuery).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(transactionReviewColumns).
+						AddRow("tx1", "user1", "withdraw", "success", "USD", 200, nil, nil, nil, "2026-10-17T00:00:00Z",
+							"wallet1", nil, 10, "new_wallet_drain", "approved", decidedAt))
+				mock.ExpectCommit()
+			},
+			expectedReview: domainwallet.TransactionReview{
+				Transaction: domainwallet.Transaction{
+					ID:                    "tx1",
+					InitiatorWalletUserId: "user1",
+					Type:                  domainwallet.Withdraw,
+					Status:                domainwallet.Success,
+					Asset:                 "USD",
+					Amount:                200,
+					CreatedAt:             "2026-10-17T00:00:00Z",
+				},
+				Fee:       10,
+				Rule:      "new_wallet_drain",
+				Decision:  &approved,
+				DecidedAt: &decidedAt,
+			},
+		},
+		{
+			name:          "approve transfer to a closed recipient wallet",
+			transactionID: "tx2",
+			decision:      domainwallet.ReviewApproved,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockReviewQuery).
+					WithArgs("tx2").
+					WillReturnRows(pendingTransfer())
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet2", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "balance", "held"}).
+						AddRow("wallet1", "user1", "active", 1000, 500).
+						AddRow("wallet2", "user2", "closed", 0, 0))
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrWalletClosed,
+		},
+		{
+			name:          "reject transfer",
+			transactionID: "tx2",
+			decision:      domainwallet.ReviewRejected,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockReviewQuery).
+					WithArgs("tx2").
+					WillReturnRows(pendingTransfer())
+				mock.ExpectQuery(lockWalletsByIDQuery).
+					WithArgs("wallet1", "wallet2", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "balance", "held"}).
+						AddRow("wallet1", "user1", "active", 1000, 500).
+						AddRow("wallet2", "user2", "active", 0, 0))
+				mock.ExpectExec(releaseHeldQuery).
+					WithArgs(500, "wallet1", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, failure_reason = \$2 WHERE id = \$3`).
+					WithArgs(domainwallet.Failed, domainwallet.FailureReviewRejected, "tx2").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(updateReviewDecision).
+					WithArgs(domainwallet.ReviewRejected, "tx2").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx2")
+				mock.ExpectQuery(reviewQuery).
+					WithArgs("tx2").
+					WillReturnRows(sqlmock.NewRows(transactionReviewColumns).
+						AddRow("tx2", "user1", "transfer", "failed", "USD", 500, "user2", "review_rejected", nil, "2026-10-17T00:00:00Z",
+							"wallet1", "wallet2", 0, "new_recipient", "rejected", decidedAt))
+				mock.ExpectCommit()
+			},
+			expectedReview: domainwallet.TransactionReview{
+				Transaction: domainwallet.Transaction{
+					ID:                    "tx2",
+					InitiatorWalletUserId: "user1",
+					Type:                  domainwallet.Transfer,
+					Status:                domainwallet.Failed,
+					Asset:                 "USD",
+					Amount:                500,
+					RecipientWalletUserId: &recipientUserID,
+					FailureReason:         &rejectedReason,
+					CreatedAt:             "2026-10-17T00:00:00Z",
+				},
+				Rule:      "new_recipient",
+				Decision:  &rejected,
+				DecidedAt: &decidedAt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			review, err := r.DecideTransactionReview(context.Background(), tt.transactionID, tt.decision)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedReview, review)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// balancesAsOfQuery selects the balances at $1 of the wallets selected by a page CTE of id and user_id, which the caller
+// prepends. Balances start from the latest snapshot run at or before $1, or from nothing without any, and add the wallets'
+// ledger entries posted since, up to $1 inclusive. Opening balance journals, without transaction, are added whenever they
+// were posted after the run, like GetWalletBalancesAt, as the snapshot only holds those posted at or before it. Wallets
+// without any balance are returned with a NULL asset.
+const balancesAsOfQuery = `,
+		run AS (
+			SELECT MAX(taken_at) AS taken_at FROM balance_snapshot_runs WHERE taken_at <= $1
+		),
+		amounts AS (
+			SELECT s.wallet_id, s.asset, s.balance AS amount
+			FROM balance_snapshots s
+			JOIN run ON s.taken_at = run.taken_at
+			WHERE s.wallet_id IN (SELECT id FROM page)
+			UNION ALL
+			SELECT e.wallet_id, e.asset, e.amount
+			FROM ledger_entries e
+			JOIN ledger_journals j ON j.id = e.journal_id
+			CROSS JOIN run
+			WHERE e.wallet_id IN (SELECT id FROM page)
+			AND (run.taken_at IS NULL OR e.created_at > run.taken_at)
+			AND (j.transaction_id IS NULL OR e.created_at <= $1)
+		)
+		SELECT p.id AS wallet_id, p.user_id, a.asset, SUM(a.amount)::BIGINT AS balance
+		FROM page p
+		LEFT JOIN amounts a ON a.wallet_id = p.id
+		GROUP BY p.id, p.user_id, a.asset
+		ORDER BY p.id, a.asset;`
+
+type walletBalanceAt struct {
+	WalletID string  `db:"wallet_id"`
+	UserID   string  `db:"user_id"`
+	Asset    *string `db:"asset"`
+	Balance  *int64  `db:"balance"`
+}
+
+// groupWalletBalancesAt groups the rows of balancesAsOfQuery per wallet, leaving zero balances out.
+func groupWalletBalancesAt(rows []walletBalanceAt, asOf time.Time) []domainwallet.WalletBalancesAt {
+	var wallets []domainwallet.WalletBalancesAt
+	for _, row := range rows {
+		if len(wallets) == 0 || wallets[len(wallets)-1].WalletID != row.WalletID {
+			wallets = append(wallets, domainwallet.WalletBalancesAt{WalletID: row.WalletID, UserID: row.UserID, AsOf: asOf})
+		}
+
+		if row.Asset == nil || row.Balance == nil || *row.Balance == 0 {
+			continue
+		}
+
+		w := &wallets[len(wallets)-1]
+		w.Balances = append(w.Balances, domainwallet.AssetBalance{Asset: *row.Asset, Balance: *row.Balance})
+	}
+
+	return wallets
+}
+
+// GetWalletBalancesAsOf returns the user wallet's non-zero balances at asOf, from the latest balance snapshot
+// before it plus the ledger entries posted since, so it does not scan the wallet's whole history.
+func (r *Repository) GetWalletBalancesAsOf(
+	ctx context.Context,
+	userID string,
+	asOf time.Time,
+) (domainwallet.WalletBalancesAt, error) {
+	query := `
+		WITH page AS (
+			SELECT id, user_id FROM wallets WHERE user_id = $2
+		)` + balancesAsOfQuery
+
+	asOf = asOf.UTC()
+
+	var rows []walletBalanceAt
+	err := r.db.SelectContext(ctx, &rows, query, asOf, userID)
+	if err != nil {
+		return domainwallet.WalletBalancesAt{}, fmt.Errorf("failed to get wallet balances as of %s: %w", asOf, err)
+	}
+
+	wallets := groupWalletBalancesAt(rows, asOf)
+	if len(wallets) == 0 {
+		return domainwallet.WalletBalancesAt{}, fmt.Errorf("failed get user wallet: %w", domainwallet.ErrWalletNotFound)
+	}
+
+	return wallets[0], nil
+}
+
+// ListWalletBalancesAsOf does the following:
+// 1. Select up to limit wallets created at or before asOf, in wallet id order after the afterWalletID cursor, from the first wallet when empty
+// 2. Return their non-zero balances at asOf, from the latest balance snapshot before it plus the ledger entries posted since
+func (r *Repository) ListWalletBalancesAsOf(
+	ctx context.Context,
+	asOf time.Time,
+	afterWalletID string,
+	limit int,
+) ([]domainwallet.WalletBalancesAt, error) {
+	query := `
+		WITH page AS (
+			SELECT id, user_id FROM wallets
+			WHERE created_at <= $1
+			AND ($2 = '' OR id > $2::uuid)
+			ORDER BY id
+			LIMIT $3
+		)` + balancesAsOfQuery
+
+	asOf = asOf.UTC()
+
+	var rows []walletBalanceAt
+	err := r.db.SelectContext(ctx, &rows, query, asOf, afterWalletID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet balances as of %s: %w", asOf, err)
+	}
+
+	return groupWalletBalancesAt(rows, asOf), nil
+}
+
+// oldestInFlightQuery selects the start of the oldest transaction still open on the database, other than the caller's.
+// Ledger entries are created at the start of their transaction, so every entry created before it has committed.
+// pg_stat_activity hides the transactions of other roles unless the caller is granted pg_read_all_stats, so the backends
+// it cannot see, whose backend_type is hidden too, are counted instead of leaving their transactions out.
+const oldestInFlightQuery = `
+	SELECT
+		MIN(xact_start) AS oldest_xact_start,
+		COUNT(*) FILTER (
+			WHERE NOT pg_has_role(usesysid, 'MEMBER') AND NOT pg_has_role('pg_read_all_stats', 'MEMBER')
+		) AS hidden_backends
+	FROM pg_stat_activity
+	WHERE datname = current_database() AND pid <> pg_backend_pid()
+	AND (backend_type = 'client backend' OR backend_type IS NULL)
+`
+
+type inFlightTransactions struct {
+	OldestXactStart *time.Time `db:"oldest_xact_start"`
+	HiddenBackends  int        `db:"hidden_backends"`
+}
+
+// SnapshotBalances does the following:
+// 1. Lock the snapshot runs, so concurrent instances take turns
+// 2. Move the instant before the oldest transaction still in flight, whose ledger entries are created at its start but
+// only visible once it commits, so the snapshot never leaves out entries created at or before the instant. Fail with
+// ErrTransactionsNotVisible when transactions of other roles cannot be seen
+// 3. Skip instants that are not after the latest run
+// 4. Insert the run and every wallet's non-zero balances at it, from the latest run plus the ledger entries posted since,
+// opening balance journals included, up to the instant so the next run adds those posted after it
+// 5. Return the number of balances snapshotted
+func (r *Repository) SnapshotBalances(ctx context.Context, at time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Readers of the snapshots are not blocked, only other snapshot runs
+	_, err = tx.ExecContext(ctx, `LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock balance snapshot runs: %w", err)
+	}
+
+	var latest *time.Time
+	err = tx.GetContext(ctx, &latest, `SELECT MAX(taken_at) FROM balance_snapshot_runs`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest balance snapshot run: %w", err)
+	}
+
+	var inFlight inFlightTransactions
+	err = tx.GetContext(ctx, &inFlight, oldestInFlightQuery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get oldest transaction in flight: %w", err)
+	}
+
+	if inFlight.HiddenBackends > 0 {
+		return 0, fmt.Errorf("%d backends hidden: %w", inFlight.HiddenBackends, domainwallet.ErrTransactionsNotVisible)
+	}
+
+	// postgres timestamps are precise to the microsecond
+	at = at.UTC().Truncate(time.Microsecond)
+	if oldest := inFlight.OldestXactStart; oldest != nil && !oldest.After(at) {
+		at = oldest.UTC().Truncate(time.Microsecond).Add(-time.Microsecond)
+	}
+
+	if latest != nil && !at.After(*latest) {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO balance_snapshot_runs (taken_at) VALUES ($1)`, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert balance snapshot run: %w", err)
+	}
+
+	insertSnapshots := `
+		INSERT INTO balance_snapshots (taken_at, wallet_id, asset, balance)
+		SELECT $1, wallet_id, asset, SUM(amount)
+		FROM (
+			SELECT s.wallet_id, s.asset, s.balance AS amount
+			FROM balance_snapshots s
+			WHERE s.taken_at = $2
+			UNION ALL
+			SELECT e.wallet_id, e.asset, e.amount
+			FROM ledger_entries e
+			WHERE e.wallet_id IS NOT NULL
+			AND ($2::timestamp IS NULL OR e.created_at > $2::timestamp)
+			AND e.created_at <= $1
+		) amounts
+		GROUP BY wallet_id, asset
+		HAVING SUM(amount) <> 0
+	`
+	result, err := tx.ExecContext(ctx, insertSnapshots, at, latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert balance snapshots: %w", err)
+	}
+
+	snapshotted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count balance snapshots: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit database tx: %w", err)
+	}
+
+	return int(snapshotted), nil
+}
@@ -0,0 +1,297 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	walletBalancesAsOfQuery = `WITH page AS \( SELECT id, user_id FROM wallets WHERE user_id = \$2 \), run AS \(.*\) SELECT p.id AS wallet_id, p.user_id, a.asset, SUM\(a.amount\)::BIGINT AS balance .* ORDER BY p.id, a.asset;`
+	listBalancesAsOfQuery   = `WITH page AS \( SELECT id, user_id FROM wallets WHERE created_at <= \$1 AND \(\$2 = '' OR id > \$2::uuid\) ORDER BY id LIMIT \$3 \), run AS \(.*\) SELECT p.id AS wallet_id, .* ORDER BY p.id, a.asset;`
+	latestSnapshotRunQuery  = `SELECT MAX\(taken_at\) FROM balance_snapshot_runs`
+	oldestInFlightQuery     = `SELECT MIN\(xact_start\) AS oldest_xact_start, .* AS hidden_backends FROM pg_stat_activity WHERE datname = current_database\(\) AND pid <> pg_backend_pid\(\)`
+	insertSnapshotRunQuery  = `INSERT INTO balance_snapshot_runs \(taken_at\) VALUES \(\$1\)`
+	insertSnapshotsQuery    = `INSERT INTO balance_snapshots \(taken_at, wallet_id, asset, balance\) SELECT \$1, wallet_id, asset, SUM\(amount\) .* HAVING SUM\(amount\) <> 0`
+)
+
+func TestGetWalletBalancesAsOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	columns := []string{"wallet_id", "user_id", "asset", "balance"}
+
+	tests := []struct {
+		name             string
+		prepareMock      func()
+		expectedBalances domainwallet.WalletBalancesAt
+		expectedError    error
+	}{
+		{
+			name: "balances as of",
+			prepareMock: func() {
+				mock.ExpectQuery(walletBalancesAsOfQuery).
+					WithArgs(asOf, "user123").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("wallet-1", "user123", "BTC", 5).
+						AddRow("wallet-1", "user123", "USDT", 0).
+						AddRow("wallet-1", "user123", "USD", 1000))
+			},
+			expectedBalances: domainwallet.WalletBalancesAt{
+				WalletID: "wallet-1",
+				UserID:   "user123",
+				AsOf:     asOf,
+				Balances: []domainwallet.AssetBalance{{Asset: "BTC", Balance: 5}, {Asset: "USD", Balance: 1000}},
+			},
+		},
+		{
+			name: "no balances yet",
+			prepareMock: func() {
+				mock.ExpectQuery(walletBalancesAsOfQuery).
+					WithArgs(asOf, "user123").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("wallet-1", "user123", nil, nil))
+			},
+			expectedBalances: domainwallet.WalletBalancesAt{WalletID: "wallet-1", UserID: "user123", AsOf: asOf},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(walletBalancesAsOfQuery).
+					WithArgs(asOf, "user123").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			balances, err := r.GetWalletBalancesAsOf(context.Background(), "user123", asOf)
+			assert.Equal(t, tt.expectedBalances, balances)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListWalletBalancesAsOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	columns := []string{"wallet_id", "user_id", "asset", "balance"}
+	errDB := errors.New("db down")
+
+	tests := []struct {
+		name            string
+		after           string
+		prepareMock     func()
+		expectedWallets []domainwallet.WalletBalancesAt
+		expectedError   error
+	}{
+		{
+			name: "first page",
+			prepareMock: func() {
+				mock.ExpectQuery(listBalancesAsOfQuery).
+					WithArgs(asOf, "", 2).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("wallet-1", "user123", "BTC", 5).
+						AddRow("wallet-1", "user123", "USD", 1000).
+						AddRow("wallet-2", "user456", nil, nil))
+			},
+			expectedWallets: []domainwallet.WalletBalancesAt{
+				{
+					WalletID: "wallet-1",
+					UserID:   "user123",
+					AsOf:     asOf,
+					Balances: []domainwallet.AssetBalance{{Asset: "BTC", Balance: 5}, {Asset: "USD", Balance: 1000}},
+				},
+				{WalletID: "wallet-2", UserID: "user456", AsOf: asOf},
+			},
+		},
+		{
+			name:  "page after cursor",
+			after: "wallet-2",
+			prepareMock: func() {
+				mock.ExpectQuery(listBalancesAsOfQuery).
+					WithArgs(asOf, "wallet-2", 2).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("wallet-3", "user789", "USD", 1))
+			},
+			expectedWallets: []domainwallet.WalletBalancesAt{
+				{WalletID: "wallet-3", UserID: "user789", AsOf: asOf, Balances: []domainwallet.AssetBalance{{Asset: "USD", Balance: 1}}},
+			},
+		},
+		{
+			name: "query error",
+			prepareMock: func() {
+				mock.ExpectQuery(listBalancesAsOfQuery).
+					WithArgs(asOf, "", 2).
+					WillReturnError(errDB)
+			},
+			expectedError: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			wallets, err := r.ListWalletBalancesAsOf(context.Background(), asOf, tt.after, 2)
+			assert.Equal(t, tt.expectedWallets, wallets)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	at := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	previous := at.Add(-24 * time.Hour)
+	inFlightColumns := []string{"oldest_xact_start", "hidden_backends"}
+
+	tests := []struct {
+		name                string
+		prepareMock         func()
+		expectedSnapshotted int
+		expectedError       error
+	}{
+		{
+			name: "first snapshot sums the whole ledger",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(nil, 0))
+				mock.ExpectExec(insertSnapshotRunQuery).
+					WithArgs(at).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertSnapshotsQuery).
+					WithArgs(at, nil).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			expectedSnapshotted: 3,
+		},
+		{
+			name: "snapshot adds the ledger entries since the latest run",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(previous))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(at.Add(time.Second), 0))
+				mock.ExpectExec(insertSnapshotRunQuery).
+					WithArgs(at).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertSnapshotsQuery).
+					WithArgs(at, previous).
+					WillReturnResult(sqlmock.NewResult(0, 5))
+				mock.ExpectCommit()
+			},
+			expectedSnapshotted: 5,
+		},
+		{
+			name: "snapshot moves before the oldest transaction in flight",
+			prepareMock: func() {
+				inFlight := at.Add(-time.Minute)
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(previous))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(inFlight, 0))
+				mock.ExpectExec(insertSnapshotRunQuery).
+					WithArgs(inFlight.Add(-time.Microsecond)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertSnapshotsQuery).
+					WithArgs(inFlight.Add(-time.Microsecond), previous).
+					WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectCommit()
+			},
+			expectedSnapshotted: 4,
+		},
+		{
+			name: "transaction in flight since the latest run",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(previous))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(previous, 0))
+				mock.ExpectRollback()
+			},
+			expectedSnapshotted: 0,
+		},
+		{
+			name: "transactions of other roles not visible",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(previous))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(nil, 2))
+				mock.ExpectRollback()
+			},
+			expectedSnapshotted: 0,
+			expectedError:       domainwallet.ErrTransactionsNotVisible,
+		},
+		{
+			name: "instant already snapshotted",
+			prepareMock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`LOCK TABLE balance_snapshot_runs IN EXCLUSIVE MODE`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(latestSnapshotRunQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(at))
+				mock.ExpectQuery(oldestInFlightQuery).
+					WillReturnRows(sqlmock.NewRows(inFlightColumns).AddRow(nil, 0))
+				mock.ExpectRollback()
+			},
+			expectedSnapshotted: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			snapshotted, err := r.SnapshotBalances(context.Background(), at)
+			assert.Equal(t, tt.expectedSnapshotted, snapshotted)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
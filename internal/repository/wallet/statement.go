@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type assetBalance struct {
+	Asset   string `db:"asset"`
+	Balance int64  `db:"balance"`
+}
+
+// GetWalletBalancesAt does the following:
+// 1. Sum the user wallet's ledger entries posted before at, per asset
+// 2. Include the opening balance journals whenever they were posted, like the running balances of StreamWalletTransactions,
+// as they stand for the transactions that predate the ledger
+func (r *Repository) GetWalletBalancesAt(ctx context.Context, userID string, at time.Time) (map[string]int64, error) {
+	walletID, err := r.getUserWalletID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+		SELECT e.asset, SUM(e.amount)::BIGINT AS balance
+		FROM ledger_entries e
+		JOIN ledger_journals j ON j.id = e.journal_id
+		WHERE e.wallet_id = $1
+		AND (j.transaction_id IS NULL OR e.created_at < $2)
+		GROUP BY e.asset;
+	`
+
+	var rows []assetBalance
+	err = r.db.SelectContext(ctx, &rows, query, walletID, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balances at %s: %w", at, err)
+	}
+
+	balances := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		balances[row.Asset] = row.Balance
+	}
+
+	return balances, nil
+}
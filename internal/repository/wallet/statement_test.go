@@ -0,0 +1,98 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const balancesAtQuery = `SELECT e.asset, SUM\(e.amount\)::BIGINT AS balance FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id WHERE e.wallet_id = \$1 AND \(j.transaction_id IS NULL OR e.created_at < \$2\) GROUP BY e.asset;`
+
+func TestGetWalletBalancesAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	at := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	errDB := errors.New("db down")
+
+	tests := []struct {
+		name             string
+		prepareMock      func()
+		expectedBalances map[string]int64
+		expectedError    error
+	}{
+		{
+			name: "balances per asset",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(balancesAtQuery).
+					WithArgs("wallet-1", at).
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance"}).
+						AddRow("BTC", 5).
+						AddRow("USD", 1000))
+			},
+			expectedBalances: map[string]int64{"BTC": 5, "USD": 1000},
+		},
+		{
+			name: "no ledger entries yet",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(balancesAtQuery).
+					WithArgs("wallet-1", at).
+					WillReturnRows(sqlmock.NewRows([]string{"asset", "balance"}))
+			},
+			expectedBalances: map[string]int64{},
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+		{
+			name: "query error",
+			prepareMock: func() {
+				mock.ExpectQuery(`SELECT id FROM wallets WHERE user_id = \$1`).
+					WithArgs("user123").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("wallet-1"))
+
+				mock.ExpectQuery(balancesAtQuery).
+					WithArgs("wallet-1", at).
+					WillReturnError(errDB)
+			},
+			expectedError: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			balances, err := r.GetWalletBalancesAt(context.Background(), "user123", at)
+			assert.Equal(t, tt.expectedBalances, balances)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// UpdateWalletStatus does the following:
+// 1. Hold row-level lock on the user wallet so no money movement races with the status change
+// 2. Validate the transition (closed is terminal) and that a wallet being closed is empty in every asset
+// 3. Persist the new status and return the updated wallet
+func (r *Repository) UpdateWalletStatus(
+	ctx context.Context,
+	userID string,
+	status domainwallet.WalletStatus,
+) (domainwallet.Wallet, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainwallet.Wallet{}, fmt.Errorf("failed to begin database tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var dst domainwallet.Wallet
+	query := `SELECT id, user_id, status, kyc_tier, created_at FROM wallets WHERE user_id = $1 FOR UPDATE`
+	err = tx.GetContext(ctx, &dst, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.Wallet{}, fmt.Errorf(
+				"wallet not found: %w",
+				domainwallet.ErrWalletNotFound,
+			)
+		}
+
+		return domainwallet.Wallet{}, fmt.Errorf(
+			"failed to hold row-level lock on wallet: %w, userID: %s",
+			err,
+			userID,
+		)
+	}
+
+	if !dst.Status.CanTransitionTo(status) {
+		return domainwallet.Wallet{}, fmt.Errorf(
+			"cannot move wallet from %s to %s: %w",
+			dst.Status,
+			status,
+			domainwallet.ErrInvalidWalletStatusTransition,
+		)
+	}
+
+	dst.Balances, err = getBalances(ctx, tx, dst.ID)
+	if err != nil {
+		return domainwallet.Wallet{}, err
+	}
+
+	if status == domainwallet.Closed && !dst.IsEmpty() {
+		return domainwallet.Wallet{}, fmt.Errorf(
+			"cannot close wallet: %w",
+			domainwallet.ErrWalletHasBalance,
+		)
+	}
+
+	update := `UPDATE wallets SET status = $1 WHERE id = $2`
+	_, err = tx.ExecContext(ctx, update, status, dst.ID)
+	if err != nil {
+		return domainwallet.Wallet{}, fmt.Errorf("failed to update wallet status: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return domainwallet.Wallet{}, fmt.Errorf("failed to commit tx: %w", err)
+	}
+
+	dst.Status = status
+
+	return dst, nil
+}
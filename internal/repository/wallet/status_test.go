@@ -0,0 +1,147 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateWalletStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	lockQuery := regexp.QuoteMeta(`SELECT id, user_id, status, kyc_tier, created_at FROM wallets WHERE user_id = $1 FOR UPDATE`)
+	balancesQuery := regexp.QuoteMeta(`SELECT asset, balance, held FROM balances WHERE wallet_id = $1 ORDER BY asset`)
+	walletColumns := []string{"id", "user_id", "status", "kyc_tier", "created_at"}
+	balanceColumns := []string{"asset", "balance"}
+
+	tests := []struct {
+		name           string
+		userID         string
+		status         domainwallet.WalletStatus
+		prepareSQL     func()
+		expectedStatus domainwallet.WalletStatus
+		expectedError  error
+	}{
+		{
+			name:   "wallet not found",
+			userID: "user1",
+			status: domainwallet.Frozen,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs("user1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
+		},
+		{
+			name:   "invalid transition from closed",
+			userID: "user2",
+			status: domainwallet.Active,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs("user2").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet2", "user2", "closed", "full", time.Now()))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf(
+				"cannot move wallet from closed to active: %w",
+				domainwallet.ErrInvalidWalletStatusTransition,
+			),
+		},
+		{
+			name:   "close wallet with balance",
+			userID: "user3",
+			status: domainwallet.Closed,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs("user3").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet3", "user3", "active", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet3").
+					WillReturnRows(sqlmock.NewRows(balanceColumns).AddRow("USD", 0).AddRow("BTC", 100))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("cannot close wallet: %w", domainwallet.ErrWalletHasBalance),
+		},
+		{
+			name:   "successful freeze",
+			userID: "user4",
+			status: domainwallet.Frozen,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs("user4").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet4", "user4", "active", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet4").
+					WillReturnRows(sqlmock.NewRows(balanceColumns).AddRow("USD", 100))
+				mock.ExpectExec(`UPDATE wallets SET status = \$1 WHERE id = \$2`).
+					WithArgs("frozen", "wallet4").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: domainwallet.Frozen,
+		},
+		{
+			name:   "successful close of empty wallet",
+			userID: "user5",
+			status: domainwallet.Closed,
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).
+					WithArgs("user5").
+					WillReturnRows(sqlmock.NewRows(walletColumns).
+						AddRow("wallet5", "user5", "frozen", "full", time.Now()))
+				mock.ExpectQuery(balancesQuery).
+					WithArgs("wallet5").
+					WillReturnRows(sqlmock.NewRows(balanceColumns).AddRow("USD", 0))
+				mock.ExpectExec(`UPDATE wallets SET status = \$1 WHERE id = \$2`).
+					WithArgs("closed", "wallet5").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: domainwallet.Closed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareSQL()
+
+			w, err := r.UpdateWalletStatus(context.Background(), tt.userID, tt.status)
+
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, w.Status)
+				assert.Equal(t, tt.userID, w.UserID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
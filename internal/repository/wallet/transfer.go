@@ -2,101 +2,165 @@ package wallet
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
-	"log/slog"
 
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
-	"github.com/redis/go-redis/v9"
 )
 
 const transferCacheKey = `transfer-%s-%s` // transfer-initiatorUserID-idempotencyKey
 
 // Transfer does the following:
-// 1. Check from redis cache on key = transfer-{initiatorUserID}-{idempotencyKey}, if exists we just return cached transactionID and nil error
-// 2. If not, proceed with transfer amount from initiatorUser wallet to recipientUser wallet
-// 3. Cache if successful and return appriopriate errors (insufficient balance)
+// 1. Check from redis cache on key = transfer-{initiatorUserID}-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock both user wallets in wallet id order and check the idempotency key recorded in postgres
+// 3. Price the transfer with the fee schedule, then proceed with transfer amount of the asset from initiatorUser wallet to recipientUser wallet, charging the fee to the initiator, and post the balanced ledger journal crediting the fee to the house fee account, or record the transfer as failed with its reason on frozen/closed wallet, insufficient balance, exceeded limit or fraud block
+// 4. Transfers the fraud rules flag reserve the amount and fee and are held for review instead, see holdForReview
+// 5. Record the response on the idempotency key in the same db transaction, cache it and return appriopriate errors
+// 6. Retry the db transaction with jittered backoff on deadlock or serialization failure
 func (r *Repository) Transfer(
 	ctx context.Context,
-	initiatorUserID, recipientUserID, idempotencyKey string,
+	initiatorUserID, recipientUserID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	cacheKey := fmt.Sprintf(transferCacheKey, initiatorUserID, idempotencyKey)
-	cachedTxID, err := r.cache.Get(ctx, cacheKey).Result()
-	// Idempotent: already processed such transfer
-	if err == nil {
-		return cachedTxID, nil
+	verdict domainwallet.FraudVerdict,
+) (domainwallet.IdempotentResponse, error) {
+	// Both sides of a self-transfer would resolve to the same locked wallet
+	if initiatorUserID == recipientUserID {
+		return domainwallet.IdempotentResponse{}, domainwallet.ErrSelfTransfer
 	}
 
-	if err != nil && err != redis.Nil {
-		return "", fmt.Errorf("redis get transferCacheKey failed: %w", err)
+	cacheKey := fmt.Sprintf(transferCacheKey, initiatorUserID, key.Key)
+	// Idempotent: already processed
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
 	}
 
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+	defer release()
+
+	// Deadlocks and serialization failures roll back the whole db transaction, so it is retried from the start
+	return r.withTxRetry(ctx, domainwallet.Transfer.Operation(), func() (domainwallet.IdempotentResponse, error) {
+		return r.transfer(ctx, cacheKey, initiatorUserID, recipientUserID, key, asset, amount, verdict)
+	})
+}
+
+// transfer runs a single attempt of the transfer db transaction.
+func (r *Repository) transfer(
+	ctx context.Context,
+	cacheKey string,
+	initiatorUserID, recipientUserID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
+	amount uint64,
+	verdict domainwallet.FraudVerdict,
+) (domainwallet.IdempotentResponse, error) {
 	// it's a new transfer operation
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to begin database tx: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Hold row-level lock on both initiator and recipient user wallets
-	query := `SELECT id, balance FROM wallets WHERE user_id = $1 FOR UPDATE`
-
-	var dbInitiatorWallet userWallet
-	err = tx.GetContext(ctx, &dbInitiatorWallet, query, initiatorUserID)
+	// Hold row-level lock on both initiator and recipient user wallets, acquired in wallet id order
+	var dbWallets []userWallet
+	err = tx.SelectContext(ctx, &dbWallets, lockWalletPairQuery, initiatorUserID, recipientUserID, asset)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
-		}
-
-		return "", fmt.Errorf(
-			"failed to hold row-level lock on wallet: %w, userID: %s",
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
+			"failed to hold row-level lock on wallets: %w, userIDs: %s, %s",
 			err,
 			initiatorUserID,
+			recipientUserID,
 		)
 	}
 
-	var dbRecipientWallet userWallet
-	err = tx.GetContext(ctx, &dbRecipientWallet, query, recipientUserID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+	var dbInitiatorWallet, dbRecipientWallet *userWallet
+	for i := range dbWallets {
+		switch dbWallets[i].UserID {
+		case initiatorUserID:
+			dbInitiatorWallet = &dbWallets[i]
+		case recipientUserID:
+			dbRecipientWallet = &dbWallets[i]
 		}
+	}
 
-		return "", fmt.Errorf(
-			"failed to hold row-level lock on wallet: %w, userID: %s",
-			err,
-			recipientUserID,
-		)
+	if dbInitiatorWallet == nil || dbRecipientWallet == nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
 	}
 
-	// Check Initiator User wallet balance
-	if dbInitiatorWallet.Balance < amount {
-		return "", fmt.Errorf(
-			"insufficient balance to transfer: %w",
-			domainwallet.ErrWalletInsufficientBalance,
-		)
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupIdempotencyKey(ctx, tx, cacheKey, initiatorUserID, domainwallet.Transfer.Operation(), key); err != nil || found {
+		return resp, err
+	}
+
+	attempt := attemptedTransaction{
+		Operation:         domainwallet.Transfer.Operation(),
+		InitiatorWalletID: dbInitiatorWallet.ID,
+		RecipientWalletID: &dbRecipientWallet.ID,
+		Type:              domainwallet.Transfer,
+		Asset:             asset,
+		Amount:            amount,
+	}
+
+	// Frozen wallets may still receive funds, closed wallets may not move funds at all
+	if err := dbInitiatorWallet.Status.CanDebit(); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, initiatorUserID, key, attempt, err)
+	}
+
+	if err := dbRecipientWallet.Status.CanCredit(); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, initiatorUserID, key, attempt, err)
+	}
+
+	fee, err := quoteFee(ctx, tx, domainwallet.Transfer, asset, amount)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	// Check Initiator User wallet balance covers the amount and its fee
+	if dbInitiatorWallet.available() < fee.Total {
+		return r.failTransaction(ctx, tx, cacheKey, initiatorUserID, key, attempt, domainwallet.ErrWalletInsufficientBalance)
+	}
+
+	// Transfers over the initiator wallet's limits are recorded as failed too
+	if err := checkTransactionLimit(ctx, tx, dbInitiatorWallet.ID, domainwallet.Transfer, asset, amount); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, initiatorUserID, key, attempt, err)
 	}
 
-	// Update balance for both wallets
-	deductQuery := `UPDATE wallets SET balance = balance - $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, deductQuery, amount, dbInitiatorWallet.ID)
+	// Transfers the fraud rules block are recorded as failed, the ones they flag are held for review instead
+	switch verdict.Decision {
+	case domainwallet.FraudBlock:
+		return r.failTransaction(ctx, tx, cacheKey, initiatorUserID, key, attempt, domainwallet.ErrTransactionBlocked)
+	case domainwallet.FraudReview:
+		return r.holdForReview(ctx, tx, cacheKey, initiatorUserID, key, attempt, fee, verdict.Rule)
+	}
+
+	journal, err := fee.Journal(
+		domainwallet.WalletAccount(dbInitiatorWallet.ID),
+		domainwallet.WalletAccount(dbRecipientWallet.ID),
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+	}
+
+	// Update balance for both wallets, the initiator pays the fee on top of the amount
+	_, err = tx.ExecContext(ctx, debitBalanceQuery, fee.Total, dbInitiatorWallet.ID, asset)
 	if err != nil {
-		return "", fmt.Errorf("failed to update balance: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	addQuery := `UPDATE wallets SET balance = balance + $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, addQuery, amount, dbRecipientWallet.ID)
+	_, err = tx.ExecContext(ctx, creditBalanceQuery, dbRecipientWallet.ID, asset, amount)
 	if err != nil {
-		return "", fmt.Errorf("failed to update balance: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
 	}
 
 	// Insert transaction record
 	var transactionID string
 	insertTxn := `
-			INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, type, status, amount, created_at)
-			VALUES ($1, $2, $3, $4, $5, NOW())
+			INSERT INTO transactions (initiator_wallet_id, recipient_wallet_id, type, status, asset, amount, fee, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 			RETURNING id
 		`
 	err = tx.GetContext(
@@ -107,28 +171,19 @@ func (r *Repository) Transfer(
 		dbRecipientWallet.ID,
 		domainwallet.Transfer,
 		domainwallet.Success,
+		asset,
 		amount,
+		fee.Fee,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert transaction record: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert transaction record: %w", err)
 	}
 
-	// Commit transaction
-	err = tx.Commit()
-	if err != nil {
-		return "", fmt.Errorf("failed to commit tx: %w", err)
-	}
-
-	// Cache idempotency Key
-	if err := r.cache.Set(ctx, cacheKey, transactionID, ttl).Err(); err != nil {
-		r.logger.Error(
-			"failed to cache idempotency key on Transfer",
-			slog.String("initiatorUserID", initiatorUserID),
-			slog.String("recipientUserID", recipientUserID),
-			slog.String("idempotencyKey", idempotencyKey),
-			slog.String("transactionID", transactionID),
-		)
+	// Post balanced ledger entries for the transaction
+	if err := postJournal(ctx, tx, transactionID, journal); err != nil {
+		return domainwallet.IdempotentResponse{}, err
 	}
 
-	return transactionID, nil
+	// Record the response on the idempotency key and commit transaction
+	return r.commitIdempotent(ctx, tx, cacheKey, initiatorUserID, domainwallet.Transfer.Operation(), key, transactionID, fee, nil)
 }
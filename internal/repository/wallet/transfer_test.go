@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	redismock "github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgconn"
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -19,6 +21,22 @@ import (
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
 )
 
+const lockWalletPairQuery = `SELECT w.id, w.user_id, w.status, COALESCE\(b.balance, 0\) AS balance, COALESCE\(b.held, 0\) AS held FROM wallets w .* WHERE w.user_id IN \(\$1, \$2\) ORDER BY w.id FOR UPDATE OF w`
+
+// txRetryMetric identifies a counter of retried db transactions published with expvar.
+type txRetryMetric struct {
+	name, key string
+}
+
+// txRetries returns the value of the retried db transactions counter.
+func txRetries(metric txRetryMetric) int64 {
+	if v, ok := expvar.Get(metric.name).(*expvar.Map).Get(metric.key).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
 func TestTransferWallet(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -30,133 +48,474 @@ func TestTransferWallet(t *testing.T) {
 	repo := wallet.New(sqlxDB, redisClient, logger)
 
 	tests := []struct {
-		name            string
-		initiatorUserID string
-		recipientUserID string
-		idempotencyKey  string
-		amount          uint64
-		prepareRedis    func()
-		prepareSQL      func()
-		expectedError   error
-		expectedTxnID   string
+		name             string
+		initiatorUserID  string
+		recipientUserID  string
+		idempotencyKey   string
+		asset            string
+		amount           uint64
+		verdict          domainwallet.FraudVerdict
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
+		expectedRetries  map[txRetryMetric]int64
 	}{
 		{
 			name:            "idempotency key already processed",
 			initiatorUserID: "user1",
 			recipientUserID: "user2",
 			idempotencyKey:  "idem001",
+			asset:           "USD",
 			amount:          100,
 			prepareRedis: func() {
-				redisMock.ExpectGet("transfer-user1-idem001").SetVal("tx-already")
+				redisMock.ExpectGet("transfer-user1-idem001").SetVal(cachedRecord("hash-idem001", okResponse("tx-already")))
 			},
-			prepareSQL:    func() {},
-			expectedTxnID: "tx-already",
-			expectedError: nil,
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("tx-already"),
 		},
 		{
-			name:            "redis get error",
+			name:            "self transfer",
+			initiatorUserID: "user1",
+			recipientUserID: "user1",
+			idempotencyKey:  "idem000",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis:    func() {},
+			prepareSQL:      func() {},
+			expectedError:   domainwallet.ErrSelfTransfer,
+		},
+		{
+			name:            "redis get error falls back to postgres",
 			initiatorUserID: "user3",
 			recipientUserID: "user4",
 			idempotencyKey:  "idem002",
+			asset:           "USD",
 			amount:          50,
 			prepareRedis: func() {
 				redisMock.ExpectGet("transfer-user3-idem002").SetErr(errors.New("redis down"))
+				expectReserveInFlight(redisMock, "transfer-user3-idem002").SetVal(true)
+				expectReleaseInFlight(redisMock, "transfer-user3-idem002")
 			},
-			prepareSQL:    func() {},
-			expectedError: fmt.Errorf("redis get transferCacheKey failed: %w", errors.New("redis down")),
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user3", "user4", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}))
+				mock.ExpectRollback()
+			},
+			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
 		},
 		{
 			name:            "wallet not found for initiator",
 			initiatorUserID: "user5",
 			recipientUserID: "user6",
 			idempotencyKey:  "idem003",
+			asset:           "USD",
 			amount:          20,
 			prepareRedis: func() {
 				redisMock.ExpectGet("transfer-user5-idem003").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user5-idem003").SetVal(true)
+				expectReleaseInFlight(redisMock, "transfer-user5-idem003")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user5").
-					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user5", "user6", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet6", "user6", 0, "active"))
 			},
 			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
 		},
+		{
+			name:            "frozen initiator wallet",
+			initiatorUserID: "user11",
+			recipientUserID: "user12",
+			idempotencyKey:  "idem006",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user11-idem006").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user11-idem006").SetVal(true)
+				redisMock.ExpectSet("transfer-user11-idem006", cachedRecord("hash-idem006", failedResponse("tx11", domainwallet.FailureWalletFrozen)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user11-idem006")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user11", "user12", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet11", "user11", 1000, "frozen").
+						AddRow("wallet12", "user12", 0, "active"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user11", "idem006", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet11", "wallet12", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureWalletFrozen, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx11"))
+				expectTransactionEvents(mock, "tx11")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user11", "idem006", domainwallet.Transfer, "hash-idem006", "tx11", nil, 422, failedResponse("tx11", domainwallet.FailureWalletFrozen).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx11", domainwallet.FailureWalletFrozen),
+		},
+		{
+			name:            "closed recipient wallet",
+			initiatorUserID: "user13",
+			recipientUserID: "user14",
+			idempotencyKey:  "idem007",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user13-idem007").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user13-idem007").SetVal(true)
+				redisMock.ExpectSet("transfer-user13-idem007", cachedRecord("hash-idem007", failedResponse("tx13", domainwallet.FailureWalletClosed)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user13-idem007")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user13", "user14", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet13", "user13", 1000, "active").
+						AddRow("wallet14", "user14", 0, "closed"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user13", "idem007", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet13", "wallet14", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureWalletClosed, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx13"))
+				expectTransactionEvents(mock, "tx13")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user13", "idem007", domainwallet.Transfer, "hash-idem007", "tx13", nil, 422, failedResponse("tx13", domainwallet.FailureWalletClosed).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx13", domainwallet.FailureWalletClosed),
+		},
 		{
 			name:            "insufficient balance",
 			initiatorUserID: "user7",
 			recipientUserID: "user8",
 			idempotencyKey:  "idem004",
+			asset:           "USD",
 			amount:          1000,
 			prepareRedis: func() {
 				redisMock.ExpectGet("transfer-user7-idem004").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user7-idem004").SetVal(true)
+				redisMock.ExpectSet("transfer-user7-idem004", cachedRecord("hash-idem004", failedResponse("tx7", domainwallet.FailureInsufficientBalance)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user7-idem004")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user7", "user8", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet7", "user7", 100, "active").
+						AddRow("wallet8", "user8", 200, "active"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user7", "idem004", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "transfer", "USD")
+
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet7", "wallet8", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureInsufficientBalance, "USD", 1000).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx7"))
+				expectTransactionEvents(mock, "tx7")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user7", "idem004", domainwallet.Transfer, "hash-idem004", "tx7", nil, 422, failedResponse("tx7", domainwallet.FailureInsufficientBalance).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx7", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:            "daily count limit used up",
+			initiatorUserID: "user19",
+			recipientUserID: "user20",
+			idempotencyKey:  "idem010",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user19-idem010").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user19-idem010").SetVal(true)
+				redisMock.ExpectSet("transfer-user19-idem010", cachedRecord("hash-idem010", failedResponse("tx19", domainwallet.FailureLimitExceeded)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user19-idem010")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user7").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet7", 100))
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user19", "user20", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet19", "user19", 1000, "active").
+						AddRow("wallet20", "user20", 0, "active"))
+
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user19", "idem010", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "transfer", "USD")
 
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user8").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet8", 200))
+				expectTransactionLimit(mock, "wallet19", "transfer", "USD", 5)
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet19", "wallet20", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureLimitExceeded, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx19"))
+				expectTransactionEvents(mock, "tx19")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user19", "idem010", domainwallet.Transfer, "hash-idem010", "tx19", nil, 422, failedResponse("tx19", domainwallet.FailureLimitExceeded).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
-			expectedError: fmt.Errorf("insufficient balance to transfer: %w", domainwallet.ErrWalletInsufficientBalance),
+			expectedResponse: failedResponse("tx19", domainwallet.FailureLimitExceeded),
 		},
 		{
 			name:            "successful transfer",
 			initiatorUserID: "user9",
 			recipientUserID: "user10",
 			idempotencyKey:  "idem005",
+			asset:           "USD",
 			amount:          500,
 			prepareRedis: func() {
 				redisMock.ExpectGet("transfer-user9-idem005").RedisNil()
-				redisMock.ExpectSet("transfer-user9-idem005", "tx999", 24*time.Hour).SetVal("OK")
+				expectReserveInFlight(redisMock, "transfer-user9-idem005").SetVal(true)
+				redisMock.ExpectSet("transfer-user9-idem005", cachedRecord("hash-idem005", okResponse("tx999 fee 10 of 510")), 24*time.Hour).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user9-idem005")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user9").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet9", 1000))
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user9", "user10", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet10", "user10", 250, "active").
+						AddRow("wallet9", "user9", 1000, "active"))
 
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user10").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet10", 250))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user9", "idem005", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
 
-				mock.ExpectExec(`UPDATE wallets SET balance = balance - \$1 WHERE id = \$2`).
-					WithArgs(500, "wallet9").
+				mock.ExpectQuery(feeScheduleQuery).
+					WithArgs("transfer", "USD").
+					WillReturnRows(sqlmock.NewRows(feeTierColumns).AddRow("transfer", "USD", 0, 0, 150, 10, nil))
+				expectNoTransactionLimit(mock, "wallet9", "transfer", "USD")
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(510, "wallet9", "USD").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
-				mock.ExpectExec(`UPDATE wallets SET balance = balance \+ \$1 WHERE id = \$2`).
-					WithArgs(500, "wallet10").
+				mock.ExpectExec(`INSERT INTO balances .* ON CONFLICT \(wallet_id, asset\) DO UPDATE`).
+					WithArgs("wallet10", "USD", 500).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
-					WithArgs("wallet9", "wallet10", "transfer", "success", 500).
+					WithArgs("wallet9", "wallet10", "transfer", "success", "USD", 500, 10).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx999"))
 
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx999").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal999"))
+
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal999", "wallet9", nil, "USD", -500, "journal999", "wallet10", nil, "USD", 500,
+						"journal999", "wallet9", nil, "USD", -10, "journal999", nil, "fees", "USD", 10).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+
+				expectTransactionEvents(mock, "tx999")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user9", "idem005", domainwallet.Transfer, "hash-idem005", "tx999", nil, 200, []byte("tx999 fee 10 of 510")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
-			expectedTxnID: "tx999",
-			expectedError: nil,
+			expectedResponse: okResponse("tx999 fee 10 of 510"),
+		},
+		{
+			name:            "first transfer to a new recipient held for review",
+			initiatorUserID: "user31",
+			recipientUserID: "user32",
+			idempotencyKey:  "idem020",
+			asset:           "USD",
+			amount:          500,
+			verdict:         domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "new_recipient"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user31-idem020").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user31-idem020").SetVal(true)
+				redisMock.ExpectSet("transfer-user31-idem020", cachedRecord("hash-idem020", pendingReviewResponse("tx31")), 24*time.Hour).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user31-idem020")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user31", "user32", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet31", "user31", 1000, "active").
+						AddRow("wallet32", "user32", 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user31", "idem020", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(feeScheduleQuery).
+					WithArgs("transfer", "USD").
+					WillReturnRows(sqlmock.NewRows(feeTierColumns).AddRow("transfer", "USD", 0, 0, 150, 10, nil))
+				expectNoTransactionLimit(mock, "wallet31", "transfer", "USD")
+				mock.ExpectExec(holdBalanceQuery).
+					WithArgs(510, "wallet31", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertPendingReviewTxn).
+					WithArgs("wallet31", "wallet32", domainwallet.Transfer, domainwallet.PendingReview, "USD", 500, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx31"))
+				mock.ExpectExec(insertTransactionReview).
+					WithArgs("tx31", "new_recipient").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx31")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user31", "idem020", domainwallet.Transfer, "hash-idem020", "tx31", nil, 202, []byte("tx31")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: pendingReviewResponse("tx31"),
+		},
+		{
+			name:            "blocked by a fraud rule",
+			initiatorUserID: "user33",
+			recipientUserID: "user34",
+			idempotencyKey:  "idem021",
+			asset:           "USD",
+			amount:          100,
+			verdict:         domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "new_recipient"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user33-idem021").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user33-idem021").SetVal(true)
+				redisMock.ExpectSet("transfer-user33-idem021", cachedRecord("hash-idem021", failedResponse("tx33", domainwallet.FailureFraudBlocked)), 24*time.Hour).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user33-idem021")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user33", "user34", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet33", "user33", 1000, "active").
+						AddRow("wallet34", "user34", 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user33", "idem021", domainwallet.Transfer.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "transfer", "USD")
+				expectNoTransactionLimit(mock, "wallet33", "transfer", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet33", "wallet34", domainwallet.Transfer, domainwallet.Failed, domainwallet.FailureFraudBlocked, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx33"))
+				expectTransactionEvents(mock, "tx33")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user33", "idem021", domainwallet.Transfer, "hash-idem021", "tx33", nil, 422, failedResponse("tx33", domainwallet.FailureFraudBlocked).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx33", domainwallet.FailureFraudBlocked),
+		},
+		{
+			name:            "deadlock retried and replayed from postgres",
+			initiatorUserID: "user15",
+			recipientUserID: "user16",
+			idempotencyKey:  "idem008",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user15-idem008").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user15-idem008").SetVal(true)
+				redisMock.ExpectSet("transfer-user15-idem008", cachedRecord("hash-idem008", okResponse("tx15")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "transfer-user15-idem008")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user15", "user16", "USD").
+					WillReturnError(&pgconn.PgError{Code: "40P01"})
+				mock.ExpectRollback()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletPairQuery).
+					WithArgs("user15", "user16", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance", "status"}).
+						AddRow("wallet15", "user15", 1000, "active").
+						AddRow("wallet16", "user16", 0, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user15", "idem008", domainwallet.Transfer.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("hash-idem008", 200, []byte("tx15")))
+				mock.ExpectRollback()
+			},
+			expectedResponse: okResponse("tx15"),
+			expectedRetries: map[txRetryMetric]int64{
+				{name: "wallet_tx_retries", key: "transfer.40P01"}: 1,
+			},
+		},
+		{
+			name:            "serialization failure retries exhausted",
+			initiatorUserID: "user17",
+			recipientUserID: "user18",
+			idempotencyKey:  "idem009",
+			asset:           "USD",
+			amount:          100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("transfer-user17-idem009").RedisNil()
+				expectReserveInFlight(redisMock, "transfer-user17-idem009").SetVal(true)
+				expectReleaseInFlight(redisMock, "transfer-user17-idem009")
+			},
+			prepareSQL: func() {
+				for range 3 {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockWalletPairQuery).
+						WithArgs("user17", "user18", "USD").
+						WillReturnError(&pgconn.PgError{Code: "40001"})
+					mock.ExpectRollback()
+				}
+			},
+			expectedError: errors.New("failed to hold row-level lock on wallets"),
+			expectedRetries: map[txRetryMetric]int64{
+				{name: "wallet_tx_retries", key: "transfer.40001"}:           2,
+				{name: "wallet_tx_retries_exhausted", key: "transfer.40001"}: 1,
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			retriesBefore := map[txRetryMetric]int64{}
+			for metric := range tt.expectedRetries {
+				retriesBefore[metric] = txRetries(metric)
+			}
+
 			tt.prepareRedis()
 			tt.prepareSQL()
 
-			txID, err := repo.Transfer(context.Background(), tt.initiatorUserID, tt.recipientUserID, tt.idempotencyKey, tt.amount)
+			resp, err := repo.Transfer(
+				context.Background(),
+				tt.initiatorUserID,
+				tt.recipientUserID,
+				testIdempotencyKey(tt.idempotencyKey),
+				tt.asset,
+				tt.amount,
+				tt.verdict,
+			)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.expectedTxnID, txID)
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+
+			for metric, retries := range tt.expectedRetries {
+				assert.Equal(t, retries, txRetries(metric)-retriesBefore[metric], metric)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/redis/go-redis/v9"
+)
+
+// SubscribeWalletUpdates does the following:
+// 1. Subscribe to the wallet's updates channel and wait for Redis to confirm it, so no event published from then on is missed
+// 2. Decode the TransactionCreatedEvent envelopes published on it into the returned channel, skipping other event types
+// 3. Unsubscribe and close the channel once ctx is done or the subscription is closed
+// Events are fanned out by the outbox relay once committed, so they arrive in order but only while subscribed.
+func (r *Repository) SubscribeWalletUpdates(
+	ctx context.Context,
+	walletID string,
+) (<-chan domainwallet.TransactionCreated, error) {
+	channel := domainwallet.WalletUpdatesChannel(walletID)
+	sub := r.cache.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to redis channel %s: %w", channel, err)
+	}
+
+	updates := make(chan domainwallet.TransactionCreated)
+	go func() {
+		defer close(updates)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				update, ok := r.decodeWalletUpdate(msg)
+				if !ok {
+					continue
+				}
+
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// decodeWalletUpdate decodes a TransactionCreatedEvent or TransactionReviewedEvent envelope, reporting false for other
+// event types and undecodable messages.
+func (r *Repository) decodeWalletUpdate(msg *redis.Message) (domainwallet.TransactionCreated, bool) {
+	var envelope domainwallet.EventEnvelope
+	if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+		r.logger.Error("failed to decode wallet update", slog.String("channel", msg.Channel), slog.Any("error", err))
+		return domainwallet.TransactionCreated{}, false
+	}
+
+	if envelope.Type != domainwallet.TransactionCreatedEvent && envelope.Type != domainwallet.TransactionReviewedEvent {
+		return domainwallet.TransactionCreated{}, false
+	}
+
+	var update domainwallet.TransactionCreated
+	if err := json.Unmarshal(envelope.Data, &update); err != nil {
+		r.logger.Error(
+			"failed to decode wallet update",
+			slog.String("channel", msg.Channel),
+			slog.Int64("event_id", envelope.ID),
+			slog.Any("error", err),
+		)
+		return domainwallet.TransactionCreated{}, false
+	}
+
+	return update, true
+}
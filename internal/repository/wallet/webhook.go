@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+const webhookSubscriptionColumns = `id, url, secret, event_types, user_id, active, created_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+// claimWebhookDeliveriesQuery pushes back the next attempt of up to $3 due pending deliveries of active subscriptions to $2,
+// skipping deliveries claimed by other dispatchers, and returns them with their subscription and event. Deliveries of a
+// dispatcher stopping before recording their attempt are claimed again once $2 passes.
+const claimWebhookDeliveriesQuery = `
+	WITH claimed AS (
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= $1 AND s.active
+			ORDER BY d.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `
+	)
+	SELECT c.id, c.subscription_id, c.event_id, c.status, c.attempts, c.next_attempt_at, c.last_status_code,
+		c.last_error, c.created_at, c.delivered_at, s.url, s.secret,
+		e.id AS "event.id", e.event_type AS "event.event_type", e.wallet_id AS "event.wallet_id",
+		e.payload AS "event.payload", e.created_at AS "event.created_at"
+	FROM claimed c
+	JOIN webhook_subscriptions s ON s.id = c.subscription_id
+	JOIN outbox_events e ON e.id = c.event_id
+	ORDER BY c.event_id
+`
+
+// webhookSubscriptionRow is a subscription as stored, its event types are a JSON array.
+type webhookSubscriptionRow struct {
+	domainwallet.WebhookSubscription
+	EventTypesJSON []byte `db:"event_types"`
+}
+
+func (row webhookSubscriptionRow) subscription() (domainwallet.WebhookSubscription, error) {
+	sub := row.WebhookSubscription
+	if err := json.Unmarshal(row.EventTypesJSON, &sub.EventTypes); err != nil {
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("failed to unmarshal webhook event types: %w", err)
+	}
+
+	return sub, nil
+}
+
+// CreateWebhookSubscription inserts the subscription and returns it with its id.
+func (r *Repository) CreateWebhookSubscription(
+	ctx context.Context,
+	sub domainwallet.WebhookSubscription,
+) (domainwallet.WebhookSubscription, error) {
+	eventTypes, err := json.Marshal(sub.EventTypes)
+	if err != nil {
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("failed to marshal webhook event types: %w", err)
+	}
+
+	var row webhookSubscriptionRow
+	insert := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookSubscriptionColumns
+	err = r.db.GetContext(ctx, &row, insert, sub.URL, sub.Secret, string(eventTypes), sub.UserID)
+	if err != nil {
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+
+	return row.subscription()
+}
+
+// ListWebhookSubscriptions returns every subscription, most recent first.
+func (r *Repository) ListWebhookSubscriptions(ctx context.Context) ([]domainwallet.WebhookSubscription, error) {
+	var rows []webhookSubscriptionRow
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &rows, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	subs := make([]domainwallet.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		sub, err := row.subscription()
+		if err != nil {
+			return nil, err
+		}
+
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// DeactivateWebhookSubscription stops the subscription receiving new events and attempting its pending deliveries,
+// which are kept to be replayed.
+func (r *Repository) DeactivateWebhookSubscription(
+	ctx context.Context,
+	subscriptionID string,
+) (domainwallet.WebhookSubscription, error) {
+	var row webhookSubscriptionRow
+	update := `
+		UPDATE webhook_subscriptions SET active = FALSE, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + webhookSubscriptionColumns
+	err := r.db.GetContext(ctx, &row, update, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.WebhookSubscription{}, fmt.Errorf(
+				"webhook subscription %s: %w",
+				subscriptionID,
+				domainwallet.ErrWebhookNotFound,
+			)
+		}
+
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("failed to deactivate webhook subscription: %w", err)
+	}
+
+	return row.subscription()
+}
+
+// ListWebhookDeliveries does the following:
+// 1. Check the subscription exists
+// 2. Select its latest deliveries, most recent first, of the given status when not empty
+func (r *Repository) ListWebhookDeliveries(
+	ctx context.Context,
+	subscriptionID string,
+	status domainwallet.WebhookDeliveryStatus,
+	limit int,
+) ([]domainwallet.WebhookDelivery, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $1)`, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("webhook subscription %s: %w", subscriptionID, domainwallet.ErrWebhookNotFound)
+	}
+
+	var dst []domainwallet.WebhookDelivery
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status::TEXT = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	err = r.db.SelectContext(ctx, &dst, query, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return dst, nil
+}
+
+// ReplayWebhookDelivery queues the delivery of the subscription to be attempted right away, whatever its status.
+// Dead deliveries get their attempts back.
+func (r *Repository) ReplayWebhookDelivery(
+	ctx context.Context,
+	subscriptionID, deliveryID string,
+) (domainwallet.WebhookDelivery, error) {
+	var dst domainwallet.WebhookDelivery
+	update := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', next_attempt_at = NOW(), delivered_at = NULL,
+			attempts = CASE WHEN status = 'dead' THEN 0 ELSE attempts END
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING ` + webhookDeliveryColumns
+	err := r.db.GetContext(ctx, &dst, update, deliveryID, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainwallet.WebhookDelivery{}, fmt.Errorf(
+				"webhook delivery %s: %w",
+				deliveryID,
+				domainwallet.ErrWebhookDeliveryNotFound,
+			)
+		}
+
+		return domainwallet.WebhookDelivery{}, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return dst, nil
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries due at now for leaseUntil, so concurrent dispatchers
+// never attempt the same delivery at once, and returns them oldest event first.
+func (r *Repository) ClaimWebhookDeliveries(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	limit int,
+) ([]domainwallet.WebhookDispatch, error) {
+	var dst []domainwallet.WebhookDispatch
+	err := r.db.SelectContext(ctx, &dst, claimWebhookDeliveriesQuery, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return dst, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt.
+func (r *Repository) UpdateWebhookDelivery(ctx context.Context, delivery domainwallet.WebhookDelivery) error {
+	update := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(
+		ctx,
+		update,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package wallet_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	insertWebhookSubscriptionQuery     = `INSERT INTO webhook_subscriptions \(url, secret, event_types, user_id\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id, url, secret, event_types, user_id, active, created_at`
+	deactivateWebhookSubscriptionQuery = `UPDATE webhook_subscriptions SET active = FALSE, updated_at = NOW\(\) WHERE id = \$1 RETURNING .*`
+	webhookSubscriptionExistsQuery     = `SELECT EXISTS \(SELECT 1 FROM webhook_subscriptions WHERE id = \$1\)`
+	listWebhookDeliveriesQuery         = `SELECT id, subscription_id, event_id, .* FROM webhook_deliveries WHERE subscription_id = \$1 AND \(\$2 = '' OR status::TEXT = \$2\) ORDER BY created_at DESC LIMIT \$3`
+	replayWebhookDeliveryQuery         = `UPDATE webhook_deliveries SET status = 'pending', next_attempt_at = NOW\(\), delivered_at = NULL, attempts = CASE WHEN status = 'dead' THEN 0 ELSE attempts END WHERE id = \$1 AND subscription_id = \$2 RETURNING .*`
+	claimWebhookDeliveriesQuery        = `WITH claimed AS \( UPDATE webhook_deliveries SET next_attempt_at = \$2 .* FOR UPDATE OF d SKIP LOCKED \) RETURNING .* \) SELECT c.id, .* FROM claimed c .* ORDER BY c.event_id`
+	updateWebhookDeliveryQuery         = `UPDATE webhook_deliveries SET status = \$2, attempts = \$3, next_attempt_at = \$4, last_status_code = \$5, last_error = \$6, delivered_at = \$7 WHERE id = \$1`
+)
+
+var (
+	webhookSubscriptionColumns = []string{"id", "url", "secret", "event_types", "user_id", "active", "created_at"}
+	webhookDeliveryColumns     = []string{
+		"id", "subscription_id", "event_id", "status", "attempts", "next_attempt_at",
+		"last_status_code", "last_error", "created_at", "delivered_at",
+	}
+)
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	userID := "user-1"
+	sub := domainwallet.WebhookSubscription{
+		URL:        "https://example.com/hooks",
+		Secret:     "whsec_0123456789abcdef",
+		EventTypes: []string{domainwallet.TransactionCreatedEvent},
+		UserID:     &userID,
+		Active:     true,
+	}
+
+	mock.ExpectQuery(insertWebhookSubscriptionQuery).
+		WithArgs(sub.URL, sub.Secret, `["wallet.transaction.created"]`, &userID).
+		WillReturnRows(sqlmock.NewRows(webhookSubscriptionColumns).
+			AddRow("sub-1", sub.URL, sub.Secret, []byte(`["wallet.transaction.created"]`), userID, true, createdAt))
+
+	created, err := r.CreateWebhookSubscription(context.Background(), sub)
+
+	require.NoError(t, err)
+	sub.ID = "sub-1"
+	sub.CreatedAt = createdAt
+	assert.Equal(t, sub, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeactivateWebhookSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		prepareMock   func()
+		expectedSub   domainwallet.WebhookSubscription
+		expectedError error
+	}{
+		{
+			name: "subscription deactivated",
+			prepareMock: func() {
+				mock.ExpectQuery(deactivateWebhookSubscriptionQuery).
+					WithArgs("sub-1").
+					WillReturnRows(sqlmock.NewRows(webhookSubscriptionColumns).
+						AddRow("sub-1", "https://example.com/hooks", "whsec_0123456789abcdef",
+							[]byte(`["wallet.transaction.created"]`), nil, false, createdAt))
+			},
+			expectedSub: domainwallet.WebhookSubscription{
+				ID:         "sub-1",
+				URL:        "https://example.com/hooks",
+				Secret:     "whsec_0123456789abcdef",
+				EventTypes: []string{domainwallet.TransactionCreatedEvent},
+				CreatedAt:  createdAt,
+			},
+		},
+		{
+			name: "subscription not found",
+			prepareMock: func() {
+				mock.ExpectQuery(deactivateWebhookSubscriptionQuery).
+					WithArgs("sub-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			sub, err := r.DeactivateWebhookSubscription(context.Background(), "sub-1")
+			assert.Equal(t, tt.expectedSub, sub)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	lastError := "connection refused"
+
+	tests := []struct {
+		name               string
+		prepareMock        func()
+		expectedDeliveries []domainwallet.WebhookDelivery
+		expectedError      error
+	}{
+		{
+			name: "dead deliveries listed",
+			prepareMock: func() {
+				mock.ExpectQuery(webhookSubscriptionExistsQuery).
+					WithArgs("sub-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(listWebhookDeliveriesQuery).
+					WithArgs("sub-1", domainwallet.WebhookDead, 50).
+					WillReturnRows(sqlmock.NewRows(webhookDeliveryColumns).
+						AddRow("d-1", "sub-1", 7, "dead", 8, createdAt, nil, lastError, createdAt, nil))
+			},
+			expectedDeliveries: []domainwallet.WebhookDelivery{
+				{
+					ID:             "d-1",
+					SubscriptionID: "sub-1",
+					EventID:        7,
+					Status:         domainwallet.WebhookDead,
+					Attempts:       8,
+					NextAttemptAt:  createdAt,
+					LastError:      &lastError,
+					CreatedAt:      createdAt,
+				},
+			},
+		},
+		{
+			name: "subscription not found",
+			prepareMock: func() {
+				mock.ExpectQuery(webhookSubscriptionExistsQuery).
+					WithArgs("sub-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: domainwallet.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			deliveries, err := r.ListWebhookDeliveries(context.Background(), "sub-1", domainwallet.WebhookDead, 50)
+			assert.Equal(t, tt.expectedDeliveries, deliveries)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		prepareMock      func()
+		expectedDelivery domainwallet.WebhookDelivery
+		expectedError    error
+	}{
+		{
+			name: "dead delivery replayed",
+			prepareMock: func() {
+				mock.ExpectQuery(replayWebhookDeliveryQuery).
+					WithArgs("d-1", "sub-1").
+					WillReturnRows(sqlmock.NewRows(webhookDeliveryColumns).
+						AddRow("d-1", "sub-1", 7, "pending", 0, now, 500, nil, now, nil))
+			},
+			expectedDelivery: domainwallet.WebhookDelivery{
+				ID:             "d-1",
+				SubscriptionID: "sub-1",
+				EventID:        7,
+				Status:         domainwallet.WebhookPending,
+				NextAttemptAt:  now,
+				LastStatusCode: func() *int { code := 500; return &code }(),
+				CreatedAt:      now,
+			},
+		},
+		{
+			name: "delivery of another subscription",
+			prepareMock: func() {
+				mock.ExpectQuery(replayWebhookDeliveryQuery).
+					WithArgs("d-1", "sub-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domainwallet.ErrWebhookDeliveryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMock()
+			delivery, err := r.ReplayWebhookDelivery(context.Background(), "sub-1", "d-1")
+			assert.Equal(t, tt.expectedDelivery, delivery)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	leaseUntil := now.Add(time.Minute)
+	columns := append(append([]string{}, webhookDeliveryColumns...),
+		"url", "secret", "event.id", "event.event_type", "event.wallet_id", "event.payload", "event.created_at")
+
+	mock.ExpectQuery(claimWebhookDeliveriesQuery).
+		WithArgs(now, leaseUntil, 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("d-1", "sub-1", 7, "pending", 1, leaseUntil, nil, nil, now, nil,
+				"https://example.com/hooks", "whsec_0123456789abcdef",
+				7, domainwallet.TransactionCreatedEvent, "wallet-1", []byte(`{}`), now))
+
+	dispatches, err := r.ClaimWebhookDeliveries(context.Background(), now, leaseUntil, 20)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domainwallet.WebhookDispatch{
+		{
+			WebhookDelivery: domainwallet.WebhookDelivery{
+				ID:             "d-1",
+				SubscriptionID: "sub-1",
+				EventID:        7,
+				Status:         domainwallet.WebhookPending,
+				Attempts:       1,
+				NextAttemptAt:  leaseUntil,
+				CreatedAt:      now,
+			},
+			URL:    "https://example.com/hooks",
+			Secret: "whsec_0123456789abcdef",
+			Event: domainwallet.OutboxEvent{
+				ID:        7,
+				Type:      domainwallet.TransactionCreatedEvent,
+				WalletID:  "wallet-1",
+				Payload:   []byte(`{}`),
+				CreatedAt: now,
+			},
+		},
+	}, dispatches)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	r := wallet.New(sqlxDB, nil, nil)
+
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	code := 204
+	delivery := domainwallet.WebhookDelivery{
+		ID:             "d-1",
+		Status:         domainwallet.WebhookSucceeded,
+		Attempts:       2,
+		NextAttemptAt:  now,
+		LastStatusCode: &code,
+		DeliveredAt:    &now,
+	}
+
+	mock.ExpectExec(updateWebhookDeliveryQuery).
+		WithArgs("d-1", domainwallet.WebhookSucceeded, 2, now, &code, nil, &now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = r.UpdateWebhookDelivery(context.Background(), delivery)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,82 +5,121 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log/slog"
 
 	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
-	"github.com/redis/go-redis/v9"
 )
 
 const withdrawCacheKey = `withdraw-%s-%s` // withdraw-userID-idempotencyKey
 
-type userWallet struct {
-	ID      string `db:"id"`
-	Balance uint64 `db:"balance"`
-}
-
 // WithdrawWallet does the following:
-// 1. Check from redis cache on key = withdraw-{userID}-{idempotencyKey}, if exists we just return nil error
-// 2. If not, proceed with withdraw amount from user wallet
-// 3. Cache if successful and return appriopriate errors (insufficient balance)
+// 1. Check from redis cache on key = withdraw-{userID}-{idempotencyKey}, if exists we just return the recorded response
+// 2. If not, reserve the key while in flight, lock the user wallet and check the idempotency key recorded in postgres
+// 3. Price the withdrawal with the fee schedule, then proceed with withdraw amount and fee of the asset from user wallet and post the balanced ledger journal crediting the fee to the house fee account, or record the withdrawal as failed with its reason on frozen/closed wallet, insufficient balance, exceeded limit or fraud block
+// 4. Withdrawals the fraud rules flag reserve the amount and fee and are held for review instead, see holdForReview
+// 5. Record the response on the idempotency key in the same db transaction, cache it and return appriopriate errors
 func (r *Repository) WithdrawWallet(
 	ctx context.Context,
-	userID, idempotencyKey string,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	cacheKey := fmt.Sprintf(withdrawCacheKey, userID, idempotencyKey)
-	cachedTxID, err := r.cache.Get(ctx, cacheKey).Result()
+	verdict domainwallet.FraudVerdict,
+) (domainwallet.IdempotentResponse, error) {
+	cacheKey := fmt.Sprintf(withdrawCacheKey, userID, key.Key)
 	// Idempotent: already processed
-	if err == nil {
-		return cachedTxID, nil
+	if resp, found, err := r.cachedResponse(ctx, cacheKey, key); err != nil || found {
+		return resp, err
 	}
 
-	if err != nil && err != redis.Nil {
-		return "", fmt.Errorf("redis get failed: %w", err)
+	// Reserve the key while in flight, so concurrent duplicates are rejected instead of racing the cache miss
+	release, err := r.reserveInFlight(ctx, cacheKey)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, err
 	}
+	defer release()
 
 	// it's a new withdrawal operation
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to begin database tx: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to begin database tx: %w", err)
 	}
 	defer tx.Rollback()
 
 	// Hold row-level lock on user wallet
 	var dbWallet userWallet
-	query := `SELECT id, balance FROM wallets WHERE user_id = $1 FOR UPDATE`
-	err = tx.GetContext(ctx, &dbWallet, query, userID)
+	err = tx.GetContext(ctx, &dbWallet, lockWalletQuery, userID, asset)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
+			return domainwallet.IdempotentResponse{}, fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound)
 		}
 
-		return "", fmt.Errorf(
+		return domainwallet.IdempotentResponse{}, fmt.Errorf(
 			"failed to hold row-level lock on wallet: %w, userID: %s",
 			err,
 			userID,
 		)
 	}
 
-	// Insufficient balance
-	if dbWallet.Balance < amount {
-		return "", fmt.Errorf(
-			"insufficient balance to deduct: %w",
-			domainwallet.ErrWalletInsufficientBalance,
-		)
+	// Idempotent: already processed, with the key missing or evicted from cache
+	if resp, found, err := r.lookupIdempotencyKey(ctx, tx, cacheKey, userID, domainwallet.Withdraw.Operation(), key); err != nil || found {
+		return resp, err
 	}
 
-	// Update (deduct) balance
-	update := `UPDATE wallets SET balance = balance - $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, update, amount, dbWallet.ID)
+	attempt := attemptedTransaction{
+		Operation:         domainwallet.Withdraw.Operation(),
+		InitiatorWalletID: dbWallet.ID,
+		Type:              domainwallet.Withdraw,
+		Asset:             asset,
+		Amount:            amount,
+	}
+
+	// Frozen or closed wallets cannot be debited, recorded as a failed withdrawal
+	if err := dbWallet.Status.CanDebit(); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, err)
+	}
+
+	fee, err := quoteFee(ctx, tx, domainwallet.Withdraw, asset, amount)
 	if err != nil {
-		return "", fmt.Errorf("failed to update balance: %w", err)
+		return domainwallet.IdempotentResponse{}, err
+	}
+
+	// Insufficient balance for the amount and its fee
+	if dbWallet.available() < fee.Total {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, domainwallet.ErrWalletInsufficientBalance)
+	}
+
+	// Withdrawals over the wallet's limits are recorded as failed too
+	if err := checkTransactionLimit(ctx, tx, dbWallet.ID, domainwallet.Withdraw, asset, amount); err != nil {
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, err)
+	}
+
+	// Withdrawals the fraud rules block are recorded as failed, the ones they flag are held for review instead
+	switch verdict.Decision {
+	case domainwallet.FraudBlock:
+		return r.failTransaction(ctx, tx, cacheKey, userID, key, attempt, domainwallet.ErrTransactionBlocked)
+	case domainwallet.FraudReview:
+		return r.holdForReview(ctx, tx, cacheKey, userID, key, attempt, fee, verdict.Rule)
+	}
+
+	journal, err := fee.Journal(
+		domainwallet.WalletAccount(dbWallet.ID),
+		domainwallet.SystemLedgerAccount(domainwallet.ExternalCashOut),
+	)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to build journal: %w", err)
+	}
+
+	// Update (deduct) balance by the amount and its fee
+	_, err = tx.ExecContext(ctx, debitBalanceQuery, fee.Total, dbWallet.ID, asset)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to update balance: %w", err)
 	}
 
 	// Insert transaction record
 	var transactionID string
 	insertTxn := `
-		INSERT INTO transactions (initiator_wallet_id, type, status, amount, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
+		INSERT INTO transactions (initiator_wallet_id, type, status, asset, amount, fee, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
 		RETURNING id
 	`
 	err = tx.GetContext(
@@ -90,27 +129,19 @@ func (r *Repository) WithdrawWallet(
 		dbWallet.ID,
 		domainwallet.Withdraw,
 		domainwallet.Success,
+		asset,
 		amount,
+		fee.Fee,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to insert transaction record: %w", err)
-	}
-
-	// Commit transaction
-	err = tx.Commit()
-	if err != nil {
-		return "", fmt.Errorf("failed to commit tx: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("failed to insert transaction record: %w", err)
 	}
 
-	// Cache idempotency Key
-	if err := r.cache.Set(ctx, cacheKey, transactionID, ttl).Err(); err != nil {
-		r.logger.Error(
-			"failed to cache idempotency key on WithdrawWallet",
-			slog.String("userID", userID),
-			slog.String("idempotencyKey", idempotencyKey),
-			slog.String("transactionID", transactionID),
-		)
+	// Post balanced ledger entries for the transaction
+	if err := postJournal(ctx, tx, transactionID, journal); err != nil {
+		return domainwallet.IdempotentResponse{}, err
 	}
 
-	return transactionID, nil
+	// Record the response on the idempotency key and commit transaction
+	return r.commitIdempotent(ctx, tx, cacheKey, userID, domainwallet.Withdraw.Operation(), key, transactionID, fee, nil)
 }
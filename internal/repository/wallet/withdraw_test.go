@@ -19,6 +19,13 @@ import (
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
 )
 
+const (
+	lockWalletQuery      = `SELECT w.id, w.status, COALESCE\(b.balance, 0\) AS balance, COALESCE\(b.held, 0\) AS held FROM wallets w .* FOR UPDATE OF w`
+	idempotencyKeyQuery  = `SELECT request_hash, response_status, response_body FROM idempotency_keys WHERE user_id = \$1 AND idempotency_key = \$2 AND scope = 'user' AND operation = \$3`
+	insertIdempotencyKey = `INSERT INTO idempotency_keys \(user_id, idempotency_key, operation, request_hash, transaction_id, hold_id, response_status, response_body, created_at\)`
+	insertFailedTxn      = `INSERT INTO transactions \(initiator_wallet_id, recipient_wallet_id, type, status, failure_reason, asset, amount, created_at\)`
+)
+
 func TestWithdrawWallet(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -30,99 +37,572 @@ func TestWithdrawWallet(t *testing.T) {
 	repo := wallet.New(sqlxDB, redisClient, logger)
 
 	tests := []struct {
-		name           string
-		userID         string
-		idempotencyKey string
-		amount         uint64
-		prepareRedis   func()
-		prepareSQL     func()
-		expectedError  error
-		expectTxnID    string
+		name             string
+		userID           string
+		idempotencyKey   string
+		asset            string
+		amount           uint64
+		verdict          domainwallet.FraudVerdict
+		prepareRedis     func()
+		prepareSQL       func()
+		expectedError    error
+		expectedResponse domainwallet.IdempotentResponse
 	}{
 		{
 			name:           "already processed idempotent request",
 			userID:         "user123",
 			idempotencyKey: "idem123",
+			asset:          "USD",
 			amount:         100,
 			prepareRedis: func() {
-				redisMock.ExpectGet("withdraw-user123-idem123").SetVal("tx-already")
+				redisMock.ExpectGet("withdraw-user123-idem123").SetVal(cachedRecord("hash-idem123", okResponse("tx-already")))
+			},
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("tx-already"),
+		},
+		{
+			name:           "already processed request cached as transaction ID",
+			userID:         "user123",
+			idempotencyKey: "idem131",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user123-idem131").SetVal("tx131")
+			},
+			prepareSQL:       func() {},
+			expectedResponse: okResponse("tx131"),
+		},
+		{
+			name:           "already failed request cached with failure reason",
+			userID:         "user129",
+			idempotencyKey: "idem129",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user129-idem129").SetVal("tx129:insufficient_balance")
+			},
+			prepareSQL:       func() {},
+			expectedResponse: failedResponse("tx129", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:           "cached idempotency key reused for a different request",
+			userID:         "user132",
+			idempotencyKey: "idem132",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user132-idem132").SetVal(cachedRecord("other-hash", okResponse("tx132")))
 			},
 			prepareSQL:    func() {},
-			expectTxnID:   "tx-already",
-			expectedError: nil,
+			expectedError: domainwallet.ErrIdempotencyKeyReused,
+		},
+		{
+			name:           "concurrent duplicate request in flight",
+			userID:         "user136",
+			idempotencyKey: "idem136",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user136-idem136").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user136-idem136").SetVal(false)
+			},
+			prepareSQL:    func() {},
+			expectedError: domainwallet.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name:           "in-flight reservation error falls back to postgres",
+			userID:         "user137",
+			idempotencyKey: "idem137",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user137-idem137").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user137-idem137").SetErr(errors.New("redis failure"))
+				redisMock.ExpectSet("withdraw-user137-idem137", cachedRecord("hash-idem137", okResponse("tx137")), time.Hour*24).SetVal("OK")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user137", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet137", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user137", "idem137", domainwallet.Withdraw.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("hash-idem137", 200, []byte("tx137")))
+				mock.ExpectRollback()
+			},
+			expectedResponse: okResponse("tx137"),
 		},
 		{
 			name:           "wallet not found",
 			userID:         "user124",
 			idempotencyKey: "idem124",
+			asset:          "USD",
 			amount:         100,
 			prepareRedis: func() {
 				redisMock.ExpectGet("withdraw-user124-idem124").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user124-idem124").SetVal(true)
+				expectReleaseInFlight(redisMock, "withdraw-user124-idem124")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user124").
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user124", "USD").
 					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
 			expectedError: fmt.Errorf("wallet not found: %w", domainwallet.ErrWalletNotFound),
 		},
+		{
+			name:           "already processed request recorded in postgres",
+			userID:         "user133",
+			idempotencyKey: "idem133",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user133-idem133").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user133-idem133").SetVal(true)
+				redisMock.ExpectSet("withdraw-user133-idem133", cachedRecord("hash-idem133", okResponse("tx133")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user133-idem133")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user133", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet133", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user133", "idem133", domainwallet.Withdraw.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("hash-idem133", 200, []byte("tx133")))
+				mock.ExpectRollback()
+			},
+			expectedResponse: okResponse("tx133"),
+		},
+		{
+			name:           "idempotency key recorded in postgres for a different request",
+			userID:         "user134",
+			idempotencyKey: "idem134",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user134-idem134").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user134-idem134").SetVal(true)
+				expectReleaseInFlight(redisMock, "withdraw-user134-idem134")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user134", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet134", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user134", "idem134", domainwallet.Withdraw.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("other-hash", 200, []byte("tx134")))
+				mock.ExpectRollback()
+			},
+			expectedError: domainwallet.ErrIdempotencyKeyReused,
+		},
+		{
+			name:           "frozen wallet",
+			userID:         "user128",
+			idempotencyKey: "idem128",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user128-idem128").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user128-idem128").SetVal(true)
+				redisMock.ExpectSet("withdraw-user128-idem128", cachedRecord("hash-idem128", failedResponse("tx128", domainwallet.FailureWalletFrozen)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user128-idem128")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user128", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet128", 1000, "frozen"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user128", "idem128", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet128", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureWalletFrozen, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx128"))
+				expectTransactionEvents(mock, "tx128")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user128", "idem128", domainwallet.Withdraw, "hash-idem128", "tx128", nil, 422, failedResponse("tx128", domainwallet.FailureWalletFrozen).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx128", domainwallet.FailureWalletFrozen),
+		},
 		{
 			name:           "insufficient balance",
 			userID:         "user125",
 			idempotencyKey: "idem125",
+			asset:          "USD",
 			amount:         500,
 			prepareRedis: func() {
 				redisMock.ExpectGet("withdraw-user125-idem125").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user125-idem125").SetVal(true)
+				redisMock.ExpectSet("withdraw-user125-idem125", cachedRecord("hash-idem125", failedResponse("tx125", domainwallet.FailureInsufficientBalance)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user125-idem125")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user125", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet125", 100, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user125", "idem125", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet125", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureInsufficientBalance, "USD", 500).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx125"))
+				expectTransactionEvents(mock, "tx125")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user125", "idem125", domainwallet.Withdraw, "hash-idem125", "tx125", nil, 422, failedResponse("tx125", domainwallet.FailureInsufficientBalance).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx125", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:           "insufficient available balance with funds on hold",
+			userID:         "user138",
+			idempotencyKey: "idem138",
+			asset:          "USD",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user138-idem138").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user138-idem138").SetVal(true)
+				redisMock.ExpectSet("withdraw-user138-idem138", cachedRecord("hash-idem138", failedResponse("tx138", domainwallet.FailureInsufficientBalance)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user138-idem138")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user125").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet125", 100))
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user138", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "held", "status"}).AddRow("wallet138", 1000, 600, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user138", "idem138", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet138", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureInsufficientBalance, "USD", 500).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx138"))
+				expectTransactionEvents(mock, "tx138")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user138", "idem138", domainwallet.Withdraw, "hash-idem138", "tx138", nil, 422, failedResponse("tx138", domainwallet.FailureInsufficientBalance).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx138", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:           "balance covers the amount but not its fee",
+			userID:         "user141",
+			idempotencyKey: "idem141",
+			asset:          "USD",
+			amount:         1000,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user141-idem141").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user141-idem141").SetVal(true)
+				redisMock.ExpectSet("withdraw-user141-idem141", cachedRecord("hash-idem141", failedResponse("tx141", domainwallet.FailureInsufficientBalance)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user141-idem141")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user141", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet141", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user141", "idem141", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet141", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureInsufficientBalance, "USD", 1000).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx141"))
+				expectTransactionEvents(mock, "tx141")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user141", "idem141", domainwallet.Withdraw, "hash-idem141", "tx141", nil, 422, failedResponse("tx141", domainwallet.FailureInsufficientBalance).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx141", domainwallet.FailureInsufficientBalance),
+		},
+		{
+			name:           "over per transaction limit",
+			userID:         "user139",
+			idempotencyKey: "idem139",
+			asset:          "USD",
+			amount:         1500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user139-idem139").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user139-idem139").SetVal(true)
+				redisMock.ExpectSet("withdraw-user139-idem139", cachedRecord("hash-idem139", failedResponse("tx139", domainwallet.FailureLimitExceeded)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user139-idem139")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user139", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet139", 5000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user139", "idem139", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				expectTransactionLimit(mock, "wallet139", "withdraw", "USD", 0)
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet139", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureLimitExceeded, "USD", 1500).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx139"))
+				expectTransactionEvents(mock, "tx139")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user139", "idem139", domainwallet.Withdraw, "hash-idem139", "tx139", nil, 422, failedResponse("tx139", domainwallet.FailureLimitExceeded).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx139", domainwallet.FailureLimitExceeded),
+		},
+		{
+			name:           "blocked by a fraud rule",
+			userID:         "user142",
+			idempotencyKey: "idem142",
+			asset:          "USD",
+			amount:         100,
+			verdict:        domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "withdrawal_velocity"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user142-idem142").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user142-idem142").SetVal(true)
+				redisMock.ExpectSet("withdraw-user142-idem142", cachedRecord("hash-idem142", failedResponse("tx142", domainwallet.FailureFraudBlocked)), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user142-idem142")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user142", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet142", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user142", "idem142", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				expectNoTransactionLimit(mock, "wallet142", "withdraw", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WithArgs("wallet142", nil, domainwallet.Withdraw, domainwallet.Failed, domainwallet.FailureFraudBlocked, "USD", 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx142"))
+				expectTransactionEvents(mock, "tx142")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user142", "idem142", domainwallet.Withdraw, "hash-idem142", "tx142", nil, 422, failedResponse("tx142", domainwallet.FailureFraudBlocked).Body).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: failedResponse("tx142", domainwallet.FailureFraudBlocked),
+		},
+		{
+			name:           "held for review by a fraud rule",
+			userID:         "user143",
+			idempotencyKey: "idem143",
+			asset:          "USD",
+			amount:         200,
+			verdict:        domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "new_wallet_drain"},
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user143-idem143").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user143-idem143").SetVal(true)
+				redisMock.ExpectSet("withdraw-user143-idem143", cachedRecord("hash-idem143", pendingReviewResponse("tx143")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user143-idem143")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user143", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet143", 210, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user143", "idem143", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				expectNoTransactionLimit(mock, "wallet143", "withdraw", "USD")
+				mock.ExpectExec(holdBalanceQuery).
+					WithArgs(210, "wallet143", "USD").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(insertPendingReviewTxn).
+					WithArgs("wallet143", nil, domainwallet.Withdraw, domainwallet.PendingReview, "USD", 200, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx143"))
+				mock.ExpectExec(insertTransactionReview).
+					WithArgs("tx143", "new_wallet_drain").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectTransactionEvents(mock, "tx143")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user143", "idem143", domainwallet.Withdraw, "hash-idem143", "tx143", nil, 202, []byte("tx143")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: pendingReviewResponse("tx143"),
+		},
+		{
+			name:           "error reading limit usage",
+			userID:         "user140",
+			idempotencyKey: "idem140",
+			asset:          "USD",
+			amount:         100,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user140-idem140").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user140-idem140").SetVal(true)
+				expectReleaseInFlight(redisMock, "withdraw-user140-idem140")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user140", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet140", 5000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user140", "idem140", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(walletLimitsQuery).
+					WithArgs("wallet140", "withdraw", "USD").
+					WillReturnRows(sqlmock.NewRows(transactionLimitColumns).
+						AddRow(nil, nil, "withdraw", "USD", 1000, nil, 5, nil, nil, nil, nil))
+				mock.ExpectQuery(limitUsageQuery).
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("failed to get transaction limit usage: db error"),
+		},
+		{
+			name:           "error recording failed withdraw",
+			userID:         "user130",
+			idempotencyKey: "idem130",
+			asset:          "USD",
+			amount:         500,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user130-idem130").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user130-idem130").SetVal(true)
+				expectReleaseInFlight(redisMock, "withdraw-user130-idem130")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user130", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet130", 100, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user130", "idem130", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				mock.ExpectQuery(insertFailedTxn).
+					WillReturnError(errors.New("db error"))
 				mock.ExpectRollback()
 			},
-			expectedError: fmt.Errorf("insufficient balance to deduct: %w", domainwallet.ErrWalletInsufficientBalance),
+			expectedError: errors.New("failed to insert failed transaction record: db error"),
 		},
 		{
 			name:           "successful withdraw",
 			userID:         "user126",
 			idempotencyKey: "idem126",
+			asset:          "USD",
 			amount:         200,
 			prepareRedis: func() {
 				redisMock.ExpectGet("withdraw-user126-idem126").RedisNil()
-				redisMock.ExpectSet("withdraw-user126-idem126", "tx126", time.Hour*24).SetVal("OK")
+				expectReserveInFlight(redisMock, "withdraw-user126-idem126").SetVal(true)
+				redisMock.ExpectSet("withdraw-user126-idem126", cachedRecord("hash-idem126", okResponse("tx126 fee 10 of 210")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user126-idem126")
 			},
 			prepareSQL: func() {
 				mock.ExpectBegin()
 
-				mock.ExpectQuery(`SELECT id, balance FROM wallets WHERE user_id = \$1 FOR UPDATE`).
-					WithArgs("user126").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("wallet126", 1000))
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user126", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet126", 1000, "active"))
 
-				mock.ExpectExec(`UPDATE wallets SET balance = balance - \$1 WHERE id = \$2`).
-					WithArgs(200, "wallet126").
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user126", "idem126", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+
+				expectFeeSchedule(mock, "withdraw", "USD", 10)
+				expectNoTransactionLimit(mock, "wallet126", "withdraw", "USD")
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WithArgs(210, "wallet126", "USD").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
-					WithArgs("wallet126", "withdraw", "success", 200).
+					WithArgs("wallet126", "withdraw", "success", "USD", 200, 10).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx126"))
 
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WithArgs("tx126").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal126"))
+
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WithArgs("journal126", "wallet126", nil, "USD", -200, "journal126", nil, "external_cash_out", "USD", 200,
+						"journal126", "wallet126", nil, "USD", -10, "journal126", nil, "fees", "USD", 10).
+					WillReturnResult(sqlmock.NewResult(4, 4))
+
+				expectTransactionEvents(mock, "tx126")
+				mock.ExpectExec(insertIdempotencyKey).
+					WithArgs("user126", "idem126", domainwallet.Withdraw, "hash-idem126", "tx126", nil, 200, []byte("tx126 fee 10 of 210")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
-			expectTxnID:   "tx126",
-			expectedError: nil,
+			expectedResponse: okResponse("tx126 fee 10 of 210"),
+		},
+		{
+			name:           "error recording idempotency key",
+			userID:         "user135",
+			idempotencyKey: "idem135",
+			asset:          "USD",
+			amount:         200,
+			prepareRedis: func() {
+				redisMock.ExpectGet("withdraw-user135-idem135").RedisNil()
+				expectReserveInFlight(redisMock, "withdraw-user135-idem135").SetVal(true)
+				expectReleaseInFlight(redisMock, "withdraw-user135-idem135")
+			},
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user135", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet135", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user135", "idem135", domainwallet.Withdraw.Operation()).
+					WillReturnError(sql.ErrNoRows)
+				expectNoFeeSchedule(mock, "withdraw", "USD")
+				expectNoTransactionLimit(mock, "wallet135", "withdraw", "USD")
+				mock.ExpectExec(`UPDATE balances SET balance = balance - \$1 WHERE wallet_id = \$2 AND asset = \$3`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO transactions .* RETURNING id`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tx135"))
+				mock.ExpectQuery(`INSERT INTO ledger_journals \(transaction_id\) VALUES \(\$1\) RETURNING id`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("journal135"))
+				mock.ExpectExec(`INSERT INTO ledger_entries \(journal_id, wallet_id, system_account, asset, amount\)`).
+					WillReturnResult(sqlmock.NewResult(2, 2))
+				expectTransactionEvents(mock, "tx135")
+				mock.ExpectExec(insertIdempotencyKey).
+					WillReturnError(errors.New("duplicate key"))
+				mock.ExpectRollback()
+			},
+			expectedError: errors.New("failed to insert idempotency key: duplicate key"),
 		},
 		{
-			name:           "redis get failure",
+			name:           "redis get failure falls back to postgres",
 			userID:         "user127",
 			idempotencyKey: "idem127",
+			asset:          "USD",
 			amount:         100,
 			prepareRedis: func() {
 				redisMock.ExpectGet("withdraw-user127-idem127").SetErr(errors.New("redis failure"))
+				expectReserveInFlight(redisMock, "withdraw-user127-idem127").SetVal(true)
+				redisMock.ExpectSet("withdraw-user127-idem127", cachedRecord("hash-idem127", okResponse("tx127")), time.Hour*24).SetVal("OK")
+				expectReleaseInFlight(redisMock, "withdraw-user127-idem127")
 			},
-			prepareSQL:    func() {},
-			expectedError: fmt.Errorf("redis get failed: %w", errors.New("redis failure")),
+			prepareSQL: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWalletQuery).
+					WithArgs("user127", "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "status"}).AddRow("wallet127", 1000, "active"))
+				mock.ExpectQuery(idempotencyKeyQuery).
+					WithArgs("user127", "idem127", domainwallet.Withdraw.Operation()).
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response_status", "response_body"}).
+						AddRow("hash-idem127", 200, []byte("tx127")))
+				mock.ExpectRollback()
+			},
+			expectedResponse: okResponse("tx127"),
 		},
 	}
 
@@ -131,14 +611,21 @@ func TestWithdrawWallet(t *testing.T) {
 			tt.prepareRedis()
 			tt.prepareSQL()
 
-			txID, err := repo.WithdrawWallet(context.Background(), tt.userID, tt.idempotencyKey, tt.amount)
+			resp, err := repo.WithdrawWallet(
+				context.Background(),
+				tt.userID,
+				testIdempotencyKey(tt.idempotencyKey),
+				tt.asset,
+				tt.amount,
+				tt.verdict,
+			)
 
 			if tt.expectedError != nil {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.expectTxnID, txID)
+				assert.Equal(t, tt.expectedResponse, resp)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// CreateAPIKey validates and creates an API key with the scopes, returning its secret derived with serverSecret,
+// which is not stored and cannot be retrieved again.
+func (s *Service) CreateAPIKey(
+	ctx context.Context,
+	serverSecret, name string,
+	scopes []string,
+) (domainwallet.APIKey, string, error) {
+	key, secret, err := domainwallet.NewAPIKey(uuid.NewString(), name, scopes, serverSecret)
+	if err != nil {
+		return domainwallet.APIKey{}, "", err
+	}
+
+	key, err = s.walletRepo.CreateAPIKey(ctx, key)
+	if err != nil {
+		return domainwallet.APIKey{}, "", fmt.Errorf("create api key repo err: %w", err)
+	}
+
+	return key, secret, nil
+}
+
+// ListAPIKeys returns every API key, revoked ones included, most recent first.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]domainwallet.APIKey, error) {
+	keys, err := s.walletRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys repo err: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes the API key, its requests are rejected from then on.
+func (s *Service) RevokeAPIKey(ctx context.Context, keyID string) (domainwallet.APIKey, error) {
+	key, err := s.walletRepo.RevokeAPIKey(ctx, keyID)
+	if err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("revoke api key repo err: %w", err)
+	}
+
+	return key, nil
+}
+
+// AuthenticateAPIRequest returns the active API key the request was signed with, less than window ago.
+func (s *Service) AuthenticateAPIRequest(
+	ctx context.Context,
+	serverSecret string,
+	req domainwallet.SignedAPIRequest,
+	window time.Duration,
+) (domainwallet.APIKey, error) {
+	key, err := s.walletRepo.GetAPIKey(ctx, req.KeyID)
+	if err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("get api key repo err: %w", err)
+	}
+
+	secret, err := key.Secret(serverSecret)
+	if err != nil {
+		return domainwallet.APIKey{}, err
+	}
+
+	if err := req.Verify(secret, time.Now(), window); err != nil {
+		return domainwallet.APIKey{}, fmt.Errorf("api key %s: %w", key.ID, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,147 @@
+package wallet_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiKeyServerSecret = "server-secret-0123456789abcdefghij"
+
+func TestCreateAPIKey(t *testing.T) {
+	testCases := []struct {
+		name        string
+		scopes      []string
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name:   "key created with its secret",
+			scopes: []string{wallet.ScopeWalletDeposit},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key wallet.APIKey) (wallet.APIKey, error) {
+						return key, nil
+					})
+			},
+		},
+		{
+			name:        "unknown scope",
+			scopes:      []string{"wallet:write"},
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			key, secret, err := s.CreateAPIKey(context.Background(), apiKeyServerSecret, "payment-gateway", tc.scopes)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr != nil {
+				return
+			}
+
+			assert.NotEmpty(t, key.ID)
+			assert.Equal(t, wallet.DeriveAPIKeySecret(apiKeyServerSecret, key.ID), secret)
+			assert.Equal(t, wallet.HashAPIKeySecret(secret), key.SecretHash)
+		})
+	}
+}
+
+func TestAuthenticateAPIRequest(t *testing.T) {
+	key, secret, err := wallet.NewAPIKey("key-1", "payment-gateway", []string{wallet.ScopeWalletDeposit}, apiKeyServerSecret)
+	require.NoError(t, err)
+
+	revokedAt := time.Now()
+	revoked := key
+	revoked.RevokedAt = &revokedAt
+
+	body := []byte(`{"amount":100}`)
+	signedAt := time.Now()
+	req := wallet.SignedAPIRequest{
+		KeyID:     "key-1",
+		Method:    "POST",
+		Path:      "/api/v1/wallet/deposit",
+		Timestamp: strconv.FormatInt(signedAt.Unix(), 10),
+		Signature: wallet.SignAPIRequest(secret, "POST", "/api/v1/wallet/deposit", signedAt, body),
+		Body:      body,
+	}
+
+	testCases := []struct {
+		name        string
+		req         wallet.SignedAPIRequest
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name: "request signed with the key",
+			req:  req,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), "key-1").Return(key, nil)
+			},
+		},
+		{
+			name: "request tampered with",
+			req: func() wallet.SignedAPIRequest {
+				tampered := req
+				tampered.Body = []byte(`{"amount":100000}`)
+				return tampered
+			}(),
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), "key-1").Return(key, nil)
+			},
+			expectedErr: wallet.ErrInvalidAPIRequestSignature,
+		},
+		{
+			name: "key revoked",
+			req:  req,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), "key-1").Return(revoked, nil)
+			},
+			expectedErr: wallet.ErrAPIKeyRevoked,
+		},
+		{
+			name: "key not found",
+			req:  req,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetAPIKey(gomock.Any(), "key-1").Return(wallet.APIKey{}, wallet.ErrAPIKeyNotFound)
+			},
+			expectedErr: wallet.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			got, err := s.AuthenticateAPIRequest(context.Background(), apiKeyServerSecret, tc.req, 5*time.Minute)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(t, key, got)
+			}
+		})
+	}
+}
@@ -2,22 +2,112 @@ package wallet
 
 import (
 	"context"
+	"time"
 
 	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/publisher"
+	"github.com/jennwah/crypto-assignment/internal/pkg/webhook"
 )
 
 type IWalletService interface {
 	GetWallet(ctx context.Context, userID string) (wallet.Wallet, error)
 	GetWalletTransactionsHistory(
-		ctx context.Context, userID string, offset, pageSize int,
+		ctx context.Context, userID string, filter wallet.TransactionFilter, offset, pageSize int,
 	) ([]wallet.Transaction, int, error)
-	DepositWallet(ctx context.Context, userID, idempotencyKey string, amount uint64) (string, error)
+	GetWalletTransactionsPage(
+		ctx context.Context, userID string, filter wallet.TransactionFilter, after *wallet.TransactionCursor, pageSize int,
+	) ([]wallet.Transaction, *wallet.TransactionCursor, error)
+	GetWalletTransaction(ctx context.Context, userID, transactionID string) (wallet.Transaction, error)
+	GetWalletBalancesAsOf(ctx context.Context, userID string, asOf time.Time) (wallet.WalletBalancesAt, error)
+	ListWalletBalancesAsOf(
+		ctx context.Context, asOf time.Time, afterWalletID string, pageSize int,
+	) ([]wallet.WalletBalancesAt, string, error)
+	SnapshotBalances(ctx context.Context) (int, error)
+	Reconcile(ctx context.Context, freeze bool) (wallet.Reconciliation, error)
+	GetReconciliation(ctx context.Context, reconciliationID string) (wallet.Reconciliation, error)
+	ListReconciliations(ctx context.Context, limit int) ([]wallet.Reconciliation, error)
+	RelayOutboxEvents(ctx context.Context, pub publisher.Publisher) (int, error)
+	SubscribeWalletUpdates(ctx context.Context, userID string) (wallet.Wallet, <-chan wallet.TransactionCreated, error)
+	CreateWebhookSubscription(
+		ctx context.Context, url, secret string, eventTypes []string, userID *string,
+	) (wallet.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]wallet.WebhookSubscription, error)
+	DeactivateWebhookSubscription(ctx context.Context, subscriptionID string) (wallet.WebhookSubscription, error)
+	ListWebhookDeliveries(
+		ctx context.Context, subscriptionID string, status wallet.WebhookDeliveryStatus, limit int,
+	) ([]wallet.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, subscriptionID, deliveryID string) (wallet.WebhookDelivery, error)
+	DispatchWebhooks(ctx context.Context, sender webhook.Sender) (int, error)
+	CreateAPIKey(ctx context.Context, serverSecret, name string, scopes []string) (wallet.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]wallet.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string) (wallet.APIKey, error)
+	SetWalletKYCTier(ctx context.Context, userID, tier string) (wallet.Wallet, error)
+	GetTransactionLimits(ctx context.Context, userID string) ([]wallet.LimitStatus, error)
+	SetTransactionLimit(ctx context.Context, userID string, limit wallet.TransactionLimit) (wallet.TransactionLimit, error)
+	DeleteTransactionLimit(ctx context.Context, userID string, txnType wallet.TransactionType, asset string) error
+	QuoteFee(ctx context.Context, txnType wallet.TransactionType, asset string, amount uint64) (wallet.FeeBreakdown, error)
+	GetFeeSchedules(ctx context.Context) ([]wallet.FeeSchedule, error)
+	SetFeeSchedule(ctx context.Context, schedule wallet.FeeSchedule) (wallet.FeeSchedule, error)
+	DeleteFeeSchedule(ctx context.Context, txnType wallet.TransactionType, asset string) error
+	ListTransactionReviews(ctx context.Context, limit int) ([]wallet.TransactionReview, error)
+	ApproveTransactionReview(ctx context.Context, transactionID string) (wallet.TransactionReview, error)
+	RejectTransactionReview(ctx context.Context, transactionID string) (wallet.TransactionReview, error)
+	AuthenticateAPIRequest(
+		ctx context.Context, serverSecret string, req wallet.SignedAPIRequest, window time.Duration,
+	) (wallet.APIKey, error)
+	StreamWalletTransactions(
+		ctx context.Context, userID string, filter wallet.TransactionFilter, fn func(wallet.StatementLine) error,
+	) error
+	GetWalletStatement(
+		ctx context.Context, userID string, period wallet.StatementPeriod, asset string,
+	) (*wallet.Statement, error)
+	DepositWallet(
+		ctx context.Context,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
 	WithdrawWallet(
 		ctx context.Context,
-		userID, idempotencyKey string,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
 		amount uint64,
-	) (string, error)
+	) (wallet.IdempotentResponse, error)
 	Transfer(
-		ctx context.Context, initiatorUserID, recipientUserID, idempotencyKey string, amount uint64,
-	) (string, error)
+		ctx context.Context,
+		initiatorUserID, recipientUserID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
+	CreateWallet(ctx context.Context, userID string) (wallet.Wallet, bool, error)
+	FreezeWallet(ctx context.Context, userID string) (wallet.Wallet, error)
+	UnfreezeWallet(ctx context.Context, userID string) (wallet.Wallet, error)
+	CloseWallet(ctx context.Context, userID string) (wallet.Wallet, error)
+	GetWalletLedgerBalances(ctx context.Context, userID string) ([]wallet.LedgerBalance, error)
+	CreateHold(
+		ctx context.Context,
+		userID string,
+		key wallet.IdempotencyKey,
+		asset string,
+		amount uint64,
+		expiresAt time.Time,
+	) (wallet.IdempotentResponse, error)
+	CaptureHold(
+		ctx context.Context,
+		userID, holdID string,
+		key wallet.IdempotencyKey,
+		recipientUserID string,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
+	ReleaseHold(ctx context.Context, userID, holdID string) (wallet.Hold, error)
+	ReleaseExpiredHolds(ctx context.Context) (int, error)
+	ReverseTransaction(
+		ctx context.Context,
+		transactionID string,
+		key wallet.IdempotencyKey,
+		amount uint64,
+	) (wallet.IdempotentResponse, error)
 }
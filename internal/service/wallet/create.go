@@ -0,0 +1,20 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func (s *Service) CreateWallet(
+	ctx context.Context,
+	userID string,
+) (domainwallet.Wallet, bool, error) {
+	wallet, created, err := s.walletRepo.CreateWallet(ctx, userID)
+	if err != nil {
+		return domainwallet.Wallet{}, false, fmt.Errorf("create wallet repo err: %w", err)
+	}
+
+	return wallet, created, nil
+}
@@ -0,0 +1,83 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	svc := servicewallet.New(mockRepo)
+
+	testCases := []struct {
+		name          string
+		userID        string
+		mockResult    wallet.Wallet
+		mockCreated   bool
+		mockError     error
+		expectError   bool
+		expectCreated bool
+	}{
+		{
+			name:   "new wallet",
+			userID: "user123",
+			mockResult: wallet.Wallet{
+				ID:     "id-1",
+				UserID: "user123",
+				Status: wallet.Active,
+			},
+			mockCreated:   true,
+			expectCreated: true,
+		},
+		{
+			name:   "existing wallet",
+			userID: "user456",
+			mockResult: wallet.Wallet{
+				ID:       "id-2",
+				UserID:   "user456",
+				Balances: []wallet.Balance{{Asset: "USD", Balance: 100}},
+				Status:   wallet.Active,
+			},
+			mockCreated:   false,
+			expectCreated: false,
+		},
+		{
+			name:        "repo error",
+			userID:      "user789",
+			mockResult:  wallet.Wallet{},
+			mockError:   errors.New("db error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.
+				EXPECT().
+				CreateWallet(gomock.Any(), tc.userID).
+				Return(tc.mockResult, tc.mockCreated, tc.mockError)
+
+			result, created, err := svc.CreateWallet(context.Background(), tc.userID)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Equal(t, wallet.Wallet{}, result)
+				assert.False(t, created)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.mockResult, result)
+				assert.Equal(t, tc.expectCreated, created)
+			}
+		})
+	}
+}
@@ -3,17 +3,25 @@ package wallet
 import (
 	"context"
 	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 )
 
 func (s *Service) DepositWallet(
 	ctx context.Context,
-	userID, idempotencyKey string,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	txID, err := s.walletRepo.DepositWallet(ctx, userID, idempotencyKey, amount)
+) (domainwallet.IdempotentResponse, error) {
+	if _, err := domainwallet.LookupAsset(asset); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("deposit wallet asset err: %w", err)
+	}
+
+	resp, err := s.walletRepo.DepositWallet(ctx, userID, key, asset, amount)
 	if err != nil {
-		return "", fmt.Errorf("deposit wallet repo err: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("deposit wallet repo err: %w", err)
 	}
 
-	return txID, nil
+	return resp, nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
 	"github.com/jennwah/crypto-assignment/internal/service/wallet"
 	"github.com/stretchr/testify/assert"
@@ -15,45 +16,58 @@ func TestDepositWallet(t *testing.T) {
 	type args struct {
 		userID         string
 		idempotencyKey string
+		asset          string
 		amount         uint64
 	}
 	tests := []struct {
-		name          string
-		args          args
-		mockBehavior  func(m *mocks.MockIWalletRepository)
-		expectedTxID  string
-		expectedError error
+		name             string
+		args             args
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
 	}{
 		{
 			name: "success - valid deposit",
 			args: args{
 				userID:         "user123",
 				idempotencyKey: "deposit-key-1",
+				asset:          "USD",
 				amount:         1500,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
 				m.EXPECT().
-					DepositWallet(gomock.Any(), "user123", "deposit-key-1", uint64(1500)).
-					Return("tx456", nil)
+					DepositWallet(gomock.Any(), "user123", domainwallet.IdempotencyKey{Key: "deposit-key-1"}, "USD", uint64(1500)).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx456"}`)}, nil)
 			},
-			expectedTxID:  "tx456",
-			expectedError: nil,
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx456"}`)},
+			expectedError:    nil,
 		},
 		{
 			name: "error - repository failure",
 			args: args{
 				userID:         "user999",
 				idempotencyKey: "deposit-fail",
+				asset:          "USD",
 				amount:         100,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
 				m.EXPECT().
-					DepositWallet(gomock.Any(), "user999", "deposit-fail", uint64(100)).
-					Return("", errors.New("db write error"))
+					DepositWallet(gomock.Any(), "user999", domainwallet.IdempotencyKey{Key: "deposit-fail"}, "USD", uint64(100)).
+					Return(domainwallet.IdempotentResponse{}, errors.New("db write error"))
 			},
-			expectedTxID:  "",
 			expectedError: errors.New("deposit wallet repo err: db write error"),
 		},
+		{
+			name: "error - unsupported asset",
+			args: args{
+				userID:         "user123",
+				idempotencyKey: "key-doge",
+				asset:          "DOGE",
+				amount:         100,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("deposit wallet asset err: asset \"DOGE\": unsupported asset"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -66,14 +80,15 @@ func TestDepositWallet(t *testing.T) {
 
 			service := wallet.New(mockRepo)
 
-			txID, err := service.DepositWallet(
+			resp, err := service.DepositWallet(
 				context.Background(),
 				tt.args.userID,
-				tt.args.idempotencyKey,
+				domainwallet.IdempotencyKey{Key: tt.args.idempotencyKey},
+				tt.args.asset,
 				tt.args.amount,
 			)
 
-			assert.Equal(t, tt.expectedTxID, txID)
+			assert.Equal(t, tt.expectedResponse, resp)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
@@ -0,0 +1,27 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// StreamWalletTransactions passes the transactions matching the filter to fn one at a time, oldest first,
+// along with the wallet's running balance after each of them.
+func (s *Service) StreamWalletTransactions(
+	ctx context.Context,
+	userID string,
+	filter domainwallet.TransactionFilter,
+	fn func(domainwallet.StatementLine) error,
+) error {
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("wallet service stream wallet transactions filter err: %w", err)
+	}
+
+	if err := s.walletRepo.StreamWalletTransactions(ctx, userID, filter, fn); err != nil {
+		return fmt.Errorf("wallet service stream wallet transactions err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWalletTransactions(t *testing.T) {
+	lines := []wallet.StatementLine{
+		{Transaction: wallet.Transaction{ID: "tx1", Asset: "USD", Amount: 500, Type: wallet.Deposit, Status: wallet.Success}, Change: 500, Balance: 500},
+		{Transaction: wallet.Transaction{ID: "tx2", Asset: "USD", Amount: 200, Type: wallet.Withdraw, Status: wallet.Success}, Change: -200, Balance: 300},
+	}
+	errWrite := errors.New("client went away")
+
+	testCases := []struct {
+		name          string
+		filter        wallet.TransactionFilter
+		fnErr         error
+		mockBehavior  func(m *mocks.MockIWalletRepository)
+		expectedLines []wallet.StatementLine
+		expectedErr   error
+	}{
+		{
+			name: "streams every line",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					StreamWalletTransactions(gomock.Any(), "user789", wallet.TransactionFilter{}, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ wallet.TransactionFilter, fn func(wallet.StatementLine) error) error {
+						for _, line := range lines {
+							if err := fn(line); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+			expectedLines: lines,
+		},
+		{
+			name:  "stops on write error",
+			fnErr: errWrite,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					StreamWalletTransactions(gomock.Any(), "user789", wallet.TransactionFilter{}, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ wallet.TransactionFilter, fn func(wallet.StatementLine) error) error {
+						for _, line := range lines {
+							if err := fn(line); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+			expectedLines: lines[:1],
+			expectedErr:   errWrite,
+		},
+		{
+			name:         "invalid filter",
+			filter:       wallet.TransactionFilter{Type: "refund"},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {},
+			expectedErr:  wallet.ErrInvalidTransactionFilter,
+		},
+		{
+			name: "wallet not found",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					StreamWalletTransactions(gomock.Any(), "user789", wallet.TransactionFilter{}, gomock.Any()).
+					Return(wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			var streamed []wallet.StatementLine
+			err := svc.StreamWalletTransactions(context.Background(), "user789", tc.filter, func(line wallet.StatementLine) error {
+				streamed = append(streamed, line)
+				return tc.fnErr
+			})
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedLines, streamed)
+		})
+	}
+}
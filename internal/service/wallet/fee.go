@@ -0,0 +1,82 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// QuoteFee prices a transaction of the type, asset and amount with the fee schedule in effect, without moving funds.
+func (s *Service) QuoteFee(
+	ctx context.Context,
+	txnType domainwallet.TransactionType,
+	asset string,
+	amount uint64,
+) (domainwallet.FeeBreakdown, error) {
+	if err := validateFeeSchedule(txnType, asset); err != nil {
+		return domainwallet.FeeBreakdown{}, err
+	}
+
+	schedule, err := s.walletRepo.GetFeeSchedule(ctx, txnType, asset)
+	if err != nil {
+		return domainwallet.FeeBreakdown{}, fmt.Errorf("get fee schedule repo err: %w", err)
+	}
+
+	return schedule.Quote(asset, amount)
+}
+
+// GetFeeSchedules returns the fee schedule of every transaction type and asset with one.
+func (s *Service) GetFeeSchedules(ctx context.Context) ([]domainwallet.FeeSchedule, error) {
+	schedules, err := s.walletRepo.GetFeeSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get fee schedules repo err: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// SetFeeSchedule validates and sets the fee schedule of its transaction type and asset, replacing the one in effect.
+func (s *Service) SetFeeSchedule(
+	ctx context.Context,
+	schedule domainwallet.FeeSchedule,
+) (domainwallet.FeeSchedule, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.walletRepo.SetFeeSchedule(ctx, schedule)
+	if err != nil {
+		return nil, fmt.Errorf("set fee schedule repo err: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteFeeSchedule deletes the fee schedule of the transaction type and asset, its transactions are free again.
+func (s *Service) DeleteFeeSchedule(
+	ctx context.Context,
+	txnType domainwallet.TransactionType,
+	asset string,
+) error {
+	if err := validateFeeSchedule(txnType, asset); err != nil {
+		return err
+	}
+
+	if err := s.walletRepo.DeleteFeeSchedule(ctx, txnType, asset); err != nil {
+		return fmt.Errorf("delete fee schedule repo err: %w", err)
+	}
+
+	return nil
+}
+
+// validateFeeSchedule checks fees can be charged on transactions of the type and asset.
+func validateFeeSchedule(txnType domainwallet.TransactionType, asset string) error {
+	if !slices.Contains(domainwallet.FeeTransactionTypes, txnType) {
+		return fmt.Errorf("%w: type must be one of %v", domainwallet.ErrInvalidFeeSchedule, domainwallet.FeeTransactionTypes)
+	}
+
+	_, err := domainwallet.LookupAsset(asset)
+	return err
+}
@@ -0,0 +1,188 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteFee(t *testing.T) {
+	testCases := []struct {
+		name          string
+		txnType       wallet.TransactionType
+		asset         string
+		amount        uint64
+		prepareMock   func(*mocks.MockIWalletRepository)
+		expectedQuote wallet.FeeBreakdown
+		expectedErr   error
+	}{
+		{
+			name:    "priced with the schedule",
+			txnType: wallet.Withdraw,
+			asset:   "USD",
+			amount:  10000,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetFeeSchedule(gomock.Any(), wallet.Withdraw, "USD").
+					Return(wallet.FeeSchedule{{Type: wallet.Withdraw, Asset: "USD", FlatFee: 25, BasisPoints: 100}}, nil)
+			},
+			expectedQuote: wallet.FeeBreakdown{Asset: "USD", Amount: 10000, FlatFee: 25, PercentageFee: 100, Fee: 125, Total: 10125},
+		},
+		{
+			name:    "free without a schedule",
+			txnType: wallet.Transfer,
+			asset:   "BTC",
+			amount:  500,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetFeeSchedule(gomock.Any(), wallet.Transfer, "BTC").Return(nil, nil)
+			},
+			expectedQuote: wallet.FeeBreakdown{Asset: "BTC", Amount: 500, Total: 500},
+		},
+		{
+			name:        "deposits are not charged",
+			txnType:     wallet.Deposit,
+			asset:       "USD",
+			amount:      500,
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidFeeSchedule,
+		},
+		{
+			name:        "unsupported asset",
+			txnType:     wallet.Withdraw,
+			asset:       "DOGE",
+			amount:      500,
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+		{
+			name:    "repo error",
+			txnType: wallet.Withdraw,
+			asset:   "USD",
+			amount:  500,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetFeeSchedule(gomock.Any(), wallet.Withdraw, "USD").Return(nil, errors.New("db error"))
+			},
+			expectedErr: errors.New("get fee schedule repo err: db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			quote, err := s.QuoteFee(context.Background(), tc.txnType, tc.asset, tc.amount)
+
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			}
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedQuote, quote)
+		})
+	}
+}
+
+func TestSetFeeSchedule(t *testing.T) {
+	maxFee := uint64(10)
+
+	testCases := []struct {
+		name        string
+		schedule    wallet.FeeSchedule
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name:     "schedule set",
+			schedule: wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", BasisPoints: 50, MaxFee: &maxFee}},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					SetFeeSchedule(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, schedule wallet.FeeSchedule) (wallet.FeeSchedule, error) {
+						return schedule, nil
+					})
+			},
+		},
+		{
+			name:        "invalid schedule",
+			schedule:    wallet.FeeSchedule{{Type: wallet.Transfer, Asset: "USD", BasisPoints: 10001}},
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidFeeSchedule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			schedule, err := s.SetFeeSchedule(context.Background(), tc.schedule)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.schedule, schedule)
+			}
+		})
+	}
+}
+
+func TestDeleteFeeSchedule(t *testing.T) {
+	testCases := []struct {
+		name        string
+		txnType     wallet.TransactionType
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name:    "schedule deleted",
+			txnType: wallet.Withdraw,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DeleteFeeSchedule(gomock.Any(), wallet.Withdraw, "USD").Return(nil)
+			},
+		},
+		{
+			name:    "no schedule",
+			txnType: wallet.Withdraw,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DeleteFeeSchedule(gomock.Any(), wallet.Withdraw, "USD").Return(wallet.ErrFeeScheduleNotFound)
+			},
+			expectedErr: wallet.ErrFeeScheduleNotFound,
+		},
+		{
+			name:        "reversals are not charged",
+			txnType:     wallet.Reversal,
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidFeeSchedule,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			err := s.DeleteFeeSchedule(context.Background(), tc.txnType, "USD")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// screen evaluates the fraud rules on a withdrawal or transfer, everything is allowed without rules.
+func (s *Service) screen(ctx context.Context, check domainwallet.FraudCheck) (domainwallet.FraudVerdict, error) {
+	if len(s.fraudRules) == 0 {
+		return domainwallet.FraudVerdict{Decision: domainwallet.FraudAllow}, nil
+	}
+
+	signals, err := s.walletRepo.GetFraudSignals(ctx, check)
+	if err != nil {
+		return domainwallet.FraudVerdict{}, fmt.Errorf("get fraud signals repo err: %w", err)
+	}
+
+	return s.fraudRules.Screen(signals), nil
+}
@@ -19,11 +19,17 @@ func (s *Service) GetWallet(ctx context.Context, userID string) (domainwallet.Wa
 func (s *Service) GetWalletTransactionsHistory(
 	ctx context.Context,
 	userID string,
+	filter domainwallet.TransactionFilter,
 	offset, pageSize int,
 ) ([]domainwallet.Transaction, int, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("wallet service get wallet transactions history filter err: %w", err)
+	}
+
 	transactions, total, err := s.walletRepo.GetWalletTransactionsHistory(
 		ctx,
 		userID,
+		filter,
 		offset,
 		pageSize,
 	)
@@ -33,3 +39,47 @@ func (s *Service) GetWalletTransactionsHistory(
 
 	return transactions, total, nil
 }
+
+// GetWalletTransactionsPage returns the page of transactions after the cursor, or the first page without one,
+// and the cursor of the next page, nil on the last page.
+func (s *Service) GetWalletTransactionsPage(
+	ctx context.Context,
+	userID string,
+	filter domainwallet.TransactionFilter,
+	after *domainwallet.TransactionCursor,
+	pageSize int,
+) ([]domainwallet.Transaction, *domainwallet.TransactionCursor, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("wallet service get wallet transactions page filter err: %w", err)
+	}
+
+	// fetch one more transaction than the page holds to know whether there is a next page
+	transactions, err := s.walletRepo.GetWalletTransactionsAfter(ctx, userID, filter, after, pageSize+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wallet service get wallet transactions page err: %w", err)
+	}
+
+	if len(transactions) <= pageSize {
+		return transactions, nil, nil
+	}
+
+	transactions = transactions[:pageSize]
+	next, err := domainwallet.CursorOf(transactions[pageSize-1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("wallet service get wallet transactions page cursor err: %w", err)
+	}
+
+	return transactions, &next, nil
+}
+
+func (s *Service) GetWalletTransaction(
+	ctx context.Context,
+	userID, transactionID string,
+) (domainwallet.Transaction, error) {
+	transaction, err := s.walletRepo.GetWalletTransaction(ctx, userID, transactionID)
+	if err != nil {
+		return domainwallet.Transaction{}, fmt.Errorf("wallet service get wallet transaction err: %w", err)
+	}
+
+	return transaction, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
@@ -32,7 +33,7 @@ func TestGetWallet(t *testing.T) {
 			mockResult: wallet.Wallet{
 				ID:        "id-1",
 				UserID:    "user123",
-				Balance:   10000,
+				Balances:  []wallet.Balance{{Asset: "USD", Balance: 10000}},
 				CreatedAt: "2016-06-01T14:46:22.001Z",
 			},
 			mockError:   nil,
@@ -77,6 +78,7 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 	testCases := []struct {
 		name           string
 		userID         string
+		filter         wallet.TransactionFilter
 		offset         int
 		pageSize       int
 		mockTxs        []wallet.Transaction
@@ -88,11 +90,12 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 		{
 			name:     "happy case",
 			userID:   "user789",
+			filter:   wallet.TransactionFilter{Asset: "USD", Type: wallet.Deposit},
 			offset:   0,
 			pageSize: 2,
 			mockTxs: []wallet.Transaction{
-				{ID: "tx1", Amount: 500, Type: wallet.Deposit, Status: wallet.Success, InitiatorWalletUserId: "user789"},
-				{ID: "tx2", Amount: 700, Type: wallet.Withdraw, Status: wallet.Success, InitiatorWalletUserId: "user789"},
+				{ID: "tx1", Asset: "USD", Amount: 500, Type: wallet.Deposit, Status: wallet.Success, InitiatorWalletUserId: "user789"},
+				{ID: "tx2", Asset: "USD", Amount: 700, Type: wallet.Withdraw, Status: wallet.Success, InitiatorWalletUserId: "user789"},
 			},
 			mockTotal:      10,
 			mockError:      nil,
@@ -116,10 +119,10 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo.
 				EXPECT().
-				GetWalletTransactionsHistory(gomock.Any(), tc.userID, tc.offset, tc.pageSize).
+				GetWalletTransactionsHistory(gomock.Any(), tc.userID, tc.filter, tc.offset, tc.pageSize).
 				Return(tc.mockTxs, tc.mockTotal, tc.mockError)
 
-			txs, total, err := svc.GetWalletTransactionsHistory(context.Background(), tc.userID, tc.offset, tc.pageSize)
+			txs, total, err := svc.GetWalletTransactionsHistory(context.Background(), tc.userID, tc.filter, tc.offset, tc.pageSize)
 
 			if tc.expectError {
 				assert.Error(t, err)
@@ -133,3 +136,130 @@ func TestGetWalletTransactionsHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWalletTransactionsPage(t *testing.T) {
+	after := &wallet.TransactionCursor{CreatedAt: time.Date(2025, 5, 13, 13, 0, 0, 0, time.UTC), ID: "tx0"}
+	txs := []wallet.Transaction{
+		{ID: "tx1", Asset: "USD", Amount: 500, Type: wallet.Deposit, Status: wallet.Success, CreatedAt: "2025-05-13T12:50:39.101388Z"},
+		{ID: "tx2", Asset: "USD", Amount: 700, Type: wallet.Withdraw, Status: wallet.Success, CreatedAt: "2025-05-13T12:46:53.713914Z"},
+		{ID: "tx3", Asset: "USD", Amount: 900, Type: wallet.Deposit, Status: wallet.Success, CreatedAt: "2025-05-13T12:44:41.853495Z"},
+	}
+
+	testCases := []struct {
+		name         string
+		filter       wallet.TransactionFilter
+		mockBehavior func(m *mocks.MockIWalletRepository)
+		expectedTxs  []wallet.Transaction
+		expectedNext *wallet.TransactionCursor
+		expectedErr  error
+	}{
+		{
+			name: "page with a next page",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletTransactionsAfter(gomock.Any(), "user789", wallet.TransactionFilter{}, after, 3).
+					Return(txs, nil)
+			},
+			expectedTxs:  txs[:2],
+			expectedNext: &wallet.TransactionCursor{CreatedAt: time.Date(2025, 5, 13, 12, 46, 53, 713914000, time.UTC), ID: "tx2"},
+		},
+		{
+			name: "last page",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletTransactionsAfter(gomock.Any(), "user789", wallet.TransactionFilter{}, after, 3).
+					Return(txs[:2], nil)
+			},
+			expectedTxs: txs[:2],
+		},
+		{
+			name:         "invalid filter",
+			filter:       wallet.TransactionFilter{Status: "pending"},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {},
+			expectedErr:  wallet.ErrInvalidTransactionFilter,
+		},
+		{
+			name: "wallet not found",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletTransactionsAfter(gomock.Any(), "user789", wallet.TransactionFilter{}, after, 3).
+					Return(nil, wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			txs, next, err := svc.GetWalletTransactionsPage(context.Background(), "user789", tc.filter, after, 2)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedTxs, txs)
+			assert.Equal(t, tc.expectedNext, next)
+		})
+	}
+}
+
+func TestGetWalletTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	svc := servicewallet.New(mockRepo)
+
+	idempotencyKey := "idem1"
+
+	testCases := []struct {
+		name        string
+		userID      string
+		mockTx      wallet.Transaction
+		mockError   error
+		expectedErr error
+	}{
+		{
+			name:   "happy case",
+			userID: "user789",
+			mockTx: wallet.Transaction{
+				ID:                    "tx1",
+				Asset:                 "USD",
+				Amount:                500,
+				Type:                  wallet.Deposit,
+				Status:                wallet.Success,
+				InitiatorWalletUserId: "user789",
+				IdempotencyKey:        &idempotencyKey,
+			},
+		},
+		{
+			name:        "not a party to the transaction",
+			userID:      "user123",
+			mockError:   wallet.ErrTransactionNotFound,
+			expectedErr: wallet.ErrTransactionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.
+				EXPECT().
+				GetWalletTransaction(gomock.Any(), tc.userID, "tx1").
+				Return(tc.mockTx, tc.mockError)
+
+			tx, err := svc.GetWalletTransaction(context.Background(), tc.userID, "tx1")
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Equal(t, wallet.Transaction{}, tx)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.mockTx, tx)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// ExpiredHoldsBatchSize is the most expired holds released per sweep.
+const ExpiredHoldsBatchSize = 100
+
+func (s *Service) CreateHold(
+	ctx context.Context,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
+	amount uint64,
+	expiresAt time.Time,
+) (domainwallet.IdempotentResponse, error) {
+	if _, err := domainwallet.LookupAsset(asset); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("create hold asset err: %w", err)
+	}
+
+	if err := domainwallet.ValidateHoldExpiry(expiresAt, time.Now()); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("create hold expiry err: %w", err)
+	}
+
+	resp, found, err := s.recordedResponse(ctx, userID, domainwallet.HoldOperation, key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("create hold idempotency err: %w", err)
+	}
+	if found {
+		return resp, nil
+	}
+
+	// A hold is captured into a withdrawal or a transfer, funds a wallet not allowed to withdraw cannot hold either.
+	if err := s.checkKYCTier(ctx, userID, domainwallet.Withdraw); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("create hold kyc err: %w", err)
+	}
+
+	resp, err = s.walletRepo.CreateHold(ctx, userID, key, asset, amount, expiresAt.UTC())
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("create hold repo err: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) CaptureHold(
+	ctx context.Context,
+	userID, holdID string,
+	key domainwallet.IdempotencyKey,
+	recipientUserID string,
+	amount uint64,
+) (domainwallet.IdempotentResponse, error) {
+	if userID == recipientUserID {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("capture hold err: %w", domainwallet.ErrSelfTransfer)
+	}
+
+	txnType := domainwallet.Withdraw
+	if recipientUserID != "" {
+		txnType = domainwallet.Transfer
+	}
+
+	resp, found, err := s.recordedResponse(ctx, userID, domainwallet.CaptureOperation, key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("capture hold idempotency err: %w", err)
+	}
+	if found {
+		return resp, nil
+	}
+
+	if err := s.checkKYCTier(ctx, userID, txnType); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("capture hold kyc err: %w", err)
+	}
+
+	// Captures move the held funds as the withdrawal or transfer they become, screened as such
+	verdict, err := s.screen(ctx, domainwallet.FraudCheck{
+		Type:            txnType,
+		UserID:          userID,
+		RecipientUserID: recipientUserID,
+		HoldID:          holdID,
+		Amount:          amount,
+	})
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("capture hold fraud err: %w", err)
+	}
+
+	resp, err = s.walletRepo.CaptureHold(ctx, userID, holdID, key, recipientUserID, amount, time.Now().UTC(), verdict)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("capture hold repo err: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) ReleaseHold(ctx context.Context, userID, holdID string) (domainwallet.Hold, error) {
+	hold, err := s.walletRepo.ReleaseHold(ctx, userID, holdID)
+	if err != nil {
+		return domainwallet.Hold{}, fmt.Errorf("release hold repo err: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ReleaseExpiredHolds releases a batch of expired holds back to their wallets' available balance.
+func (s *Service) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	released, err := s.walletRepo.ReleaseExpiredHolds(ctx, time.Now().UTC(), ExpiredHoldsBatchSize)
+	if err != nil {
+		return released, fmt.Errorf("release expired holds repo err: %w", err)
+	}
+
+	return released, nil
+}
@@ -0,0 +1,453 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHold(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	type args struct {
+		userID    string
+		asset     string
+		amount    uint64
+		expiresAt time.Time
+	}
+	tests := []struct {
+		name             string
+		args             args
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
+	}{
+		{
+			name: "success - hold created",
+			args: args{
+				userID:    "user123",
+				asset:     "USD",
+				amount:    500,
+				expiresAt: expiresAt,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.HoldOperation, "hold-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().
+					CreateHold(gomock.Any(), "user123", domainwallet.IdempotencyKey{Key: "hold-key"}, "USD", uint64(500), expiresAt.UTC()).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"hold_id":"hold1"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"hold_id":"hold1"}`)},
+		},
+		{
+			name: "error - insufficient available balance",
+			args: args{
+				userID:    "user123",
+				asset:     "USD",
+				amount:    500,
+				expiresAt: expiresAt,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.HoldOperation, "hold-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().
+					CreateHold(gomock.Any(), "user123", domainwallet.IdempotencyKey{Key: "hold-key"}, "USD", uint64(500), expiresAt.UTC()).
+					Return(domainwallet.IdempotentResponse{}, domainwallet.ErrWalletInsufficientBalance)
+			},
+			expectedError: errors.New("create hold repo err: wallet insufficient balance"),
+		},
+		{
+			name: "success - recorded response replayed before the kyc check",
+			args: args{
+				userID:    "user555",
+				asset:     "USD",
+				amount:    500,
+				expiresAt: expiresAt,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetIdempotentResponse(gomock.Any(), "user555", domainwallet.HoldOperation, domainwallet.IdempotencyKey{Key: "hold-key"}).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"hold_id":"hold1"}`)}, true, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"hold_id":"hold1"}`)},
+		},
+		{
+			name: "error - unverified wallet cannot hold",
+			args: args{
+				userID:    "user555",
+				asset:     "USD",
+				amount:    500,
+				expiresAt: expiresAt,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user555", domainwallet.HoldOperation, "hold-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user555").Return(domainwallet.Unverified, nil)
+			},
+			expectedError: errors.New("create hold kyc err: kyc tier does not allow this operation: unverified wallets cannot withdraw"),
+		},
+		{
+			name: "error - unsupported asset",
+			args: args{
+				userID:    "user123",
+				asset:     "DOGE",
+				amount:    500,
+				expiresAt: expiresAt,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("create hold asset err: asset \"DOGE\": unsupported asset"),
+		},
+		{
+			name: "error - expiry in the past",
+			args: args{
+				userID:    "user123",
+				asset:     "USD",
+				amount:    500,
+				expiresAt: time.Now().Add(-time.Minute),
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("create hold expiry err: invalid hold expiry"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			service := wallet.New(mockRepo)
+
+			resp, err := service.CreateHold(
+				context.Background(),
+				tt.args.userID,
+				domainwallet.IdempotencyKey{Key: "hold-key"},
+				tt.args.asset,
+				tt.args.amount,
+				tt.args.expiresAt,
+			)
+
+			assert.Equal(t, tt.expectedResponse, resp)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	type args struct {
+		userID          string
+		recipientUserID string
+		amount          uint64
+	}
+	allowed := domainwallet.FraudVerdict{Decision: domainwallet.FraudAllow}
+	newRecipient := domainwallet.NewRecipientRule{Thresholds: map[string]uint64{"USD": 1000}, Decision: domainwallet.FraudBlock}
+	newRecipientCheck := domainwallet.FraudCheck{Type: domainwallet.Transfer, UserID: "user123", RecipientUserID: "user456", HoldID: "hold1", Amount: 5000}
+	velocity := domainwallet.WithdrawalVelocityRule{Max: 3, Decision: domainwallet.FraudReview}
+	velocityCheck := domainwallet.FraudCheck{Type: domainwallet.Withdraw, UserID: "user123", HoldID: "hold1", Amount: 300}
+
+	tests := []struct {
+		name             string
+		args             args
+		fraudRules       []domainwallet.FraudRule
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
+	}{
+		{
+			name: "success - captured into withdrawal",
+			args: args{
+				userID: "user123",
+				amount: 300,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().
+					CaptureHold(gomock.Any(), "user123", "hold1", domainwallet.IdempotencyKey{Key: "capture-key"}, "", uint64(300), gomock.Any(), allowed).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx1"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx1"}`)},
+		},
+		{
+			name: "success - captured into transfer",
+			args: args{
+				userID:          "user123",
+				recipientUserID: "user456",
+				amount:          300,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().
+					CaptureHold(gomock.Any(), "user123", "hold1", domainwallet.IdempotencyKey{Key: "capture-key"}, "user456", uint64(300), gomock.Any(), allowed).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx2"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx2"}`)},
+		},
+		{
+			name: "success - blocked by a fraud rule",
+			args: args{
+				userID:          "user123",
+				recipientUserID: "user456",
+				amount:          5000,
+			},
+			fraudRules: []domainwallet.FraudRule{newRecipient},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				signalsCheck := newRecipientCheck
+				signalsCheck.Asset = "USD"
+				m.EXPECT().GetFraudSignals(gomock.Any(), newRecipientCheck).
+					Return(domainwallet.FraudSignals{FraudCheck: signalsCheck, Total: 5000, NewRecipient: true}, nil)
+				m.EXPECT().
+					CaptureHold(
+						gomock.Any(),
+						"user123",
+						"hold1",
+						domainwallet.IdempotencyKey{Key: "capture-key"},
+						"user456",
+						uint64(5000),
+						gomock.Any(),
+						domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "new_recipient"},
+					).
+					Return(domainwallet.IdempotentResponse{StatusCode: 403, Body: []byte(`{"message":"transaction blocked"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 403, Body: []byte(`{"message":"transaction blocked"}`)},
+		},
+		{
+			name: "success - held for review by a fraud rule",
+			args: args{
+				userID: "user123",
+				amount: 300,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				signalsCheck := velocityCheck
+				signalsCheck.Asset = "USD"
+				m.EXPECT().GetFraudSignals(gomock.Any(), velocityCheck).
+					Return(domainwallet.FraudSignals{FraudCheck: signalsCheck, Total: 300, RecentWithdrawals: 3}, nil)
+				m.EXPECT().
+					CaptureHold(
+						gomock.Any(),
+						"user123",
+						"hold1",
+						domainwallet.IdempotencyKey{Key: "capture-key"},
+						"",
+						uint64(300),
+						gomock.Any(),
+						domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "withdrawal_velocity"},
+					).
+					Return(domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx3","status":"pending_review"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx3","status":"pending_review"}`)},
+		},
+		{
+			name: "success - recorded response replayed before the kyc and fraud checks",
+			args: args{
+				userID: "user123",
+				amount: 300,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetIdempotentResponse(gomock.Any(), "user123", domainwallet.CaptureOperation, domainwallet.IdempotencyKey{Key: "capture-key"}).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx1"}`)}, true, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx1"}`)},
+		},
+		{
+			name: "error - fraud signals repo error",
+			args: args{
+				userID: "user123",
+				amount: 300,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().GetFraudSignals(gomock.Any(), velocityCheck).
+					Return(domainwallet.FraudSignals{}, domainwallet.ErrHoldNotFound)
+			},
+			expectedError: errors.New("capture hold fraud err: get fraud signals repo err: hold not found"),
+		},
+		{
+			name: "error - hold expired",
+			args: args{
+				userID: "user123",
+				amount: 300,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().
+					CaptureHold(gomock.Any(), "user123", "hold1", domainwallet.IdempotencyKey{Key: "capture-key"}, "", uint64(300), gomock.Any(), allowed).
+					Return(domainwallet.IdempotentResponse{}, domainwallet.ErrHoldExpired)
+			},
+			expectedError: errors.New("capture hold repo err: hold has expired"),
+		},
+		{
+			name: "error - unverified wallet cannot capture into transfer",
+			args: args{
+				userID:          "user555",
+				recipientUserID: "user456",
+				amount:          300,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user555", domainwallet.CaptureOperation, "capture-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user555").Return(domainwallet.Unverified, nil)
+			},
+			expectedError: errors.New("capture hold kyc err: kyc tier does not allow this operation: unverified wallets cannot transfer"),
+		},
+		{
+			name: "error - capture into own wallet",
+			args: args{
+				userID:          "user123",
+				recipientUserID: "user123",
+				amount:          300,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("capture hold err: cannot transfer to own wallet"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			service := wallet.New(mockRepo, tt.fraudRules...)
+
+			resp, err := service.CaptureHold(
+				context.Background(),
+				tt.args.userID,
+				"hold1",
+				domainwallet.IdempotencyKey{Key: "capture-key"},
+				tt.args.recipientUserID,
+				tt.args.amount,
+			)
+
+			assert.Equal(t, tt.expectedResponse, resp)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReleaseHold(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockBehavior  func(m *mocks.MockIWalletRepository)
+		expectedHold  domainwallet.Hold
+		expectedError error
+	}{
+		{
+			name: "success - hold released",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					ReleaseHold(gomock.Any(), "user123", "hold1").
+					Return(domainwallet.Hold{ID: "hold1", Status: domainwallet.HoldReleased}, nil)
+			},
+			expectedHold: domainwallet.Hold{ID: "hold1", Status: domainwallet.HoldReleased},
+		},
+		{
+			name: "error - hold already captured",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					ReleaseHold(gomock.Any(), "user123", "hold1").
+					Return(domainwallet.Hold{}, domainwallet.ErrHoldNotActive)
+			},
+			expectedError: errors.New("release hold repo err: hold is no longer active"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			service := wallet.New(mockRepo)
+
+			hold, err := service.ReleaseHold(context.Background(), "user123", "hold1")
+
+			assert.Equal(t, tt.expectedHold, hold)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedReleased int
+		expectedError    error
+	}{
+		{
+			name: "success - expired holds released",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Any(), 100).Return(3, nil)
+			},
+			expectedReleased: 3,
+		},
+		{
+			name: "error - repo error after releasing some holds",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ReleaseExpiredHolds(gomock.Any(), gomock.Any(), 100).Return(1, errors.New("db error"))
+			},
+			expectedReleased: 1,
+			expectedError:    errors.New("release expired holds repo err: db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			service := wallet.New(mockRepo)
+
+			released, err := service.ReleaseExpiredHolds(context.Background())
+
+			assert.Equal(t, tt.expectedReleased, released)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
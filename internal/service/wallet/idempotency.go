@@ -0,0 +1,24 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// recordedResponse returns the response recorded on the user's idempotency key for the operation, if any. It is
+// checked before the KYC tier and fraud rules, so retries replay their recorded response even once those changed.
+func (s *Service) recordedResponse(
+	ctx context.Context,
+	userID string,
+	operation domainwallet.IdempotentOperation,
+	key domainwallet.IdempotencyKey,
+) (domainwallet.IdempotentResponse, bool, error) {
+	resp, found, err := s.walletRepo.GetIdempotentResponse(ctx, userID, operation, key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, false, fmt.Errorf("get idempotent response repo err: %w", err)
+	}
+
+	return resp, found, nil
+}
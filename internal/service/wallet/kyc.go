@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// SetWalletKYCTier sets the KYC tier of the user's wallet, deciding the operations and default limits that apply to it.
+func (s *Service) SetWalletKYCTier(ctx context.Context, userID, tier string) (domainwallet.Wallet, error) {
+	kycTier, err := domainwallet.ParseKYCTier(tier)
+	if err != nil {
+		return domainwallet.Wallet{}, err
+	}
+
+	wallet, err := s.walletRepo.SetWalletKYCTier(ctx, userID, kycTier)
+	if err != nil {
+		return domainwallet.Wallet{}, fmt.Errorf("set wallet kyc tier repo err: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// checkKYCTier returns ErrKYCTierNotAllowed when the KYC tier of the user's wallet does not allow initiating
+// transactions of the type.
+func (s *Service) checkKYCTier(ctx context.Context, userID string, txnType domainwallet.TransactionType) error {
+	tier, err := s.walletRepo.GetWalletKYCTier(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get wallet kyc tier repo err: %w", err)
+	}
+
+	return tier.Allows(txnType)
+}
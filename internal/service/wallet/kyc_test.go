@@ -0,0 +1,64 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetWalletKYCTier(t *testing.T) {
+	tests := []struct {
+		name           string
+		tier           string
+		mockBehavior   func(m *mocks.MockIWalletRepository)
+		expectedWallet domainwallet.Wallet
+		expectedError  error
+	}{
+		{
+			name: "tier set",
+			tier: "basic",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					SetWalletKYCTier(gomock.Any(), "user1", domainwallet.BasicKYC).
+					Return(domainwallet.Wallet{UserID: "user1", KYCTier: domainwallet.BasicKYC}, nil)
+			},
+			expectedWallet: domainwallet.Wallet{UserID: "user1", KYCTier: domainwallet.BasicKYC},
+		},
+		{
+			name:          "invalid tier",
+			tier:          "gold",
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: domainwallet.ErrInvalidKYCTier,
+		},
+		{
+			name: "repo error",
+			tier: "full",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					SetWalletKYCTier(gomock.Any(), "user1", domainwallet.FullKYC).
+					Return(domainwallet.Wallet{}, domainwallet.ErrWalletNotFound)
+			},
+			expectedError: domainwallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			w, err := wallet.New(mockRepo).SetWalletKYCTier(context.Background(), "user1", tt.tier)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedWallet, w)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func (s *Service) GetWalletLedgerBalances(ctx context.Context, userID string) ([]domainwallet.LedgerBalance, error) {
+	balances, err := s.walletRepo.GetWalletLedgerBalances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get wallet ledger balances repo err: %w", err)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,64 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWalletLedgerBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	svc := servicewallet.New(mockRepo)
+
+	testCases := []struct {
+		name          string
+		userID        string
+		mockResult    []wallet.LedgerBalance
+		mockError     error
+		expectedError error
+	}{
+		{
+			name:   "success",
+			userID: "user1",
+			mockResult: []wallet.LedgerBalance{
+				{Asset: "USD", Balance: 100, LedgerBalance: 100},
+			},
+		},
+		{
+			name:          "repo error",
+			userID:        "user2",
+			mockError:     wallet.ErrWalletNotFound,
+			expectedError: errors.New("get wallet ledger balances repo err: wallet not found"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.
+				EXPECT().
+				GetWalletLedgerBalances(gomock.Any(), tc.userID).
+				Return(tc.mockResult, tc.mockError)
+
+			result, err := svc.GetWalletLedgerBalances(context.Background(), tc.userID)
+
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tc.mockError)
+				assert.Equal(t, tc.expectedError.Error(), err.Error())
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.mockResult, result)
+			}
+		})
+	}
+}
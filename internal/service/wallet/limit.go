@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// GetTransactionLimits returns the limits in effect for the user's wallet with its usage of each.
+func (s *Service) GetTransactionLimits(ctx context.Context, userID string) ([]domainwallet.LimitStatus, error) {
+	statuses, err := s.walletRepo.GetTransactionLimits(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction limits repo err: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// SetTransactionLimit validates and sets the user's wallet own limit of the limit's type and asset, overriding the default.
+func (s *Service) SetTransactionLimit(
+	ctx context.Context,
+	userID string,
+	limit domainwallet.TransactionLimit,
+) (domainwallet.TransactionLimit, error) {
+	if err := limit.Validate(); err != nil {
+		return domainwallet.TransactionLimit{}, err
+	}
+
+	limit, err := s.walletRepo.SetTransactionLimit(ctx, userID, limit)
+	if err != nil {
+		return domainwallet.TransactionLimit{}, fmt.Errorf("set transaction limit repo err: %w", err)
+	}
+
+	return limit, nil
+}
+
+// DeleteTransactionLimit deletes the user's wallet own limit of the type and asset, the default applies again.
+func (s *Service) DeleteTransactionLimit(
+	ctx context.Context,
+	userID string,
+	txnType domainwallet.TransactionType,
+	asset string,
+) error {
+	if err := (domainwallet.TransactionLimit{Type: txnType, Asset: asset}).Validate(); err != nil {
+		return err
+	}
+
+	if err := s.walletRepo.DeleteTransactionLimit(ctx, userID, txnType, asset); err != nil {
+		return fmt.Errorf("delete transaction limit repo err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTransactionLimit(t *testing.T) {
+	perTransaction := uint64(1000)
+
+	testCases := []struct {
+		name        string
+		limit       wallet.TransactionLimit
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name:  "limit set",
+			limit: wallet.TransactionLimit{Type: wallet.Withdraw, Asset: "USD", PerTransaction: &perTransaction},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					SetTransactionLimit(gomock.Any(), "user1", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, limit wallet.TransactionLimit) (wallet.TransactionLimit, error) {
+						return limit, nil
+					})
+			},
+		},
+		{
+			name:        "deposits are not limited",
+			limit:       wallet.TransactionLimit{Type: wallet.Deposit, Asset: "USD"},
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidTransactionLimit,
+		},
+		{
+			name:        "unsupported asset",
+			limit:       wallet.TransactionLimit{Type: wallet.Transfer, Asset: "DOGE"},
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrUnsupportedAsset,
+		},
+		{
+			name:  "wallet not found",
+			limit: wallet.TransactionLimit{Type: wallet.Transfer, Asset: "USD"},
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					SetTransactionLimit(gomock.Any(), "user1", gomock.Any()).
+					Return(wallet.TransactionLimit{}, wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			limit, err := s.SetTransactionLimit(context.Background(), "user1", tc.limit)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.limit, limit)
+			}
+		})
+	}
+}
+
+func TestDeleteTransactionLimit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		txnType     wallet.TransactionType
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name:    "limit deleted",
+			txnType: wallet.Transfer,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DeleteTransactionLimit(gomock.Any(), "user1", wallet.Transfer, "USD").Return(nil)
+			},
+		},
+		{
+			name:        "reversals are not limited",
+			txnType:     wallet.Reversal,
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidTransactionLimit,
+		},
+		{
+			name:    "no limit of the wallet",
+			txnType: wallet.Withdraw,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					DeleteTransactionLimit(gomock.Any(), "user1", wallet.Withdraw, "USD").
+					Return(wallet.ErrTransactionLimitNotFound)
+			},
+			expectedErr: wallet.ErrTransactionLimitNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			err := s.DeleteTransactionLimit(context.Background(), "user1", tc.txnType, "USD")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestGetTransactionLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	mockRepo.EXPECT().GetTransactionLimits(gomock.Any(), "user1").Return(nil, errors.New("db error"))
+	s := servicewallet.New(mockRepo)
+
+	_, err := s.GetTransactionLimits(context.Background(), "user1")
+
+	assert.EqualError(t, err, "get transaction limits repo err: db error")
+}
@@ -0,0 +1,24 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/publisher"
+)
+
+// OutboxBatchSize is the most outbox events relayed per batch.
+const OutboxBatchSize = 100
+
+// RelayOutboxEvents publishes a batch of unpublished outbox events in order, returning the number of events published.
+func (s *Service) RelayOutboxEvents(ctx context.Context, pub publisher.Publisher) (int, error) {
+	published, err := s.walletRepo.PublishOutboxEvents(ctx, OutboxBatchSize, func(event domainwallet.OutboxEvent) error {
+		return pub.Publish(ctx, event)
+	})
+	if err != nil {
+		return published, fmt.Errorf("publish outbox events repo err: %w", err)
+	}
+
+	return published, nil
+}
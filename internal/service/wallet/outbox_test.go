@@ -0,0 +1,73 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/publisher"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayOutboxEvents(t *testing.T) {
+	events := []wallet.OutboxEvent{
+		{ID: 1, Type: wallet.TransactionCreatedEvent, WalletID: "wallet-1", Payload: []byte(`{}`)},
+		{ID: 2, Type: wallet.TransactionCreatedEvent, WalletID: "wallet-2", Payload: []byte(`{}`)},
+	}
+	errStream := errors.New("stream unavailable")
+
+	// publishEvents stands in for the repository, publishing the events in order until the first failure
+	publishEvents := func(_ context.Context, limit int, publish func(wallet.OutboxEvent) error) (int, error) {
+		assert.Equal(t, servicewallet.OutboxBatchSize, limit)
+		for i, e := range events {
+			if err := publish(e); err != nil {
+				return i, err
+			}
+		}
+		return len(events), nil
+	}
+
+	testCases := []struct {
+		name              string
+		publishErr        error
+		expectedPublished []wallet.OutboxEvent
+		expectedCount     int
+		expectedErr       error
+	}{
+		{
+			name:              "events relayed",
+			expectedPublished: events,
+			expectedCount:     2,
+		},
+		{
+			name:          "publisher unavailable",
+			publishErr:    errStream,
+			expectedErr:   errStream,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			mockRepo.EXPECT().PublishOutboxEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(publishEvents)
+			svc := servicewallet.New(mockRepo)
+
+			pub := publisher.NewMemoryPublisher()
+			pub.FailWith(tc.publishErr)
+
+			count, err := svc.RelayOutboxEvents(context.Background(), pub)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedCount, count)
+			assert.Equal(t, tc.expectedPublished, pub.Events())
+		})
+	}
+}
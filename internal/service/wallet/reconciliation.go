@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// ReconciliationBatchSize is the most wallets reconciled per query.
+const ReconciliationBatchSize = 500
+
+// Reconcile recomputes every wallet's balances from its transactions, page by page, and records the balances that do not
+// match the stored ones. With freeze, wallets failing reconciliation are frozen unless they are already frozen or closed.
+// The finished reconciliation is returned with every mismatch found.
+func (s *Service) Reconcile(ctx context.Context, freeze bool) (domainwallet.Reconciliation, error) {
+	reconciliation, err := s.walletRepo.StartReconciliation(ctx, freeze)
+	if err != nil {
+		return domainwallet.Reconciliation{}, fmt.Errorf("start reconciliation repo err: %w", err)
+	}
+
+	mismatches, checked, err := s.reconcileWallets(ctx, reconciliation.ID, freeze)
+	if err != nil {
+		// Still record the failure when the reconciliation was cancelled
+		_, finishErr := s.walletRepo.FinishReconciliation(
+			context.WithoutCancel(ctx),
+			reconciliation.ID,
+			domainwallet.ReconciliationFailed,
+			checked,
+			len(mismatches),
+		)
+
+		return domainwallet.Reconciliation{}, errors.Join(err, finishErr)
+	}
+
+	reconciliation, err = s.walletRepo.FinishReconciliation(
+		ctx,
+		reconciliation.ID,
+		domainwallet.ReconciliationCompleted,
+		checked,
+		len(mismatches),
+	)
+	if err != nil {
+		return domainwallet.Reconciliation{}, fmt.Errorf("finish reconciliation repo err: %w", err)
+	}
+
+	reconciliation.Mismatches = mismatches
+
+	return reconciliation, nil
+}
+
+// reconcileWallets reconciles every wallet in pages of ReconciliationBatchSize, returning the mismatches recorded and the number of wallets checked.
+func (s *Service) reconcileWallets(
+	ctx context.Context,
+	reconciliationID string,
+	freeze bool,
+) ([]domainwallet.BalanceMismatch, int, error) {
+	var (
+		mismatches    []domainwallet.BalanceMismatch
+		checked       int
+		afterWalletID string
+	)
+
+	for {
+		page, err := s.walletRepo.ReconcileWallets(ctx, afterWalletID, ReconciliationBatchSize)
+		if err != nil {
+			return mismatches, checked, fmt.Errorf("reconcile wallets repo err: %w", err)
+		}
+
+		if freeze {
+			if err := s.freezeMismatchedWallets(ctx, page.Mismatches); err != nil {
+				return mismatches, checked, err
+			}
+		}
+
+		err = s.walletRepo.RecordReconciliationMismatches(ctx, reconciliationID, page.Mismatches)
+		if err != nil {
+			return mismatches, checked, fmt.Errorf("record reconciliation mismatches repo err: %w", err)
+		}
+
+		mismatches = append(mismatches, page.Mismatches...)
+		checked += page.WalletsChecked
+
+		if page.WalletsChecked < ReconciliationBatchSize {
+			return mismatches, checked, nil
+		}
+
+		afterWalletID = page.LastWalletID
+	}
+}
+
+// freezeMismatchedWallets freezes the wallets of the mismatches once each and marks their mismatches as frozen.
+// Wallets that cannot be frozen, or got frozen or closed in the meantime, are left as they are.
+func (s *Service) freezeMismatchedWallets(ctx context.Context, mismatches []domainwallet.BalanceMismatch) error {
+	frozen := make(map[string]bool)
+	for i := range mismatches {
+		m := &mismatches[i]
+
+		done, ok := frozen[m.WalletID]
+		if !ok {
+			done = false
+			if m.WalletStatus.CanTransitionTo(domainwallet.Frozen) {
+				_, err := s.updateWalletStatus(ctx, m.UserID, domainwallet.Frozen)
+				if err != nil && !errors.Is(err, domainwallet.ErrInvalidWalletStatusTransition) {
+					return fmt.Errorf("freeze mismatched wallet %s err: %w", m.WalletID, err)
+				}
+
+				done = err == nil
+			}
+
+			frozen[m.WalletID] = done
+		}
+
+		m.Frozen = done
+	}
+
+	return nil
+}
+
+// GetReconciliation returns the reconciliation with its mismatched balances.
+func (s *Service) GetReconciliation(ctx context.Context, reconciliationID string) (domainwallet.Reconciliation, error) {
+	reconciliation, err := s.walletRepo.GetReconciliation(ctx, reconciliationID)
+	if err != nil {
+		return domainwallet.Reconciliation{}, fmt.Errorf("get reconciliation repo err: %w", err)
+	}
+
+	return reconciliation, nil
+}
+
+// ListReconciliations returns the latest reconciliations, most recent first.
+func (s *Service) ListReconciliations(ctx context.Context, limit int) ([]domainwallet.Reconciliation, error) {
+	reconciliations, err := s.walletRepo.ListReconciliations(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliations repo err: %w", err)
+	}
+
+	return reconciliations, nil
+}
@@ -0,0 +1,196 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	running := wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationRunning}
+	mismatchUSD := wallet.BalanceMismatch{
+		WalletID: "wallet-1", UserID: "user1", WalletStatus: wallet.Active, Asset: "USD", Balance: 1500, ComputedBalance: 1000,
+	}
+	mismatchBTC := wallet.BalanceMismatch{
+		WalletID: "wallet-1", UserID: "user1", WalletStatus: wallet.Active, Asset: "BTC", Balance: 1, ComputedBalance: 2,
+	}
+	mismatchClosed := wallet.BalanceMismatch{
+		WalletID: "wallet-9", UserID: "user9", WalletStatus: wallet.Closed, Asset: "USD", Balance: 10,
+	}
+	errDB := errors.New("db down")
+
+	frozen := func(m wallet.BalanceMismatch) wallet.BalanceMismatch {
+		m.Frozen = true
+		return m
+	}
+
+	testCases := []struct {
+		name                   string
+		freeze                 bool
+		mockBehavior           func(m *mocks.MockIWalletRepository)
+		expectedReconciliation wallet.Reconciliation
+		expectedErr            error
+	}{
+		{
+			name: "reconcile every page",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().StartReconciliation(gomock.Any(), false).Return(running, nil)
+				m.EXPECT().ReconcileWallets(gomock.Any(), "", servicewallet.ReconciliationBatchSize).Return(
+					wallet.ReconciliationPage{
+						WalletsChecked: servicewallet.ReconciliationBatchSize,
+						LastWalletID:   "wallet-500",
+						Mismatches:     []wallet.BalanceMismatch{mismatchUSD},
+					}, nil,
+				)
+				m.EXPECT().RecordReconciliationMismatches(gomock.Any(), "rec-1", []wallet.BalanceMismatch{mismatchUSD}).Return(nil)
+				m.EXPECT().ReconcileWallets(gomock.Any(), "wallet-500", servicewallet.ReconciliationBatchSize).Return(
+					wallet.ReconciliationPage{WalletsChecked: 2, LastWalletID: "wallet-502"}, nil,
+				)
+				m.EXPECT().RecordReconciliationMismatches(gomock.Any(), "rec-1", nil).Return(nil)
+				m.EXPECT().
+					FinishReconciliation(gomock.Any(), "rec-1", wallet.ReconciliationCompleted, servicewallet.ReconciliationBatchSize+2, 1).
+					Return(wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationCompleted, WalletsChecked: 502, MismatchCount: 1}, nil)
+			},
+			expectedReconciliation: wallet.Reconciliation{
+				ID:             "rec-1",
+				Status:         wallet.ReconciliationCompleted,
+				WalletsChecked: 502,
+				MismatchCount:  1,
+				Mismatches:     []wallet.BalanceMismatch{mismatchUSD},
+			},
+		},
+		{
+			name:   "freeze mismatched wallets once",
+			freeze: true,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().StartReconciliation(gomock.Any(), true).Return(running, nil)
+				m.EXPECT().ReconcileWallets(gomock.Any(), "", servicewallet.ReconciliationBatchSize).Return(
+					wallet.ReconciliationPage{
+						WalletsChecked: 9,
+						LastWalletID:   "wallet-9",
+						Mismatches:     []wallet.BalanceMismatch{mismatchBTC, mismatchUSD, mismatchClosed},
+					}, nil,
+				)
+				m.EXPECT().UpdateWalletStatus(gomock.Any(), "user1", wallet.Frozen).Return(wallet.Wallet{}, nil)
+				m.EXPECT().RecordReconciliationMismatches(
+					gomock.Any(), "rec-1", []wallet.BalanceMismatch{frozen(mismatchBTC), frozen(mismatchUSD), mismatchClosed},
+				).Return(nil)
+				m.EXPECT().
+					FinishReconciliation(gomock.Any(), "rec-1", wallet.ReconciliationCompleted, 9, 3).
+					Return(wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationCompleted, Freeze: true, WalletsChecked: 9, MismatchCount: 3}, nil)
+			},
+			expectedReconciliation: wallet.Reconciliation{
+				ID:             "rec-1",
+				Status:         wallet.ReconciliationCompleted,
+				Freeze:         true,
+				WalletsChecked: 9,
+				MismatchCount:  3,
+				Mismatches:     []wallet.BalanceMismatch{frozen(mismatchBTC), frozen(mismatchUSD), mismatchClosed},
+			},
+		},
+		{
+			name:   "wallet frozen in the meantime",
+			freeze: true,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().StartReconciliation(gomock.Any(), true).Return(running, nil)
+				m.EXPECT().ReconcileWallets(gomock.Any(), "", servicewallet.ReconciliationBatchSize).Return(
+					wallet.ReconciliationPage{WalletsChecked: 1, LastWalletID: "wallet-1", Mismatches: []wallet.BalanceMismatch{mismatchUSD}}, nil,
+				)
+				m.EXPECT().UpdateWalletStatus(gomock.Any(), "user1", wallet.Frozen).
+					Return(wallet.Wallet{}, wallet.ErrInvalidWalletStatusTransition)
+				m.EXPECT().RecordReconciliationMismatches(gomock.Any(), "rec-1", []wallet.BalanceMismatch{mismatchUSD}).Return(nil)
+				m.EXPECT().
+					FinishReconciliation(gomock.Any(), "rec-1", wallet.ReconciliationCompleted, 1, 1).
+					Return(wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationCompleted}, nil)
+			},
+			expectedReconciliation: wallet.Reconciliation{
+				ID:         "rec-1",
+				Status:     wallet.ReconciliationCompleted,
+				Mismatches: []wallet.BalanceMismatch{mismatchUSD},
+			},
+		},
+		{
+			name: "another reconciliation running",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().StartReconciliation(gomock.Any(), false).Return(wallet.Reconciliation{}, wallet.ErrReconciliationInProgress)
+			},
+			expectedErr: wallet.ErrReconciliationInProgress,
+		},
+		{
+			name: "failed reconciliation is recorded",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().StartReconciliation(gomock.Any(), false).Return(running, nil)
+				m.EXPECT().ReconcileWallets(gomock.Any(), "", servicewallet.ReconciliationBatchSize).
+					Return(wallet.ReconciliationPage{}, errDB)
+				m.EXPECT().
+					FinishReconciliation(gomock.Any(), "rec-1", wallet.ReconciliationFailed, 0, 0).
+					Return(wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationFailed}, nil)
+			},
+			expectedErr: errDB,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			result, err := svc.Reconcile(context.Background(), tc.freeze)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedReconciliation, result)
+		})
+	}
+}
+
+func TestGetReconciliation(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		mockBehavior           func(m *mocks.MockIWalletRepository)
+		expectedReconciliation wallet.Reconciliation
+		expectedErr            error
+	}{
+		{
+			name: "reconciliation found",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetReconciliation(gomock.Any(), "rec-1").
+					Return(wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationCompleted}, nil)
+			},
+			expectedReconciliation: wallet.Reconciliation{ID: "rec-1", Status: wallet.ReconciliationCompleted},
+		},
+		{
+			name: "reconciliation not found",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetReconciliation(gomock.Any(), "rec-1").
+					Return(wallet.Reconciliation{}, wallet.ErrReconciliationNotFound)
+			},
+			expectedErr: wallet.ErrReconciliationNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			result, err := svc.GetReconciliation(context.Background(), "rec-1")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedReconciliation, result)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// ReverseTransaction moves amount of a transaction back to where it came from, or its whole remainder when amount is zero.
+func (s *Service) ReverseTransaction(
+	ctx context.Context,
+	transactionID string,
+	key domainwallet.IdempotencyKey,
+	amount uint64,
+) (domainwallet.IdempotentResponse, error) {
+	resp, err := s.walletRepo.ReverseTransaction(ctx, transactionID, key, amount)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("reverse transaction repo err: %w", err)
+	}
+
+	return resp, nil
+}
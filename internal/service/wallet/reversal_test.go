@@ -0,0 +1,81 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseTransaction(t *testing.T) {
+	tests := []struct {
+		name             string
+		amount           uint64
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
+	}{
+		{
+			name:   "success - full reversal",
+			amount: 0,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					ReverseTransaction(gomock.Any(), "tx1", domainwallet.IdempotencyKey{Key: "reverse-key"}, uint64(0)).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx2"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx2"}`)},
+		},
+		{
+			name:   "success - partial refund",
+			amount: 40,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					ReverseTransaction(gomock.Any(), "tx1", domainwallet.IdempotencyKey{Key: "reverse-key"}, uint64(40)).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx3"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx3"}`)},
+		},
+		{
+			name:   "error - funds already spent",
+			amount: 40,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					ReverseTransaction(gomock.Any(), "tx1", domainwallet.IdempotencyKey{Key: "reverse-key"}, uint64(40)).
+					Return(domainwallet.IdempotentResponse{}, domainwallet.ErrReversalFundsSpent)
+			},
+			expectedError: errors.New("reverse transaction repo err: insufficient balance to reverse, funds already spent"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tt.mockBehavior(mockRepo)
+
+			service := wallet.New(mockRepo)
+
+			resp, err := service.ReverseTransaction(
+				context.Background(),
+				"tx1",
+				domainwallet.IdempotencyKey{Key: "reverse-key"},
+				tt.amount,
+			)
+
+			assert.Equal(t, tt.expectedResponse, resp)
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func (s *Service) ListTransactionReviews(ctx context.Context, limit int) ([]domainwallet.TransactionReview, error) {
+	reviews, err := s.walletRepo.ListTransactionReviews(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list transaction reviews repo err: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// ApproveTransactionReview executes a transaction held for review.
+func (s *Service) ApproveTransactionReview(
+	ctx context.Context,
+	transactionID string,
+) (domainwallet.TransactionReview, error) {
+	review, err := s.walletRepo.DecideTransactionReview(ctx, transactionID, domainwallet.ReviewApproved)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("approve transaction review repo err: %w", err)
+	}
+
+	return review, nil
+}
+
+// RejectTransactionReview fails a transaction held for review, releasing its reserved funds.
+func (s *Service) RejectTransactionReview(
+	ctx context.Context,
+	transactionID string,
+) (domainwallet.TransactionReview, error) {
+	review, err := s.walletRepo.DecideTransactionReview(ctx, transactionID, domainwallet.ReviewRejected)
+	if err != nil {
+		return domainwallet.TransactionReview{}, fmt.Errorf("reject transaction review repo err: %w", err)
+	}
+
+	return review, nil
+}
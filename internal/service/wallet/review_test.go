@@ -0,0 +1,132 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTransactionReviews(t *testing.T) {
+	reviews := []wallet.TransactionReview{{Transaction: wallet.Transaction{ID: "tx1"}, Rule: "new_recipient"}}
+
+	testCases := []struct {
+		name            string
+		prepareMock     func(*mocks.MockIWalletRepository)
+		expectedReviews []wallet.TransactionReview
+		expectedErr     error
+	}{
+		{
+			name: "pending reviews",
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ListTransactionReviews(gomock.Any(), 100).Return(reviews, nil)
+			},
+			expectedReviews: reviews,
+		},
+		{
+			name: "repo error",
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ListTransactionReviews(gomock.Any(), 100).Return(nil, errors.New("db error"))
+			},
+			expectedErr: errors.New("list transaction reviews repo err: db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+
+			got, err := servicewallet.New(mockRepo).ListTransactionReviews(context.Background(), 100)
+
+			if tc.expectedErr != nil {
+				assert.ErrorContains(t, err, tc.expectedErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReviews, got)
+		})
+	}
+}
+
+func TestDecideTransactionReview(t *testing.T) {
+	approved, rejected := wallet.ReviewApproved, wallet.ReviewRejected
+
+	testCases := []struct {
+		name           string
+		approve        bool
+		prepareMock    func(*mocks.MockIWalletRepository)
+		expectedReview wallet.TransactionReview
+		expectedErr    error
+	}{
+		{
+			name:    "approve",
+			approve: true,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DecideTransactionReview(gomock.Any(), "tx1", wallet.ReviewApproved).
+					Return(wallet.TransactionReview{Transaction: wallet.Transaction{ID: "tx1"}, Decision: &approved}, nil)
+			},
+			expectedReview: wallet.TransactionReview{Transaction: wallet.Transaction{ID: "tx1"}, Decision: &approved},
+		},
+		{
+			name: "reject",
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DecideTransactionReview(gomock.Any(), "tx1", wallet.ReviewRejected).
+					Return(wallet.TransactionReview{Transaction: wallet.Transaction{ID: "tx1"}, Decision: &rejected}, nil)
+			},
+			expectedReview: wallet.TransactionReview{Transaction: wallet.Transaction{ID: "tx1"}, Decision: &rejected},
+		},
+		{
+			name:    "approve already decided review",
+			approve: true,
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DecideTransactionReview(gomock.Any(), "tx1", wallet.ReviewApproved).
+					Return(wallet.TransactionReview{}, wallet.ErrReviewDecided)
+			},
+			expectedErr: wallet.ErrReviewDecided,
+		},
+		{
+			name: "reject missing review",
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().DecideTransactionReview(gomock.Any(), "tx1", wallet.ReviewRejected).
+					Return(wallet.TransactionReview{}, wallet.ErrReviewNotFound)
+			},
+			expectedErr: wallet.ErrReviewNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+
+			service := servicewallet.New(mockRepo)
+			decide := service.RejectTransactionReview
+			if tc.approve {
+				decide = service.ApproveTransactionReview
+			}
+
+			got, err := decide(context.Background(), "tx1")
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedReview, got)
+		})
+	}
+}
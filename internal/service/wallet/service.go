@@ -1,13 +1,19 @@
 package wallet
 
-import "github.com/jennwah/crypto-assignment/internal/repository/wallet"
+import (
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet"
+)
 
 type Service struct {
 	walletRepo wallet.IWalletRepository
+	fraudRules domainwallet.FraudRules
 }
 
-func New(walletRepo wallet.IWalletRepository) *Service {
+// New returns the wallet service, withdrawals and transfers are screened with fraudRules before they execute.
+func New(walletRepo wallet.IWalletRepository, fraudRules ...domainwallet.FraudRule) *Service {
 	return &Service{
 		walletRepo: walletRepo,
+		fraudRules: fraudRules,
 	}
 }
@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// BalanceSnapshotLag is how far in the past balances are snapshotted, so transactions still in flight
+// at the snapshot instant have usually committed by the time it is taken. The repository moves the instant
+// before any transaction still in flight regardless.
+const BalanceSnapshotLag = 5 * time.Minute
+
+// GetWalletBalancesAsOf returns the wallet's balances at a past instant.
+func (s *Service) GetWalletBalancesAsOf(
+	ctx context.Context,
+	userID string,
+	asOf time.Time,
+) (domainwallet.WalletBalancesAt, error) {
+	if err := domainwallet.ValidateAsOf(asOf, time.Now()); err != nil {
+		return domainwallet.WalletBalancesAt{}, fmt.Errorf("wallet service get wallet balances as of err: %w", err)
+	}
+
+	balances, err := s.walletRepo.GetWalletBalancesAsOf(ctx, userID, asOf)
+	if err != nil {
+		return domainwallet.WalletBalancesAt{}, fmt.Errorf("wallet service get wallet balances as of err: %w", err)
+	}
+
+	return balances, nil
+}
+
+// ListWalletBalancesAsOf returns the page of wallets after the afterWalletID cursor, or the first page when empty,
+// with their balances at a past instant, and the cursor of the next page, empty on the last page.
+func (s *Service) ListWalletBalancesAsOf(
+	ctx context.Context,
+	asOf time.Time,
+	afterWalletID string,
+	pageSize int,
+) ([]domainwallet.WalletBalancesAt, string, error) {
+	if err := domainwallet.ValidateAsOf(asOf, time.Now()); err != nil {
+		return nil, "", fmt.Errorf("wallet service list wallet balances as of err: %w", err)
+	}
+
+	// fetch one more wallet than the page holds to know whether there is a next page
+	wallets, err := s.walletRepo.ListWalletBalancesAsOf(ctx, asOf, afterWalletID, pageSize+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("wallet service list wallet balances as of err: %w", err)
+	}
+
+	if len(wallets) <= pageSize {
+		return wallets, "", nil
+	}
+
+	wallets = wallets[:pageSize]
+
+	return wallets, wallets[pageSize-1].WalletID, nil
+}
+
+// SnapshotBalances snapshots every wallet's balances BalanceSnapshotLag ago, returning the number of balances snapshotted.
+func (s *Service) SnapshotBalances(ctx context.Context) (int, error) {
+	snapshotted, err := s.walletRepo.SnapshotBalances(ctx, time.Now().Add(-BalanceSnapshotLag))
+	if err != nil {
+		return 0, fmt.Errorf("snapshot balances repo err: %w", err)
+	}
+
+	return snapshotted, nil
+}
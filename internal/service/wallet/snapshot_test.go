@@ -0,0 +1,151 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWalletBalancesAsOf(t *testing.T) {
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	balances := wallet.WalletBalancesAt{
+		WalletID: "wallet-1",
+		UserID:   "user789",
+		AsOf:     asOf,
+		Balances: []wallet.AssetBalance{{Asset: "USD", Balance: 1000}},
+	}
+
+	testCases := []struct {
+		name             string
+		asOf             time.Time
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedBalances wallet.WalletBalancesAt
+		expectedErr      error
+	}{
+		{
+			name: "balances as of",
+			asOf: asOf,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetWalletBalancesAsOf(gomock.Any(), "user789", asOf).Return(balances, nil)
+			},
+			expectedBalances: balances,
+		},
+		{
+			name:         "future instant",
+			asOf:         time.Now().Add(time.Hour),
+			mockBehavior: func(m *mocks.MockIWalletRepository) {},
+			expectedErr:  wallet.ErrInvalidAsOf,
+		},
+		{
+			name: "wallet not found",
+			asOf: asOf,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().GetWalletBalancesAsOf(gomock.Any(), "user789", asOf).Return(wallet.WalletBalancesAt{}, wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			result, err := svc.GetWalletBalancesAsOf(context.Background(), "user789", tc.asOf)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedBalances, result)
+		})
+	}
+}
+
+func TestListWalletBalancesAsOf(t *testing.T) {
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+	wallets := []wallet.WalletBalancesAt{
+		{WalletID: "wallet-1", UserID: "user1", AsOf: asOf},
+		{WalletID: "wallet-2", UserID: "user2", AsOf: asOf},
+		{WalletID: "wallet-3", UserID: "user3", AsOf: asOf},
+	}
+
+	testCases := []struct {
+		name            string
+		asOf            time.Time
+		mockBehavior    func(m *mocks.MockIWalletRepository)
+		expectedWallets []wallet.WalletBalancesAt
+		expectedNext    string
+		expectedErr     error
+	}{
+		{
+			name: "page with a next page",
+			asOf: asOf,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ListWalletBalancesAsOf(gomock.Any(), asOf, "wallet-0", 3).Return(wallets, nil)
+			},
+			expectedWallets: wallets[:2],
+			expectedNext:    "wallet-2",
+		},
+		{
+			name: "last page",
+			asOf: asOf,
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().ListWalletBalancesAsOf(gomock.Any(), asOf, "wallet-0", 3).Return(wallets[:1], nil)
+			},
+			expectedWallets: wallets[:1],
+		},
+		{
+			name:         "future instant",
+			asOf:         time.Now().Add(time.Hour),
+			mockBehavior: func(m *mocks.MockIWalletRepository) {},
+			expectedErr:  wallet.ErrInvalidAsOf,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			result, next, err := svc.ListWalletBalancesAsOf(context.Background(), tc.asOf, "wallet-0", 2)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedWallets, result)
+			assert.Equal(t, tc.expectedNext, next)
+		})
+	}
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	svc := servicewallet.New(mockRepo)
+
+	before := time.Now().Add(-servicewallet.BalanceSnapshotLag)
+	mockRepo.EXPECT().
+		SnapshotBalances(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, at time.Time) (int, error) {
+			assert.False(t, at.Before(before))
+			assert.True(t, at.Before(time.Now().Add(-servicewallet.BalanceSnapshotLag+time.Second)))
+			return 4, nil
+		})
+
+	snapshotted, err := svc.SnapshotBalances(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, snapshotted)
+}
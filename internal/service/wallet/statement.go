@@ -0,0 +1,44 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// GetWalletStatement generates the wallet's statement over the period, of a single asset or of every asset when empty.
+func (s *Service) GetWalletStatement(
+	ctx context.Context,
+	userID string,
+	period domainwallet.StatementPeriod,
+	asset string,
+) (*domainwallet.Statement, error) {
+	if asset != "" {
+		if _, err := domainwallet.LookupAsset(asset); err != nil {
+			return nil, fmt.Errorf("wallet service get wallet statement asset err: %w", err)
+		}
+	}
+
+	openingBalances, err := s.walletRepo.GetWalletBalancesAt(ctx, userID, period.Start)
+	if err != nil {
+		return nil, fmt.Errorf("wallet service get wallet statement opening balances err: %w", err)
+	}
+
+	if asset != "" {
+		openingBalances = map[string]int64{asset: openingBalances[asset]}
+	}
+
+	statement := domainwallet.NewStatement(userID, period, openingBalances)
+
+	filter := domainwallet.TransactionFilter{Asset: asset, From: period.Start, To: period.End}
+	err = s.walletRepo.StreamWalletTransactions(ctx, userID, filter, func(line domainwallet.StatementLine) error {
+		statement.Add(line)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wallet service get wallet statement transactions err: %w", err)
+	}
+
+	return statement, nil
+}
@@ -0,0 +1,113 @@
+package wallet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWalletStatement(t *testing.T) {
+	period := wallet.StatementPeriod{
+		Start: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	lines := []wallet.StatementLine{
+		{Transaction: wallet.Transaction{ID: "tx1", Type: wallet.Deposit, Status: wallet.Success, Asset: "USD", Amount: 500}, Change: 500, Balance: 1500},
+		{Transaction: wallet.Transaction{ID: "tx2", Type: wallet.Withdraw, Status: wallet.Success, Asset: "USD", Amount: 200}, Change: -200, Balance: 1300},
+	}
+	streamLines := func(_ context.Context, _ string, _ wallet.TransactionFilter, fn func(wallet.StatementLine) error) error {
+		for _, line := range lines {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name              string
+		asset             string
+		mockBehavior      func(m *mocks.MockIWalletRepository)
+		expectedStatement *wallet.Statement
+		expectedErr       error
+	}{
+		{
+			name: "statement of every asset",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletBalancesAt(gomock.Any(), "user789", period.Start).
+					Return(map[string]int64{"USD": 1000, "BTC": 7}, nil)
+				m.EXPECT().
+					StreamWalletTransactions(gomock.Any(), "user789", wallet.TransactionFilter{From: period.Start, To: period.End}, gomock.Any()).
+					DoAndReturn(streamLines)
+			},
+			expectedStatement: &wallet.Statement{
+				UserID: "user789",
+				Period: period,
+				Assets: []wallet.AssetStatement{
+					{Asset: "BTC", OpeningBalance: 7, ClosingBalance: 7},
+					{
+						Asset:          "USD",
+						OpeningBalance: 1000,
+						ClosingBalance: 1300,
+						Totals: []wallet.StatementTotal{
+							{Type: wallet.Deposit, Count: 1, Credited: 500},
+							{Type: wallet.Withdraw, Count: 1, Debited: 200},
+						},
+						Lines: lines,
+					},
+				},
+			},
+		},
+		{
+			name:  "statement of a single asset without opening balance",
+			asset: "USDT",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletBalancesAt(gomock.Any(), "user789", period.Start).
+					Return(map[string]int64{"USD": 1000}, nil)
+				m.EXPECT().
+					StreamWalletTransactions(gomock.Any(), "user789", wallet.TransactionFilter{Asset: "USDT", From: period.Start, To: period.End}, gomock.Any()).
+					Return(nil)
+			},
+			expectedStatement: &wallet.Statement{UserID: "user789", Period: period},
+		},
+		{
+			name:         "unsupported asset",
+			asset:        "DOGE",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {},
+			expectedErr:  wallet.ErrUnsupportedAsset,
+		},
+		{
+			name: "wallet not found",
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetWalletBalancesAt(gomock.Any(), "user789", period.Start).
+					Return(nil, wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.mockBehavior(mockRepo)
+			svc := servicewallet.New(mockRepo)
+
+			statement, err := svc.GetWalletStatement(context.Background(), "user789", period, tc.asset)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedStatement, statement)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+func (s *Service) FreezeWallet(ctx context.Context, userID string) (domainwallet.Wallet, error) {
+	return s.updateWalletStatus(ctx, userID, domainwallet.Frozen)
+}
+
+func (s *Service) UnfreezeWallet(ctx context.Context, userID string) (domainwallet.Wallet, error) {
+	return s.updateWalletStatus(ctx, userID, domainwallet.Active)
+}
+
+func (s *Service) CloseWallet(ctx context.Context, userID string) (domainwallet.Wallet, error) {
+	return s.updateWalletStatus(ctx, userID, domainwallet.Closed)
+}
+
+func (s *Service) updateWalletStatus(
+	ctx context.Context,
+	userID string,
+	status domainwallet.WalletStatus,
+) (domainwallet.Wallet, error) {
+	wallet, err := s.walletRepo.UpdateWalletStatus(ctx, userID, status)
+	if err != nil {
+		return domainwallet.Wallet{}, fmt.Errorf("update wallet status repo err: %w", err)
+	}
+
+	return wallet, nil
+}
@@ -0,0 +1,82 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateWalletStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	svc := servicewallet.New(mockRepo)
+
+	testCases := []struct {
+		name          string
+		userID        string
+		call          func(ctx context.Context, userID string) (wallet.Wallet, error)
+		status        wallet.WalletStatus
+		mockError     error
+		expectedError error
+	}{
+		{
+			name:   "freeze wallet",
+			userID: "user1",
+			call:   svc.FreezeWallet,
+			status: wallet.Frozen,
+		},
+		{
+			name:   "unfreeze wallet",
+			userID: "user2",
+			call:   svc.UnfreezeWallet,
+			status: wallet.Active,
+		},
+		{
+			name:   "close wallet",
+			userID: "user3",
+			call:   svc.CloseWallet,
+			status: wallet.Closed,
+		},
+		{
+			name:          "repo error",
+			userID:        "user4",
+			call:          svc.CloseWallet,
+			status:        wallet.Closed,
+			mockError:     wallet.ErrWalletHasBalance,
+			expectedError: errors.New("update wallet status repo err: wallet still has balance"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockResult := wallet.Wallet{}
+			if tc.mockError == nil {
+				mockResult = wallet.Wallet{ID: "id", UserID: tc.userID, Status: tc.status}
+			}
+
+			mockRepo.
+				EXPECT().
+				UpdateWalletStatus(gomock.Any(), tc.userID, tc.status).
+				Return(mockResult, tc.mockError)
+
+			result, err := tc.call(context.Background(), tc.userID)
+
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tc.mockError)
+				assert.Equal(t, tc.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.status, result.Status)
+			}
+		})
+	}
+}
@@ -3,23 +3,60 @@ package wallet
 import (
 	"context"
 	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 )
 
 func (s *Service) Transfer(
 	ctx context.Context,
-	initiatorUserID, recipientUserID, idempotencyKey string,
+	initiatorUserID, recipientUserID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	txID, err := s.walletRepo.Transfer(
+) (domainwallet.IdempotentResponse, error) {
+	if _, err := domainwallet.LookupAsset(asset); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transfer asset err: %w", err)
+	}
+
+	if initiatorUserID == recipientUserID {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transfer err: %w", domainwallet.ErrSelfTransfer)
+	}
+
+	resp, found, err := s.recordedResponse(ctx, initiatorUserID, domainwallet.Transfer.Operation(), key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transfer idempotency err: %w", err)
+	}
+	if found {
+		return resp, nil
+	}
+
+	if err := s.checkKYCTier(ctx, initiatorUserID, domainwallet.Transfer); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transfer kyc err: %w", err)
+	}
+
+	verdict, err := s.screen(ctx, domainwallet.FraudCheck{
+		Type:            domainwallet.Transfer,
+		UserID:          initiatorUserID,
+		RecipientUserID: recipientUserID,
+		Asset:           asset,
+		Amount:          amount,
+	})
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("transfer fraud err: %w", err)
+	}
+
+	resp, err = s.walletRepo.Transfer(
 		ctx,
 		initiatorUserID,
 		recipientUserID,
-		idempotencyKey,
+		key,
+		asset,
 		amount,
+		verdict,
 	)
 	if err != nil {
-		return "", fmt.Errorf("repo transfer err: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("repo transfer err: %w", err)
 	}
 
-	return txID, nil
+	return resp, nil
 }
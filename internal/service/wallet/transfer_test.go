@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
 	"github.com/jennwah/crypto-assignment/internal/service/wallet"
 	"github.com/stretchr/testify/assert"
@@ -16,14 +17,29 @@ func TestTransfer(t *testing.T) {
 		initiatorUserID string
 		recipientUserID string
 		idempotencyKey  string
+		asset           string
 		amount          uint64
 	}
+	allowed := domainwallet.FraudVerdict{Decision: domainwallet.FraudAllow}
+	newRecipient := domainwallet.NewRecipientRule{
+		Thresholds: map[string]uint64{"USD": 1000},
+		Decision:   domainwallet.FraudBlock,
+	}
+	newRecipientCheck := domainwallet.FraudCheck{
+		Type:            domainwallet.Transfer,
+		UserID:          "user111",
+		RecipientUserID: "user222",
+		Asset:           "USD",
+		Amount:          5000,
+	}
+
 	tests := []struct {
-		name          string
-		args          args
-		mockBehavior  func(m *mocks.MockIWalletRepository)
-		expectedTxID  string
-		expectedError error
+		name             string
+		args             args
+		fraudRules       []domainwallet.FraudRule
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
 	}{
 		{
 			name: "happy case - valid transfer",
@@ -31,15 +47,18 @@ func TestTransfer(t *testing.T) {
 				initiatorUserID: "user123",
 				recipientUserID: "user456",
 				idempotencyKey:  "unique-key",
+				asset:           "USD",
 				amount:          1000,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.Transfer.Operation(), "unique-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
 				m.EXPECT().
-					Transfer(gomock.Any(), "user123", "user456", "unique-key", uint64(1000)).
-					Return("tx123", nil)
+					Transfer(gomock.Any(), "user123", "user456", domainwallet.IdempotencyKey{Key: "unique-key"}, "USD", uint64(1000), allowed).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx123"}`)}, nil)
 			},
-			expectedTxID:  "tx123",
-			expectedError: nil,
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx123"}`)},
+			expectedError:    nil,
 		},
 		{
 			name: "repo error",
@@ -47,16 +66,120 @@ func TestTransfer(t *testing.T) {
 				initiatorUserID: "user789",
 				recipientUserID: "user321",
 				idempotencyKey:  "unique-key",
+				asset:           "USD",
 				amount:          500,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user789", domainwallet.Transfer.Operation(), "unique-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user789").Return(domainwallet.BasicKYC, nil)
 				m.EXPECT().
-					Transfer(gomock.Any(), "user789", "user321", "unique-key", uint64(500)).
-					Return("", errors.New("db connection error"))
+					Transfer(gomock.Any(), "user789", "user321", domainwallet.IdempotencyKey{Key: "unique-key"}, "USD", uint64(500), allowed).
+					Return(domainwallet.IdempotentResponse{}, errors.New("db connection error"))
 			},
-			expectedTxID:  "",
 			expectedError: errors.New("repo transfer err: db connection error"),
 		},
+		{
+			name: "blocked first transfer to a new recipient",
+			args: args{
+				initiatorUserID: "user111",
+				recipientUserID: "user222",
+				idempotencyKey:  "unique-key",
+				asset:           "USD",
+				amount:          5000,
+			},
+			fraudRules: []domainwallet.FraudRule{newRecipient},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user111", domainwallet.Transfer.Operation(), "unique-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user111").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().GetFraudSignals(gomock.Any(), newRecipientCheck).
+					Return(domainwallet.FraudSignals{FraudCheck: newRecipientCheck, Total: 5000, NewRecipient: true}, nil)
+				m.EXPECT().
+					Transfer(
+						gomock.Any(),
+						"user111",
+						"user222",
+						domainwallet.IdempotencyKey{Key: "unique-key"},
+						"USD",
+						uint64(5000),
+						domainwallet.FraudVerdict{Decision: domainwallet.FraudBlock, Rule: "new_recipient"},
+					).
+					Return(domainwallet.IdempotentResponse{StatusCode: 403, Body: []byte(`{"message":"transaction blocked"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 403, Body: []byte(`{"message":"transaction blocked"}`)},
+		},
+		{
+			name: "error - fraud signals repo error",
+			args: args{
+				initiatorUserID: "user111",
+				recipientUserID: "user222",
+				idempotencyKey:  "unique-key",
+				asset:           "USD",
+				amount:          5000,
+			},
+			fraudRules: []domainwallet.FraudRule{newRecipient},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user111", domainwallet.Transfer.Operation(), "unique-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user111").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().GetFraudSignals(gomock.Any(), newRecipientCheck).
+					Return(domainwallet.FraudSignals{}, errors.New("db error"))
+			},
+			expectedError: errors.New("transfer fraud err: get fraud signals repo err: db error"),
+		},
+		{
+			name: "error - unverified initiator cannot transfer",
+			args: args{
+				initiatorUserID: "user555",
+				recipientUserID: "user456",
+				idempotencyKey:  "unique-key",
+				asset:           "USD",
+				amount:          1000,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user555", domainwallet.Transfer.Operation(), "unique-key")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user555").Return(domainwallet.Unverified, nil)
+			},
+			expectedError: errors.New("transfer kyc err: kyc tier does not allow this operation: unverified wallets cannot transfer"),
+		},
+		{
+			name: "success - recorded response replayed before the kyc and fraud checks",
+			args: args{
+				initiatorUserID: "user555",
+				recipientUserID: "user456",
+				idempotencyKey:  "unique-key",
+				asset:           "USD",
+				amount:          1000,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetIdempotentResponse(gomock.Any(), "user555", domainwallet.Transfer.Operation(), domainwallet.IdempotencyKey{Key: "unique-key"}).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx555"}`)}, true, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx555"}`)},
+		},
+		{
+			name: "error - unsupported asset",
+			args: args{
+				initiatorUserID: "user123",
+				recipientUserID: "user456",
+				idempotencyKey:  "unique-key",
+				asset:           "DOGE",
+				amount:          1000,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("transfer asset err: asset \"DOGE\": unsupported asset"),
+		},
+		{
+			name: "error - self transfer",
+			args: args{
+				initiatorUserID: "user123",
+				recipientUserID: "user123",
+				idempotencyKey:  "unique-key",
+				asset:           "USD",
+				amount:          1000,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("transfer err: cannot transfer to own wallet"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,17 +190,18 @@ func TestTransfer(t *testing.T) {
 			mockRepo := mocks.NewMockIWalletRepository(ctrl)
 			tt.mockBehavior(mockRepo)
 
-			service := wallet.New(mockRepo)
+			service := wallet.New(mockRepo, tt.fraudRules...)
 
-			txID, err := service.Transfer(
+			resp, err := service.Transfer(
 				context.Background(),
 				tt.args.initiatorUserID,
 				tt.args.recipientUserID,
-				tt.args.idempotencyKey,
+				domainwallet.IdempotencyKey{Key: tt.args.idempotencyKey},
+				tt.args.asset,
 				tt.args.amount,
 			)
 
-			assert.Equal(t, tt.expectedTxID, txID)
+			assert.Equal(t, tt.expectedResponse, resp)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
@@ -0,0 +1,32 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+)
+
+// SubscribeWalletUpdates returns the user's wallet with the updates of its transactions from now on, until ctx is done.
+// The wallet is read again once subscribed, so its balances followed by the updates never miss a transaction.
+func (s *Service) SubscribeWalletUpdates(
+	ctx context.Context,
+	userID string,
+) (domainwallet.Wallet, <-chan domainwallet.TransactionCreated, error) {
+	wallet, err := s.walletRepo.GetWallet(ctx, userID)
+	if err != nil {
+		return domainwallet.Wallet{}, nil, fmt.Errorf("get wallet repo err: %w", err)
+	}
+
+	updates, err := s.walletRepo.SubscribeWalletUpdates(ctx, wallet.ID)
+	if err != nil {
+		return domainwallet.Wallet{}, nil, fmt.Errorf("subscribe wallet updates repo err: %w", err)
+	}
+
+	wallet, err = s.walletRepo.GetWallet(ctx, userID)
+	if err != nil {
+		return domainwallet.Wallet{}, nil, fmt.Errorf("get wallet repo err: %w", err)
+	}
+
+	return wallet, updates, nil
+}
@@ -0,0 +1,78 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeWalletUpdates(t *testing.T) {
+	before := wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balances: []wallet.Balance{{Asset: "USD", Balance: 100}}}
+	after := wallet.Wallet{ID: "wallet-1", UserID: "user-1", Balances: []wallet.Balance{{Asset: "USD", Balance: 150}}}
+	errRedis := errors.New("redis unavailable")
+
+	testCases := []struct {
+		name           string
+		prepareMock    func(*mocks.MockIWalletRepository, chan wallet.TransactionCreated)
+		expectedWallet wallet.Wallet
+		expectedErr    error
+	}{
+		{
+			name: "wallet read again once subscribed",
+			prepareMock: func(m *mocks.MockIWalletRepository, updates chan wallet.TransactionCreated) {
+				gomock.InOrder(
+					m.EXPECT().GetWallet(gomock.Any(), "user-1").Return(before, nil),
+					m.EXPECT().SubscribeWalletUpdates(gomock.Any(), "wallet-1").Return(updates, nil),
+					m.EXPECT().GetWallet(gomock.Any(), "user-1").Return(after, nil),
+				)
+			},
+			expectedWallet: after,
+		},
+		{
+			name: "wallet not found",
+			prepareMock: func(m *mocks.MockIWalletRepository, _ chan wallet.TransactionCreated) {
+				m.EXPECT().GetWallet(gomock.Any(), "user-1").Return(wallet.Wallet{}, wallet.ErrWalletNotFound)
+			},
+			expectedErr: wallet.ErrWalletNotFound,
+		},
+		{
+			name: "subscription failed",
+			prepareMock: func(m *mocks.MockIWalletRepository, _ chan wallet.TransactionCreated) {
+				m.EXPECT().GetWallet(gomock.Any(), "user-1").Return(before, nil)
+				m.EXPECT().SubscribeWalletUpdates(gomock.Any(), "wallet-1").Return(nil, errRedis)
+			},
+			expectedErr: errRedis,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			updates := make(chan wallet.TransactionCreated, 1)
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo, updates)
+			s := servicewallet.New(mockRepo)
+
+			w, got, err := s.SubscribeWalletUpdates(context.Background(), "user-1")
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedWallet, w)
+			if tc.expectedErr != nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			update := wallet.TransactionCreated{WalletID: "wallet-1", UserID: "user-1"}
+			updates <- update
+			assert.Equal(t, update, <-got)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/webhook"
+)
+
+const (
+	// WebhookBatchSize is the most webhook deliveries attempted per batch, concurrently.
+	WebhookBatchSize = 20
+	// WebhookClaimLease is how long a claimed delivery is kept from other dispatchers, longer than any attempt takes.
+	WebhookClaimLease = time.Minute
+)
+
+// CreateWebhookSubscription validates and creates a subscription to the event types, of every wallet when userID is nil.
+func (s *Service) CreateWebhookSubscription(
+	ctx context.Context,
+	url, secret string,
+	eventTypes []string,
+	userID *string,
+) (domainwallet.WebhookSubscription, error) {
+	sub, err := domainwallet.NewWebhookSubscription(url, secret, eventTypes, userID)
+	if err != nil {
+		return domainwallet.WebhookSubscription{}, err
+	}
+
+	sub, err = s.walletRepo.CreateWebhookSubscription(ctx, sub)
+	if err != nil {
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("create webhook subscription repo err: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListWebhookSubscriptions returns every subscription, most recent first.
+func (s *Service) ListWebhookSubscriptions(ctx context.Context) ([]domainwallet.WebhookSubscription, error) {
+	subs, err := s.walletRepo.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions repo err: %w", err)
+	}
+
+	return subs, nil
+}
+
+// DeactivateWebhookSubscription stops the subscription, its deliveries are kept.
+func (s *Service) DeactivateWebhookSubscription(
+	ctx context.Context,
+	subscriptionID string,
+) (domainwallet.WebhookSubscription, error) {
+	sub, err := s.walletRepo.DeactivateWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return domainwallet.WebhookSubscription{}, fmt.Errorf("deactivate webhook subscription repo err: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ListWebhookDeliveries returns the latest deliveries of the subscription, of the status when not empty.
+func (s *Service) ListWebhookDeliveries(
+	ctx context.Context,
+	subscriptionID string,
+	status domainwallet.WebhookDeliveryStatus,
+	limit int,
+) ([]domainwallet.WebhookDelivery, error) {
+	deliveries, err := s.walletRepo.ListWebhookDeliveries(ctx, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries repo err: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayWebhookDelivery queues the delivery to be attempted again right away.
+func (s *Service) ReplayWebhookDelivery(
+	ctx context.Context,
+	subscriptionID, deliveryID string,
+) (domainwallet.WebhookDelivery, error) {
+	delivery, err := s.walletRepo.ReplayWebhookDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return domainwallet.WebhookDelivery{}, fmt.Errorf("replay webhook delivery repo err: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// DispatchWebhooks claims a batch of due deliveries, sends them concurrently and records each attempt,
+// returning the number of deliveries attempted. Failed attempts are retried with backoff until dead-lettered.
+func (s *Service) DispatchWebhooks(ctx context.Context, sender webhook.Sender) (int, error) {
+	now := time.Now()
+	dispatches, err := s.walletRepo.ClaimWebhookDeliveries(ctx, now, now.Add(WebhookClaimLease), WebhookBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim webhook deliveries repo err: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, dispatch := range dispatches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			attempt := sender.Send(ctx, dispatch)
+			delivery := dispatch.WebhookDelivery.Attempted(attempt, time.Now())
+
+			// Record the attempt even when dispatching is being stopped, so it is not attempted again early
+			err := s.walletRepo.UpdateWebhookDelivery(context.WithoutCancel(ctx), delivery)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("update webhook delivery %s repo err: %w", delivery.ID, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(dispatches), errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/pkg/webhook"
+	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
+	servicewallet "github.com/jennwah/crypto-assignment/internal/service/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookSecret = "whsec_0123456789abcdef"
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	testCases := []struct {
+		name        string
+		url         string
+		prepareMock func(*mocks.MockIWalletRepository)
+		expectedErr error
+	}{
+		{
+			name: "subscription created",
+			url:  "https://example.com/hooks",
+			prepareMock: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), wallet.WebhookSubscription{
+						URL:        "https://example.com/hooks",
+						Secret:     webhookSecret,
+						EventTypes: []string{wallet.TransactionCreatedEvent},
+						Active:     true,
+					}).
+					Return(wallet.WebhookSubscription{ID: "sub-1"}, nil)
+			},
+		},
+		{
+			name:        "invalid url",
+			url:         "example.com",
+			prepareMock: func(m *mocks.MockIWalletRepository) {},
+			expectedErr: wallet.ErrInvalidWebhookSubscription,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			tc.prepareMock(mockRepo)
+			s := servicewallet.New(mockRepo)
+
+			_, err := s.CreateWebhookSubscription(
+				context.Background(), tc.url, webhookSecret, []string{wallet.TransactionCreatedEvent}, nil,
+			)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestDispatchWebhooks(t *testing.T) {
+	createdAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	payload := []byte(`{"wallet_id":"wallet-1"}`)
+
+	testCases := []struct {
+		name           string
+		receiverStatus int
+		attempts       int
+		expectedStatus wallet.WebhookDeliveryStatus
+	}{
+		{
+			name:           "delivery acknowledged",
+			receiverStatus: http.StatusOK,
+			expectedStatus: wallet.WebhookSucceeded,
+		},
+		{
+			name:           "delivery rejected and retried",
+			receiverStatus: http.StatusInternalServerError,
+			attempts:       1,
+			expectedStatus: wallet.WebhookPending,
+		},
+		{
+			name:           "delivery dead-lettered after its last attempt",
+			receiverStatus: http.StatusServiceUnavailable,
+			attempts:       wallet.MaxWebhookAttempts - 1,
+			expectedStatus: wallet.WebhookDead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			received := make(chan *http.Request, 1)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+
+				// Verify the request the way a receiver would
+				ts, err := strconv.ParseInt(r.Header.Get(wallet.WebhookTimestampHeader), 10, 64)
+				assert.NoError(t, err)
+				assert.Equal(t, wallet.SignWebhook(webhookSecret, time.Unix(ts, 0), body), r.Header.Get(wallet.WebhookSignatureHeader))
+				assert.JSONEq(t, `{"id":7,"type":"wallet.transaction.created","created_at":"2026-10-17T00:00:00Z","data":{"wallet_id":"wallet-1"}}`, string(body))
+
+				received <- r
+				w.WriteHeader(tc.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dispatch := wallet.WebhookDispatch{
+				WebhookDelivery: wallet.WebhookDelivery{
+					ID:             "d-1",
+					SubscriptionID: "sub-1",
+					EventID:        7,
+					Status:         wallet.WebhookPending,
+					Attempts:       tc.attempts,
+				},
+				URL:    receiver.URL,
+				Secret: webhookSecret,
+				Event: wallet.OutboxEvent{
+					ID:        7,
+					Type:      wallet.TransactionCreatedEvent,
+					WalletID:  "wallet-1",
+					Payload:   payload,
+					CreatedAt: createdAt,
+				},
+			}
+
+			mockRepo := mocks.NewMockIWalletRepository(ctrl)
+			mockRepo.EXPECT().
+				ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), servicewallet.WebhookBatchSize).
+				DoAndReturn(func(_ context.Context, now, leaseUntil time.Time, _ int) ([]wallet.WebhookDispatch, error) {
+					assert.Equal(t, servicewallet.WebhookClaimLease, leaseUntil.Sub(now))
+					return []wallet.WebhookDispatch{dispatch}, nil
+				})
+			mockRepo.EXPECT().
+				UpdateWebhookDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, d wallet.WebhookDelivery) error {
+					assert.Equal(t, tc.expectedStatus, d.Status)
+					assert.Equal(t, tc.attempts+1, d.Attempts)
+					require.NotNil(t, d.LastStatusCode)
+					assert.Equal(t, tc.receiverStatus, *d.LastStatusCode)
+					return nil
+				})
+
+			s := servicewallet.New(mockRepo)
+			attempted, err := s.DispatchWebhooks(context.Background(), webhook.NewHTTPSender(time.Second))
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, attempted)
+
+			r := <-received
+			assert.Equal(t, "d-1", r.Header.Get(wallet.WebhookIDHeader))
+			assert.Equal(t, wallet.TransactionCreatedEvent, r.Header.Get(wallet.WebhookEventHeader))
+		})
+	}
+}
+
+func TestDispatchWebhooksUnreachableReceiver(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiverURL := receiver.URL
+	receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errUpdate := errors.New("db unavailable")
+	mockRepo := mocks.NewMockIWalletRepository(ctrl)
+	mockRepo.EXPECT().
+		ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]wallet.WebhookDispatch{{
+			WebhookDelivery: wallet.WebhookDelivery{ID: "d-1", Status: wallet.WebhookPending},
+			URL:             receiverURL,
+			Secret:          webhookSecret,
+			Event:           wallet.OutboxEvent{ID: 7, Type: wallet.TransactionCreatedEvent, Payload: []byte(`{}`)},
+		}}, nil)
+	mockRepo.EXPECT().
+		UpdateWebhookDelivery(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, d wallet.WebhookDelivery) error {
+			assert.Equal(t, wallet.WebhookPending, d.Status)
+			assert.Nil(t, d.LastStatusCode)
+			assert.NotNil(t, d.LastError)
+			return errUpdate
+		})
+
+	s := servicewallet.New(mockRepo)
+	attempted, err := s.DispatchWebhooks(context.Background(), webhook.NewHTTPSender(time.Second))
+
+	assert.Equal(t, 1, attempted)
+	assert.ErrorIs(t, err, errUpdate)
+}
@@ -3,17 +3,47 @@ package wallet
 import (
 	"context"
 	"fmt"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 )
 
 func (s *Service) WithdrawWallet(
 	ctx context.Context,
-	userID, idempotencyKey string,
+	userID string,
+	key domainwallet.IdempotencyKey,
+	asset string,
 	amount uint64,
-) (string, error) {
-	txID, err := s.walletRepo.WithdrawWallet(ctx, userID, idempotencyKey, amount)
+) (domainwallet.IdempotentResponse, error) {
+	if _, err := domainwallet.LookupAsset(asset); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("withdraw wallet asset err: %w", err)
+	}
+
+	resp, found, err := s.recordedResponse(ctx, userID, domainwallet.Withdraw.Operation(), key)
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("withdraw wallet idempotency err: %w", err)
+	}
+	if found {
+		return resp, nil
+	}
+
+	if err := s.checkKYCTier(ctx, userID, domainwallet.Withdraw); err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("withdraw wallet kyc err: %w", err)
+	}
+
+	verdict, err := s.screen(ctx, domainwallet.FraudCheck{
+		Type:   domainwallet.Withdraw,
+		UserID: userID,
+		Asset:  asset,
+		Amount: amount,
+	})
+	if err != nil {
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("withdraw wallet fraud err: %w", err)
+	}
+
+	resp, err = s.walletRepo.WithdrawWallet(ctx, userID, key, asset, amount, verdict)
 	if err != nil {
-		return "", fmt.Errorf("withdraw wallet repo err: %w", err)
+		return domainwallet.IdempotentResponse{}, fmt.Errorf("withdraw wallet repo err: %w", err)
 	}
 
-	return txID, nil
+	return resp, nil
 }
@@ -6,54 +6,187 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
 	"github.com/jennwah/crypto-assignment/internal/repository/wallet/mocks"
 	"github.com/jennwah/crypto-assignment/internal/service/wallet"
 	"github.com/stretchr/testify/assert"
 )
 
+// expectNoRecordedResponse expects the idempotency key to be looked up before the KYC tier and fraud checks, not recorded yet.
+func expectNoRecordedResponse(m *mocks.MockIWalletRepository, userID string, operation domainwallet.IdempotentOperation, key string) {
+	m.EXPECT().
+		GetIdempotentResponse(gomock.Any(), userID, operation, domainwallet.IdempotencyKey{Key: key}).
+		Return(domainwallet.IdempotentResponse{}, false, nil)
+}
+
 func TestWithdrawWallet(t *testing.T) {
 	type args struct {
 		userID         string
 		idempotencyKey string
+		asset          string
 		amount         uint64
 	}
+	allowed := domainwallet.FraudVerdict{Decision: domainwallet.FraudAllow}
+	velocity := domainwallet.WithdrawalVelocityRule{Max: 3, Decision: domainwallet.FraudReview}
+	velocityCheck := domainwallet.FraudCheck{Type: domainwallet.Withdraw, UserID: "user777", Asset: "USD", Amount: 100}
+
 	tests := []struct {
-		name          string
-		args          args
-		mockBehavior  func(m *mocks.MockIWalletRepository)
-		expectedTxID  string
-		expectedError error
+		name             string
+		args             args
+		fraudRules       []domainwallet.FraudRule
+		mockBehavior     func(m *mocks.MockIWalletRepository)
+		expectedResponse domainwallet.IdempotentResponse
+		expectedError    error
 	}{
 		{
 			name: "success - valid withdrawal",
 			args: args{
 				userID:         "user123",
 				idempotencyKey: "withdraw-key-1",
+				asset:          "USD",
 				amount:         750,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user123", domainwallet.Withdraw.Operation(), "withdraw-key-1")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user123").Return(domainwallet.BasicKYC, nil)
 				m.EXPECT().
-					WithdrawWallet(gomock.Any(), "user123", "withdraw-key-1", uint64(750)).
-					Return("tx789", nil)
+					WithdrawWallet(gomock.Any(), "user123", domainwallet.IdempotencyKey{Key: "withdraw-key-1"}, "USD", uint64(750), allowed).
+					Return(domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx789"}`)}, nil)
 			},
-			expectedTxID:  "tx789",
-			expectedError: nil,
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 200, Body: []byte(`{"transaction_id":"tx789"}`)},
+			expectedError:    nil,
 		},
 		{
 			name: "error - insufficient funds",
 			args: args{
 				userID:         "user999",
 				idempotencyKey: "withdraw-fail",
+				asset:          "USD",
 				amount:         5000,
 			},
 			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user999", domainwallet.Withdraw.Operation(), "withdraw-fail")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user999").Return(domainwallet.BasicKYC, nil)
 				m.EXPECT().
-					WithdrawWallet(gomock.Any(), "user999", "withdraw-fail", uint64(5000)).
-					Return("", errors.New("insufficient funds"))
+					WithdrawWallet(gomock.Any(), "user999", domainwallet.IdempotencyKey{Key: "withdraw-fail"}, "USD", uint64(5000), allowed).
+					Return(domainwallet.IdempotentResponse{}, errors.New("insufficient funds"))
 			},
-			expectedTxID:  "",
 			expectedError: errors.New("withdraw wallet repo err: insufficient funds"),
 		},
+		{
+			name: "error - unverified wallet cannot withdraw",
+			args: args{
+				userID:         "user555",
+				idempotencyKey: "withdraw-unverified",
+				asset:          "USD",
+				amount:         100,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user555", domainwallet.Withdraw.Operation(), "withdraw-unverified")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user555").Return(domainwallet.Unverified, nil)
+			},
+			expectedError: errors.New("withdraw wallet kyc err: kyc tier does not allow this operation: unverified wallets cannot withdraw"),
+		},
+		{
+			name: "error - kyc tier repo error",
+			args: args{
+				userID:         "user556",
+				idempotencyKey: "withdraw-tier-err",
+				asset:          "USD",
+				amount:         100,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user556", domainwallet.Withdraw.Operation(), "withdraw-tier-err")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user556").Return(domainwallet.KYCTier(""), domainwallet.ErrWalletNotFound)
+			},
+			expectedError: errors.New("withdraw wallet kyc err: get wallet kyc tier repo err: wallet not found"),
+		},
+		{
+			name: "success - held for review by a fraud rule",
+			args: args{
+				userID:         "user777",
+				idempotencyKey: "withdraw-velocity",
+				asset:          "USD",
+				amount:         100,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user777", domainwallet.Withdraw.Operation(), "withdraw-velocity")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user777").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().GetFraudSignals(gomock.Any(), velocityCheck).
+					Return(domainwallet.FraudSignals{FraudCheck: velocityCheck, Total: 100, RecentWithdrawals: 3}, nil)
+				m.EXPECT().
+					WithdrawWallet(
+						gomock.Any(),
+						"user777",
+						domainwallet.IdempotencyKey{Key: "withdraw-velocity"},
+						"USD",
+						uint64(100),
+						domainwallet.FraudVerdict{Decision: domainwallet.FraudReview, Rule: "withdrawal_velocity"},
+					).
+					Return(domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx777"}`)}, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx777"}`)},
+		},
+		{
+			name: "error - fraud signals repo error",
+			args: args{
+				userID:         "user778",
+				idempotencyKey: "withdraw-signals-err",
+				asset:          "USD",
+				amount:         100,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				expectNoRecordedResponse(m, "user778", domainwallet.Withdraw.Operation(), "withdraw-signals-err")
+				m.EXPECT().GetWalletKYCTier(gomock.Any(), "user778").Return(domainwallet.BasicKYC, nil)
+				m.EXPECT().GetFraudSignals(gomock.Any(), gomock.Any()).
+					Return(domainwallet.FraudSignals{}, domainwallet.ErrWalletNotFound)
+			},
+			expectedError: errors.New("withdraw wallet fraud err: get fraud signals repo err: wallet not found"),
+		},
+		{
+			name: "success - recorded response replayed before the kyc and fraud checks",
+			args: args{
+				userID:         "user555",
+				idempotencyKey: "withdraw-replay",
+				asset:          "USD",
+				amount:         100,
+			},
+			fraudRules: []domainwallet.FraudRule{velocity},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetIdempotentResponse(gomock.Any(), "user555", domainwallet.Withdraw.Operation(), domainwallet.IdempotencyKey{Key: "withdraw-replay"}).
+					Return(domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx555"}`)}, true, nil)
+			},
+			expectedResponse: domainwallet.IdempotentResponse{StatusCode: 202, Body: []byte(`{"transaction_id":"tx555"}`)},
+		},
+		{
+			name: "error - idempotency key reused for a different request",
+			args: args{
+				userID:         "user555",
+				idempotencyKey: "withdraw-reused",
+				asset:          "USD",
+				amount:         100,
+			},
+			mockBehavior: func(m *mocks.MockIWalletRepository) {
+				m.EXPECT().
+					GetIdempotentResponse(gomock.Any(), "user555", domainwallet.Withdraw.Operation(), domainwallet.IdempotencyKey{Key: "withdraw-reused"}).
+					Return(domainwallet.IdempotentResponse{}, true, domainwallet.ErrIdempotencyKeyReused)
+			},
+			expectedError: errors.New("withdraw wallet idempotency err: get idempotent response repo err: " + domainwallet.ErrIdempotencyKeyReused.Error()),
+		},
+		{
+			name: "error - unsupported asset",
+			args: args{
+				userID:         "user123",
+				idempotencyKey: "key-doge",
+				asset:          "DOGE",
+				amount:         100,
+			},
+			mockBehavior:  func(m *mocks.MockIWalletRepository) {},
+			expectedError: errors.New("withdraw wallet asset err: asset \"DOGE\": unsupported asset"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -64,16 +197,17 @@ func TestWithdrawWallet(t *testing.T) {
 			mockRepo := mocks.NewMockIWalletRepository(ctrl)
 			tt.mockBehavior(mockRepo)
 
-			service := wallet.New(mockRepo)
+			service := wallet.New(mockRepo, tt.fraudRules...)
 
-			txID, err := service.WithdrawWallet(
+			resp, err := service.WithdrawWallet(
 				context.Background(),
 				tt.args.userID,
-				tt.args.idempotencyKey,
+				domainwallet.IdempotencyKey{Key: tt.args.idempotencyKey},
+				tt.args.asset,
 				tt.args.amount,
 			)
 
-			assert.Equal(t, tt.expectedTxID, txID)
+			assert.Equal(t, tt.expectedResponse, resp)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
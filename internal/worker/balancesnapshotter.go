@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+// BalanceSnapshotter periodically snapshots every wallet's balances, so point-in-time balances
+// only sum the ledger entries posted since the latest snapshot.
+type BalanceSnapshotter struct {
+	logger        *slog.Logger
+	walletService wallet.IWalletService
+	interval      time.Duration
+}
+
+func NewBalanceSnapshotter(logger *slog.Logger, walletService wallet.IWalletService, interval time.Duration) *BalanceSnapshotter {
+	return &BalanceSnapshotter{
+		logger:        logger,
+		walletService: walletService,
+		interval:      interval,
+	}
+}
+
+// Run snapshots balances on start and every interval until ctx is done, so restarts do not postpone snapshots.
+func (s *BalanceSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.snapshot(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.snapshot(ctx)
+		}
+	}
+}
+
+func (s *BalanceSnapshotter) snapshot(ctx context.Context) {
+	snapshotted, err := s.walletService.SnapshotBalances(ctx)
+	if err != nil {
+		s.logger.Error("balance snapshotter err", slog.Any("error", err))
+		return
+	}
+
+	s.logger.Info("snapshotted balances", slog.Int("balances", snapshotted))
+}
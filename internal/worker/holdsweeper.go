@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+// HoldSweeper periodically releases expired holds back to their wallets' available balance.
+type HoldSweeper struct {
+	logger        *slog.Logger
+	walletService wallet.IWalletService
+	interval      time.Duration
+}
+
+func NewHoldSweeper(logger *slog.Logger, walletService wallet.IWalletService, interval time.Duration) *HoldSweeper {
+	return &HoldSweeper{
+		logger:        logger,
+		walletService: walletService,
+		interval:      interval,
+	}
+}
+
+// Run sweeps expired holds every interval until ctx is done. A full batch is swept again
+// right away, as more expired holds are likely waiting.
+func (s *HoldSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *HoldSweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		released, err := s.walletService.ReleaseExpiredHolds(ctx)
+		if released > 0 {
+			s.logger.Info("released expired holds", slog.Int("released", released))
+		}
+
+		if err != nil {
+			s.logger.Error("hold sweeper err", slog.Any("error", err))
+			return
+		}
+
+		if released < wallet.ExpiredHoldsBatchSize {
+			return
+		}
+	}
+}
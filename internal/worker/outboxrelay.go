@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jennwah/crypto-assignment/internal/pkg/publisher"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+// OutboxRelay periodically publishes the outbox events of wallet changes, in order and at least once.
+type OutboxRelay struct {
+	logger        *slog.Logger
+	walletService wallet.IWalletService
+	publisher     publisher.Publisher
+	interval      time.Duration
+}
+
+func NewOutboxRelay(
+	logger *slog.Logger,
+	walletService wallet.IWalletService,
+	pub publisher.Publisher,
+	interval time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{
+		logger:        logger,
+		walletService: walletService,
+		publisher:     pub,
+		interval:      interval,
+	}
+}
+
+// Run relays events every interval until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay publishes batches until the outbox is drained, a failed batch is retried on the next tick.
+func (r *OutboxRelay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := r.walletService.RelayOutboxEvents(ctx, r.publisher)
+		if err != nil {
+			r.logger.Error("outbox relay err", slog.Int("published", published), slog.Any("error", err))
+			return
+		}
+
+		if published < wallet.OutboxBatchSize {
+			return
+		}
+	}
+}
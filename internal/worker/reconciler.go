@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	domainwallet "github.com/jennwah/crypto-assignment/internal/domain/wallet"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+// Reconciler periodically verifies every wallet's balances against its transactions, optionally freezing
+// the wallets failing reconciliation. Instances take turns, a run is skipped while another one is in progress.
+type Reconciler struct {
+	logger        *slog.Logger
+	walletService wallet.IWalletService
+	interval      time.Duration
+	freeze        bool
+}
+
+func NewReconciler(logger *slog.Logger, walletService wallet.IWalletService, interval time.Duration, freeze bool) *Reconciler {
+	return &Reconciler{
+		logger:        logger,
+		walletService: walletService,
+		interval:      interval,
+		freeze:        freeze,
+	}
+}
+
+// Run reconciles every interval until ctx is done. Unlike snapshots, a full reconciliation is not run on start,
+// so deploys do not trigger one per instance.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reconcile(ctx)
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	reconciliation, err := r.walletService.Reconcile(ctx, r.freeze)
+	if err != nil {
+		if errors.Is(err, domainwallet.ErrReconciliationInProgress) {
+			r.logger.Info("reconciliation already in progress, skipped")
+			return
+		}
+
+		r.logger.Error("reconciler err", slog.Any("error", err))
+		return
+	}
+
+	if reconciliation.MismatchCount == 0 {
+		r.logger.Info(
+			"reconciled wallets",
+			slog.String("reconciliation_id", reconciliation.ID),
+			slog.Int("wallets", reconciliation.WalletsChecked),
+		)
+		return
+	}
+
+	walletIDs := make([]string, 0, len(reconciliation.Mismatches))
+	for _, m := range reconciliation.Mismatches {
+		if len(walletIDs) == 0 || walletIDs[len(walletIDs)-1] != m.WalletID {
+			walletIDs = append(walletIDs, m.WalletID)
+		}
+	}
+
+	r.logger.Error(
+		"reconciliation found mismatched balances",
+		slog.String("reconciliation_id", reconciliation.ID),
+		slog.Int("wallets", reconciliation.WalletsChecked),
+		slog.Int("mismatches", reconciliation.MismatchCount),
+		slog.Any("wallet_ids", walletIDs),
+	)
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jennwah/crypto-assignment/internal/pkg/webhook"
+	"github.com/jennwah/crypto-assignment/internal/service/wallet"
+)
+
+// WebhookDispatcher periodically sends the due webhook deliveries, retrying failed ones with backoff.
+type WebhookDispatcher struct {
+	logger        *slog.Logger
+	walletService wallet.IWalletService
+	sender        webhook.Sender
+	interval      time.Duration
+}
+
+func NewWebhookDispatcher(
+	logger *slog.Logger,
+	walletService wallet.IWalletService,
+	sender webhook.Sender,
+	interval time.Duration,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		logger:        logger,
+		walletService: walletService,
+		sender:        sender,
+		interval:      interval,
+	}
+}
+
+// Run dispatches deliveries every interval until ctx is done.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatch(ctx)
+		}
+	}
+}
+
+// dispatch sends batches until no delivery is due.
+func (d *WebhookDispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		attempted, err := d.walletService.DispatchWebhooks(ctx, d.sender)
+		if err != nil {
+			d.logger.Error("webhook dispatcher err", slog.Int("attempted", attempted), slog.Any("error", err))
+			return
+		}
+
+		if attempted < wallet.WebhookBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,5 @@
+ALTER TABLE crypto.wallets
+    DROP COLUMN IF EXISTS status,
+    ALTER COLUMN balance DROP DEFAULT;
+
+DROP TYPE IF EXISTS crypto.wallet_status;
\ No newline at end of file
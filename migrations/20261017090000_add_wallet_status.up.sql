@@ -0,0 +1,5 @@
+CREATE TYPE crypto.wallet_status AS ENUM ('active', 'frozen', 'closed');
+
+ALTER TABLE crypto.wallets
+    ADD COLUMN status crypto.wallet_status NOT NULL DEFAULT 'active',
+    ALTER COLUMN balance SET DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- only USD balances can be restored, other assets are dropped
+ALTER TABLE crypto.wallets ADD COLUMN balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0);
+
+UPDATE crypto.wallets w
+SET balance = b.balance
+FROM crypto.balances b
+WHERE b.wallet_id = w.id AND b.asset = 'USD';
+
+ALTER TABLE crypto.transactions DROP COLUMN IF EXISTS asset;
+
+DROP TABLE IF EXISTS crypto.balances;
\ No newline at end of file
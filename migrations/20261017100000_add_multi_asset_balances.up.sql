@@ -0,0 +1,16 @@
+-- balances table, one row per wallet per asset held
+CREATE TABLE crypto.balances (
+    wallet_id UUID NOT NULL REFERENCES crypto.wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    PRIMARY KEY (wallet_id, asset)
+);
+
+-- existing wallets were USD cents wallets
+INSERT INTO crypto.balances (wallet_id, asset, balance)
+SELECT id, 'USD', balance FROM crypto.wallets;
+
+ALTER TABLE crypto.wallets DROP COLUMN balance;
+
+ALTER TABLE crypto.transactions ADD COLUMN asset VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE crypto.transactions ALTER COLUMN asset DROP DEFAULT;
\ No newline at end of file
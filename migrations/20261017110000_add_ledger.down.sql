@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS ledger_journal_balanced ON crypto.ledger_entries;
+DROP FUNCTION IF EXISTS crypto.check_ledger_journal_balanced();
+DROP TABLE IF EXISTS crypto.ledger_entries;
+DROP TABLE IF EXISTS crypto.ledger_journals;
+DROP TYPE IF EXISTS crypto.system_account;
\ No newline at end of file
@@ -0,0 +1,59 @@
+CREATE TYPE crypto.system_account AS ENUM ('external_cash_in', 'external_cash_out', 'fees', 'opening_balance');
+
+-- one journal per transaction, journals without transaction are opening balances
+CREATE TABLE crypto.ledger_journals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID UNIQUE REFERENCES crypto.transactions(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- positive amounts credit the account, negative amounts debit it
+CREATE TABLE crypto.ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    journal_id UUID NOT NULL REFERENCES crypto.ledger_journals(id),
+    wallet_id UUID REFERENCES crypto.wallets(id),
+    system_account crypto.system_account,
+    asset VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((wallet_id IS NULL) <> (system_account IS NULL))
+);
+
+CREATE INDEX idx_ledger_entries_journal_id ON crypto.ledger_entries(journal_id);
+CREATE INDEX idx_ledger_entries_wallet_id_asset ON crypto.ledger_entries(wallet_id, asset);
+
+-- double-entry invariant, checked at commit once every entry of the journal is inserted
+CREATE FUNCTION crypto.check_ledger_journal_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM crypto.ledger_entries
+        WHERE journal_id = NEW.journal_id
+        GROUP BY asset
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger journal % is not balanced', NEW.journal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_journal_balanced
+    AFTER INSERT ON crypto.ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION crypto.check_ledger_journal_balanced();
+
+-- existing balances predate the ledger, post them as opening balances
+DO $$
+DECLARE
+    b RECORD;
+    j UUID;
+BEGIN
+    FOR b IN SELECT wallet_id, asset, balance FROM crypto.balances WHERE balance > 0 LOOP
+        INSERT INTO crypto.ledger_journals DEFAULT VALUES RETURNING id INTO j;
+        INSERT INTO crypto.ledger_entries (journal_id, wallet_id, asset, amount)
+        VALUES (j, b.wallet_id, b.asset, b.balance);
+        INSERT INTO crypto.ledger_entries (journal_id, system_account, asset, amount)
+        VALUES (j, 'opening_balance', b.asset, -b.balance);
+    END LOOP;
+END;
+$$;
\ No newline at end of file
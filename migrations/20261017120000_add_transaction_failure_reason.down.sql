@@ -0,0 +1,4 @@
+ALTER TABLE crypto.transactions DROP CONSTRAINT transactions_failure_reason_check;
+ALTER TABLE crypto.transactions DROP COLUMN failure_reason;
+
+DROP TYPE crypto.transaction_failure_reason;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TYPE crypto.transaction_failure_reason AS ENUM ('insufficient_balance', 'wallet_frozen', 'wallet_closed', 'limit_exceeded');
+
+-- failed transactions carry the reason they were rejected, successful ones never do
+ALTER TABLE crypto.transactions ADD COLUMN failure_reason crypto.transaction_failure_reason;
+ALTER TABLE crypto.transactions ADD CONSTRAINT transactions_failure_reason_check
+    CHECK ((status = 'failed') = (failure_reason IS NOT NULL));
\ No newline at end of file
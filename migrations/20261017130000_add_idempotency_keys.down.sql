@@ -0,0 +1 @@
+DROP TABLE IF EXISTS crypto.idempotency_keys;
\ No newline at end of file
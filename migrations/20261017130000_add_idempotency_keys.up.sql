@@ -0,0 +1,15 @@
+-- idempotency keys are recorded in the same db transaction as the money movement
+-- with the fingerprint of the request and the full response replayed on retries
+CREATE TABLE crypto.idempotency_keys (
+    user_id UUID NOT NULL,
+    idempotency_key UUID NOT NULL,
+    operation crypto.transaction_type NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    transaction_id UUID NOT NULL REFERENCES crypto.transactions(id),
+    response_status SMALLINT NOT NULL,
+    response_body BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON crypto.idempotency_keys(created_at);
\ No newline at end of file
@@ -0,0 +1,13 @@
+DELETE FROM crypto.idempotency_keys WHERE operation IN ('hold', 'capture');
+ALTER TABLE crypto.idempotency_keys DROP CONSTRAINT idempotency_keys_reference_check;
+ALTER TABLE crypto.idempotency_keys DROP COLUMN hold_id;
+ALTER TABLE crypto.idempotency_keys ALTER COLUMN transaction_id SET NOT NULL;
+ALTER TABLE crypto.idempotency_keys
+    ALTER COLUMN operation TYPE crypto.transaction_type USING operation::text::crypto.transaction_type;
+DROP TYPE crypto.idempotent_operation;
+
+ALTER TABLE crypto.balances DROP CONSTRAINT balances_held_check;
+ALTER TABLE crypto.balances DROP COLUMN held;
+
+DROP TABLE crypto.holds;
+DROP TYPE crypto.hold_status;
\ No newline at end of file
@@ -0,0 +1,32 @@
+CREATE TYPE crypto.hold_status AS ENUM ('active', 'captured', 'released', 'expired');
+
+-- holds reserve part of a wallet's asset balance until captured into a withdrawal or transfer, released or expired
+CREATE TABLE crypto.holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES crypto.wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    captured_amount BIGINT NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status crypto.hold_status NOT NULL DEFAULT 'active',
+    transaction_id UUID REFERENCES crypto.transactions(id),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_id ON crypto.holds(wallet_id);
+-- the sweeper only scans active holds by expiry
+CREATE INDEX idx_holds_active_expires_at ON crypto.holds(expires_at) WHERE status = 'active';
+
+-- sum of the wallet's active holds of the asset, which can neither be withdrawn nor transferred
+ALTER TABLE crypto.balances ADD COLUMN held BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE crypto.balances ADD CONSTRAINT balances_held_check CHECK (held >= 0 AND held <= balance);
+
+-- idempotency keys of created holds refer to the hold instead of a transaction
+CREATE TYPE crypto.idempotent_operation AS ENUM ('deposit', 'withdraw', 'transfer', 'hold', 'capture');
+ALTER TABLE crypto.idempotency_keys
+    ALTER COLUMN operation TYPE crypto.idempotent_operation USING operation::text::crypto.idempotent_operation;
+ALTER TABLE crypto.idempotency_keys ALTER COLUMN transaction_id DROP NOT NULL;
+ALTER TABLE crypto.idempotency_keys ADD COLUMN hold_id UUID REFERENCES crypto.holds(id);
+ALTER TABLE crypto.idempotency_keys ADD CONSTRAINT idempotency_keys_reference_check
+    CHECK ((transaction_id IS NULL) <> (hold_id IS NULL));
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- enum values cannot be dropped, the types are recreated without them.
+-- This fails while reversals are recorded, rather than silently dropping money movements.
+DELETE FROM crypto.idempotency_keys WHERE operation = 'reverse';
+ALTER TYPE crypto.idempotent_operation RENAME TO idempotent_operation_old;
+CREATE TYPE crypto.idempotent_operation AS ENUM ('deposit', 'withdraw', 'transfer', 'hold', 'capture');
+ALTER TABLE crypto.idempotency_keys
+    ALTER COLUMN operation TYPE crypto.idempotent_operation USING operation::text::crypto.idempotent_operation;
+DROP TYPE crypto.idempotent_operation_old;
+
+DROP INDEX crypto.idx_transactions_parent_transaction_id;
+ALTER TABLE crypto.transactions DROP CONSTRAINT transactions_parent_transaction_check;
+ALTER TABLE crypto.transactions DROP COLUMN parent_transaction_id;
+
+ALTER TYPE crypto.transaction_type RENAME TO transaction_type_old;
+CREATE TYPE crypto.transaction_type AS ENUM ('deposit', 'withdraw', 'transfer');
+ALTER TABLE crypto.transactions
+    ALTER COLUMN type TYPE crypto.transaction_type USING type::text::crypto.transaction_type;
+DROP TYPE crypto.transaction_type_old;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TYPE crypto.transaction_type ADD VALUE 'reversal';
+ALTER TYPE crypto.idempotent_operation ADD VALUE 'reverse';
+
+-- reversals and partial refunds move funds back in the opposite direction of their parent transaction
+ALTER TABLE crypto.transactions ADD COLUMN parent_transaction_id UUID REFERENCES crypto.transactions(id);
+ALTER TABLE crypto.transactions ADD CONSTRAINT transactions_parent_transaction_check
+    CHECK ((type::text = 'reversal') = (parent_transaction_id IS NOT NULL));
+
+CREATE INDEX idx_transactions_parent_transaction_id ON crypto.transactions(parent_transaction_id)
+    WHERE parent_transaction_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- This fails while a user and an admin key collide, rather than silently dropping recorded responses.
+DROP INDEX crypto.idx_idempotency_keys_admin_key;
+ALTER TABLE crypto.idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE crypto.idempotency_keys DROP COLUMN scope;
+ALTER TABLE crypto.idempotency_keys ADD PRIMARY KEY (user_id, idempotency_key);
+DROP TYPE crypto.idempotency_scope;
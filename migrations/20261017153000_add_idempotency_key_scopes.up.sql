@@ -0,0 +1,9 @@
+-- reversal keys are chosen by admins, not by the user whose transaction is reversed, so they are recorded in their own
+-- scope, unique across admins, and a user and an admin picking the same key never replay each other's response
+CREATE TYPE crypto.idempotency_scope AS ENUM ('user', 'admin');
+ALTER TABLE crypto.idempotency_keys ADD COLUMN scope crypto.idempotency_scope NOT NULL
+    GENERATED ALWAYS AS (CASE WHEN operation = 'reverse' THEN 'admin'::crypto.idempotency_scope ELSE 'user'::crypto.idempotency_scope END) STORED;
+
+ALTER TABLE crypto.idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE crypto.idempotency_keys ADD PRIMARY KEY (scope, user_id, idempotency_key);
+CREATE UNIQUE INDEX idx_idempotency_keys_admin_key ON crypto.idempotency_keys(idempotency_key) WHERE scope = 'admin';
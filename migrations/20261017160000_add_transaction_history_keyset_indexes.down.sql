@@ -0,0 +1,5 @@
+CREATE INDEX idx_transactions_initiator_wallet_id ON crypto.transactions(initiator_wallet_id);
+CREATE INDEX idx_transactions_recipient_wallet_id ON crypto.transactions(recipient_wallet_id);
+
+DROP INDEX IF EXISTS crypto.idx_transactions_initiator_wallet_id_created_at_id;
+DROP INDEX IF EXISTS crypto.idx_transactions_recipient_wallet_id_created_at_id;
\ No newline at end of file
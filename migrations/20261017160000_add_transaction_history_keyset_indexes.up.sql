@@ -0,0 +1,9 @@
+-- history pages seek both sides of a wallet's transactions in (created_at, id) order,
+-- superseding the single column wallet indexes
+CREATE INDEX idx_transactions_initiator_wallet_id_created_at_id
+    ON crypto.transactions(initiator_wallet_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_recipient_wallet_id_created_at_id
+    ON crypto.transactions(recipient_wallet_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS crypto.idx_transactions_initiator_wallet_id;
+DROP INDEX IF EXISTS crypto.idx_transactions_recipient_wallet_id;
\ No newline at end of file
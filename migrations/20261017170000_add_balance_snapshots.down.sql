@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS crypto.idx_ledger_entries_created_at;
+DROP INDEX IF EXISTS crypto.idx_ledger_entries_wallet_id_created_at;
+
+DROP TABLE crypto.balance_snapshots;
+DROP TABLE crypto.balance_snapshot_runs;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- instants at which every wallet's balances were snapshotted, point-in-time balances start from the latest one before the instant
+CREATE TABLE crypto.balance_snapshot_runs (
+    taken_at TIMESTAMP PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- non-zero wallet balances derived from the ledger at a snapshot run, zero balances are left out
+CREATE TABLE crypto.balance_snapshots (
+    taken_at TIMESTAMP NOT NULL REFERENCES crypto.balance_snapshot_runs(taken_at),
+    wallet_id UUID NOT NULL REFERENCES crypto.wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    balance BIGINT NOT NULL,
+    PRIMARY KEY (taken_at, wallet_id, asset)
+);
+
+-- ledger entries are only summed since the latest snapshot, per wallet and for every wallet
+CREATE INDEX idx_ledger_entries_wallet_id_created_at ON crypto.ledger_entries(wallet_id, created_at);
+CREATE INDEX idx_ledger_entries_created_at ON crypto.ledger_entries(created_at);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE crypto.reconciliation_mismatches;
+DROP TABLE crypto.reconciliations;
+DROP TYPE crypto.reconciliation_status;
\ No newline at end of file
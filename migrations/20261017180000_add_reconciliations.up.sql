@@ -0,0 +1,27 @@
+CREATE TYPE crypto.reconciliation_status AS ENUM ('running', 'completed', 'failed');
+
+-- runs verifying every wallet's stored balances against the balances recomputed from its transactions
+CREATE TABLE crypto.reconciliations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status crypto.reconciliation_status NOT NULL DEFAULT 'running',
+    freeze BOOLEAN NOT NULL DEFAULT FALSE,
+    wallets_checked INT NOT NULL DEFAULT 0,
+    mismatches INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+-- a single reconciliation runs at a time
+CREATE UNIQUE INDEX idx_reconciliations_running ON crypto.reconciliations(status) WHERE status = 'running';
+CREATE INDEX idx_reconciliations_started_at ON crypto.reconciliations(started_at DESC);
+
+-- wallet assets whose stored balance did not match their transactions
+CREATE TABLE crypto.reconciliation_mismatches (
+    reconciliation_id UUID NOT NULL REFERENCES crypto.reconciliations(id),
+    wallet_id UUID NOT NULL REFERENCES crypto.wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    balance BIGINT NOT NULL,
+    computed_balance BIGINT NOT NULL,
+    frozen BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (reconciliation_id, wallet_id, asset)
+);
\ No newline at end of file
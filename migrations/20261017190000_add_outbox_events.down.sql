@@ -0,0 +1 @@
+DROP TABLE crypto.outbox_events;
\ No newline at end of file
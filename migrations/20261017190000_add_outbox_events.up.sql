@@ -0,0 +1,12 @@
+-- events of wallet changes, written in the same db transaction as the change and relayed to downstream services
+CREATE TABLE crypto.outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    wallet_id UUID NOT NULL REFERENCES crypto.wallets(id),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP
+);
+
+-- the relay only scans unpublished events in id order
+CREATE INDEX idx_outbox_events_unpublished ON crypto.outbox_events(id) WHERE published_at IS NULL;
\ No newline at end of file
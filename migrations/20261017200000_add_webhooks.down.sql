@@ -0,0 +1,3 @@
+DROP TABLE crypto.webhook_deliveries;
+DROP TYPE crypto.webhook_delivery_status;
+DROP TABLE crypto.webhook_subscriptions;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- partner endpoints pushed the events of the subscribed types, of every wallet or of a single user's wallet
+CREATE TABLE crypto.webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types JSONB NOT NULL,
+    user_id UUID,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TYPE crypto.webhook_delivery_status AS ENUM ('pending', 'succeeded', 'dead');
+
+-- deliveries of outbox events to subscriptions, queued in the same db transaction as the event
+CREATE TABLE crypto.webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES crypto.webhook_subscriptions(id),
+    event_id BIGINT NOT NULL REFERENCES crypto.outbox_events(id),
+    status crypto.webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_status_code INT,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_subscriptions_active ON crypto.webhook_subscriptions(user_id) WHERE active;
+-- the dispatcher only scans pending deliveries by next attempt
+CREATE INDEX idx_webhook_deliveries_pending_next_attempt_at ON crypto.webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_subscription_id_created_at ON crypto.webhook_deliveries(subscription_id, created_at DESC);
\ No newline at end of file
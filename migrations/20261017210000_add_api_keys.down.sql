@@ -0,0 +1 @@
+DROP TABLE crypto.api_keys;
\ No newline at end of file
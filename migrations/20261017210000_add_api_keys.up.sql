@@ -0,0 +1,9 @@
+-- service-to-service API keys, only the hash of their secret is stored
+CREATE TABLE crypto.api_keys (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    scopes JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
\ No newline at end of file
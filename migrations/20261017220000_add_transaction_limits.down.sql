@@ -0,0 +1 @@
+DROP TABLE crypto.transaction_limits;
\ No newline at end of file
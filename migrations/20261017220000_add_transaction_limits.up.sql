@@ -0,0 +1,26 @@
+-- caps on the withdrawals and transfers a wallet initiates, amounts in the asset's minor unit and NULL for uncapped.
+-- Limits without a wallet are the defaults of every wallet, a wallet's own limit of the same type and asset overrides it.
+CREATE TABLE crypto.transaction_limits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID REFERENCES crypto.wallets(id),
+    type crypto.transaction_type NOT NULL CHECK (type IN ('withdraw', 'transfer')),
+    asset VARCHAR(10) NOT NULL,
+    per_transaction_amount BIGINT CHECK (per_transaction_amount >= 0),
+    daily_amount BIGINT CHECK (daily_amount >= 0),
+    daily_count BIGINT CHECK (daily_count >= 0),
+    weekly_amount BIGINT CHECK (weekly_amount >= 0),
+    weekly_count BIGINT CHECK (weekly_count >= 0),
+    monthly_amount BIGINT CHECK (monthly_amount >= 0),
+    monthly_count BIGINT CHECK (monthly_count >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_transaction_limits_default ON crypto.transaction_limits(type, asset) WHERE wallet_id IS NULL;
+CREATE UNIQUE INDEX idx_transaction_limits_wallet ON crypto.transaction_limits(wallet_id, type, asset) WHERE wallet_id IS NOT NULL;
+
+-- USD defaults: 10,000.00 per transaction, 25,000.00 and 50 a day, 100,000.00 and 200 a week, 250,000.00 and 500 a month
+INSERT INTO crypto.transaction_limits (type, asset, per_transaction_amount, daily_amount, daily_count, weekly_amount, weekly_count, monthly_amount, monthly_count)
+VALUES
+    ('withdraw', 'USD', 1000000, 2500000, 50, 10000000, 200, 25000000, 500),
+    ('transfer', 'USD', 1000000, 2500000, 50, 10000000, 200, 25000000, 500);
\ No newline at end of file
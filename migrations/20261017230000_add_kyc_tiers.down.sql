@@ -0,0 +1,9 @@
+DELETE FROM crypto.transaction_limits WHERE kyc_tier IS NOT NULL;
+
+DROP INDEX crypto.idx_transaction_limits_kyc_tier;
+DROP INDEX crypto.idx_transaction_limits_default;
+CREATE UNIQUE INDEX idx_transaction_limits_default ON crypto.transaction_limits(type, asset) WHERE wallet_id IS NULL;
+
+ALTER TABLE crypto.transaction_limits DROP COLUMN kyc_tier;
+ALTER TABLE crypto.wallets DROP COLUMN kyc_tier;
+DROP TYPE crypto.kyc_tier;
\ No newline at end of file
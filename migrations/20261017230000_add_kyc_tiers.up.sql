@@ -0,0 +1,19 @@
+CREATE TYPE crypto.kyc_tier AS ENUM ('unverified', 'basic', 'full');
+
+-- wallets opened before KYC tiers keep every capability they had, new wallets start unverified
+ALTER TABLE crypto.wallets ADD COLUMN kyc_tier crypto.kyc_tier NOT NULL DEFAULT 'full';
+ALTER TABLE crypto.wallets ALTER COLUMN kyc_tier SET DEFAULT 'unverified';
+
+-- limits of a tier are the defaults of its wallets, overriding the defaults of every wallet
+ALTER TABLE crypto.transaction_limits ADD COLUMN kyc_tier crypto.kyc_tier;
+ALTER TABLE crypto.transaction_limits ADD CONSTRAINT transaction_limits_wallet_or_kyc_tier CHECK (wallet_id IS NULL OR kyc_tier IS NULL);
+
+DROP INDEX crypto.idx_transaction_limits_default;
+CREATE UNIQUE INDEX idx_transaction_limits_default ON crypto.transaction_limits(type, asset) WHERE wallet_id IS NULL AND kyc_tier IS NULL;
+CREATE UNIQUE INDEX idx_transaction_limits_kyc_tier ON crypto.transaction_limits(kyc_tier, type, asset) WHERE kyc_tier IS NOT NULL;
+
+-- basic USD defaults: 1,000.00 per transaction, 2,000.00 and 10 a day, 5,000.00 and 30 a week, 10,000.00 and 60 a month
+INSERT INTO crypto.transaction_limits (kyc_tier, type, asset, per_transaction_amount, daily_amount, daily_count, weekly_amount, weekly_count, monthly_amount, monthly_count)
+VALUES
+    ('basic', 'withdraw', 'USD', 100000, 200000, 10, 500000, 30, 1000000, 60),
+    ('basic', 'transfer', 'USD', 100000, 200000, 10, 500000, 30, 1000000, 60);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE crypto.fee_tiers;
+ALTER TABLE crypto.transactions DROP COLUMN fee;
\ No newline at end of file
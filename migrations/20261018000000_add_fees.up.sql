@@ -0,0 +1,20 @@
+-- fee charged on top of the amount of a transaction, credited to the fees system account of the ledger
+ALTER TABLE crypto.transactions ADD COLUMN fee BIGINT NOT NULL DEFAULT 0 CHECK (fee >= 0);
+
+-- tiers of the fee schedule of a transaction type and asset, amounts in the asset's minor unit.
+-- A tier prices amounts from min_amount up to the next tier's: flat_fee plus basis_points of the amount,
+-- rounded up, then raised to min_fee and lowered to max_fee when set.
+CREATE TABLE crypto.fee_tiers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type crypto.transaction_type NOT NULL CHECK (type IN ('withdraw', 'transfer')),
+    asset VARCHAR(10) NOT NULL,
+    min_amount BIGINT NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    flat_fee BIGINT NOT NULL DEFAULT 0 CHECK (flat_fee >= 0),
+    basis_points BIGINT NOT NULL DEFAULT 0 CHECK (basis_points BETWEEN 0 AND 10000),
+    min_fee BIGINT CHECK (min_fee >= 0),
+    max_fee BIGINT CHECK (max_fee >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (min_fee <= max_fee)
+);
+
+CREATE UNIQUE INDEX idx_fee_tiers_type_asset_min_amount ON crypto.fee_tiers(type, asset, min_amount);
\ No newline at end of file
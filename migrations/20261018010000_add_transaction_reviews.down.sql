@@ -0,0 +1,21 @@
+-- enum values cannot be dropped, the types are recreated without them.
+-- This fails while transactions are pending review, blocked or rejected, rather than silently dropping them.
+DROP TABLE crypto.transaction_reviews;
+DROP TYPE crypto.review_decision;
+
+ALTER TABLE crypto.transactions DROP CONSTRAINT transactions_failure_reason_check;
+
+ALTER TYPE crypto.transaction_status RENAME TO transaction_status_old;
+CREATE TYPE crypto.transaction_status AS ENUM ('success', 'failed');
+ALTER TABLE crypto.transactions
+    ALTER COLUMN status TYPE crypto.transaction_status USING status::text::crypto.transaction_status;
+DROP TYPE crypto.transaction_status_old;
+
+ALTER TYPE crypto.transaction_failure_reason RENAME TO transaction_failure_reason_old;
+CREATE TYPE crypto.transaction_failure_reason AS ENUM ('insufficient_balance', 'wallet_frozen', 'wallet_closed', 'limit_exceeded');
+ALTER TABLE crypto.transactions
+    ALTER COLUMN failure_reason TYPE crypto.transaction_failure_reason USING failure_reason::text::crypto.transaction_failure_reason;
+DROP TYPE crypto.transaction_failure_reason_old;
+
+ALTER TABLE crypto.transactions ADD CONSTRAINT transactions_failure_reason_check
+    CHECK ((status = 'failed') = (failure_reason IS NOT NULL));
\ No newline at end of file
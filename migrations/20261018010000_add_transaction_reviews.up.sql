@@ -0,0 +1,18 @@
+ALTER TYPE crypto.transaction_status ADD VALUE 'pending_review';
+ALTER TYPE crypto.transaction_failure_reason ADD VALUE 'fraud_blocked';
+ALTER TYPE crypto.transaction_failure_reason ADD VALUE 'review_rejected';
+
+CREATE TYPE crypto.review_decision AS ENUM ('approved', 'rejected');
+
+-- withdrawals and transfers a fraud rule held for review, with the rule, until ops approve or reject them.
+-- Their amount and fee stay reserved in the initiator's held balance meanwhile.
+CREATE TABLE crypto.transaction_reviews (
+    transaction_id UUID PRIMARY KEY REFERENCES crypto.transactions(id),
+    rule TEXT NOT NULL,
+    decision crypto.review_decision,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMP,
+    CHECK ((decision IS NULL) = (decided_at IS NULL))
+);
+
+CREATE INDEX idx_transaction_reviews_pending_created_at ON crypto.transaction_reviews(created_at) WHERE decision IS NULL;
\ No newline at end of file
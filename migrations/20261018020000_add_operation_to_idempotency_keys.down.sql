@@ -0,0 +1,3 @@
+-- This fails while a user reused a key across operations, rather than silently dropping recorded responses.
+ALTER TABLE crypto.idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE crypto.idempotency_keys ADD PRIMARY KEY (scope, user_id, idempotency_key);
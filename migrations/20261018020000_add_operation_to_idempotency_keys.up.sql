@@ -0,0 +1,4 @@
+-- keys of users are unique per operation, like their redis cache keys, so a key is looked up in the same operation in
+-- redis and postgres. Keys of admins stay unique across admins with idx_idempotency_keys_admin_key.
+ALTER TABLE crypto.idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE crypto.idempotency_keys ADD PRIMARY KEY (scope, user_id, operation, idempotency_key);
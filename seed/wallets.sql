@@ -1,3 +1,24 @@
-INSERT INTO crypto.wallets (user_id, balance) VALUES 
-    ('59d8d8e6-452d-4f58-b090-1bb6e0dbb1ab', 100),
-    ('97889db9-9784-4018-aaf5-b8017197e6b5', 100);
+-- seeded users are fully verified so they can withdraw and transfer
+INSERT INTO crypto.wallets (user_id, kyc_tier) VALUES 
+    ('59d8d8e6-452d-4f58-b090-1bb6e0dbb1ab', 'full'),
+    ('97889db9-9784-4018-aaf5-b8017197e6b5', 'full');
+
+INSERT INTO crypto.balances (wallet_id, asset, balance)
+SELECT id, 'USD', 100 FROM crypto.wallets
+WHERE user_id IN ('59d8d8e6-452d-4f58-b090-1bb6e0dbb1ab', '97889db9-9784-4018-aaf5-b8017197e6b5');
+
+-- seeded balances are posted to the ledger as opening balances
+DO $$
+DECLARE
+    b RECORD;
+    j UUID;
+BEGIN
+    FOR b IN SELECT wallet_id, asset, balance FROM crypto.balances WHERE balance > 0 LOOP
+        INSERT INTO crypto.ledger_journals DEFAULT VALUES RETURNING id INTO j;
+        INSERT INTO crypto.ledger_entries (journal_id, wallet_id, asset, amount)
+        VALUES (j, b.wallet_id, b.asset, b.balance);
+        INSERT INTO crypto.ledger_entries (journal_id, system_account, asset, amount)
+        VALUES (j, 'opening_balance', b.asset, -b.balance);
+    END LOOP;
+END;
+$$;
\ No newline at end of file